@@ -2,6 +2,7 @@ package api
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"time"
@@ -92,6 +93,31 @@ func (s *Server) getCompetitiveBenchmark(c *gin.Context) {
 	})
 }
 
+// getShareOfVoiceTrends handles POST /api/v1/geo/analytics/trends
+func (s *Server) getShareOfVoiceTrends(c *gin.Context) {
+	var req models.ShareOfVoiceTrendsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		s.errorResponse(c, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+
+	trends, err := s.competitiveBenchmarkService.GetShareOfVoiceTrends(c.Request.Context(), &req)
+	if errors.Is(err, services.ErrInvalidTrendInterval) {
+		s.errorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		s.errorResponse(c, http.StatusInternalServerError, "Failed to get share of voice trends: "+err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    trends,
+		Message: "Share of voice trends retrieved successfully",
+	})
+}
+
 // getPositionAnalytics handles POST /api/v1/geo/analytics/position
 func (s *Server) getPositionAnalytics(c *gin.Context) {
 	var req struct {
@@ -134,26 +160,18 @@ func getPositionAnalyticsForBrand(
 ) (*models.PositionAnalyticsResponse, error) {
 	// Get logo service
 	logoService := services.NewLogoService(database)
-	// Fetch responses
+	// Fetch the brand's responses
 	filter := shared.ResponseFilter{
+		Brand:     brand,
 		StartTime: startTime,
 		EndTime:   endTime,
-		Limit:     10000,
 	}
 
-	allResponses, err := database.ListResponses(ctx, filter)
+	brandResponses, err := database.ListResponses(ctx, filter)
 	if err != nil {
 		return nil, err
 	}
 
-	// Filter for brand
-	var brandResponses []*models.Response
-	for _, resp := range allResponses {
-		if resp.Brand == brand {
-			brandResponses = append(brandResponses, resp)
-		}
-	}
-
 	if len(brandResponses) == 0 {
 		return &models.PositionAnalyticsResponse{
 			Brand:         brand,
@@ -182,15 +200,12 @@ func getPositionAnalyticsForBrand(
 			posKey := fmt.Sprintf("position_%d", resp.BrandPosition)
 			positionBreakdown[posKey]++
 
-			// Get prompt to determine type
-			prompt, err := database.GetPrompt(ctx, resp.PromptID)
-			if err == nil && prompt != nil {
-				promptType := string(prompt.PromptType)
-				if promptType == "" {
-					promptType = "unknown"
-				}
-				byPromptType[promptType] = append(byPromptType[promptType], float64(resp.BrandPosition))
+			// Prompt type snapshotted on the response
+			promptType := string(resp.PromptType)
+			if promptType == "" {
+				promptType = "unknown"
 			}
+			byPromptType[promptType] = append(byPromptType[promptType], float64(resp.BrandPosition))
 
 			// By LLM
 			byLLM[resp.LLMName] = append(byLLM[resp.LLMName], float64(resp.BrandPosition))
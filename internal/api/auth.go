@@ -0,0 +1,88 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/fissionx/gego/internal/models"
+	"github.com/fissionx/gego/internal/services"
+)
+
+// apiKeyContextKey is the gin context key of the authenticated API key
+const apiKeyContextKey = "apiKey"
+
+// DisableAuth lets every request through without an API key
+func (s *Server) DisableAuth() {
+	s.authRequired = false
+}
+
+// authenticate rejects requests without a valid bearer API key
+func (s *Server) authenticate() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !s.authRequired {
+			c.Next()
+			return
+		}
+
+		secret := bearerToken(c.GetHeader("Authorization"))
+		if secret == "" {
+			c.Header("WWW-Authenticate", `Bearer realm="gego"`)
+			s.abortWithError(c, http.StatusUnauthorized, "Missing API key. Send it as 'Authorization: Bearer <key>'")
+			return
+		}
+
+		key, err := s.apiKeyService.Authenticate(c.Request.Context(), secret)
+		if errors.Is(err, services.ErrInvalidAPIKey) {
+			c.Header("WWW-Authenticate", `Bearer realm="gego", error="invalid_token"`)
+			s.abortWithError(c, http.StatusUnauthorized, "Invalid or revoked API key")
+			return
+		}
+		if err != nil {
+			s.abortWithError(c, http.StatusInternalServerError, "Failed to authenticate: "+err.Error())
+			return
+		}
+
+		c.Set(apiKeyContextKey, key)
+		c.Next()
+	}
+}
+
+// requireScope rejects requests whose API key lacks the given scope
+func (s *Server) requireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !s.authRequired {
+			c.Next()
+			return
+		}
+
+		value, _ := c.Get(apiKeyContextKey)
+		key, ok := value.(*models.APIKey)
+		if !ok || !key.HasScope(scope) {
+			s.abortWithError(c, http.StatusForbidden, fmt.Sprintf("API key lacks the %q scope", scope))
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// abortWithError writes an error response and stops the handler chain
+func (s *Server) abortWithError(c *gin.Context, status int, message string) {
+	c.AbortWithStatusJSON(status, models.APIResponse{
+		Success: false,
+		Error:   message,
+	})
+}
+
+// bearerToken extracts the token of a "Bearer <token>" Authorization header
+func bearerToken(header string) string {
+	scheme, token, ok := strings.Cut(strings.TrimSpace(header), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
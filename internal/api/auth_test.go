@@ -0,0 +1,59 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/fissionx/gego/internal/models"
+)
+
+func TestAuthentication(t *testing.T) {
+	ctx := context.Background()
+	server, _ := newTestServer(t)
+
+	_, readSecret, err := server.apiKeyService.CreateKey(ctx, "dashboard", []string{models.APIKeyScopeRead})
+	if err != nil {
+		t.Fatal(err)
+	}
+	revoked, revokedSecret, err := server.apiKeyService.CreateKey(ctx, "old", models.APIKeyScopes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := server.apiKeyService.RevokeKey(ctx, revoked.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name          string
+		method        string
+		path          string
+		authorization string
+		want          int
+	}{
+		{"open health check", http.MethodGet, "/api/v1/health", "", http.StatusOK},
+		{"missing header", http.MethodGet, "/api/v1/prompts", "", http.StatusUnauthorized},
+		{"malformed bearer", http.MethodGet, "/api/v1/prompts", "Basic " + readSecret, http.StatusUnauthorized},
+		{"bearer without token", http.MethodGet, "/api/v1/prompts", "Bearer", http.StatusUnauthorized},
+		{"unknown key", http.MethodGet, "/api/v1/prompts", "Bearer gego_unknown", http.StatusUnauthorized},
+		{"revoked key", http.MethodGet, "/api/v1/prompts", "Bearer " + revokedSecret, http.StatusUnauthorized},
+		{"read key on a manage route", http.MethodPost, "/api/v1/prompts", "Bearer " + readSecret, http.StatusForbidden},
+		{"read key on an execute route", http.MethodPost, "/api/v1/execute", "Bearer " + readSecret, http.StatusForbidden},
+		{"read key on a read route", http.MethodGet, "/api/v1/prompts", "Bearer " + readSecret, http.StatusOK},
+		{"case-insensitive scheme", http.MethodGet, "/api/v1/prompts", "bearer " + readSecret, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := serve(server, tt.method, tt.path, tt.authorization, "")
+			if recorder.Code != tt.want {
+				t.Errorf("%s %s = %d %s, want %d", tt.method, tt.path, recorder.Code, recorder.Body.String(), tt.want)
+			}
+		})
+	}
+
+	// Without auth, the scopes are not checked either
+	server.DisableAuth()
+	if recorder := serve(server, http.MethodGet, "/api/v1/prompts", "", ""); recorder.Code != http.StatusOK {
+		t.Errorf("GET /api/v1/prompts without auth = %d, want 200", recorder.Code)
+	}
+}
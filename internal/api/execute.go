@@ -1,90 +1,158 @@
 package api
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
 	"log"
 	"net/http"
-	"regexp"
-	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 
 	"github.com/fissionx/gego/internal/llm"
-	"github.com/fissionx/gego/internal/llm/anthropic"
-	"github.com/fissionx/gego/internal/llm/google"
-	"github.com/fissionx/gego/internal/llm/ollama"
-	"github.com/fissionx/gego/internal/llm/openai"
-	"github.com/fissionx/gego/internal/llm/perplexity"
 	"github.com/fissionx/gego/internal/models"
 	"github.com/fissionx/gego/internal/services"
+	"github.com/fissionx/gego/internal/shared"
 )
 
-// geoJSONResponse represents the JSON structure returned by the LLM for GEO analysis
-type geoJSONResponse struct {
-	SearchAnswer string `json:"search_answer"`
-	GEOAnalysis  struct {
-		VisibilityScore    int      `json:"visibility_score"`
-		BrandMentioned     bool     `json:"brand_mentioned"`
-		InGroundingSources bool     `json:"in_grounding_sources"`
-		MentionStatus      string   `json:"mention_status"`
-		Reason             string   `json:"reason"`
-		Insights           []string `json:"insights"`
-		Actions            []string `json:"actions"`
-		CompetitorInfo     string   `json:"competitor_info"`
-		Competitors        []string `json:"competitors"`
-		Sentiment          string   `json:"sentiment"`
-	} `json:"geo_analysis"`
+// executeInput is a validated execute request with its resolved LLM
+type executeInput struct {
+	req         models.ExecuteRequest
+	llmConfig   *models.LLMConfig
+	provider    llm.Provider
+	temperature float64
+}
+
+// generationConfig returns the LLM generation settings of the request
+func (in *executeInput) generationConfig() llm.Config {
+	return llm.Config{
+		Model:       in.llmConfig.Model,
+		Temperature: in.temperature,
+		MaxTokens:   4096,
+		Brand:       in.req.Brand,
+		WebSearch:   llm.WebSearchEnabled(in.llmConfig),
+	}
 }
 
 // execute handles POST /api/v1/execute
 func (s *Server) execute(c *gin.Context) {
+	input := s.bindExecuteRequest(c)
+	if input == nil {
+		return
+	}
+
+	// Generate response from LLM
+	llmResponse, err := input.provider.Generate(c.Request.Context(), input.req.Prompt, input.generationConfig())
+	if err != nil {
+		s.errorResponse(c, http.StatusInternalServerError, "Failed to generate response: "+err.Error())
+		return
+	}
+
+	if llmResponse.Error != "" {
+		s.errorResponse(c, http.StatusInternalServerError, "LLM error: "+llmResponse.Error)
+		return
+	}
+
+	response, err := s.completeExecution(c.Request.Context(), input, llmResponse)
+	if err != nil {
+		s.errorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    response,
+		Message: "Prompt executed successfully",
+	})
+}
+
+// executeStream handles POST /api/v1/execute/stream. The answer is streamed as
+// server-sent "token" events, followed by an "analyzing" event while the GEO
+// analysis runs and a final "result" event with the stored execution.
+// Failures after the stream started are reported as an "error" event.
+func (s *Server) executeStream(c *gin.Context) {
+	input := s.bindExecuteRequest(c)
+	if input == nil {
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	send := func(event string, data interface{}) {
+		c.SSEvent(event, data)
+		c.Writer.Flush()
+	}
+
+	llmResponse, err := llm.GenerateStream(ctx, input.provider, input.req.Prompt, input.generationConfig(), func(chunk string) error {
+		send("token", gin.H{"text": chunk})
+		return ctx.Err()
+	})
+	if err != nil {
+		send("error", gin.H{"error": "Failed to generate response: " + err.Error()})
+		return
+	}
+
+	if llmResponse.Error != "" {
+		send("error", gin.H{"error": "LLM error: " + llmResponse.Error})
+		return
+	}
+
+	if input.req.Brand != "" {
+		send("analyzing", gin.H{"brand": input.req.Brand})
+	}
+
+	response, err := s.completeExecution(ctx, input, llmResponse)
+	if err != nil {
+		send("error", gin.H{"error": err.Error()})
+		return
+	}
+
+	send("result", response)
+}
+
+// bindExecuteRequest validates an execute request and resolves its LLM provider.
+// On failure the error response is written and nil is returned.
+func (s *Server) bindExecuteRequest(c *gin.Context) *executeInput {
 	var req models.ExecuteRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		s.errorResponse(c, http.StatusBadRequest, "Invalid request: "+err.Error())
-		return
+		return nil
 	}
 
 	if len(req.Prompt) < 1 {
 		s.errorResponse(c, http.StatusBadRequest, "Prompt cannot be empty")
-		return
+		return nil
 	}
 
 	if len(req.Prompt) > 10000 {
 		s.errorResponse(c, http.StatusBadRequest, "Prompt too long (max 10000 characters)")
-		return
+		return nil
 	}
 
 	// Get the LLM configuration
 	llmConfig, err := s.llmService.GetLLM(c.Request.Context(), req.LLMID)
 	if err != nil {
 		s.errorResponse(c, http.StatusNotFound, "LLM not found: "+err.Error())
-		return
+		return nil
 	}
 
 	if !llmConfig.Enabled {
 		s.errorResponse(c, http.StatusBadRequest, "LLM is disabled")
-		return
+		return nil
 	}
 
-	// Create the LLM provider with the specific API key from config
-	var provider llm.Provider
-	switch llmConfig.Provider {
-	case "openai":
-		provider = openai.New(llmConfig.APIKey, llmConfig.BaseURL)
-	case "anthropic":
-		provider = anthropic.New(llmConfig.APIKey, llmConfig.BaseURL)
-	case "ollama":
-		provider = ollama.New(llmConfig.BaseURL)
-	case "google":
-		provider = google.New(llmConfig.APIKey, llmConfig.BaseURL)
-	case "perplexity":
-		provider = perplexity.New(llmConfig.APIKey, llmConfig.BaseURL)
-	default:
-		s.errorResponse(c, http.StatusInternalServerError, "Unknown LLM provider: "+llmConfig.Provider)
-		return
+	// Resolve the provider built with this config's API key and base URL
+	provider, err := s.llmFactory.Get(llmConfig)
+	if err != nil {
+		s.errorResponse(c, http.StatusInternalServerError, err.Error())
+		return nil
 	}
 
 	// Set default temperature if not provided
@@ -96,37 +164,35 @@ func (s *Server) execute(c *gin.Context) {
 	// Validate temperature
 	if temperature < 0 || temperature > 2 {
 		s.errorResponse(c, http.StatusBadRequest, "Temperature must be between 0 and 2")
-		return
+		return nil
 	}
 
-	// Generate response from LLM
-	llmResponse, err := provider.Generate(c.Request.Context(), req.Prompt, llm.Config{
-		Model:       llmConfig.Model,
-		Temperature: temperature,
-		MaxTokens:   4096,
-		Brand:       req.Brand,
-	})
-	if err != nil {
-		s.errorResponse(c, http.StatusInternalServerError, "Failed to generate response: "+err.Error())
-		return
+	return &executeInput{
+		req:         req,
+		llmConfig:   llmConfig,
+		provider:    provider,
+		temperature: temperature,
 	}
+}
 
-	if llmResponse.Error != "" {
-		s.errorResponse(c, http.StatusInternalServerError, "LLM error: "+llmResponse.Error)
-		return
-	}
+// completeExecution runs the GEO analysis on an LLM answer, stores the response
+// and returns the execution result
+func (s *Server) completeExecution(ctx context.Context, input *executeInput, llmResponse *llm.Response) (*models.ExecuteResponse, error) {
+	req := input.req
+	llmConfig := input.llmConfig
+	temperature := input.temperature
 
 	var promptID string
+	prompt := &models.Prompt{
+		ID:       uuid.New().String(),
+		Template: req.Prompt,
+		Tags:     req.Tags,
+		Enabled:  true,
+	}
 
 	// Optionally save the prompt
 	if req.SavePrompt {
-		prompt := &models.Prompt{
-			ID:       uuid.New().String(),
-			Template: req.Prompt,
-			Tags:     req.Tags,
-			Enabled:  true,
-		}
-		if err := s.promptService.CreatePrompt(c.Request.Context(), prompt); err != nil {
+		if err := s.promptService.CreatePrompt(ctx, prompt); err != nil {
 			// Log but don't fail the request
 			// Just continue without saving the prompt
 		} else {
@@ -134,63 +200,14 @@ func (s *Server) execute(c *gin.Context) {
 		}
 	}
 
-	// Parse GEO analysis if brand was provided
-	var geoAnalysis *models.GEOAnalysis
-	responseText := llmResponse.Text
+	// Run the GEO analysis stage if brand was provided
+	var geoResult *shared.GEOAnalysisResult
 
 	if req.Brand != "" {
-		// Try to parse the JSON response
-		var geoResponse geoJSONResponse
-		
-		// Clean up the response - remove markdown code blocks if present
-		cleanedText := strings.TrimSpace(llmResponse.Text)
-		
-		// Remove markdown code block wrappers (```json ... ``` or ``` ... ```)
-		jsonBlockRegex := regexp.MustCompile("(?s)```(?:json)?\\s*(.+?)\\s*```")
-		if matches := jsonBlockRegex.FindStringSubmatch(cleanedText); len(matches) > 1 {
-			cleanedText = strings.TrimSpace(matches[1])
-		} else {
-			// Try simple prefix/suffix removal
-			cleanedText = strings.TrimPrefix(cleanedText, "```json")
-			cleanedText = strings.TrimPrefix(cleanedText, "```")
-			cleanedText = strings.TrimSuffix(cleanedText, "```")
-			cleanedText = strings.TrimSpace(cleanedText)
-		}
-
-		// Try to find JSON object in the text if it's mixed with other content
-		if !strings.HasPrefix(cleanedText, "{") {
-			jsonStartIdx := strings.Index(cleanedText, "{")
-			jsonEndIdx := strings.LastIndex(cleanedText, "}")
-			if jsonStartIdx != -1 && jsonEndIdx != -1 && jsonEndIdx > jsonStartIdx {
-				cleanedText = cleanedText[jsonStartIdx : jsonEndIdx+1]
-			}
-		}
-
-		log.Printf("========== ATTEMPTING TO PARSE GEO JSON ==========")
-		log.Printf("Cleaned text (first 500 chars): %s", truncateString(cleanedText, 500))
-
-		if err := json.Unmarshal([]byte(cleanedText), &geoResponse); err == nil {
-			// Successfully parsed JSON
-			log.Printf("✅ Successfully parsed GEO JSON response")
-			responseText = geoResponse.SearchAnswer
-			geoAnalysis = &models.GEOAnalysis{
-				VisibilityScore:    geoResponse.GEOAnalysis.VisibilityScore,
-				BrandMentioned:     geoResponse.GEOAnalysis.BrandMentioned,
-				InGroundingSources: geoResponse.GEOAnalysis.InGroundingSources,
-				MentionStatus:      geoResponse.GEOAnalysis.MentionStatus,
-				Reason:             geoResponse.GEOAnalysis.Reason,
-				Insights:           geoResponse.GEOAnalysis.Insights,
-				Actions:            geoResponse.GEOAnalysis.Actions,
-				CompetitorInfo:     geoResponse.GEOAnalysis.CompetitorInfo,
-				Competitors:        geoResponse.GEOAnalysis.Competitors,
-				Sentiment:          geoResponse.GEOAnalysis.Sentiment,
-			}
-			log.Printf("GEO Analysis: Score=%d, Mentioned=%v, Grounded=%v, Sentiment=%s", 
-				geoAnalysis.VisibilityScore, geoAnalysis.BrandMentioned, 
-				geoAnalysis.InGroundingSources, geoAnalysis.Sentiment)
-		} else {
-			log.Printf("❌ Failed to parse GEO JSON: %v", err)
-			log.Printf("Raw response kept as-is")
+		var err error
+		geoResult, err = s.geoAnalysisService.Analyze(ctx, req.Prompt, req.Brand, llmResponse, llmConfig)
+		if err != nil {
+			log.Printf("GEO analysis failed for brand %s: %v", req.Brand, err)
 		}
 	}
 
@@ -210,73 +227,45 @@ func (s *Server) execute(c *gin.Context) {
 		LatencyMs:    llmResponse.LatencyMs,
 		CreatedAt:    time.Now(),
 	}
+	services.ApplyPromptMetadata(responseModel, prompt)
+	services.ApplyCitations(responseModel, llmResponse)
 
-	// Add GEO metrics if available
-	if geoAnalysis != nil {
-		responseModel.VisibilityScore = geoAnalysis.VisibilityScore
-		responseModel.BrandMentioned = geoAnalysis.BrandMentioned
-		responseModel.InGroundingSources = geoAnalysis.InGroundingSources
-		responseModel.Sentiment = geoAnalysis.Sentiment
-		responseModel.CompetitorsMention = geoAnalysis.Competitors
-		responseModel.GroundingSources = llmResponse.GroundingSources
-		
-		// NEW: Extract position/ranking from response
-		if req.Brand != "" && geoAnalysis.BrandMentioned {
-			position, totalBrands := services.ExtractBrandPosition(responseText, req.Brand)
-			responseModel.BrandPosition = position
-			responseModel.TotalBrandsListed = totalBrands
-		}
-		
-		// NEW: Extract domains from grounding sources
-		if len(llmResponse.GroundingSources) > 0 {
-			responseModel.GroundingDomains = services.ExtractDomainsFromSources(llmResponse.GroundingSources)
+	if req.Brand != "" {
+		matcher := s.brandProfileService.GetMatcher(ctx, req.Brand)
+
+		// Add GEO metrics if available
+		if geoResult != nil {
+			services.ApplyGEOAnalysis(responseModel, geoResult, llmResponse.GroundingSources, matcher)
 		}
+
+		// Rule-based baseline alongside the LLM judgement
+		services.ApplyBrandExtraction(responseModel, responseModel.ResponseText, matcher)
 	}
-	
-	// NEW: Add time-series fields
-	now := time.Now()
-	responseModel.Week = now.Format("2006-W02")
-	responseModel.Month = now.Format("2006-01")
-	quarter := (int(now.Month()) - 1) / 3 + 1
-	responseModel.Quarter = fmt.Sprintf("%d-Q%d", now.Year(), quarter)
-	
+
 	// NEW: Add region/language if provided
 	responseModel.Region = req.Region
 	responseModel.Language = req.Language
 
-	if err := s.db.CreateResponse(c.Request.Context(), responseModel); err != nil {
-		s.errorResponse(c, http.StatusInternalServerError, "Failed to save response: "+err.Error())
-		return
+	if err := s.db.CreateResponse(ctx, responseModel); err != nil {
+		return nil, fmt.Errorf("failed to save response: %w", err)
 	}
 
-	response := models.ExecuteResponse{
-		ResponseID:  responseModel.ID,
-		PromptID:    promptID,
-		Prompt:      req.Prompt,
-		Brand:       req.Brand,
-		Response:    responseText,
-		GEOAnalysis: geoAnalysis,
-		LLMName:     llmConfig.Name,
-		LLMProvider: llmConfig.Provider,
-		LLMModel:    llmConfig.Model,
-		Temperature: temperature,
-		TokensUsed:  llmResponse.TokensUsed,
-		LatencyMs:   llmResponse.LatencyMs,
-		CreatedAt:   responseModel.CreatedAt,
+	response := &models.ExecuteResponse{
+		ResponseID:      responseModel.ID,
+		PromptID:        promptID,
+		Prompt:          req.Prompt,
+		Brand:           req.Brand,
+		Response:        responseModel.ResponseText,
+		GEOAnalysis:     responseModel.Analysis,
+		BrandExtraction: responseModel.BrandExtraction,
+		LLMName:         llmConfig.Name,
+		LLMProvider:     llmConfig.Provider,
+		LLMModel:        llmConfig.Model,
+		Temperature:     temperature,
+		TokensUsed:      llmResponse.TokensUsed,
+		LatencyMs:       llmResponse.LatencyMs,
+		CreatedAt:       responseModel.CreatedAt,
 	}
 
-	c.JSON(http.StatusOK, models.APIResponse{
-		Success: true,
-		Data:    response,
-		Message: "Prompt executed successfully",
-	})
+	return response, nil
 }
-
-// truncateString truncates a string to maxLen characters
-func truncateString(s string, maxLen int) string {
-	if len(s) <= maxLen {
-		return s
-	}
-	return s[:maxLen] + "..."
-}
-
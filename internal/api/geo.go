@@ -1,7 +1,9 @@
 package api
 
 import (
+	"context"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 
@@ -27,7 +29,7 @@ func (s *Server) generatePrompts(c *gin.Context) {
 	}
 
 	// Create prompt generation service
-	promptGenService := services.NewPromptGenerationService(s.db, s.llmRegistry)
+	promptGenService := services.NewPromptGenerationService(s.db, s.llmFactory)
 
 	// Generate prompts with optional website scraping
 	prompts, existingCount, generatedCount, err := promptGenService.GeneratePromptsForBrand(
@@ -104,17 +106,15 @@ func (s *Server) bulkExecute(c *gin.Context) {
 		return
 	}
 
-	// Create bulk execution service
-	bulkService := services.NewBulkExecutionService(s.db, s.llmRegistry)
-
 	// Start campaign execution
-	campaign, err := bulkService.ExecuteCampaign(
+	campaign, err := s.bulkExecutionService.ExecuteCampaign(
 		c.Request.Context(),
 		req.CampaignName,
 		req.Brand,
 		req.PromptIDs,
 		req.LLMIDs,
 		req.Temperature,
+		req.Variables,
 	)
 	if err != nil {
 		s.errorResponse(c, http.StatusInternalServerError, "Failed to start campaign: "+err.Error())
@@ -138,6 +138,88 @@ func (s *Server) bulkExecute(c *gin.Context) {
 	})
 }
 
+// listCampaigns handles GET /api/v1/geo/campaigns
+// Query params: brand
+func (s *Server) listCampaigns(c *gin.Context) {
+	campaigns, err := s.bulkExecutionService.ListCampaigns(c.Request.Context(), c.Query("brand"))
+	if err != nil {
+		s.errorResponse(c, http.StatusInternalServerError, "Failed to list campaigns: "+err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    campaigns,
+		Message: "Campaigns retrieved successfully",
+	})
+}
+
+// getCampaign handles GET /api/v1/geo/campaigns/:id
+func (s *Server) getCampaign(c *gin.Context) {
+	campaign, err := s.bulkExecutionService.GetCampaign(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		s.errorResponse(c, http.StatusNotFound, "Campaign not found: "+err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    campaign,
+		Message: "Campaign retrieved successfully",
+	})
+}
+
+// cancelCampaign handles POST /api/v1/geo/campaigns/:id/cancel
+func (s *Server) cancelCampaign(c *gin.Context) {
+	campaign, err := s.bulkExecutionService.CancelCampaign(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		s.errorResponse(c, http.StatusBadRequest, "Failed to cancel campaign: "+err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    campaign,
+		Message: "Campaign cancelled",
+	})
+}
+
+// retryFailedCampaignRuns handles POST /api/v1/geo/campaigns/:id/retry
+func (s *Server) retryFailedCampaignRuns(c *gin.Context) {
+	campaign, err := s.bulkExecutionService.RetryFailedRuns(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		s.errorResponse(c, http.StatusBadRequest, "Failed to retry campaign: "+err.Error())
+		return
+	}
+
+	c.JSON(http.StatusAccepted, models.APIResponse{
+		Success: true,
+		Data:    campaign,
+		Message: "Retrying failed campaign runs",
+	})
+}
+
+// listJobs handles GET /api/v1/geo/jobs
+// Query params: status (pending, leased, done, dead, cancelled), limit
+func (s *Server) listJobs(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if limit <= 0 || limit > 1000 {
+		limit = 100
+	}
+
+	jobs, err := s.jobQueue.ListJobs(c.Request.Context(), c.Query("status"), limit)
+	if err != nil {
+		s.errorResponse(c, http.StatusInternalServerError, "Failed to list jobs: "+err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    jobs,
+		Message: "Jobs retrieved successfully",
+	})
+}
+
 // getGEOInsights handles POST /api/v1/geo/insights
 func (s *Server) getGEOInsights(c *gin.Context) {
 	var req models.GEOInsightsRequest
@@ -158,6 +240,7 @@ func (s *Server) getGEOInsights(c *gin.Context) {
 	insights, err := analyticsService.GetGEOInsights(
 		c.Request.Context(),
 		req.Brand,
+		req.CampaignID,
 		req.StartTime,
 		req.EndTime,
 	)
@@ -228,3 +311,45 @@ func (s *Server) getBrandProfile(c *gin.Context) {
 		Message: "Brand profile retrieved successfully",
 	})
 }
+
+// setBrandEntities handles PUT /api/v1/geo/profiles/:brand/entities
+func (s *Server) setBrandEntities(c *gin.Context) {
+	s.updateBrandEntities(c, s.brandProfileService.SetEntities)
+}
+
+// addBrandEntities handles POST /api/v1/geo/profiles/:brand/entities
+func (s *Server) addBrandEntities(c *gin.Context) {
+	s.updateBrandEntities(c, s.brandProfileService.AddEntities)
+}
+
+// removeBrandEntities handles DELETE /api/v1/geo/profiles/:brand/entities
+func (s *Server) removeBrandEntities(c *gin.Context) {
+	s.updateBrandEntities(c, s.brandProfileService.RemoveEntities)
+}
+
+// updateBrandEntities binds an entity dictionary request and applies it with the given update
+func (s *Server) updateBrandEntities(c *gin.Context, update func(context.Context, string, models.BrandEntitiesRequest) (*models.BrandProfile, error)) {
+	brandName := c.Param("brand")
+	if brandName == "" {
+		s.errorResponse(c, http.StatusBadRequest, "Brand name is required")
+		return
+	}
+
+	var req models.BrandEntitiesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		s.errorResponse(c, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+
+	profile, err := update(c.Request.Context(), brandName, req)
+	if err != nil {
+		s.errorResponse(c, http.StatusBadRequest, "Failed to update brand entities: "+err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    profile,
+		Message: "Brand entities updated successfully",
+	})
+}
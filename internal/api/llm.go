@@ -3,11 +3,14 @@ package api
 import (
 	"net/http"
 	"slices"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 
 	"github.com/fissionx/gego/internal/models"
+	"github.com/fissionx/gego/internal/secrets"
+	"github.com/fissionx/gego/internal/services"
 	"github.com/fissionx/gego/internal/shared"
 )
 
@@ -23,18 +26,7 @@ func (s *Server) listLLMs(c *gin.Context) {
 
 	responses := make([]models.LLMResponse, len(llms))
 	for i, llm := range llms {
-		responses[i] = models.LLMResponse{
-			ID:        llm.ID,
-			Name:      llm.Name,
-			Provider:  llm.Provider,
-			Model:     llm.Model,
-			APIKey:    s.maskAPIKey(llm.APIKey),
-			BaseURL:   llm.BaseURL,
-			Config:    llm.Config,
-			Enabled:   llm.Enabled,
-			CreatedAt: llm.CreatedAt,
-			UpdatedAt: llm.UpdatedAt,
-		}
+		responses[i] = s.llmResponse(llm)
 	}
 
 	s.successResponse(c, responses)
@@ -50,18 +42,7 @@ func (s *Server) getLLM(c *gin.Context) {
 		return
 	}
 
-	response := models.LLMResponse{
-		ID:        llm.ID,
-		Name:      llm.Name,
-		Provider:  llm.Provider,
-		Model:     llm.Model,
-		APIKey:    s.maskAPIKey(llm.APIKey),
-		BaseURL:   llm.BaseURL,
-		Config:    llm.Config,
-		Enabled:   llm.Enabled,
-		CreatedAt: llm.CreatedAt,
-		UpdatedAt: llm.UpdatedAt,
-	}
+	response := s.llmResponse(llm)
 
 	s.successResponse(c, response)
 }
@@ -75,7 +56,7 @@ func (s *Server) createLLM(c *gin.Context) {
 	}
 
 	if !s.isValidProvider(req.Provider) {
-		s.errorResponse(c, http.StatusBadRequest, "Invalid provider. Must be one of: openai, anthropic, ollama, google, perplexity")
+		s.errorResponse(c, http.StatusBadRequest, "Invalid provider. Must be one of: "+strings.Join(s.llmFactory.List(), ", "))
 		return
 	}
 
@@ -95,18 +76,7 @@ func (s *Server) createLLM(c *gin.Context) {
 		return
 	}
 
-	response := models.LLMResponse{
-		ID:        llm.ID,
-		Name:      llm.Name,
-		Provider:  llm.Provider,
-		Model:     llm.Model,
-		APIKey:    s.maskAPIKey(llm.APIKey),
-		BaseURL:   llm.BaseURL,
-		Config:    llm.Config,
-		Enabled:   llm.Enabled,
-		CreatedAt: llm.CreatedAt,
-		UpdatedAt: llm.UpdatedAt,
-	}
+	response := s.llmResponse(llm)
 
 	c.JSON(http.StatusCreated, models.APIResponse{
 		Success: true,
@@ -136,7 +106,7 @@ func (s *Server) updateLLM(c *gin.Context) {
 	}
 	if req.Provider != "" {
 		if !s.isValidProvider(req.Provider) {
-			s.errorResponse(c, http.StatusBadRequest, "Invalid provider. Must be one of: openai, anthropic, ollama, google, perplexity")
+			s.errorResponse(c, http.StatusBadRequest, "Invalid provider. Must be one of: "+strings.Join(s.llmFactory.List(), ", "))
 			return
 		}
 		llm.Provider = req.Provider
@@ -151,6 +121,7 @@ func (s *Server) updateLLM(c *gin.Context) {
 		llm.BaseURL = req.BaseURL
 	}
 	if req.Config != nil {
+		services.UnmaskLLMConfig(req.Config, llm.Config)
 		llm.Config = req.Config
 	}
 	if req.Enabled != nil {
@@ -162,18 +133,7 @@ func (s *Server) updateLLM(c *gin.Context) {
 		return
 	}
 
-	response := models.LLMResponse{
-		ID:        llm.ID,
-		Name:      llm.Name,
-		Provider:  llm.Provider,
-		Model:     llm.Model,
-		APIKey:    s.maskAPIKey(llm.APIKey),
-		BaseURL:   llm.BaseURL,
-		Config:    llm.Config,
-		Enabled:   llm.Enabled,
-		CreatedAt: llm.CreatedAt,
-		UpdatedAt: llm.UpdatedAt,
-	}
+	response := s.llmResponse(llm)
 
 	s.successResponse(c, response)
 }
@@ -194,15 +154,36 @@ func (s *Server) deleteLLM(c *gin.Context) {
 }
 
 // Helper functions for LLM endpoints
+
+// llmResponse converts an LLM configuration to its API representation, with
+// the API key and secret config options masked
+func (s *Server) llmResponse(llm *models.LLMConfig) models.LLMResponse {
+	return models.LLMResponse{
+		ID:        llm.ID,
+		Name:      llm.Name,
+		Provider:  llm.Provider,
+		Model:     llm.Model,
+		APIKey:    s.maskAPIKey(llm.APIKey),
+		BaseURL:   llm.BaseURL,
+		Config:    services.MaskLLMConfig(llm.Config),
+		Enabled:   llm.Enabled,
+		CreatedAt: llm.CreatedAt,
+		UpdatedAt: llm.UpdatedAt,
+	}
+}
+
+// isValidProvider reports whether a provider is registered with the LLM factory
 func (s *Server) isValidProvider(provider string) bool {
-	validProviders := []string{"openai", "anthropic", "ollama", "google", "perplexity"}
-	return slices.Contains(validProviders, provider)
+	return slices.Contains(s.llmFactory.List(), provider)
 }
 
 func (s *Server) maskAPIKey(apiKey string) string {
 	if apiKey == "" {
 		return ""
 	}
+	if secrets.IsReference(apiKey) {
+		return apiKey
+	}
 	if len(apiKey) <= 8 {
 		return "***"
 	}
@@ -0,0 +1,87 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"maps"
+	"net/http"
+	"testing"
+
+	"github.com/fissionx/gego/internal/models"
+)
+
+func TestLLMConfigMasking(t *testing.T) {
+	ctx := context.Background()
+	server, database := newTestServer(t)
+	server.DisableAuth()
+
+	stored := map[string]string{
+		"header.X-Api-Key": "gw-secret-123456",
+		"header.X-Title":   "env:GATEWAY_TITLE",
+		"auth_header":      "api-key",
+		"query.version":    "2024-10-21",
+	}
+	llm := &models.LLMConfig{ID: "gateway", Name: "Gateway", Provider: "openai-compatible", Model: "llama3",
+		APIKey: "sk-gateway-123456", BaseURL: "http://localhost:8000/v1", Config: maps.Clone(stored), Enabled: true}
+	if err := database.CreateLLM(ctx, llm); err != nil {
+		t.Fatalf("CreateLLM() error = %v", err)
+	}
+
+	masked := map[string]string{
+		"header.X-Api-Key": "gw-s...3456",
+		"header.X-Title":   "env:GATEWAY_TITLE",
+		"auth_header":      "***",
+		"query.version":    "2024-10-21",
+	}
+	read := func(method, path, body string) models.LLMResponse {
+		t.Helper()
+		recorder := serve(server, method, path, "", body)
+		if recorder.Code != http.StatusOK {
+			t.Fatalf("%s %s = %d %s", method, path, recorder.Code, recorder.Body.String())
+		}
+		var envelope struct {
+			Data json.RawMessage `json:"data"`
+		}
+		if err := json.Unmarshal(recorder.Body.Bytes(), &envelope); err != nil {
+			t.Fatal(err)
+		}
+		var responses []models.LLMResponse
+		if json.Unmarshal(envelope.Data, &responses) == nil {
+			if len(responses) != 1 {
+				t.Fatalf("%s %s = %d LLMs, want 1", method, path, len(responses))
+			}
+			return responses[0]
+		}
+		var response models.LLMResponse
+		if err := json.Unmarshal(envelope.Data, &response); err != nil {
+			t.Fatal(err)
+		}
+		return response
+	}
+
+	for _, path := range []string{"/api/v1/llms", "/api/v1/llms/gateway"} {
+		response := read(http.MethodGet, path, "")
+		if !maps.Equal(response.Config, masked) || response.APIKey != "sk-g...3456" {
+			t.Errorf("GET %s = key %s, config %v; want masked", path, response.APIKey, response.Config)
+		}
+	}
+
+	// A config read back masked keeps the stored secrets
+	update, _ := json.Marshal(models.UpdateLLMRequest{Config: map[string]string{
+		"header.X-Api-Key": masked["header.X-Api-Key"],
+		"header.X-Title":   masked["header.X-Title"],
+		"auth_header":      masked["auth_header"],
+		"query.version":    "2025-01-01",
+	}})
+	if response := read(http.MethodPut, "/api/v1/llms/gateway", string(update)); response.Config["query.version"] != "2025-01-01" {
+		t.Errorf("PUT config = %v", response.Config)
+	}
+	updated, err := database.GetLLM(ctx, "gateway")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stored["query.version"] = "2025-01-01"
+	if !maps.Equal(updated.Config, stored) {
+		t.Errorf("stored config = %v, want %v", updated.Config, stored)
+	}
+}
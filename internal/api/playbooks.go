@@ -0,0 +1,59 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/fissionx/gego/internal/models"
+	"github.com/fissionx/gego/internal/services"
+)
+
+// listPlaybooks handles GET /api/v1/geo/playbooks
+func (s *Server) listPlaybooks(c *gin.Context) {
+	s.successResponse(c, s.recommendationsEngine.Playbooks(c.Query("brand")))
+}
+
+// dryRunPlaybook handles POST /api/v1/geo/playbooks/dry-run. It evaluates
+// the playbook of a brand against its source and position analytics, and
+// its competitive benchmark when competitors are given.
+func (s *Server) dryRunPlaybook(c *gin.Context) {
+	var req models.PlaybookDryRunRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		s.errorResponse(c, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+
+	ctx := c.Request.Context()
+	var inputs services.PlaybookInputs
+	var err error
+
+	inputs.Sources, err = s.sourceAnalyticsService.GetSourceAnalytics(ctx, req.Brand, req.StartTime, req.EndTime, 20)
+	if err != nil {
+		s.errorResponse(c, http.StatusInternalServerError, "Failed to get source analytics: "+err.Error())
+		return
+	}
+
+	if len(req.Competitors) > 0 {
+		inputs.Competitive, err = s.competitiveBenchmarkService.GetCompetitiveBenchmark(
+			ctx, req.Brand, req.Competitors, nil, nil, req.StartTime, req.EndTime, "")
+		if err != nil {
+			s.errorResponse(c, http.StatusInternalServerError, "Failed to get competitive benchmark: "+err.Error())
+			return
+		}
+	}
+
+	inputs.Position, err = getPositionAnalyticsForBrand(ctx, s.db, req.Brand, req.StartTime, req.EndTime)
+	if err != nil {
+		s.errorResponse(c, http.StatusInternalServerError, "Failed to get position analytics: "+err.Error())
+		return
+	}
+
+	result, err := s.recommendationsEngine.DryRun(req.Brand, req.Rules, inputs)
+	if err != nil {
+		s.errorResponse(c, http.StatusBadRequest, "Invalid rules: "+err.Error())
+		return
+	}
+
+	s.successResponse(c, result)
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/google/uuid"
 
 	"github.com/fissionx/gego/internal/models"
+	"github.com/fissionx/gego/internal/services"
 	"github.com/fissionx/gego/internal/shared"
 )
 
@@ -46,6 +47,7 @@ func (s *Server) listSchedules(c *gin.Context) {
 			LLMIDs:      schedule.LLMIDs,
 			CronExpr:    schedule.CronExpr,
 			Temperature: schedule.Temperature,
+			Variables:   schedule.Variables,
 			Enabled:     schedule.Enabled,
 			LastRun:     schedule.LastRun,
 			NextRun:     schedule.NextRun,
@@ -84,6 +86,7 @@ func (s *Server) getSchedule(c *gin.Context) {
 		LLMIDs:      schedule.LLMIDs,
 		CronExpr:    schedule.CronExpr,
 		Temperature: schedule.Temperature,
+		Variables:   schedule.Variables,
 		Enabled:     schedule.Enabled,
 		LastRun:     schedule.LastRun,
 		NextRun:     schedule.NextRun,
@@ -129,7 +132,7 @@ func (s *Server) createSchedule(c *gin.Context) {
 		return
 	}
 
-	if err := s.validateScheduleReferences(c.Request.Context(), req.PromptIDs, req.LLMIDs); err != nil {
+	if err := s.validateScheduleReferences(c.Request.Context(), req.PromptIDs, req.LLMIDs, req.Variables); err != nil {
 		s.errorResponse(c, http.StatusBadRequest, err.Error())
 		return
 	}
@@ -141,6 +144,7 @@ func (s *Server) createSchedule(c *gin.Context) {
 		LLMIDs:      req.LLMIDs,
 		CronExpr:    req.CronExpr,
 		Temperature: req.Temperature,
+		Variables:   req.Variables,
 		Enabled:     req.Enabled,
 	}
 
@@ -156,6 +160,7 @@ func (s *Server) createSchedule(c *gin.Context) {
 		LLMIDs:      schedule.LLMIDs,
 		CronExpr:    schedule.CronExpr,
 		Temperature: schedule.Temperature,
+		Variables:   schedule.Variables,
 		Enabled:     schedule.Enabled,
 		LastRun:     schedule.LastRun,
 		NextRun:     schedule.NextRun,
@@ -221,12 +226,15 @@ func (s *Server) updateSchedule(c *gin.Context) {
 		}
 		schedule.Temperature = *req.Temperature
 	}
+	if req.Variables != nil {
+		schedule.Variables = req.Variables
+	}
 	if req.Enabled != nil {
 		schedule.Enabled = *req.Enabled
 	}
 
-	if req.PromptIDs != nil || req.LLMIDs != nil {
-		if err := s.validateScheduleReferences(c.Request.Context(), schedule.PromptIDs, schedule.LLMIDs); err != nil {
+	if req.PromptIDs != nil || req.LLMIDs != nil || req.Variables != nil {
+		if err := s.validateScheduleReferences(c.Request.Context(), schedule.PromptIDs, schedule.LLMIDs, schedule.Variables); err != nil {
 			s.errorResponse(c, http.StatusBadRequest, err.Error())
 			return
 		}
@@ -244,6 +252,7 @@ func (s *Server) updateSchedule(c *gin.Context) {
 		LLMIDs:      schedule.LLMIDs,
 		CronExpr:    schedule.CronExpr,
 		Temperature: schedule.Temperature,
+		Variables:   schedule.Variables,
 		Enabled:     schedule.Enabled,
 		LastRun:     schedule.LastRun,
 		NextRun:     schedule.NextRun,
@@ -270,11 +279,19 @@ func (s *Server) deleteSchedule(c *gin.Context) {
 }
 
 // validateScheduleReferences validates that all referenced prompts and LLMs exist
-func (s *Server) validateScheduleReferences(ctx context.Context, promptIDs, llmIDs []string) error {
+func (s *Server) validateScheduleReferences(ctx context.Context, promptIDs, llmIDs []string, variables map[string][]string) error {
+	if err := services.ValidateVariables(variables); err != nil {
+		return err
+	}
+
 	for _, promptID := range promptIDs {
-		if _, err := s.promptService.GetPrompt(ctx, promptID); err != nil {
+		prompt, err := s.promptService.GetPrompt(ctx, promptID)
+		if err != nil {
 			return fmt.Errorf("prompt not found: %s", promptID)
 		}
+		if err := services.ValidatePromptVariables(prompt.Template, prompt.Brand, variables); err != nil {
+			return err
+		}
 	}
 
 	for _, llmID := range llmIDs {
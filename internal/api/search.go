@@ -67,11 +67,13 @@ func (s *Server) search(c *gin.Context) {
 }
 
 // listResponses handles GET /api/v1/responses
-// Query params: prompt_id, llm_id, schedule_id, limit, offset
+// Query params: prompt_id, llm_id, schedule_id, campaign_id, brand, region,
+// language, provider, prompt_type, limit, offset
 func (s *Server) listResponses(c *gin.Context) {
 	promptID := c.Query("prompt_id")
 	llmID := c.Query("llm_id")
 	scheduleID := c.Query("schedule_id")
+	campaignID := c.Query("campaign_id")
 
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "100"))
 	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
@@ -84,11 +86,18 @@ func (s *Server) listResponses(c *gin.Context) {
 	}
 
 	filter := shared.ResponseFilter{
-		PromptID:   promptID,
-		LLMID:      llmID,
-		ScheduleID: scheduleID,
-		Limit:      limit,
-		Offset:     offset,
+		PromptID:       promptID,
+		LLMID:          llmID,
+		ScheduleID:     scheduleID,
+		CampaignID:     campaignID,
+		Brand:          c.Query("brand"),
+		Region:         c.Query("region"),
+		Language:       c.Query("language"),
+		LLMProvider:    c.Query("provider"),
+		PromptType:     c.Query("prompt_type"),
+		SourceCategory: c.Query("source_category"),
+		Limit:          limit,
+		Offset:         offset,
 	}
 
 	responses, err := s.searchService.ListResponses(c.Request.Context(), filter)
@@ -1,6 +1,9 @@
 package api
 
 import (
+	"context"
+	"errors"
+	"net"
 	"net/http"
 	"strconv"
 	"strings"
@@ -22,15 +25,25 @@ type Server struct {
 	statsService                *services.StatsService
 	searchService               *services.SearchService
 	sourceAnalyticsService      *services.SourceAnalyticsService
+	sourceTaxonomyService       *services.SourceTaxonomyService
 	competitiveBenchmarkService *services.CompetitiveBenchmarkService
 	promptPerformanceService    *services.PromptPerformanceService
-	llmRegistry                 *llm.Registry
+	recommendationsEngine       *services.RecommendationsEngine
+	geoAnalysisService          *services.GEOAnalysisService
+	brandProfileService         *services.BrandProfileService
+	bulkExecutionService        *services.BulkExecutionService
+	jobQueue                    *services.JobQueueService
+	apiKeyService               *services.APIKeyService
+	llmFactory                  *llm.Factory
 	router                      *gin.Engine
+	httpServer                  *http.Server
+	shutdownDone                chan struct{}
 	corsOrigin                  string
+	authRequired                bool
 }
 
 // NewServer creates a new API server
-func NewServer(database db.Database, llmRegistry *llm.Registry, corsOrigin string) *Server {
+func NewServer(database db.Database, llmFactory *llm.Factory, corsOrigin string) *Server {
 	gin.SetMode(gin.ReleaseMode)
 
 	router := gin.Default()
@@ -57,6 +70,8 @@ func NewServer(database db.Database, llmRegistry *llm.Registry, corsOrigin strin
 		c.Next()
 	})
 
+	jobQueue := services.NewJobQueueService(database)
+
 	server := &Server{
 		db:                          database,
 		llmService:                  services.NewLLMService(database),
@@ -65,77 +80,145 @@ func NewServer(database db.Database, llmRegistry *llm.Registry, corsOrigin strin
 		statsService:                services.NewStatsService(database),
 		searchService:               services.NewSearchService(database),
 		sourceAnalyticsService:      services.NewSourceAnalyticsService(database),
+		sourceTaxonomyService:       services.NewSourceTaxonomyService(database),
 		competitiveBenchmarkService: services.NewCompetitiveBenchmarkService(database),
 		promptPerformanceService:    services.NewPromptPerformanceService(database),
-		llmRegistry:                 llmRegistry,
+		recommendationsEngine:       services.NewRecommendationsEngine(),
+		geoAnalysisService:          services.NewGEOAnalysisService(database, llmFactory),
+		brandProfileService:         services.NewBrandProfileService(database),
+		bulkExecutionService:        services.NewBulkExecutionService(database, llmFactory, jobQueue),
+		jobQueue:                    jobQueue,
+		apiKeyService:               services.NewAPIKeyService(database),
+		llmFactory:                  llmFactory,
 		router:                      router,
+		httpServer:                  &http.Server{Handler: router},
+		shutdownDone:                make(chan struct{}),
 		corsOrigin:                  corsOrigin,
+		authRequired:                true,
 	}
 
 	server.setupRoutes()
 	return server
 }
 
-// setupRoutes configures all API routes
+// setupRoutes configures all API routes. Every route but the health check
+// requires an API key with the scope of the route.
 func (s *Server) setupRoutes() {
 	api := s.router.Group("/api/v1")
 
-	api.GET("/llms", s.listLLMs)
-	api.GET("/llms/:id", s.getLLM)
-	api.POST("/llms", s.createLLM)
-	api.PUT("/llms/:id", s.updateLLM)
-	api.DELETE("/llms/:id", s.deleteLLM)
+	api.GET("/health", s.healthCheck)
+
+	api.Use(s.authenticate())
+
+	read := s.requireScope(models.APIKeyScopeRead)
+	manage := s.requireScope(models.APIKeyScopeManage)
+	execute := s.requireScope(models.APIKeyScopeExecute)
+
+	api.GET("/llms", read, s.listLLMs)
+	api.GET("/llms/:id", read, s.getLLM)
+	api.POST("/llms", manage, s.createLLM)
+	api.PUT("/llms/:id", manage, s.updateLLM)
+	api.DELETE("/llms/:id", manage, s.deleteLLM)
 
-	api.GET("/prompts", s.listPrompts)
-	api.GET("/prompts/:id", s.getPrompt)
-	api.POST("/prompts", s.createPrompt)
-	api.PUT("/prompts/:id", s.updatePrompt)
-	api.DELETE("/prompts/:id", s.deletePrompt)
+	api.GET("/prompts", read, s.listPrompts)
+	api.GET("/prompts/:id", read, s.getPrompt)
+	api.POST("/prompts", manage, s.createPrompt)
+	api.PUT("/prompts/:id", manage, s.updatePrompt)
+	api.DELETE("/prompts/:id", manage, s.deletePrompt)
 
-	api.GET("/schedules", s.listSchedules)
-	api.GET("/schedules/:id", s.getSchedule)
-	api.POST("/schedules", s.createSchedule)
-	api.PUT("/schedules/:id", s.updateSchedule)
-	api.DELETE("/schedules/:id", s.deleteSchedule)
+	api.GET("/schedules", read, s.listSchedules)
+	api.GET("/schedules/:id", read, s.getSchedule)
+	api.POST("/schedules", manage, s.createSchedule)
+	api.PUT("/schedules/:id", manage, s.updateSchedule)
+	api.DELETE("/schedules/:id", manage, s.deleteSchedule)
 
-	api.GET("/stats", s.getStats)
+	api.GET("/stats", read, s.getStats)
 
-	api.POST("/search", s.search)
+	api.POST("/search", read, s.search)
 
-	api.GET("/responses", s.listResponses)
+	api.GET("/responses", read, s.listResponses)
 
-	api.POST("/execute", s.execute)
+	api.POST("/execute", execute, s.execute)
+	api.POST("/execute/stream", execute, s.executeStream)
 
 	// GEO (Generative Engine Optimization) endpoints
 	geo := api.Group("/geo")
 	{
 		// Prompt Generation & Library
-		geo.POST("/prompts/generate", s.generatePrompts)
-		geo.GET("/libraries", s.listPromptLibraries)
+		geo.POST("/prompts/generate", execute, s.generatePrompts)
+		geo.GET("/libraries", read, s.listPromptLibraries)
 
 		// Brand Profiles
-		geo.GET("/profiles", s.listBrandProfiles)
-		geo.GET("/profiles/:brand", s.getBrandProfile)
+		geo.GET("/profiles", read, s.listBrandProfiles)
+		geo.GET("/profiles/:brand", read, s.getBrandProfile)
+		geo.PUT("/profiles/:brand/entities", manage, s.setBrandEntities)
+		geo.POST("/profiles/:brand/entities", manage, s.addBrandEntities)
+		geo.DELETE("/profiles/:brand/entities", manage, s.removeBrandEntities)
 
 		// Bulk Execution
-		geo.POST("/execute/bulk", s.bulkExecute)
+		geo.POST("/execute/bulk", execute, s.bulkExecute)
+
+		// Campaigns
+		geo.GET("/campaigns", read, s.listCampaigns)
+		geo.GET("/campaigns/:id", read, s.getCampaign)
+		geo.POST("/campaigns/:id/cancel", execute, s.cancelCampaign)
+		geo.POST("/campaigns/:id/retry", execute, s.retryFailedCampaignRuns)
+
+		// Job queue
+		geo.GET("/jobs", read, s.listJobs)
+
+		// Source taxonomy
+		geo.GET("/sources/taxonomy", read, s.getSourceTaxonomy)
+		geo.POST("/sources/classify", read, s.classifySources)
+		geo.PUT("/sources/overrides/:domain", manage, s.setSourceOverride)
+		geo.DELETE("/sources/overrides/:domain", manage, s.deleteSourceOverride)
+		geo.POST("/sources/reclassify", manage, s.reclassifySources)
 
 		// Analytics & Insights
-		geo.POST("/insights", s.getGEOInsights)
+		geo.POST("/insights", read, s.getGEOInsights)
 
 		// NEW: Advanced Analytics
-		geo.POST("/analytics/sources", s.getSourceAnalytics)
-		geo.POST("/analytics/competitive", s.getCompetitiveBenchmark)
-		geo.POST("/analytics/position", s.getPositionAnalytics)
-		geo.POST("/analytics/prompt-performance", s.getPromptPerformance)
+		geo.POST("/analytics/sources", read, s.getSourceAnalytics)
+		geo.POST("/analytics/competitive", read, s.getCompetitiveBenchmark)
+		geo.POST("/analytics/trends", read, s.getShareOfVoiceTrends)
+		geo.POST("/analytics/position", read, s.getPositionAnalytics)
+		geo.POST("/analytics/prompt-performance", read, s.getPromptPerformance)
+
+		// Recommendation playbooks
+		geo.GET("/playbooks", read, s.listPlaybooks)
+		geo.POST("/playbooks/dry-run", read, s.dryRunPlaybook)
 	}
-
-	api.GET("/health", s.healthCheck)
 }
 
-// Run starts the API server
+// Run starts the API server. After a Shutdown, it returns once the shutdown
+// is complete.
 func (s *Server) Run(address string) error {
-	return s.router.Run(address)
+	// Campaign runs are executed from the job queue, including any left
+	// unfinished by a previous server
+	s.jobQueue.Start(services.DefaultJobWorkers)
+	defer s.jobQueue.Stop()
+
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return err
+	}
+
+	err = s.httpServer.Serve(listener)
+	if errors.Is(err, http.ErrServerClosed) {
+		<-s.shutdownDone
+		return nil
+	}
+	return err
+}
+
+// Shutdown stops accepting requests, waits for the ongoing ones and then
+// for the running jobs, which are drained before the queue stops
+func (s *Server) Shutdown(ctx context.Context) error {
+	defer close(s.shutdownDone)
+
+	err := s.httpServer.Shutdown(ctx)
+	s.jobQueue.Stop()
+	return err
 }
 
 // Helper functions
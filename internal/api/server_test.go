@@ -0,0 +1,70 @@
+package api
+
+import (
+	"context"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/fissionx/gego/internal/db"
+	"github.com/fissionx/gego/internal/llm"
+	"github.com/fissionx/gego/internal/models"
+)
+
+// newTestServer creates a server on a migrated temporary SQLite database and an in-memory document store
+func newTestServer(t *testing.T) (*Server, *db.HybridDB) {
+	t.Helper()
+	ctx := context.Background()
+
+	database, err := db.New(
+		&models.Config{Provider: "sqlite", URI: filepath.Join(t.TempDir(), "gego.db"), SkipSchemaCheck: true},
+		&models.Config{Provider: "sqlite", URI: ":memory:"},
+	)
+	if err != nil {
+		t.Fatalf("db.New() error = %v", err)
+	}
+	if err := database.Connect(ctx); err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	t.Cleanup(func() { database.Disconnect(ctx) })
+
+	if err := db.RunMigrations(ctx, database); err != nil {
+		t.Fatalf("RunMigrations() error = %v", err)
+	}
+	return NewServer(database, llm.NewFactory(), "*"), database
+}
+
+// serve sends a request to the server's router with an optional Authorization header
+func serve(s *Server, method, path, authorization, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+	recorder := httptest.NewRecorder()
+	s.router.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func TestServerShutdown(t *testing.T) {
+	server, _ := newTestServer(t)
+
+	done := make(chan error, 1)
+	go func() { done <- server.Run("127.0.0.1:0") }()
+
+	// Shutdown stops the HTTP server and the job queue, then Run returns
+	if err := server.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	if err := <-done; err != nil {
+		t.Errorf("Run() after Shutdown() = %v, want nil", err)
+	}
+	if server.jobQueue.Start(1) {
+		server.jobQueue.Stop()
+	} else {
+		t.Error("job queue still running after Shutdown()")
+	}
+}
@@ -0,0 +1,78 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/fissionx/gego/internal/models"
+)
+
+// getSourceTaxonomy handles GET /api/v1/geo/sources/taxonomy
+func (s *Server) getSourceTaxonomy(c *gin.Context) {
+	taxonomy, err := s.sourceTaxonomyService.Taxonomy()
+	if err != nil {
+		s.errorResponse(c, http.StatusInternalServerError, "Failed to load source taxonomy: "+err.Error())
+		return
+	}
+
+	s.successResponse(c, taxonomy)
+}
+
+// classifySources handles POST /api/v1/geo/sources/classify
+func (s *Server) classifySources(c *gin.Context) {
+	var req models.ClassifySourcesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		s.errorResponse(c, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+
+	s.successResponse(c, s.sourceTaxonomyService.Classify(req.Domains))
+}
+
+// setSourceOverride handles PUT /api/v1/geo/sources/overrides/:domain
+func (s *Server) setSourceOverride(c *gin.Context) {
+	var req models.SetSourceOverrideRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		s.errorResponse(c, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+
+	updated, err := s.sourceTaxonomyService.SetOverride(c.Request.Context(), c.Param("domain"), req.Categories)
+	if err != nil {
+		s.errorResponse(c, http.StatusBadRequest, "Failed to set source override: "+err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    models.ReclassifySourcesResponse{Updated: updated},
+		Message: "Source override set successfully",
+	})
+}
+
+// deleteSourceOverride handles DELETE /api/v1/geo/sources/overrides/:domain
+func (s *Server) deleteSourceOverride(c *gin.Context) {
+	updated, err := s.sourceTaxonomyService.DeleteOverride(c.Request.Context(), c.Param("domain"))
+	if err != nil {
+		s.errorResponse(c, http.StatusBadRequest, "Failed to delete source override: "+err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    models.ReclassifySourcesResponse{Updated: updated},
+		Message: "Source override deleted successfully",
+	})
+}
+
+// reclassifySources handles POST /api/v1/geo/sources/reclassify
+func (s *Server) reclassifySources(c *gin.Context) {
+	updated, err := s.sourceTaxonomyService.Reclassify(c.Request.Context())
+	if err != nil {
+		s.errorResponse(c, http.StatusInternalServerError, "Failed to reclassify sources: "+err.Error())
+		return
+	}
+
+	s.successResponse(c, models.ReclassifySourcesResponse{Updated: updated})
+}
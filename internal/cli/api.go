@@ -7,26 +7,26 @@ import (
 	"os/signal"
 	"path/filepath"
 	"syscall"
+	"time"
 
 	"github.com/spf13/cobra"
 
 	"github.com/fissionx/gego/internal/api"
 	"github.com/fissionx/gego/internal/config"
 	"github.com/fissionx/gego/internal/db"
-	"github.com/fissionx/gego/internal/llm"
-	"github.com/fissionx/gego/internal/llm/anthropic"
-	"github.com/fissionx/gego/internal/llm/google"
-	"github.com/fissionx/gego/internal/llm/ollama"
-	"github.com/fissionx/gego/internal/llm/openai"
-	"github.com/fissionx/gego/internal/llm/perplexity"
 	"github.com/fissionx/gego/internal/models"
+	"github.com/fissionx/gego/internal/services"
 	"github.com/fissionx/gego/internal/shared"
 )
 
+// apiShutdownTimeout is how long ongoing requests get to finish on shutdown
+const apiShutdownTimeout = 30 * time.Second
+
 var (
 	apiPort    string
 	apiHost    string
 	corsOrigin string
+	apiNoAuth  bool
 )
 
 var apiCmd = &cobra.Command{
@@ -39,7 +39,8 @@ var apiCmd = &cobra.Command{
 - Stats (Read-only)
 - Search (POST endpoint for keyword search)
 
-The API runs on HTTP (no authentication required for now).`,
+Every endpoint except /api/v1/health requires an API key sent as
+'Authorization: Bearer <key>'. Create keys with 'gego apikey create'.`,
 	RunE: runAPI,
 }
 
@@ -47,6 +48,7 @@ func init() {
 	apiCmd.Flags().StringVarP(&apiPort, "port", "p", "8989", "Port to run the API server on")
 	apiCmd.Flags().StringVarP(&apiHost, "host", "H", "0.0.0.0", "Host to bind the API server to")
 	apiCmd.Flags().StringVarP(&corsOrigin, "cors-origin", "c", "", "CORS origin to allow (overrides config file, use '*' for all origins)")
+	apiCmd.Flags().BoolVar(&apiNoAuth, "no-auth", false, "Serve the API without API key authentication (local development only)")
 }
 
 func runAPI(cmd *cobra.Command, args []string) error {
@@ -77,6 +79,14 @@ func runAPI(cmd *cobra.Command, args []string) error {
 		shared.SetExclusionFilePath(exclusionPath)
 	}
 
+	services.SetGEOJudgeLLMID(cfg.GEOJudgeLLMID)
+	configureSourceTaxonomy(cfg, configPath)
+	configurePlaybooks(cfg, configPath)
+
+	if err := configureMasterKey(cfg, configPath); err != nil {
+		return err
+	}
+
 	selectedCORSOrigin := corsOrigin
 	if selectedCORSOrigin == "" {
 		if cfg.CORSOrigin != "" {
@@ -94,18 +104,21 @@ func runAPI(cmd *cobra.Command, args []string) error {
 	fmt.Printf("URL: http://%s:%s/api/v1\n", apiHost, apiPort)
 	fmt.Println()
 
+	// The server migrates the databases on startup, so it connects to them at any version
 	sqlConfig := &models.Config{
-		Provider: cfg.SQLDatabase.Provider,
-		URI:      cfg.SQLDatabase.URI,
-		Database: cfg.SQLDatabase.Database,
-		Options:  cfg.SQLDatabase.Options,
+		Provider:        cfg.SQLDatabase.Provider,
+		URI:             cfg.SQLDatabase.URI,
+		Database:        cfg.SQLDatabase.Database,
+		Options:         cfg.SQLDatabase.Options,
+		SkipSchemaCheck: true,
 	}
 
 	nosqlConfig := &models.Config{
-		Provider: cfg.NoSQLDatabase.Provider,
-		URI:      cfg.NoSQLDatabase.URI,
-		Database: cfg.NoSQLDatabase.Database,
-		Options:  cfg.NoSQLDatabase.Options,
+		Provider:        cfg.NoSQLDatabase.Provider,
+		URI:             cfg.NoSQLDatabase.URI,
+		Database:        cfg.NoSQLDatabase.Database,
+		Options:         cfg.NoSQLDatabase.Options,
+		SkipSchemaCheck: true,
 	}
 
 	database, err := db.New(sqlConfig, nosqlConfig)
@@ -126,51 +139,41 @@ func runAPI(cmd *cobra.Command, args []string) error {
 	fmt.Println("✅ Database connection successful!")
 
 	fmt.Println("\n🔄 Running database migrations...")
-	if err := runAPIMigrations(ctx, database); err != nil {
+	if err := db.RunMigrations(ctx, database); err != nil {
 		return fmt.Errorf("failed to run migrations: %w", err)
 	}
 	fmt.Println("✅ Database migrations completed successfully!")
 
-	// Initialize LLM registry with all providers
-	apiLLMRegistry := llm.NewRegistry()
-	apiLLMRegistry.Register(openai.New("", ""))
-	apiLLMRegistry.Register(anthropic.New("", ""))
-	apiLLMRegistry.Register(ollama.New(""))
-	apiLLMRegistry.Register(google.New("", ""))
-	apiLLMRegistry.Register(perplexity.New("", ""))
-
-	// Initialize providers with API keys from database
-	llms, err := database.ListLLMs(ctx, nil)
-	if err == nil {
-		for _, llmConfig := range llms {
-			if llmConfig.APIKey != "" {
-				switch llmConfig.Provider {
-				case "openai":
-					apiLLMRegistry.Register(openai.New(llmConfig.APIKey, llmConfig.BaseURL))
-				case "anthropic":
-					apiLLMRegistry.Register(anthropic.New(llmConfig.APIKey, llmConfig.BaseURL))
-				case "ollama":
-					apiLLMRegistry.Register(ollama.New(llmConfig.BaseURL))
-				case "google":
-					apiLLMRegistry.Register(google.New(llmConfig.APIKey, llmConfig.BaseURL))
-				case "perplexity":
-					apiLLMRegistry.Register(perplexity.New(llmConfig.APIKey, llmConfig.BaseURL))
-				}
-			}
+	// Providers are built per LLM config, with that config's API key and base URL
+	server := api.NewServer(database, newLLMFactory(cfg), selectedCORSOrigin)
+
+	if apiNoAuth || cfg.APIAuthDisabled {
+		server.DisableAuth()
+		fmt.Printf("%s⚠️  API key authentication is disabled: anyone who can reach the server can use it%s\n", WarningStyle, Reset)
+	} else {
+		hasKeys, err := services.NewAPIKeyService(database).HasActiveKeys(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to check API keys: %w", err)
+		}
+		fmt.Println("🔒 API key authentication is enabled")
+		if !hasKeys {
+			fmt.Printf("%s⚠️  No active API keys yet. Create one with '%s'%s\n", WarningStyle, FormatSecondary("gego apikey create --name <name> --scope read"), Reset)
 		}
 	}
-	fmt.Println("✅ LLM providers initialized!")
-
-	server := api.NewServer(database, apiLLMRegistry, selectedCORSOrigin)
+	fmt.Println()
 
 	c := make(chan os.Signal, 1)
 	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
 
+	// Run returns once the shutdown is complete, then the database is disconnected
 	go func() {
 		<-c
 		fmt.Println("\n🛑 Shutting down API server...")
-		database.Disconnect(ctx)
-		os.Exit(0)
+		shutdownCtx, cancel := context.WithTimeout(ctx, apiShutdownTimeout)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			fmt.Printf("%s⚠️  Failed to shut down cleanly: %v%s\n", WarningStyle, err, Reset)
+		}
 	}()
 
 	fmt.Println("🌐 API Server is running!")
@@ -204,37 +207,10 @@ func runAPI(cmd *cobra.Command, args []string) error {
 	fmt.Println()
 	fmt.Println("  Execute:")
 	fmt.Println("    POST   /api/v1/execute           - Execute prompt with LLM")
+	fmt.Println("    POST   /api/v1/execute/stream    - Execute prompt, streaming server-sent events")
 	fmt.Println()
 	fmt.Println("Press Ctrl+C to stop the server")
 
 	address := fmt.Sprintf("%s:%s", apiHost, apiPort)
 	return server.Run(address)
 }
-
-func runAPIMigrations(ctx context.Context, database db.Database) error {
-	hybridDB, ok := database.(*db.HybridDB)
-	if !ok {
-		return fmt.Errorf("database is not a HybridDB instance")
-	}
-
-	sqliteDB := hybridDB.GetSQLiteDatabase()
-	if sqliteDB == nil {
-		return fmt.Errorf("SQLite database not available")
-	}
-
-	migrationsDir := os.Getenv("GEGO_MIGRATIONS_DIR")
-	if migrationsDir == "" {
-		migrationsDir = "/migrations"
-		if _, err := os.Stat(migrationsDir); os.IsNotExist(err) {
-			workDir, _ := os.Getwd()
-			migrationsDir = filepath.Join(workDir, "internal", "db", "migrations")
-		}
-	}
-
-	sqlDB := sqliteDB.GetDB()
-	if sqlDB == nil {
-		return fmt.Errorf("database connection not available")
-	}
-
-	return db.RunMigrations(ctx, sqlDB, migrationsDir)
-}
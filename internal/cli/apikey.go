@@ -0,0 +1,142 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/fissionx/gego/internal/models"
+	"github.com/fissionx/gego/internal/services"
+)
+
+var (
+	apiKeyName   string
+	apiKeyScopes []string
+)
+
+var apiKeyCmd = &cobra.Command{
+	Use:   "apikey",
+	Short: "Manage API keys for the REST API",
+	Long: `Create, list and revoke the API keys that authenticate REST API clients.
+
+Scopes:
+  read     Listings, stats, search and analytics
+  manage   Create, update and delete LLMs, prompts, schedules and brand profiles
+  execute  Run prompts against LLMs (single, streaming and bulk executions)`,
+}
+
+var apiKeyCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Create an API key",
+	Example: `  gego apikey create --name dashboard --scope read
+  gego apikey create --name ci --scope read,manage,execute`,
+	Args: cobra.NoArgs,
+	RunE: runAPIKeyCreate,
+}
+
+var apiKeyListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List API keys",
+	Args:  cobra.NoArgs,
+	RunE:  runAPIKeyList,
+}
+
+var apiKeyRevokeCmd = &cobra.Command{
+	Use:   "revoke [id]",
+	Short: "Revoke an API key",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runAPIKeyRevoke,
+}
+
+func init() {
+	apiKeyCreateCmd.Flags().StringVar(&apiKeyName, "name", "", "Name of the key, e.g. the client using it (required)")
+	apiKeyCreateCmd.Flags().StringSliceVar(&apiKeyScopes, "scope", []string{models.APIKeyScopeRead}, "Scopes granted to the key: read, manage, execute")
+	apiKeyCreateCmd.MarkFlagRequired("name")
+
+	apiKeyCmd.AddCommand(apiKeyCreateCmd)
+	apiKeyCmd.AddCommand(apiKeyListCmd)
+	apiKeyCmd.AddCommand(apiKeyRevokeCmd)
+}
+
+func runAPIKeyCreate(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	apiKeyService := services.NewAPIKeyService(database)
+
+	key, secret, err := apiKeyService.CreateKey(ctx, apiKeyName, apiKeyScopes)
+	if err != nil {
+		return fmt.Errorf("failed to create API key: %w", err)
+	}
+
+	fmt.Printf("%s✅ API key created%s\n", SuccessStyle, Reset)
+	fmt.Printf("%sID: %s\n", LabelStyle, FormatSecondary(key.ID))
+	fmt.Printf("%sName: %s\n", LabelStyle, FormatValue(key.Name))
+	fmt.Printf("%sScopes: %s\n", LabelStyle, FormatValue(strings.Join(key.Scopes, ", ")))
+	fmt.Println()
+	fmt.Printf("%sKey: %s\n", LabelStyle, FormatValue(secret))
+	fmt.Printf("%sStore it now: it cannot be shown again.%s\n", WarningStyle, Reset)
+	fmt.Printf("%sUse it as: Authorization: Bearer <key>%s\n", DimStyle, Reset)
+
+	return nil
+}
+
+func runAPIKeyList(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	apiKeyService := services.NewAPIKeyService(database)
+
+	keys, err := apiKeyService.ListKeys(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list API keys: %w", err)
+	}
+
+	if len(keys) == 0 {
+		fmt.Printf("%sNo API keys. Use '%s' to create one.%s\n", WarningStyle, FormatSecondary("gego apikey create"), Reset)
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "%sID\tNAME\tKEY\tSCOPES\tLAST USED\tSTATUS%s\n", LabelStyle, Reset)
+	fmt.Fprintf(w, "%s──\t────\t───\t──────\t─────────\t──────%s\n", DimStyle, Reset)
+
+	for _, key := range keys {
+		lastUsed := "Never"
+		if key.LastUsedAt != nil {
+			lastUsed = key.LastUsedAt.Format("2006-01-02 15:04")
+		}
+		status := "Active"
+		if key.RevokedAt != nil {
+			status = "Revoked " + key.RevokedAt.Format("2006-01-02")
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			FormatSecondary(key.ID),
+			FormatValue(key.Name),
+			FormatSecondary(key.Prefix+"…"),
+			FormatValue(strings.Join(key.Scopes, ",")),
+			FormatSecondary(lastUsed),
+			FormatValue(status),
+		)
+	}
+
+	w.Flush()
+	fmt.Printf("\n%sTotal: %s API keys%s\n", InfoStyle, FormatCount(len(keys)), Reset)
+
+	return nil
+}
+
+func runAPIKeyRevoke(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	apiKeyService := services.NewAPIKeyService(database)
+
+	if err := apiKeyService.RevokeKey(ctx, args[0]); err != nil {
+		return fmt.Errorf("failed to revoke API key: %w", err)
+	}
+
+	fmt.Printf("%s✅ API key %s revoked%s\n", SuccessStyle, args[0], Reset)
+	return nil
+}
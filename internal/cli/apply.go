@@ -0,0 +1,238 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/fissionx/gego/internal/models"
+	"github.com/fissionx/gego/internal/services"
+)
+
+var applyFile string
+
+var applyCmd = &cobra.Command{
+	Use:   "apply",
+	Short: "Apply a declarative workspace file",
+	Long: `Bring LLMs, prompts, prompt libraries, brand profiles and schedules to the
+state declared in a YAML or JSON workspace file, so that the tracking setup can
+live in version control.
+
+Records are matched by stable name: LLMs, prompts and schedules by name, prompt
+libraries by brand, domain and category, brand profiles by brand name. Missing
+records are created and differing ones updated. In each section of the file,
+LLMs, prompts and schedules that the file does not declare are disabled; a
+section left out of the file leaves its records untouched.
+
+  llms:
+    - name: gpt-4o
+      provider: openai
+      model: gpt-4o
+      api_key: env:OPENAI_API_KEY   # kept as stored when left out
+  prompts:
+    - name: best-crm
+      template: What are the best CRM tools for startups?
+      tags: [crm]
+      category: software
+  prompt_libraries:
+    - brand: Acme
+      domain: acme.com
+      category: CRM
+      prompts: [best-crm]
+  brand_profiles:
+    - brand_name: Acme
+      domain: acme.com
+      aliases: [Acme CRM]
+  schedules:
+    - name: daily
+      prompts: [best-crm]           # prompt names
+      llms: [gpt-4o]                # LLM names
+      cron: "0 9 * * *"
+      temperature: 0.7              # or random
+      variables:                    # values for {{name}} in the prompts
+        city: [Paris, Berlin]
+
+The changes are listed and confirmed (or --yes) before anything is written.`,
+	Example: `  gego apply -f workspace.yaml
+  gego apply plan -f workspace.yaml
+  gego apply diff -f workspace.yaml -o json`,
+	Args: cobra.NoArgs,
+	RunE: runApply,
+}
+
+var applyPlanCmd = &cobra.Command{
+	Use:         "plan",
+	Short:       "List the records applying a workspace file would change",
+	Args:        cobra.NoArgs,
+	Annotations: structuredOutput,
+	RunE:        runApplyPlan,
+}
+
+var applyDiffCmd = &cobra.Command{
+	Use:         "diff",
+	Short:       "Show the field changes applying a workspace file would make",
+	Args:        cobra.NoArgs,
+	Annotations: structuredOutput,
+	RunE:        runApplyDiff,
+}
+
+func init() {
+	applyCmd.PersistentFlags().StringVarP(&applyFile, "file", "f", "", "Workspace file, - for standard input")
+	applyCmd.MarkPersistentFlagRequired("file")
+
+	applyCmd.AddCommand(applyPlanCmd)
+	applyCmd.AddCommand(applyDiffCmd)
+}
+
+// planWorkspace reads the workspace file and plans its changes
+func planWorkspace(ctx context.Context) (*services.WorkspacePlan, error) {
+	var in io.Reader = os.Stdin
+	if applyFile != "-" {
+		file, err := os.Open(applyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open workspace: %w", err)
+		}
+		defer file.Close()
+		in = file
+	}
+
+	workspace, err := services.ReadWorkspace(in)
+	if err != nil {
+		return nil, err
+	}
+
+	return services.NewWorkspaceService(database).Plan(ctx, workspace)
+}
+
+func runApply(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	plan, err := planWorkspace(ctx)
+	if err != nil {
+		return err
+	}
+
+	if len(plan.Changes) == 0 {
+		fmt.Printf("%s✅ Nothing to change: the databases match the workspace%s\n", SuccessStyle, Reset)
+		return nil
+	}
+
+	printWorkspaceChanges(plan.Changes, true)
+	fmt.Println()
+	printWorkspaceSummary(plan)
+
+	confirmed, err := confirm(bufio.NewReader(os.Stdin), fmt.Sprintf("\n%sApply these %s changes? (y/N): %s", LabelStyle, FormatCount(len(plan.Changes)), Reset))
+	if err != nil {
+		return err
+	}
+	if !confirmed {
+		fmt.Printf("%sCancelled.%s\n", WarningStyle, Reset)
+		return nil
+	}
+
+	if err := services.NewWorkspaceService(database).Apply(ctx, plan); err != nil {
+		return err
+	}
+
+	fmt.Printf("\n%s✅ Workspace applied%s\n", SuccessStyle, Reset)
+	for _, change := range plan.Changes {
+		if change.Kind == services.WorkspaceKindSchedule {
+			fmt.Printf("%sRestart the scheduler to apply changes: %s%s\n", InfoStyle, FormatSecondary("gego scheduler start"), Reset)
+			break
+		}
+	}
+	return nil
+}
+
+func runApplyPlan(cmd *cobra.Command, args []string) error {
+	plan, err := planWorkspace(context.Background())
+	if err != nil {
+		return err
+	}
+
+	if isStructuredOutput() {
+		changes := make([]models.WorkspaceChange, 0, len(plan.Changes))
+		for _, change := range plan.Changes {
+			change.Fields = nil
+			changes = append(changes, change)
+		}
+		return writeStructured(changes)
+	}
+
+	if len(plan.Changes) == 0 {
+		fmt.Printf("%s✅ Nothing to change: the databases match the workspace%s\n", SuccessStyle, Reset)
+		return nil
+	}
+
+	printWorkspaceChanges(plan.Changes, false)
+	fmt.Println()
+	printWorkspaceSummary(plan)
+	return nil
+}
+
+func runApplyDiff(cmd *cobra.Command, args []string) error {
+	plan, err := planWorkspace(context.Background())
+	if err != nil {
+		return err
+	}
+
+	if isStructuredOutput() {
+		changes := plan.Changes
+		if changes == nil {
+			changes = []models.WorkspaceChange{}
+		}
+		return writeStructured(changes)
+	}
+
+	if len(plan.Changes) == 0 {
+		fmt.Printf("%s✅ Nothing to change: the databases match the workspace%s\n", SuccessStyle, Reset)
+		return nil
+	}
+
+	printWorkspaceChanges(plan.Changes, true)
+	return nil
+}
+
+// printWorkspaceChanges lists changes one per line, with the old and new value
+// of each updated field when fields is set
+func printWorkspaceChanges(changes []models.WorkspaceChange, fields bool) {
+	for _, change := range changes {
+		kind := strings.ReplaceAll(change.Kind, "_", " ")
+		switch change.Action {
+		case models.WorkspaceCreate:
+			fmt.Printf("%s+ create %s %s%s\n", SuccessStyle, kind, FormatValue(change.Name), Reset)
+		case models.WorkspaceUpdate:
+			fmt.Printf("%s~ update %s %s%s\n", WarningStyle, kind, FormatValue(change.Name), Reset)
+		case models.WorkspaceDisable:
+			fmt.Printf("%s- disable %s %s%s\n", ErrorStyle, kind, FormatValue(change.Name), Reset)
+		}
+
+		if !fields {
+			continue
+		}
+		for _, field := range change.Fields {
+			fmt.Printf("    %s: %s → %s\n", FormatLabel(field.Field), FormatDim(displayField(field.Old)), FormatValue(displayField(field.New)))
+		}
+	}
+}
+
+// displayField shows empty values explicitly
+func displayField(value string) string {
+	if value == "" {
+		return "(empty)"
+	}
+	return value
+}
+
+func printWorkspaceSummary(plan *services.WorkspacePlan) {
+	fmt.Printf("%sPlan: %s to create, %s to update, %s to disable%s\n", LabelStyle,
+		FormatCount(plan.Count(models.WorkspaceCreate)),
+		FormatCount(plan.Count(models.WorkspaceUpdate)),
+		FormatCount(plan.Count(models.WorkspaceDisable)),
+		Reset)
+}
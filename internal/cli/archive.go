@@ -0,0 +1,193 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/fissionx/gego/internal/models"
+	"github.com/fissionx/gego/internal/services"
+)
+
+var (
+	exportIncludeSecrets bool
+	exportSkipResponses  bool
+	importMode           string
+	importDryRun         bool
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export [file]",
+	Short: "Export LLMs, schedules, prompts and responses to an archive",
+	Long: `Export the data of both databases into a single versioned archive:
+LLM configurations, schedules, prompts, prompt libraries, brand profiles and
+responses. Files ending in .gz are gzip-compressed; without a file, or with -,
+the archive is written to standard output.
+
+LLM API keys are left out unless --include-secrets is given. Keys stored as
+env: or file: references are always kept, since they hold no secret.`,
+	Example: `  gego export backup.json.gz
+  gego export --skip-responses config.json
+  gego export --include-secrets - | ssh prod gego import -`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runExport,
+}
+
+var importCmd = &cobra.Command{
+	Use:   "import [file]",
+	Short: "Import an archive written by gego export",
+	Long: `Import an archive written by 'gego export'. With -, the archive is read from
+standard input. The references between schedules, prompt libraries, prompts
+and LLMs are checked before anything is written.
+
+Modes:
+  merge  Keep the archive IDs. Records already present under the same ID, or
+         matching an existing one (LLMs with the same provider, model and name,
+         prompts with the same template), are not imported again.
+  remap  Give every imported record a new ID, rewriting the references, to load
+         an archive next to the data it came from.
+
+In both modes, prompt libraries merge into the library of the same brand,
+domain and category, and an existing brand profile is kept.`,
+	Example: `  gego import backup.json.gz
+  gego import --dry-run backup.json.gz
+  gego import --mode remap demo.json`,
+	Args: cobra.ExactArgs(1),
+	RunE: runImport,
+}
+
+func init() {
+	exportCmd.Flags().BoolVar(&exportIncludeSecrets, "include-secrets", false, "Include LLM API keys, in plain text")
+	exportCmd.Flags().BoolVar(&exportSkipResponses, "skip-responses", false, "Leave responses out of the archive")
+
+	importCmd.Flags().StringVar(&importMode, "mode", string(services.ImportMerge), "How imported IDs meet existing ones: merge or remap")
+	importCmd.Flags().BoolVar(&importDryRun, "dry-run", false, "Check the archive and show what would be imported, without writing")
+}
+
+func runExport(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	archiveService := services.NewArchiveService(database)
+	archive, warnings, err := archiveService.Export(ctx, services.ExportOptions{
+		IncludeSecrets:   exportIncludeSecrets,
+		IncludeResponses: !exportSkipResponses,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to export: %w", err)
+	}
+
+	path := "-"
+	if len(args) == 1 {
+		path = args[0]
+	}
+
+	// Progress goes to stderr when the archive itself goes to stdout
+	status := os.Stdout
+	if path == "-" {
+		status = os.Stderr
+		if err := services.WriteArchive(os.Stdout, archive, false); err != nil {
+			return err
+		}
+	} else {
+		file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+		if err != nil {
+			return fmt.Errorf("failed to create archive: %w", err)
+		}
+		if err := services.WriteArchive(file, archive, strings.HasSuffix(path, ".gz")); err != nil {
+			file.Close()
+			return err
+		}
+		if err := file.Close(); err != nil {
+			return fmt.Errorf("failed to write archive: %w", err)
+		}
+	}
+
+	for _, warning := range warnings {
+		fmt.Fprintf(status, "%s⚠️  %s%s\n", WarningStyle, warning, Reset)
+	}
+	if path != "-" {
+		fmt.Fprintf(status, "%s✅ Exported to %s%s\n", SuccessStyle, path, Reset)
+	}
+	fmt.Fprintf(status, "%sLLMs: %s, schedules: %s, prompts: %s, prompt libraries: %s, brand profiles: %s, responses: %s%s\n",
+		LabelStyle,
+		FormatCount(len(archive.LLMs)),
+		FormatCount(len(archive.Schedules)),
+		FormatCount(len(archive.Prompts)),
+		FormatCount(len(archive.PromptLibraries)),
+		FormatCount(len(archive.BrandProfiles)),
+		FormatCount(len(archive.Responses)),
+		Reset)
+	if exportIncludeSecrets {
+		fmt.Fprintf(status, "%sThe archive holds LLM API keys in plain text: keep it safe.%s\n", WarningStyle, Reset)
+	}
+
+	return nil
+}
+
+func runImport(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	var in io.Reader = os.Stdin
+	if args[0] != "-" {
+		file, err := os.Open(args[0])
+		if err != nil {
+			return fmt.Errorf("failed to open archive: %w", err)
+		}
+		defer file.Close()
+		in = file
+	}
+
+	archive, err := services.ReadArchive(in)
+	if err != nil {
+		return err
+	}
+
+	archiveService := services.NewArchiveService(database)
+	result, err := archiveService.Import(ctx, archive, services.ImportOptions{
+		Mode:   services.ImportMode(importMode),
+		DryRun: importDryRun,
+	})
+	if result != nil {
+		printImportResult(result, importDryRun, err != nil)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to import: %w", err)
+	}
+
+	return nil
+}
+
+func printImportResult(result *models.ImportResult, dryRun, failed bool) {
+	switch {
+	case failed:
+		fmt.Printf("%s❌ Import stopped; imported so far:%s\n", ErrorStyle, Reset)
+	case dryRun:
+		fmt.Printf("%s🔍 Dry run: nothing was written%s\n", InfoStyle, Reset)
+	default:
+		fmt.Printf("%s✅ Import finished%s\n", SuccessStyle, Reset)
+	}
+
+	rows := []struct {
+		name   string
+		counts models.ImportCounts
+	}{
+		{"LLMs", result.LLMs},
+		{"Schedules", result.Schedules},
+		{"Prompts", result.Prompts},
+		{"Prompt libraries", result.PromptLibraries},
+		{"Brand profiles", result.BrandProfiles},
+		{"Responses", result.Responses},
+	}
+	for _, row := range rows {
+		fmt.Printf("%s%s: %s new, %s already present%s\n", LabelStyle, row.name,
+			FormatCount(row.counts.Created), FormatCount(row.counts.Existing), Reset)
+	}
+
+	for _, warning := range result.Warnings {
+		fmt.Printf("%s⚠️  %s%s\n", WarningStyle, warning, Reset)
+	}
+}
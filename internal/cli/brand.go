@@ -0,0 +1,176 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/fissionx/gego/internal/models"
+	"github.com/fissionx/gego/internal/services"
+)
+
+var (
+	brandAliases          []string
+	brandProducts         []string
+	brandDomains          []string
+	brandNegativePatterns []string
+)
+
+var brandCmd = &cobra.Command{
+	Use:   "brand",
+	Short: "Manage brand entity dictionaries",
+	Long: `Manage the aliases, product names, domains and negative patterns used to
+recognise a brand in LLM answers, search and competitive analytics.`,
+}
+
+var brandListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List brand profiles",
+	RunE:  runBrandList,
+}
+
+var brandShowCmd = &cobra.Command{
+	Use:   "show [brand]",
+	Short: "Show the entity dictionary of a brand",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runBrandShow,
+}
+
+var brandAddCmd = &cobra.Command{
+	Use:   "add [brand]",
+	Short: "Add aliases, products, domains or negative patterns to a brand",
+	Example: `  gego brand add HubSpot --alias "HubSpot CRM" --domain hubspot.com
+  gego brand add Mercury --negative "Mercury (the )?planet"`,
+	Args: cobra.ExactArgs(1),
+	RunE: runBrandAdd,
+}
+
+var brandRemoveCmd = &cobra.Command{
+	Use:   "remove [brand]",
+	Short: "Remove aliases, products, domains or negative patterns from a brand",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runBrandRemove,
+}
+
+func init() {
+	for _, cmd := range []*cobra.Command{brandAddCmd, brandRemoveCmd} {
+		cmd.Flags().StringSliceVar(&brandAliases, "alias", nil, "Brand alias (repeatable)")
+		cmd.Flags().StringSliceVar(&brandProducts, "product", nil, "Product name (repeatable)")
+		cmd.Flags().StringSliceVar(&brandDomains, "domain", nil, "Brand domain or URL (repeatable)")
+		cmd.Flags().StringSliceVar(&brandNegativePatterns, "negative", nil, "Regex for false positives to ignore (repeatable)")
+	}
+
+	brandCmd.AddCommand(brandListCmd)
+	brandCmd.AddCommand(brandShowCmd)
+	brandCmd.AddCommand(brandAddCmd)
+	brandCmd.AddCommand(brandRemoveCmd)
+}
+
+func runBrandList(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	profiles, err := services.NewBrandProfileService(database).ListProfiles(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list brand profiles: %w", err)
+	}
+
+	if len(profiles) == 0 {
+		fmt.Printf("%sNo brand profiles found. Use '%s' to create one.%s\n", WarningStyle, FormatSecondary("gego brand add"), Reset)
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "%sBRAND\tALIASES\tPRODUCTS\tDOMAINS\tNEGATIVES%s\n", LabelStyle, Reset)
+	fmt.Fprintf(w, "%s─────\t───────\t────────\t───────\t─────────%s\n", DimStyle, Reset)
+
+	for _, profile := range profiles {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
+			FormatValue(profile.BrandName),
+			FormatCount(len(profile.Aliases)),
+			FormatCount(len(profile.ProductNames)),
+			FormatCount(len(profile.Domains)),
+			FormatCount(len(profile.NegativePatterns)),
+		)
+	}
+
+	w.Flush()
+	fmt.Printf("\n%sTotal: %s brand profiles%s\n", InfoStyle, FormatCount(len(profiles)), Reset)
+
+	return nil
+}
+
+func runBrandShow(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	profile, err := services.NewBrandProfileService(database).GetProfile(ctx, args[0])
+	if err != nil {
+		return fmt.Errorf("failed to get brand profile: %w", err)
+	}
+	if profile == nil {
+		return fmt.Errorf("brand profile not found: %s", args[0])
+	}
+
+	printBrandProfile(profile)
+	return nil
+}
+
+func runBrandAdd(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	profile, err := services.NewBrandProfileService(database).AddEntities(ctx, args[0], brandEntitiesFromFlags())
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s✅ Updated brand %s%s\n\n", SuccessStyle, profile.BrandName, Reset)
+	printBrandProfile(profile)
+	return nil
+}
+
+func runBrandRemove(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	profile, err := services.NewBrandProfileService(database).RemoveEntities(ctx, args[0], brandEntitiesFromFlags())
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s✅ Updated brand %s%s\n\n", SuccessStyle, profile.BrandName, Reset)
+	printBrandProfile(profile)
+	return nil
+}
+
+// brandEntitiesFromFlags collects the entity flags into a request
+func brandEntitiesFromFlags() models.BrandEntitiesRequest {
+	return models.BrandEntitiesRequest{
+		Aliases:          brandAliases,
+		ProductNames:     brandProducts,
+		Domains:          brandDomains,
+		NegativePatterns: brandNegativePatterns,
+	}
+}
+
+// printBrandProfile prints the entity dictionary of a brand profile
+func printBrandProfile(profile *models.BrandProfile) {
+	fmt.Printf("%sBrand Profile%s\n", FormatHeader(""), Reset)
+	fmt.Printf("%s=============%s\n", DimStyle, Reset)
+	fmt.Printf("%sBrand: %s\n", LabelStyle, FormatValue(profile.BrandName))
+	if profile.Website != "" {
+		fmt.Printf("%sWebsite: %s\n", LabelStyle, FormatSecondary(profile.Website))
+	}
+	fmt.Printf("%sAliases: %s\n", LabelStyle, FormatValue(formatEntityList(profile.Aliases)))
+	fmt.Printf("%sProducts: %s\n", LabelStyle, FormatValue(formatEntityList(profile.ProductNames)))
+	fmt.Printf("%sDomains: %s\n", LabelStyle, FormatValue(formatEntityList(profile.Domains)))
+	fmt.Printf("%sNegative patterns: %s\n", LabelStyle, FormatValue(formatEntityList(profile.NegativePatterns)))
+}
+
+func formatEntityList(entries []string) string {
+	if len(entries) == 0 {
+		return "-"
+	}
+	return strings.Join(entries, ", ")
+}
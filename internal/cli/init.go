@@ -5,8 +5,6 @@ import (
 	"context"
 	"fmt"
 	"os"
-	"os/exec"
-	"path/filepath"
 
 	"github.com/spf13/cobra"
 
@@ -48,7 +46,7 @@ func runInit(cmd *cobra.Command, args []string) error {
 	fmt.Println("--------------------------")
 	fmt.Println("Gego uses a hybrid approach:")
 	fmt.Println("  • SQLite for LLMs and Schedules (structured data)")
-	fmt.Println("  • MongoDB for Prompts and Responses (unstructured data)")
+	fmt.Println("  • MongoDB (or the same SQLite file) for Prompts and Responses (unstructured data)")
 	fmt.Println()
 
 	fmt.Println("🗄️  SQLite Configuration (for LLMs and Schedules)")
@@ -61,25 +59,36 @@ func runInit(cmd *cobra.Command, args []string) error {
 	cfg.SQLDatabase.Database = "gego"
 
 	fmt.Println("\n🍃 MongoDB Configuration (for Prompts and Responses)")
-	mongoURI, err2 := promptOptional(reader, "MongoDB URI [mongodb://localhost:27017]: ", "mongodb://localhost:27017")
-	if err2 != nil {
-		return err2
+	embedded, err := promptYesNo(reader, "No MongoDB server? Keep prompts and responses in the SQLite file instead (y/N): ")
+	if err != nil {
+		return err
+	}
+	if embedded {
+		cfg.NoSQLDatabase.Provider = "sqlite"
+		cfg.NoSQLDatabase.URI = sqlitePath
+	} else {
+		mongoURI, err := promptOptional(reader, "MongoDB URI [mongodb://localhost:27017]: ", "mongodb://localhost:27017")
+		if err != nil {
+			return err
+		}
+		cfg.NoSQLDatabase.Provider = "mongodb"
+		cfg.NoSQLDatabase.URI = mongoURI
 	}
-	cfg.NoSQLDatabase.Provider = "mongodb"
-	cfg.NoSQLDatabase.URI = mongoURI
 	cfg.NoSQLDatabase.Database = "gego"
 
 	fmt.Println("\n🔌 Testing database connections...")
 	sqlConfig := &models.Config{
-		Provider: cfg.SQLDatabase.Provider,
-		URI:      cfg.SQLDatabase.URI,
-		Database: cfg.SQLDatabase.Database,
+		Provider:        cfg.SQLDatabase.Provider,
+		URI:             cfg.SQLDatabase.URI,
+		Database:        cfg.SQLDatabase.Database,
+		SkipSchemaCheck: true,
 	}
 
 	nosqlConfig := &models.Config{
-		Provider: cfg.NoSQLDatabase.Provider,
-		URI:      cfg.NoSQLDatabase.URI,
-		Database: cfg.NoSQLDatabase.Database,
+		Provider:        cfg.NoSQLDatabase.Provider,
+		URI:             cfg.NoSQLDatabase.URI,
+		Database:        cfg.NoSQLDatabase.Database,
+		SkipSchemaCheck: true,
 	}
 
 	testDB, dbErr := db.New(sqlConfig, nosqlConfig)
@@ -103,13 +112,18 @@ func runInit(cmd *cobra.Command, args []string) error {
 	fmt.Println("✅ Database connection successful!")
 
 	fmt.Println("\n🔄 Running database migrations...")
-	if err := runMigrations(sqlitePath); err != nil {
+	if err := db.RunMigrations(ctx, testDB); err != nil {
 		fmt.Printf("❌ Failed to run migrations: %v\n", err)
-		fmt.Println("You may need to run migrations manually later.")
+		fmt.Println("Run 'gego migrate up' once the problem is fixed.")
 	} else {
 		fmt.Println("✅ Database migrations completed successfully!")
 	}
 
+	fmt.Println("\n🔑 Setting up the master key for LLM API keys...")
+	if err := ensureMasterKeyFile(cfg, configPath); err != nil {
+		return err
+	}
+
 	fmt.Println("\n💾 Saving configuration...")
 	if err := cfg.Save(configPath); err != nil {
 		return fmt.Errorf("failed to save config: %w", err)
@@ -127,7 +141,11 @@ func runInit(cmd *cobra.Command, args []string) error {
 	fmt.Println()
 	fmt.Println("ℹ️  Gego uses a hybrid database approach:")
 	fmt.Println("   • SQLite stores LLM configurations and schedules")
-	fmt.Println("   • MongoDB stores prompts and responses for keyword analysis")
+	if cfg.NoSQLDatabase.Provider == "sqlite" {
+		fmt.Println("   • SQLite also stores prompts and responses for keyword analysis")
+	} else {
+		fmt.Println("   • MongoDB stores prompts and responses for keyword analysis")
+	}
 	fmt.Println()
 	fmt.Println("Next steps:")
 	fmt.Println("  1. Add LLM providers: gego llm add")
@@ -141,41 +159,3 @@ func runInit(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
-
-// runMigrations executes database migrations using gomigrate
-func runMigrations(sqlitePath string) error {
-	if _, err := exec.LookPath("migrate"); err != nil {
-		return fmt.Errorf("migrate command not found. Please install golang-migrate: https://github.com/golang-migrate/migrate")
-	}
-
-	migrationsDir := filepath.Join("internal", "db", "migrations")
-
-	if _, err := os.Stat(migrationsDir); os.IsNotExist(err) {
-		return fmt.Errorf("migrations directory not found: %s", migrationsDir)
-	}
-
-	absSQLitePath, err := filepath.Abs(sqlitePath)
-	if err != nil {
-		return fmt.Errorf("failed to resolve SQLite path: %w", err)
-	}
-
-	dbURL := fmt.Sprintf("sqlite3://%s", absSQLitePath)
-
-	cmd := exec.Command("migrate",
-		"-path", migrationsDir,
-		"-database", dbURL,
-		"up")
-
-	cmd.Dir = "."
-
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		return fmt.Errorf("migration failed: %w\nOutput: %s", err, string(output))
-	}
-
-	if len(output) > 0 {
-		fmt.Printf("Migration output: %s", string(output))
-	}
-
-	return nil
-}
@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"context"
 	"fmt"
+	"maps"
 	"os"
 	"strings"
 	"text/tabwriter"
@@ -12,7 +13,9 @@ import (
 	"github.com/google/uuid"
 	"github.com/spf13/cobra"
 
+	"github.com/fissionx/gego/internal/llm/openaicompat"
 	"github.com/fissionx/gego/internal/models"
+	"github.com/fissionx/gego/internal/secrets"
 	"github.com/fissionx/gego/internal/services"
 )
 
@@ -22,16 +25,46 @@ var llmCmd = &cobra.Command{
 	Long:  `Add, list, update, and delete LLM provider configurations.`,
 }
 
+var (
+	llmProvider       string
+	llmModels         []string
+	llmName           string
+	llmAPIKey         string
+	llmBaseURL        string
+	llmHeaders        []string
+	llmModelsEndpoint string
+	llmEnabled        bool
+	llmSpecFile       string
+	llmDeleteAll      bool
+)
+
 var llmAddCmd = &cobra.Command{
 	Use:   "add",
 	Short: "Add a new LLM provider",
-	RunE:  runLLMAdd,
+	Long: `Add LLM models of a provider. Without flags, an interactive wizard lists the
+models of the provider to choose from. With --provider or --file, the models are
+added as given, without prompting.
+
+A spec file is YAML or JSON, holding one LLM spec or a list of them:
+
+  provider: openai
+  models: [gpt-4o, gpt-4o-mini]
+  api_key: env:OPENAI_API_KEY
+
+Other fields: name (with a single model), base_url, headers (a map, for
+openai-compatible), models_endpoint and enabled.`,
+	Example: `  gego llm add --provider openai --model gpt-4o --api-key env:OPENAI_API_KEY
+  gego llm add --provider ollama --model llama3.1 --base-url http://ollama:11434
+  gego llm add --file llms.yaml`,
+	Args: cobra.NoArgs,
+	RunE: runLLMAdd,
 }
 
 var llmListCmd = &cobra.Command{
-	Use:   "list",
-	Short: "List all LLM providers",
-	RunE:  runLLMList,
+	Use:         "list",
+	Short:       "List all LLM providers",
+	Annotations: structuredOutput,
+	RunE:        runLLMList,
 }
 
 var llmGetCmd = &cobra.Command{
@@ -42,11 +75,11 @@ var llmGetCmd = &cobra.Command{
 }
 
 var llmDeleteCmd = &cobra.Command{
-	Use:   "delete",
+	Use:   "delete [id...]",
 	Short: "Delete LLM providers",
-	Long:  `Delete LLM providers. Lists all LLMs and allows you to select which ones to delete.`,
-	Args:  cobra.NoArgs,
-	RunE:  runLLMDelete,
+	Long: `Delete LLM providers. Without IDs or --all, lists all LLMs and allows you to
+select which ones to delete. Use --yes to skip the confirmation.`,
+	RunE: runLLMDelete,
 }
 
 var llmEnableCmd = &cobra.Command{
@@ -66,11 +99,29 @@ var llmDisableCmd = &cobra.Command{
 var llmUpdateCmd = &cobra.Command{
 	Use:   "update [id]",
 	Short: "Update an LLM provider configuration",
-	Args:  cobra.ExactArgs(1),
-	RunE:  runLLMUpdate,
+	Long: `Update an LLM provider configuration. Without flags, asks for each setting.
+With flags, or a --file spec holding name, api_key, base_url, headers or
+enabled, only the given settings change.`,
+	Example: `  gego llm update 1b9d --api-key env:OPENAI_API_KEY
+  gego llm update 1b9d --enabled=false`,
+	Args: cobra.ExactArgs(1),
+	RunE: runLLMUpdate,
 }
 
 func init() {
+	llmAddCmd.Flags().StringVar(&llmProvider, "provider", "", "Provider: openai, anthropic, ollama, google, perplexity or openai-compatible")
+	llmAddCmd.Flags().StringSliceVar(&llmModels, "model", nil, "Model ID to add (repeatable)")
+	llmAddCmd.Flags().StringVar(&llmModelsEndpoint, "models-endpoint", "", "Models endpoint of an openai-compatible server ('none' if it has none)")
+	for _, cmd := range []*cobra.Command{llmAddCmd, llmUpdateCmd} {
+		cmd.Flags().StringVar(&llmName, "name", "", "Display name (defaults to the model ID)")
+		cmd.Flags().StringVar(&llmAPIKey, "api-key", "", "API key, or env:VAR_NAME / file:/path")
+		cmd.Flags().StringVar(&llmBaseURL, "base-url", "", "Base URL of an ollama or openai-compatible server")
+		cmd.Flags().StringSliceVar(&llmHeaders, "header", nil, "Extra request header Name=value for openai-compatible servers (repeatable)")
+		cmd.Flags().StringVarP(&llmSpecFile, "file", "f", "", "YAML or JSON spec file, - for standard input")
+	}
+	llmUpdateCmd.Flags().BoolVar(&llmEnabled, "enabled", true, "Enable or disable the LLM")
+	llmDeleteCmd.Flags().BoolVar(&llmDeleteAll, "all", false, "Delete all LLM providers")
+
 	llmCmd.AddCommand(llmAddCmd)
 	llmCmd.AddCommand(llmListCmd)
 	llmCmd.AddCommand(llmGetCmd)
@@ -78,12 +129,17 @@ func init() {
 	llmCmd.AddCommand(llmDeleteCmd)
 	llmCmd.AddCommand(llmEnableCmd)
 	llmCmd.AddCommand(llmDisableCmd)
+	llmCmd.AddCommand(llmRotateMasterKeyCmd)
 }
 
 func runLLMAdd(cmd *cobra.Command, args []string) error {
 	reader := bufio.NewReader(os.Stdin)
 	ctx := context.Background()
 
+	if llmSpecFile != "" || cmd.Flags().Changed("provider") {
+		return addLLMsFromSpecs(ctx, cmd)
+	}
+
 	fmt.Printf("%s➕ Add New LLM Models%s\n", FormatHeader(""), Reset)
 	fmt.Printf("%s====================%s\n", DimStyle, Reset)
 	fmt.Println()
@@ -94,31 +150,21 @@ func runLLMAdd(cmd *cobra.Command, args []string) error {
 		fmt.Printf("  %s%d. %s%s\n", CountStyle, i+1, Reset, FormatValue(provider.DisplayName()))
 	}
 
-	providerChoice, err := promptWithRetry(reader, "\nSelect provider (1, 2, 3, 4, or 5): ", func(input string) (string, error) {
-		switch input {
-		case "1", "2", "3", "4", "5":
-			return input, nil
-		default:
-			return "", fmt.Errorf("invalid provider choice: %s (choose 1, 2, 3, 4, or 5)", input)
+	providerChoice, err := promptWithRetry(reader, fmt.Sprintf("\nSelect provider (1-%d): ", len(providers)), func(input string) (string, error) {
+		var idx int
+		_, err := fmt.Sscanf(input, "%d", &idx)
+		if err != nil || idx < 1 || idx > len(providers) {
+			return "", fmt.Errorf("invalid provider choice: %s (choose 1-%d)", input, len(providers))
 		}
+		return input, nil
 	})
 	if err != nil {
 		return err
 	}
 
-	var selectedProvider services.Provider
-	switch providerChoice {
-	case "1":
-		selectedProvider = services.OpenAI
-	case "2":
-		selectedProvider = services.Anthropic
-	case "3":
-		selectedProvider = services.Ollama
-	case "4":
-		selectedProvider = services.Google
-	case "5":
-		selectedProvider = services.Perplexity
-	}
+	var providerIdx int
+	fmt.Sscanf(providerChoice, "%d", &providerIdx)
+	selectedProvider := providers[providerIdx-1]
 
 	providerName := selectedProvider.String()
 
@@ -160,7 +206,7 @@ func runLLMAdd(cmd *cobra.Command, args []string) error {
 				apiKey = existingKeys[choiceIdx-1]
 				fmt.Printf("%s✅ Using existing API key: %s%s\n", SuccessStyle, services.MaskAPIKey(apiKey), Reset)
 			} else {
-				apiKey, err = promptWithRetry(reader, "\nNew API Key: ", func(input string) (string, error) {
+				apiKey, err = promptWithRetry(reader, "\nNew API Key (or env:VAR_NAME / file:/path): ", func(input string) (string, error) {
 					if input == "" {
 						return "", fmt.Errorf("API key is required for %s", selectedProvider.DisplayName())
 					}
@@ -171,7 +217,7 @@ func runLLMAdd(cmd *cobra.Command, args []string) error {
 				}
 			}
 		} else {
-			apiKey, err = promptWithRetry(reader, "\nAPI Key: ", func(input string) (string, error) {
+			apiKey, err = promptWithRetry(reader, "\nAPI Key (or env:VAR_NAME / file:/path): ", func(input string) (string, error) {
 				if input == "" {
 					return "", fmt.Errorf("API key is required for %s", selectedProvider.DisplayName())
 				}
@@ -198,18 +244,76 @@ func runLLMAdd(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	options := make(map[string]string)
+
+	if selectedProvider == services.OpenAICompatible {
+		fmt.Printf("\n🌐 %s Configuration\n", selectedProvider.DisplayName())
+
+		baseURL, err = promptWithRetry(reader, "\nBase URL (e.g. http://localhost:8000/v1): ", func(input string) (string, error) {
+			if input == "" {
+				return "", fmt.Errorf("base URL is required for %s", selectedProvider.DisplayName())
+			}
+			return input, nil
+		})
+		if err != nil {
+			return err
+		}
+
+		apiKey, err = promptOptional(reader, "API Key, if the endpoint requires one (or env:VAR_NAME / file:/path): ", "")
+		if err != nil {
+			return err
+		}
+
+		headers, err := promptWithRetry(reader, "Extra headers (Name=value, comma-separated, optional): ", parseHeaderOptions)
+		if err != nil {
+			return err
+		}
+		for _, header := range splitNonEmpty(headers) {
+			name, value, _ := strings.Cut(header, "=")
+			options[openaicompat.HeaderOptionPrefix+strings.TrimSpace(name)] = strings.TrimSpace(value)
+		}
+
+		modelsEndpoint, err := promptOptional(reader, "Models endpoint [models] ('none' to enter model names yourself): ", "")
+		if err != nil {
+			return err
+		}
+		if modelsEndpoint != "" {
+			options[openaicompat.ModelsEndpointOption] = modelsEndpoint
+		}
+	}
+
 	fmt.Println("\n🔍 Fetching available models...")
 
-	provider, ok := llmRegistry.Get(providerName)
-	if !ok {
-		return fmt.Errorf("provider not found in registry: %s", providerName)
+	provider, err := llmFactory.New(providerName, apiKey, baseURL, options)
+	if err != nil {
+		return err
+	}
+
+	resolvedAPIKey, err := secrets.Resolve(apiKey)
+	if err != nil {
+		return err
 	}
 
-	availableModels, err := provider.ListModels(ctx, apiKey, baseURL)
+	availableModels, err := provider.ListModels(ctx, resolvedAPIKey, baseURL)
 	if err != nil {
 		return fmt.Errorf("failed to list models: %w", err)
 	}
 
+	if len(availableModels) == 0 && selectedProvider == services.OpenAICompatible {
+		names, err := promptWithRetry(reader, "\nModel names (comma-separated): ", func(input string) (string, error) {
+			if len(splitNonEmpty(input)) == 0 {
+				return "", fmt.Errorf("at least one model name is required")
+			}
+			return input, nil
+		})
+		if err != nil {
+			return err
+		}
+		for _, name := range splitNonEmpty(names) {
+			availableModels = append(availableModels, models.ModelInfo{ID: name, Name: name})
+		}
+	}
+
 	if len(availableModels) == 0 {
 		fmt.Println("\n⚠️  No models found for this provider")
 		return nil
@@ -263,32 +367,186 @@ func runLLMAdd(cmd *cobra.Command, args []string) error {
 
 	addedCount := 0
 	for _, model := range selectedModels {
-		llm := &models.LLMConfig{
-			ID:        uuid.New().String(),
-			Name:      model.Name,
-			Provider:  providerName,
-			Model:     model.ID,
-			APIKey:    apiKey,
-			BaseURL:   baseURL,
-			Enabled:   true,
-			Config:    make(map[string]string),
-			CreatedAt: time.Now(),
-			UpdatedAt: time.Now(),
-		}
-
-		if err := database.CreateLLM(ctx, llm); err != nil {
-			fmt.Printf("%s⚠️  Failed to add %s: %s%s\n", ErrorStyle, FormatValue(model.Name), FormatValue(err.Error()), Reset)
-			continue
+		if createLLM(ctx, newLLMConfig(model.Name, providerName, model.ID, apiKey, baseURL, options)) {
+			addedCount++
 		}
-
-		fmt.Printf("%s✅ Added: %s (ID: %s)%s\n", SuccessStyle, FormatValue(model.Name), FormatSecondary(llm.ID), Reset)
-		addedCount++
 	}
 
 	fmt.Printf("\n%s🎉 Successfully added %s/%s model(s)!%s\n", SuccessStyle, FormatCount(addedCount), FormatCount(len(selectedModels)), Reset)
 	return nil
 }
 
+// llmSpec describes LLMs to add or update, from flags or a spec file
+type llmSpec struct {
+	Provider       string            `yaml:"provider"`
+	Models         []string          `yaml:"models"`
+	Name           string            `yaml:"name"`
+	APIKey         string            `yaml:"api_key"`
+	BaseURL        string            `yaml:"base_url"`
+	Headers        map[string]string `yaml:"headers"`
+	ModelsEndpoint string            `yaml:"models_endpoint"`
+	Enabled        *bool             `yaml:"enabled"`
+}
+
+// llmSpecsFromCommand reads the LLM specs of --file, or builds one from the flags
+func llmSpecsFromCommand(cmd *cobra.Command) ([]llmSpec, error) {
+	if llmSpecFile != "" {
+		return readSpecs[llmSpec](llmSpecFile)
+	}
+
+	spec := llmSpec{
+		Provider:       llmProvider,
+		Models:         llmModels,
+		Name:           llmName,
+		APIKey:         llmAPIKey,
+		BaseURL:        llmBaseURL,
+		ModelsEndpoint: llmModelsEndpoint,
+	}
+	if cmd.Flags().Changed("enabled") {
+		spec.Enabled = &llmEnabled
+	}
+	if len(llmHeaders) > 0 {
+		if _, err := parseHeaderOptions(strings.Join(llmHeaders, ",")); err != nil {
+			return nil, err
+		}
+		spec.Headers = make(map[string]string)
+		for _, header := range llmHeaders {
+			name, value, _ := strings.Cut(header, "=")
+			spec.Headers[strings.TrimSpace(name)] = strings.TrimSpace(value)
+		}
+	}
+	return []llmSpec{spec}, nil
+}
+
+// options returns the provider options of an LLM spec
+func (spec llmSpec) options() map[string]string {
+	options := make(map[string]string)
+	for name, value := range spec.Headers {
+		options[openaicompat.HeaderOptionPrefix+name] = value
+	}
+	if spec.ModelsEndpoint != "" {
+		options[openaicompat.ModelsEndpointOption] = spec.ModelsEndpoint
+	}
+	return options
+}
+
+// addLLMsFromSpecs adds the LLMs of --file or the flags, without prompting
+func addLLMsFromSpecs(ctx context.Context, cmd *cobra.Command) error {
+	if llmSpecFile != "" && cmd.Flags().Changed("provider") {
+		return fmt.Errorf("--file and --provider cannot be combined")
+	}
+
+	specs, err := llmSpecsFromCommand(cmd)
+	if err != nil {
+		return err
+	}
+
+	// Check every spec before adding anything
+	for i, spec := range specs {
+		if err := validateLLMSpec(spec); err != nil {
+			if len(specs) > 1 {
+				return fmt.Errorf("LLM spec %d: %w", i+1, err)
+			}
+			return err
+		}
+	}
+
+	total, addedCount := 0, 0
+	for _, spec := range specs {
+		provider := services.FromString(spec.Provider)
+		baseURL := spec.BaseURL
+		if provider == services.Ollama && baseURL == "" {
+			baseURL = "http://localhost:11434"
+		}
+
+		for _, model := range spec.Models {
+			name := spec.Name
+			if name == "" {
+				name = model
+			}
+
+			llm := newLLMConfig(name, spec.Provider, model, spec.APIKey, baseURL, spec.options())
+			if spec.Enabled != nil {
+				llm.Enabled = *spec.Enabled
+			}
+
+			total++
+			if createLLM(ctx, llm) {
+				addedCount++
+			}
+		}
+	}
+
+	if addedCount < total {
+		return fmt.Errorf("added %d of %d LLM(s)", addedCount, total)
+	}
+	fmt.Printf("\n%s🎉 Successfully added %s model(s)!%s\n", SuccessStyle, FormatCount(addedCount), Reset)
+	return nil
+}
+
+// validateLLMSpec checks an LLM spec for the settings its provider needs
+func validateLLMSpec(spec llmSpec) error {
+	provider := services.FromString(spec.Provider)
+	if provider == 0 {
+		var names []string
+		for _, p := range services.AllProviders() {
+			names = append(names, p.String())
+		}
+		return fmt.Errorf("unknown provider %q: use one of %s", spec.Provider, strings.Join(names, ", "))
+	}
+
+	if len(spec.Models) == 0 {
+		return fmt.Errorf("at least one model is required")
+	}
+	if spec.Name != "" && len(spec.Models) > 1 {
+		return fmt.Errorf("a name can only be given with a single model")
+	}
+
+	switch provider {
+	case services.OpenAI, services.Anthropic, services.Google, services.Perplexity:
+		if spec.APIKey == "" {
+			return fmt.Errorf("API key is required for %s", provider.DisplayName())
+		}
+	case services.OpenAICompatible:
+		if spec.BaseURL == "" {
+			return fmt.Errorf("base URL is required for %s", provider.DisplayName())
+		}
+	}
+
+	if provider != services.OpenAICompatible && (len(spec.Headers) > 0 || spec.ModelsEndpoint != "") {
+		return fmt.Errorf("headers and models endpoint only apply to %s", services.OpenAICompatible.DisplayName())
+	}
+
+	return nil
+}
+
+// newLLMConfig builds the configuration of a new, enabled LLM
+func newLLMConfig(name, provider, model, apiKey, baseURL string, options map[string]string) *models.LLMConfig {
+	return &models.LLMConfig{
+		ID:        uuid.New().String(),
+		Name:      name,
+		Provider:  provider,
+		Model:     model,
+		APIKey:    apiKey,
+		BaseURL:   baseURL,
+		Enabled:   true,
+		Config:    maps.Clone(options),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+}
+
+// createLLM saves an LLM and reports the outcome
+func createLLM(ctx context.Context, llm *models.LLMConfig) bool {
+	if err := database.CreateLLM(ctx, llm); err != nil {
+		fmt.Printf("%s⚠️  Failed to add %s: %s%s\n", ErrorStyle, FormatValue(llm.Name), FormatValue(err.Error()), Reset)
+		return false
+	}
+
+	fmt.Printf("%s✅ Added: %s (ID: %s)%s\n", SuccessStyle, FormatValue(llm.Name), FormatSecondary(llm.ID), Reset)
+	return true
+}
+
 func runLLMList(cmd *cobra.Command, args []string) error {
 	ctx := context.Background()
 
@@ -298,6 +556,17 @@ func runLLMList(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to list LLMs: %w", err)
 	}
 
+	if isStructuredOutput() {
+		masked := make([]models.LLMConfig, 0, len(llms))
+		for _, llm := range llms {
+			if llm.APIKey != "" {
+				llm.APIKey = services.MaskAPIKey(llm.APIKey)
+			}
+			masked = append(masked, *llm)
+		}
+		return writeStructured(masked)
+	}
+
 	if len(llms) == 0 {
 		fmt.Printf("%sNo LLM providers configured. Use '%s' to add one.%s\n", WarningStyle, FormatSecondary("gego llm add"), Reset)
 		return nil
@@ -324,6 +593,12 @@ func runLLMList(cmd *cobra.Command, args []string) error {
 	w.Flush()
 	fmt.Printf("\n%sTotal: %s LLM providers%s\n", InfoStyle, FormatCount(len(llms)), Reset)
 
+	for _, llm := range llms {
+		if llm.KeyError != "" {
+			fmt.Printf("%s⚠️  %s is unusable: %s%s\n", WarningStyle, FormatValue(llm.Name), llm.KeyError, Reset)
+		}
+	}
+
 	return nil
 }
 
@@ -353,7 +628,7 @@ func runLLMGet(cmd *cobra.Command, args []string) error {
 
 	if len(llm.Config) > 0 {
 		fmt.Printf("\n%sConfiguration:%s\n", SuccessStyle, Reset)
-		for k, v := range llm.Config {
+		for k, v := range services.MaskLLMConfig(llm.Config) {
 			fmt.Printf("  %s: %s\n", FormatLabel(k), FormatValue(v))
 		}
 	}
@@ -380,6 +655,14 @@ func runLLMDelete(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
+	if llmDeleteAll || len(args) > 0 {
+		selectedLLMs, err := selectLLMsByID(llms, args)
+		if err != nil {
+			return err
+		}
+		return deleteLLMs(ctx, reader, llmService, selectedLLMs)
+	}
+
 	fmt.Printf("%sAvailable LLM providers:%s\n", LabelStyle, Reset)
 	fmt.Printf("%s==========================%s\n", DimStyle, Reset)
 	for i, llm := range llms {
@@ -429,6 +712,36 @@ func runLLMDelete(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	return deleteLLMs(ctx, reader, llmService, selectedLLMs)
+}
+
+// selectLLMsByID returns the LLMs with the given IDs, or all of them with --all
+func selectLLMsByID(llms []*models.LLMConfig, ids []string) ([]*models.LLMConfig, error) {
+	if llmDeleteAll {
+		if len(ids) > 0 {
+			return nil, fmt.Errorf("--all cannot be combined with LLM IDs")
+		}
+		return llms, nil
+	}
+
+	byID := make(map[string]*models.LLMConfig, len(llms))
+	for _, llm := range llms {
+		byID[llm.ID] = llm
+	}
+
+	var selected []*models.LLMConfig
+	for _, id := range ids {
+		llm, ok := byID[id]
+		if !ok {
+			return nil, fmt.Errorf("LLM not found: %s", id)
+		}
+		selected = append(selected, llm)
+	}
+	return selected, nil
+}
+
+// deleteLLMs deletes the selected LLMs once confirmed
+func deleteLLMs(ctx context.Context, reader *bufio.Reader, llmService *services.LLMService, selectedLLMs []*models.LLMConfig) error {
 	fmt.Printf("\n%s⚠️  Confirmation Required%s\n", WarningStyle, Reset)
 	fmt.Printf("%s========================%s\n", DimStyle, Reset)
 	fmt.Printf("%sThe following LLM(s) will be deleted:%s\n", LabelStyle, Reset)
@@ -437,7 +750,7 @@ func runLLMDelete(cmd *cobra.Command, args []string) error {
 	}
 	fmt.Println()
 
-	confirmed, err := promptYesNo(reader, fmt.Sprintf("%sAre you sure you want to delete %s LLM(s)? This action cannot be undone! (y/N): %s", ErrorStyle, FormatCount(len(selectedLLMs)), Reset))
+	confirmed, err := confirm(reader, fmt.Sprintf("%sAre you sure you want to delete %s LLM(s)? This action cannot be undone! (y/N): %s", ErrorStyle, FormatCount(len(selectedLLMs)), Reset))
 	if err != nil {
 		return err
 	}
@@ -498,6 +811,12 @@ func runLLMUpdate(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to get LLM: %w", err)
 	}
 
+	for _, flag := range []string{"name", "api-key", "base-url", "header", "enabled", "file"} {
+		if cmd.Flags().Changed(flag) {
+			return updateLLMFromSpec(ctx, cmd, llmService, llm)
+		}
+	}
+
 	fmt.Printf("🔄 Update LLM Provider: %s\n", llm.Name)
 	fmt.Println("================================")
 	fmt.Println()
@@ -523,7 +842,7 @@ func runLLMUpdate(cmd *cobra.Command, args []string) error {
 		llm.APIKey = apiKey
 	}
 
-	if provider == services.Ollama || llm.Provider == "custom" {
+	if provider == services.Ollama || provider == services.OpenAICompatible {
 		fmt.Print("Enter new base URL (press Enter to keep current): ")
 		baseURL, _ := reader.ReadString('\n')
 		baseURL = strings.TrimSpace(baseURL)
@@ -549,3 +868,69 @@ func runLLMUpdate(cmd *cobra.Command, args []string) error {
 	fmt.Println("\n✅ LLM provider updated successfully!")
 	return nil
 }
+
+// updateLLMFromSpec applies the settings of --file or the flags, without prompting
+func updateLLMFromSpec(ctx context.Context, cmd *cobra.Command, llmService *services.LLMService, llm *models.LLMConfig) error {
+	specs, err := llmSpecsFromCommand(cmd)
+	if err != nil {
+		return err
+	}
+	if len(specs) != 1 {
+		return fmt.Errorf("the spec file of an update must hold exactly one LLM, got %d", len(specs))
+	}
+	spec := specs[0]
+
+	if spec.Provider != "" || len(spec.Models) > 0 {
+		return fmt.Errorf("the provider and model of an LLM cannot be changed: add a new LLM instead")
+	}
+
+	if spec.Name != "" {
+		llm.Name = spec.Name
+	}
+	if spec.APIKey != "" {
+		llm.APIKey = spec.APIKey
+	}
+	if spec.BaseURL != "" {
+		llm.BaseURL = spec.BaseURL
+	}
+	if spec.Enabled != nil {
+		llm.Enabled = *spec.Enabled
+	}
+	if options := spec.options(); len(options) > 0 {
+		if llm.Config == nil {
+			llm.Config = make(map[string]string)
+		}
+		for name, value := range options {
+			llm.Config[name] = value
+		}
+	}
+
+	if err := llmService.UpdateLLM(ctx, llm); err != nil {
+		return fmt.Errorf("failed to update LLM: %w", err)
+	}
+
+	fmt.Printf("%s✅ Updated: %s%s\n", SuccessStyle, FormatValue(llm.Name), Reset)
+	return nil
+}
+
+// parseHeaderOptions validates a comma-separated list of Name=value headers
+func parseHeaderOptions(input string) (string, error) {
+	for _, header := range splitNonEmpty(input) {
+		name, _, ok := strings.Cut(header, "=")
+		if !ok || strings.TrimSpace(name) == "" {
+			return "", fmt.Errorf("invalid header: %s (use Name=value)", header)
+		}
+	}
+	return input, nil
+}
+
+// splitNonEmpty splits a comma-separated list, dropping empty entries
+func splitNonEmpty(input string) []string {
+	var values []string
+	for _, value := range strings.Split(input, ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
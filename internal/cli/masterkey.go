@@ -0,0 +1,156 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/fissionx/gego/internal/config"
+	"github.com/fissionx/gego/internal/db"
+	"github.com/fissionx/gego/internal/secrets"
+)
+
+// defaultMasterKeyFile is the master key file created by 'gego init', relative to the config directory
+const defaultMasterKeyFile = "master.key"
+
+var (
+	newMasterKeyFile     string
+	generateNewMasterKey bool
+)
+
+var llmRotateMasterKeyCmd = &cobra.Command{
+	Use:   "rotate-master-key",
+	Short: "Re-encrypt stored LLM API keys under a new master key",
+	Long: `Re-encrypt the API keys of all LLMs under a new master key.
+
+API keys are envelope encrypted: each key has its own data key, wrapped by the
+master key. Rotation rewraps the data keys under the new master key in a single
+transaction. Keys stored unencrypted are encrypted, env: and file: references
+are left alone.
+
+The config file is updated to use the new key file, unless the master key comes
+from GEGO_MASTER_KEY or GEGO_MASTER_KEY_FILE. Keep the old key until every gego
+process (API server, scheduler) has been restarted with the new one.`,
+	Example: `  gego llm rotate-master-key --new-key-file ~/.gego/master-2.key --generate
+  gego llm rotate-master-key --new-key-file /run/secrets/gego-master-key`,
+	Args: cobra.NoArgs,
+	RunE: runLLMRotateMasterKey,
+}
+
+func init() {
+	llmRotateMasterKeyCmd.Flags().StringVar(&newMasterKeyFile, "new-key-file", "", "File holding the new master key (required)")
+	llmRotateMasterKeyCmd.Flags().BoolVar(&generateNewMasterKey, "generate", false, "Generate the new master key and write it to --new-key-file")
+	llmRotateMasterKeyCmd.MarkFlagRequired("new-key-file")
+}
+
+// configureMasterKey loads the master key that encrypts LLM API keys at rest
+func configureMasterKey(cfg *config.Config, configPath string) error {
+	keyring, err := secrets.Load(resolveMasterKeyPath(cfg.MasterKeyFile, configPath))
+	if err != nil {
+		return fmt.Errorf("failed to load master key: %w", err)
+	}
+	secrets.SetDefault(keyring)
+	return nil
+}
+
+// resolveMasterKeyPath resolves a master key file relative to the config directory
+func resolveMasterKeyPath(keyFile, configPath string) string {
+	if keyFile == "" || filepath.IsAbs(keyFile) {
+		return keyFile
+	}
+	return filepath.Join(filepath.Dir(configPath), keyFile)
+}
+
+// ensureMasterKeyFile points the config at a master key file next to it,
+// generating the key unless one already exists
+func ensureMasterKeyFile(cfg *config.Config, configPath string) error {
+	if os.Getenv(secrets.MasterKeyEnv) != "" {
+		fmt.Printf("Using the master key from %s\n", secrets.MasterKeyEnv)
+		return nil
+	}
+
+	keyPath := resolveMasterKeyPath(defaultMasterKeyFile, configPath)
+	cfg.MasterKeyFile = defaultMasterKeyFile
+
+	if _, err := os.Stat(keyPath); err == nil {
+		if _, err := secrets.LoadMasterKeyFile(keyPath); err != nil {
+			return err
+		}
+		fmt.Printf("✅ Using existing master key: %s\n", keyPath)
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(keyPath), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	masterKey, err := secrets.GenerateMasterKey()
+	if err != nil {
+		return err
+	}
+	if err := secrets.WriteMasterKeyFile(keyPath, masterKey); err != nil {
+		return err
+	}
+
+	fmt.Printf("✅ Master key generated: %s\n", keyPath)
+	fmt.Println("   Back it up: stored LLM API keys cannot be decrypted without it.")
+	return nil
+}
+
+func runLLMRotateMasterKey(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	hybridDB, ok := database.(*db.HybridDB)
+	if !ok || hybridDB.GetSQLiteDatabase() == nil {
+		return fmt.Errorf("SQLite database not available")
+	}
+
+	keyPath, err := filepath.Abs(newMasterKeyFile)
+	if err != nil {
+		return fmt.Errorf("failed to resolve master key path: %w", err)
+	}
+
+	if generateNewMasterKey {
+		masterKey, err := secrets.GenerateMasterKey()
+		if err != nil {
+			return err
+		}
+		if err := secrets.WriteMasterKeyFile(keyPath, masterKey); err != nil {
+			return err
+		}
+		fmt.Printf("%s🔑 Generated new master key: %s%s\n", InfoStyle, keyPath, Reset)
+	}
+
+	next, err := secrets.LoadMasterKeyFile(keyPath)
+	if err != nil {
+		return err
+	}
+
+	current := secrets.Default()
+	if current != nil && current.ID() == next.ID() {
+		return fmt.Errorf("the new master key is the one already in use")
+	}
+
+	rotated, err := hybridDB.GetSQLiteDatabase().RotateLLMAPIKeys(ctx, current, next)
+	if err != nil {
+		return fmt.Errorf("failed to rotate master key: %w", err)
+	}
+	secrets.SetDefault(next)
+
+	fmt.Printf("%s✅ Re-encrypted %s API keys under master key %s%s\n", SuccessStyle, FormatCount(rotated), next.ID(), Reset)
+
+	if os.Getenv(secrets.MasterKeyEnv) != "" || os.Getenv("GEGO_MASTER_KEY_FILE") != "" {
+		fmt.Printf("%s⚠️  The master key is set through the environment: point GEGO_MASTER_KEY_FILE at %s (or unset GEGO_MASTER_KEY) before running gego again%s\n", WarningStyle, keyPath, Reset)
+		return nil
+	}
+
+	cfg.MasterKeyFile = keyPath
+	if err := cfg.Save(cfgFile); err != nil {
+		return fmt.Errorf("API keys were re-encrypted but the config could not be updated, set master_key_file to %s: %w", keyPath, err)
+	}
+	fmt.Printf("%sConfig updated: master_key_file = %s%s\n", DimStyle, keyPath, Reset)
+
+	return nil
+}
@@ -0,0 +1,224 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/fissionx/gego/internal/db"
+)
+
+var (
+	migrateNoSQL bool
+	migrateSteps int
+)
+
+var migrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Manage database schema migrations",
+	Long: `Apply, revert and inspect the schema migrations of both databases.
+
+The SQLite migrations are embedded in the gego binary. The MongoDB index and
+document migrations are versioned the same way, in a schema_migrations
+collection. The embedded SQLite document store creates its tables, and
+backfills older documents, on connect and has no migrations.
+
+Other commands refuse to use a SQLite database whose schema is not at the
+version of this build, and warn about pending MongoDB migrations.`,
+}
+
+var migrateUpCmd = &cobra.Command{
+	Use:   "up",
+	Short: "Apply all pending migrations",
+	Args:  cobra.NoArgs,
+	RunE:  runMigrateUp,
+}
+
+var migrateDownCmd = &cobra.Command{
+	Use:   "down",
+	Short: "Revert the last migrations of one database",
+	Example: `  gego migrate down
+  gego migrate down --steps 2
+  gego migrate down --nosql`,
+	Args: cobra.NoArgs,
+	RunE: runMigrateDown,
+}
+
+var migrateStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "List migrations and whether they are applied",
+	Args:  cobra.NoArgs,
+	RunE:  runMigrateStatus,
+}
+
+var migrateVersionCmd = &cobra.Command{
+	Use:   "version",
+	Short: "Show the schema version of both databases",
+	Args:  cobra.NoArgs,
+	RunE:  runMigrateVersion,
+}
+
+var migrateForceCmd = &cobra.Command{
+	Use:   "force [version]",
+	Short: "Record a schema version without running migrations",
+	Long: `Record the schema version of one database without running any migration.
+Use it after repairing a database left dirty by a failed migration, with the
+version the database is actually at.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runMigrateForce,
+}
+
+func init() {
+	migrateDownCmd.Flags().IntVar(&migrateSteps, "steps", 1, "Number of migrations to revert")
+	migrateDownCmd.Flags().BoolVar(&migrateNoSQL, "nosql", false, "Revert NoSQL migrations instead of SQLite ones")
+	migrateForceCmd.Flags().BoolVar(&migrateNoSQL, "nosql", false, "Force the NoSQL schema version instead of the SQLite one")
+
+	migrateCmd.AddCommand(migrateUpCmd)
+	migrateCmd.AddCommand(migrateDownCmd)
+	migrateCmd.AddCommand(migrateStatusCmd)
+	migrateCmd.AddCommand(migrateVersionCmd)
+	migrateCmd.AddCommand(migrateForceCmd)
+}
+
+// migrateTarget returns the database selected by --nosql
+func migrateTarget() string {
+	if migrateNoSQL {
+		return db.MigrateNoSQL
+	}
+	return db.MigrateSQL
+}
+
+func runMigrateUp(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	migrator, err := db.NewMigrator(database)
+	if err != nil {
+		return err
+	}
+
+	if err := migrator.Up(ctx); err != nil {
+		return err
+	}
+
+	fmt.Printf("%s✅ Migrations applied%s\n", SuccessStyle, Reset)
+	return printMigrationVersions(ctx, migrator)
+}
+
+func runMigrateDown(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	migrator, err := db.NewMigrator(database)
+	if err != nil {
+		return err
+	}
+
+	if err := migrator.Down(ctx, migrateTarget(), migrateSteps); err != nil {
+		return err
+	}
+
+	fmt.Printf("%s✅ Migrations reverted%s\n", SuccessStyle, Reset)
+	return printMigrationVersions(ctx, migrator)
+}
+
+func runMigrateForce(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	version, err := strconv.ParseUint(args[0], 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid version %q: %w", args[0], err)
+	}
+
+	migrator, err := db.NewMigrator(database)
+	if err != nil {
+		return err
+	}
+
+	if err := migrator.Force(ctx, migrateTarget(), uint(version)); err != nil {
+		return err
+	}
+
+	fmt.Printf("%s✅ Recorded %s schema version %d%s\n", SuccessStyle, migrateTarget(), version, Reset)
+	return nil
+}
+
+func runMigrateVersion(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	migrator, err := db.NewMigrator(database)
+	if err != nil {
+		return err
+	}
+	return printMigrationVersions(ctx, migrator)
+}
+
+func runMigrateStatus(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	migrator, err := db.NewMigrator(database)
+	if err != nil {
+		return err
+	}
+
+	statuses, err := migrator.Status(ctx)
+	if err != nil {
+		return err
+	}
+
+	for i, status := range statuses {
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Printf("%s%s (%s)%s\n", LabelStyle, status.Target, status.Provider, Reset)
+		if !status.Versioned {
+			fmt.Printf("%sNo migrations: the schema is created on connect%s\n", DimStyle, Reset)
+			continue
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tDESCRIPTION\tSTATE")
+		for _, migration := range status.Migrations {
+			state := "applied"
+			switch {
+			case migration.Version > status.Version:
+				state = "pending"
+			case migration.Version == status.Version && status.Dirty:
+				state = "dirty"
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\n", migration.Version, migration.Description, state)
+		}
+		w.Flush()
+	}
+
+	return nil
+}
+
+// printMigrationVersions prints the schema version of both databases
+func printMigrationVersions(ctx context.Context, migrator *db.Migrator) error {
+	statuses, err := migrator.Status(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, status := range statuses {
+		label := FormatLabel(fmt.Sprintf("%s (%s):", status.Target, status.Provider))
+		if !status.Versioned {
+			fmt.Printf("%s %s\n", label, FormatDim("not versioned"))
+			continue
+		}
+
+		state := ""
+		switch {
+		case status.Dirty:
+			state = " " + FormatError("dirty: run 'gego migrate force' once repaired")
+		case status.Pending() > 0:
+			state = " " + FormatWarning(fmt.Sprintf("%d pending: run 'gego migrate up'", status.Pending()))
+		}
+		fmt.Printf("%s %s of %s%s\n", label, FormatValue(fmt.Sprint(status.Version)), FormatValue(fmt.Sprint(status.Latest)), state)
+	}
+
+	return nil
+}
@@ -0,0 +1,145 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// Output formats of --output
+const (
+	OutputTable = "table"
+	OutputJSON  = "json"
+	OutputYAML  = "yaml"
+)
+
+// annotationStructuredOutput marks the commands that render --output json and yaml
+const annotationStructuredOutput = "gego/structured-output"
+
+var (
+	outputFormat string
+	assumeYes    bool
+)
+
+// structuredOutput is the annotation of commands supporting --output json and yaml
+var structuredOutput = map[string]string{annotationStructuredOutput: "true"}
+
+// checkOutputFormat validates --output for the command about to run
+func checkOutputFormat(cmd *cobra.Command) error {
+	switch outputFormat {
+	case OutputTable:
+		return nil
+	case OutputJSON, OutputYAML:
+		if cmd.Annotations[annotationStructuredOutput] == "" {
+			return fmt.Errorf("--output %s is not supported by '%s'", outputFormat, cmd.CommandPath())
+		}
+		return nil
+	default:
+		return fmt.Errorf("invalid --output %q: use %s, %s or %s", outputFormat, OutputTable, OutputJSON, OutputYAML)
+	}
+}
+
+// isStructuredOutput reports whether results are rendered as JSON or YAML
+// rather than as tables
+func isStructuredOutput() bool {
+	return outputFormat == OutputJSON || outputFormat == OutputYAML
+}
+
+// writeStructured renders v to stdout in the --output format. YAML output
+// uses the JSON field names, so both formats carry the same keys.
+func writeStructured(v any) error {
+	return encodeStructured(os.Stdout, outputFormat, v)
+}
+
+func encodeStructured(w io.Writer, format string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode output: %w", err)
+	}
+
+	if format == OutputJSON {
+		_, err := fmt.Fprintf(w, "%s\n", data)
+		return err
+	}
+
+	// JSON is YAML in flow style: decode it into a node, keeping the key
+	// order, and re-encode it in block style
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return fmt.Errorf("failed to encode output: %w", err)
+	}
+	blockStyle(&node)
+
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&node); err != nil {
+		return fmt.Errorf("failed to encode output: %w", err)
+	}
+	return encoder.Close()
+}
+
+// blockStyle clears the flow and quoting styles a YAML node tree got from JSON
+func blockStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		blockStyle(child)
+	}
+}
+
+// confirm asks a yes/no question, answered yes by --yes
+func confirm(reader *bufio.Reader, prompt string) (bool, error) {
+	if assumeYes {
+		return true, nil
+	}
+	return promptYesNo(reader, prompt)
+}
+
+// readSpecs reads a YAML or JSON spec file holding one object or a list of
+// objects. With -, the spec is read from standard input.
+func readSpecs[T any](path string) ([]T, error) {
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read spec file: %w", err)
+	}
+
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return nil, fmt.Errorf("invalid spec file: %w", err)
+	}
+
+	if len(node.Content) == 1 && node.Content[0].Kind == yaml.SequenceNode {
+		var specs []T
+		if err := decodeSpec(data, &specs); err != nil {
+			return nil, err
+		}
+		return specs, nil
+	}
+
+	var spec T
+	if err := decodeSpec(data, &spec); err != nil {
+		return nil, err
+	}
+	return []T{spec}, nil
+}
+
+// decodeSpec decodes YAML, or JSON as a subset of it, rejecting unknown fields
+func decodeSpec(data []byte, v any) error {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(v); err != nil {
+		return fmt.Errorf("invalid spec file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestEncodeStructured(t *testing.T) {
+	v := []struct {
+		Name    string   `json:"name"`
+		Tags    []string `json:"tags"`
+		Enabled bool     `json:"enabled"`
+	}{{Name: "gpt-4o", Tags: []string{"a", "b"}, Enabled: true}}
+
+	var out bytes.Buffer
+	if err := encodeStructured(&out, OutputYAML, v); err != nil {
+		t.Fatalf("encodeStructured(yaml) error = %v", err)
+	}
+	want := "- name: gpt-4o\n  tags:\n    - a\n    - b\n  enabled: true\n"
+	if out.String() != want {
+		t.Errorf("encodeStructured(yaml) =\n%s\nwant\n%s", out.String(), want)
+	}
+
+	out.Reset()
+	if err := encodeStructured(&out, OutputJSON, v); err != nil {
+		t.Fatalf("encodeStructured(json) error = %v", err)
+	}
+	if !bytes.HasPrefix(out.Bytes(), []byte("[\n  {\n    \"name\": \"gpt-4o\"")) {
+		t.Errorf("encodeStructured(json) = %s", out.String())
+	}
+}
+
+func TestReadSpecs(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	specs, err := readSpecs[promptSpec](write("one.yaml", "template: Best CRM?\ntags: [crm]\n"))
+	if err != nil || len(specs) != 1 || specs[0].Template != "Best CRM?" || len(specs[0].Tags) != 1 {
+		t.Errorf("readSpecs(object) = %+v, %v", specs, err)
+	}
+
+	specs, err = readSpecs[promptSpec](write("list.json", `[{"template": "a"}, {"template": "b", "enabled": false}]`))
+	if err != nil || len(specs) != 2 || specs[1].Enabled == nil || *specs[1].Enabled {
+		t.Errorf("readSpecs(list) = %+v, %v", specs, err)
+	}
+
+	if _, err := readSpecs[promptSpec](write("typo.yaml", "templat: a\n")); err == nil {
+		t.Error("readSpecs() accepted an unknown field")
+	}
+
+	schedules, err := readSpecs[scheduleSpec](write("schedule.yaml", "name: daily\nprompts: all\nllms: [a, b]\n"))
+	if err != nil || len(schedules) != 1 || len(schedules[0].Prompts) != 1 || schedules[0].Prompts[0] != "all" || len(schedules[0].LLMs) != 2 {
+		t.Errorf("readSpecs(schedule) = %+v, %v", schedules, err)
+	}
+}
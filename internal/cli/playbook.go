@@ -0,0 +1,104 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/fissionx/gego/internal/config"
+	"github.com/fissionx/gego/internal/services"
+)
+
+// defaultPlaybookFile is the playbook file of configs predating it
+const defaultPlaybookFile = "playbooks.yaml"
+
+var playbookBrand string
+
+var playbookCmd = &cobra.Command{
+	Use:   "playbook",
+	Short: "Inspect the recommendation playbooks",
+	Long: `Inspect the playbooks that turn source, competitive and position analytics
+into recommendations.
+
+The built-in playbook is patched by the playbook file (playbooks.yaml next to
+the config file): playbooks without brands apply to every brand, the others to
+the brands they list.`,
+}
+
+var playbookShowCmd = &cobra.Command{
+	Use:         "show",
+	Short:       "Show the rules in effect for a brand",
+	Example:     `  gego playbook show --brand HubSpot`,
+	Annotations: structuredOutput,
+	RunE:        runPlaybookShow,
+}
+
+var playbookCheckCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Validate the playbook file",
+	RunE:  runPlaybookCheck,
+}
+
+func init() {
+	playbookShowCmd.Flags().StringVar(&playbookBrand, "brand", "", "Brand whose playbooks to apply")
+
+	playbookCmd.AddCommand(playbookShowCmd)
+	playbookCmd.AddCommand(playbookCheckCmd)
+}
+
+// configurePlaybooks points recommendations at the playbook file, resolved
+// relative to the config directory
+func configurePlaybooks(cfg *config.Config, configPath string) {
+	playbookPath := cfg.PlaybookPath
+	if playbookPath == "" {
+		playbookPath = defaultPlaybookFile
+	}
+	if !filepath.IsAbs(playbookPath) {
+		playbookPath = filepath.Join(filepath.Dir(configPath), playbookPath)
+	}
+	services.SetPlaybookPath(playbookPath)
+}
+
+func runPlaybookShow(cmd *cobra.Command, args []string) error {
+	if _, err := services.CheckPlaybookFile(); err != nil {
+		return err
+	}
+
+	playbook := services.NewRecommendationsEngine().Playbooks(playbookBrand).Effective
+	if isStructuredOutput() {
+		return writeStructured(playbook)
+	}
+
+	fmt.Printf("%sPlaybooks:%s %s\n\n", LabelStyle, Reset, FormatValue(fmt.Sprint(playbook.Playbooks)))
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "%sRULE\tSCOPE\tPRIORITY\tCONDITIONS\tTITLE%s\n", LabelStyle, Reset)
+	fmt.Fprintf(w, "%s────\t─────\t────────\t──────────\t─────%s\n", DimStyle, Reset)
+	for _, rule := range playbook.Rules {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
+			FormatValue(rule.ID),
+			rule.Scope,
+			rule.Priority,
+			FormatCount(len(rule.When)),
+			rule.Title,
+		)
+	}
+	return w.Flush()
+}
+
+func runPlaybookCheck(cmd *cobra.Command, args []string) error {
+	path, err := services.CheckPlaybookFile()
+	if err != nil {
+		return err
+	}
+
+	if _, statErr := os.Stat(path); statErr != nil {
+		fmt.Printf("%sNo playbook file at %s: the built-in playbook applies%s\n", WarningStyle, path, Reset)
+		return nil
+	}
+	fmt.Printf("%s✅ %s is valid%s\n", SuccessStyle, path, Reset)
+	return nil
+}
@@ -13,11 +13,8 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/fissionx/gego/internal/llm"
-	"github.com/fissionx/gego/internal/llm/anthropic"
-	"github.com/fissionx/gego/internal/llm/google"
-	"github.com/fissionx/gego/internal/llm/ollama"
-	"github.com/fissionx/gego/internal/llm/openai"
 	"github.com/fissionx/gego/internal/models"
+	"github.com/fissionx/gego/internal/services"
 )
 
 var promptCmd = &cobra.Command{
@@ -29,15 +26,31 @@ var promptCmd = &cobra.Command{
 var promptAddCmd = &cobra.Command{
 	Use:   "add",
 	Short: "Add a new prompt template",
-	Long:  `Create a new prompt template that will be used to generate text for LLM analysis and keyword tracking.`,
-	RunE:  runPromptAdd,
+	Long: `Create a new prompt template that will be used to generate text for LLM analysis and keyword tracking.
+
+Without flags, asks how to create the prompt. With --template, adds that
+prompt; with --generate, has an LLM generate prompts and saves them once
+confirmed (or with --yes). A --file spec holds one prompt or a list of them:
+
+  - template: What are the best CRM tools for startups?
+    tags: [crm, startups]
+    category: software
+    domain: saas
+    brand: Acme
+    enabled: true`,
+	Example: `  gego prompt add --template "What are the best CRM tools?" --tag crm --category software
+  gego prompt add --file prompts.yaml
+  gego prompt add --generate "questions about streaming services" --llm 1b9d --language EN --count 10 --yes`,
+	Args: cobra.NoArgs,
+	RunE: runPromptAdd,
 }
 
 var promptListCmd = &cobra.Command{
-	Use:   "list",
-	Short: "List all prompt templates",
-	Long:  `Display all configured prompt templates used for keyword tracking.`,
-	RunE:  runPromptList,
+	Use:         "list",
+	Short:       "List all prompt templates",
+	Long:        `Display all configured prompt templates used for keyword tracking.`,
+	Annotations: structuredOutput,
+	RunE:        runPromptList,
 }
 
 var promptGetCmd = &cobra.Command{
@@ -49,11 +62,11 @@ var promptGetCmd = &cobra.Command{
 }
 
 var promptDeleteCmd = &cobra.Command{
-	Use:   "delete",
+	Use:   "delete [id...]",
 	Short: "Delete prompt templates",
-	Long:  `Remove prompt templates from the keyword tracking system. Lists all prompts and allows selection by number.`,
-	Args:  cobra.NoArgs,
-	RunE:  runPromptDelete,
+	Long: `Remove prompt templates from the keyword tracking system. Without IDs or --all,
+lists all prompts and allows selection by number. --yes skips the confirmation.`,
+	RunE: runPromptDelete,
 }
 
 var promptEnableCmd = &cobra.Command{
@@ -72,19 +85,65 @@ var promptDisableCmd = &cobra.Command{
 	RunE:  runPromptDisable,
 }
 
+var promptBackfillCmd = &cobra.Command{
+	Use:   "backfill",
+	Short: "Copy prompt metadata onto existing responses",
+	Long: `Snapshot each prompt's category, domain, prompt type and tags onto the responses
+that were saved before this metadata was recorded at execution time.`,
+	Args: cobra.NoArgs,
+	RunE: runPromptBackfill,
+}
+
+var (
+	promptTemplate  string
+	promptTags      []string
+	promptCategory  string
+	promptDomain    string
+	promptBrand     string
+	promptSpecFile  string
+	promptGenerate  string
+	promptLLMID     string
+	promptLanguage  string
+	promptCount     int
+	promptDeleteAll bool
+)
+
 func init() {
+	promptAddCmd.Flags().StringVar(&promptTemplate, "template", "", "Prompt template to add")
+	promptAddCmd.Flags().StringSliceVar(&promptTags, "tag", nil, "Tag of the prompt (repeatable)")
+	promptAddCmd.Flags().StringVar(&promptCategory, "category", "", "Category of the prompt")
+	promptAddCmd.Flags().StringVar(&promptDomain, "domain", "", "Domain of the prompt")
+	promptAddCmd.Flags().StringVar(&promptBrand, "brand", "", "Brand the prompt is about")
+	promptAddCmd.Flags().StringVarP(&promptSpecFile, "file", "f", "", "YAML or JSON spec file, - for standard input")
+	promptAddCmd.Flags().StringVar(&promptGenerate, "generate", "", "Generate prompts matching this description with an LLM")
+	promptAddCmd.Flags().StringVar(&promptLLMID, "llm", "", "ID of the LLM generating prompts")
+	promptAddCmd.Flags().StringVar(&promptLanguage, "language", "EN", "Language code of the generated prompts")
+	promptAddCmd.Flags().IntVar(&promptCount, "count", 20, "Number of prompts to generate")
+	promptDeleteCmd.Flags().BoolVar(&promptDeleteAll, "all", false, "Delete all prompts")
+
 	promptCmd.AddCommand(promptAddCmd)
 	promptCmd.AddCommand(promptListCmd)
 	promptCmd.AddCommand(promptGetCmd)
 	promptCmd.AddCommand(promptDeleteCmd)
 	promptCmd.AddCommand(promptEnableCmd)
 	promptCmd.AddCommand(promptDisableCmd)
+	promptCmd.AddCommand(promptBackfillCmd)
 }
 
 func runPromptAdd(cmd *cobra.Command, args []string) error {
 	reader := bufio.NewReader(os.Stdin)
 	ctx := context.Background()
 
+	if promptGenerate != "" {
+		if promptTemplate != "" || promptSpecFile != "" {
+			return fmt.Errorf("--generate cannot be combined with --template or --file")
+		}
+		return generatePromptsFromFlags(ctx, reader)
+	}
+	if promptTemplate != "" || promptSpecFile != "" {
+		return addPromptsFromSpecs(ctx)
+	}
+
 	fmt.Printf("%s➕ Add New Prompt Template%s\n", FormatHeader(""), Reset)
 	fmt.Printf("%s==========================%s\n", DimStyle, Reset)
 	fmt.Println()
@@ -123,6 +182,13 @@ func runPromptList(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to list prompts: %w", err)
 	}
 
+	if isStructuredOutput() {
+		if prompts == nil {
+			prompts = []*models.Prompt{}
+		}
+		return writeStructured(prompts)
+	}
+
 	if len(prompts) == 0 {
 		fmt.Printf("%sNo prompts configured. Use '%s' to add one.%s\n", WarningStyle, FormatSecondary("gego prompt add"), Reset)
 		return nil
@@ -202,6 +268,14 @@ func runPromptDelete(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
+	if promptDeleteAll || len(args) > 0 {
+		selected, err := selectPromptsByID(prompts, args)
+		if err != nil {
+			return err
+		}
+		return deletePrompts(ctx, reader, selected)
+	}
+
 	fmt.Printf("\n%sAvailable prompts:%s\n", LabelStyle, Reset)
 	fmt.Printf("%s==================%s\n", DimStyle, Reset)
 	for i, prompt := range prompts {
@@ -248,8 +322,42 @@ func runPromptDelete(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
-	fmt.Printf("\n%s⚠️  You are about to delete %s prompt(s). This action cannot be undone!%s\n", ErrorStyle, FormatCount(len(selectedIndices)), Reset)
-	confirmed, err := promptYesNo(reader, fmt.Sprintf("%sAre you sure? (y/N): %s", ErrorStyle, Reset))
+	var selected []*models.Prompt
+	for _, idx := range selectedIndices {
+		selected = append(selected, prompts[idx])
+	}
+	return deletePrompts(ctx, reader, selected)
+}
+
+// selectPromptsByID returns the prompts with the given IDs, or all of them with --all
+func selectPromptsByID(prompts []*models.Prompt, ids []string) ([]*models.Prompt, error) {
+	if promptDeleteAll {
+		if len(ids) > 0 {
+			return nil, fmt.Errorf("--all cannot be combined with prompt IDs")
+		}
+		return prompts, nil
+	}
+
+	byID := make(map[string]*models.Prompt, len(prompts))
+	for _, prompt := range prompts {
+		byID[prompt.ID] = prompt
+	}
+
+	var selected []*models.Prompt
+	for _, id := range ids {
+		prompt, ok := byID[id]
+		if !ok {
+			return nil, fmt.Errorf("prompt not found: %s", id)
+		}
+		selected = append(selected, prompt)
+	}
+	return selected, nil
+}
+
+// deletePrompts deletes the selected prompts once confirmed
+func deletePrompts(ctx context.Context, reader *bufio.Reader, selected []*models.Prompt) error {
+	fmt.Printf("\n%s⚠️  You are about to delete %s prompt(s). This action cannot be undone!%s\n", ErrorStyle, FormatCount(len(selected)), Reset)
+	confirmed, err := confirm(reader, fmt.Sprintf("%sAre you sure? (y/N): %s", ErrorStyle, Reset))
 	if err != nil {
 		return err
 	}
@@ -261,10 +369,9 @@ func runPromptDelete(cmd *cobra.Command, args []string) error {
 
 	fmt.Printf("\n%s🗑️  Deleting selected prompts...%s\n", InfoStyle, Reset)
 	deletedCount := 0
-	for _, idx := range selectedIndices {
-		prompt := prompts[idx]
+	for _, prompt := range selected {
 		if err := database.DeletePrompt(ctx, prompt.ID); err != nil {
-			fmt.Printf("%s⚠️  Failed to delete prompt %s: %s%s\n", ErrorStyle, FormatCount(idx+1), FormatValue(err.Error()), Reset)
+			fmt.Printf("%s⚠️  Failed to delete prompt %s: %s%s\n", ErrorStyle, FormatSecondary(prompt.ID), FormatValue(err.Error()), Reset)
 			continue
 		}
 		deletedCount++
@@ -409,6 +516,33 @@ func runPromptGenerate(reader *bufio.Reader, ctx context.Context) error {
 	var promptCount int
 	fmt.Sscanf(promptCountStr, "%d", &promptCount)
 
+	return generatePrompts(ctx, reader, selectedLLM, languageCode, userInput, promptCount)
+}
+
+// generatePromptsFromFlags generates prompts with the LLM and settings of the flags
+func generatePromptsFromFlags(ctx context.Context, reader *bufio.Reader) error {
+	if promptLLMID == "" {
+		return fmt.Errorf("--llm is required with --generate")
+	}
+	if promptCount < 1 {
+		return fmt.Errorf("--count must be at least 1")
+	}
+	languageCode := strings.ToUpper(strings.TrimSpace(promptLanguage))
+	if len(languageCode) < 2 || len(languageCode) > 3 {
+		return fmt.Errorf("invalid --language %q: use a 2-3 character code (e.g., FR, EN, IT)", promptLanguage)
+	}
+
+	selectedLLM, err := database.GetLLM(ctx, promptLLMID)
+	if err != nil {
+		return fmt.Errorf("failed to get LLM: %w", err)
+	}
+
+	return generatePrompts(ctx, reader, selectedLLM, languageCode, promptGenerate, promptCount)
+}
+
+// generatePrompts asks an LLM for prompts matching the description and saves
+// them once confirmed
+func generatePrompts(ctx context.Context, reader *bufio.Reader, selectedLLM *models.LLMConfig, languageCode, userInput string, promptCount int) error {
 	fmt.Printf("\n%s📋 Fetching existing prompts...%s\n", InfoStyle, Reset)
 	existingPrompts, err := database.ListPrompts(ctx, nil)
 	if err != nil {
@@ -426,18 +560,9 @@ func runPromptGenerate(reader *bufio.Reader, ctx context.Context) error {
 
 	prePrompt := llm.GenerateGEOPromptTemplate(userInput, existingPromptTemplates, languageCode, promptCount)
 
-	var provider llm.Provider
-	switch selectedLLM.Provider {
-	case "openai":
-		provider = openai.New(selectedLLM.APIKey, selectedLLM.BaseURL)
-	case "anthropic":
-		provider = anthropic.New(selectedLLM.APIKey, selectedLLM.BaseURL)
-	case "ollama":
-		provider = ollama.New(selectedLLM.BaseURL)
-	case "google":
-		provider = google.New(selectedLLM.APIKey, selectedLLM.BaseURL)
-	default:
-		return fmt.Errorf("unsupported LLM provider: %s", selectedLLM.Provider)
+	provider, err := llmFactory.Get(selectedLLM)
+	if err != nil {
+		return err
 	}
 
 	response, err := provider.Generate(ctx, prePrompt, llm.Config{
@@ -480,11 +605,12 @@ func runPromptGenerate(reader *bufio.Reader, ctx context.Context) error {
 		fmt.Printf("%s%d. %s%s\n", CountStyle, i+1, Reset, FormatValue(prompt))
 	}
 
-	fmt.Printf("\n%sWould you like to save all %s generated prompts? (y/n): %s", LabelStyle, FormatCount(len(generatedPrompts)), Reset)
-	choice, _ := reader.ReadString('\n')
-	choice = strings.TrimSpace(strings.ToLower(choice))
+	confirmed, err := confirm(reader, fmt.Sprintf("\n%sWould you like to save all %s generated prompts? (y/n): %s", LabelStyle, FormatCount(len(generatedPrompts)), Reset))
+	if err != nil {
+		return err
+	}
 
-	if choice != "y" && choice != "yes" {
+	if !confirmed {
 		fmt.Printf("%sOperation cancelled.%s\n", WarningStyle, Reset)
 		return nil
 	}
@@ -513,6 +639,74 @@ func runPromptGenerate(reader *bufio.Reader, ctx context.Context) error {
 	return nil
 }
 
+// promptSpec describes a prompt to add, from flags or a spec file
+type promptSpec struct {
+	Template string   `yaml:"template"`
+	Tags     []string `yaml:"tags"`
+	Category string   `yaml:"category"`
+	Domain   string   `yaml:"domain"`
+	Brand    string   `yaml:"brand"`
+	Enabled  *bool    `yaml:"enabled"`
+}
+
+// addPromptsFromSpecs adds the prompts of --file or the flags, without prompting
+func addPromptsFromSpecs(ctx context.Context) error {
+	var specs []promptSpec
+	if promptSpecFile != "" {
+		if promptTemplate != "" {
+			return fmt.Errorf("--file and --template cannot be combined")
+		}
+		var err error
+		if specs, err = readSpecs[promptSpec](promptSpecFile); err != nil {
+			return err
+		}
+	} else {
+		specs = []promptSpec{{
+			Template: promptTemplate,
+			Tags:     promptTags,
+			Category: promptCategory,
+			Domain:   promptDomain,
+			Brand:    promptBrand,
+		}}
+	}
+
+	// Check every spec before adding anything
+	for i, spec := range specs {
+		if strings.TrimSpace(spec.Template) == "" {
+			if len(specs) > 1 {
+				return fmt.Errorf("prompt spec %d: template is required", i+1)
+			}
+			return fmt.Errorf("prompt template cannot be empty")
+		}
+	}
+
+	for _, spec := range specs {
+		prompt := &models.Prompt{
+			ID:       uuid.New().String(),
+			Template: strings.TrimSpace(spec.Template),
+			Category: spec.Category,
+			Domain:   spec.Domain,
+			Brand:    spec.Brand,
+			Enabled:  true,
+		}
+		for _, tag := range spec.Tags {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				prompt.Tags = append(prompt.Tags, tag)
+			}
+		}
+		if spec.Enabled != nil {
+			prompt.Enabled = *spec.Enabled
+		}
+
+		if err := database.CreatePrompt(ctx, prompt); err != nil {
+			return fmt.Errorf("failed to create prompt: %w", err)
+		}
+		fmt.Printf("%s✅ Added prompt: %s%s\n", SuccessStyle, FormatSecondary(prompt.ID), Reset)
+	}
+
+	return nil
+}
+
 // runPromptCustom allows users to add a custom prompt
 func runPromptCustom(reader *bufio.Reader, ctx context.Context) error {
 	fmt.Printf("\n%s✏️  Add Custom Prompt%s\n", FormatHeader(""), Reset)
@@ -559,3 +753,17 @@ func runPromptCustom(reader *bufio.Reader, ctx context.Context) error {
 
 	return nil
 }
+
+func runPromptBackfill(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	fmt.Printf("%s⏳ Backfilling prompt metadata onto responses...%s\n", InfoStyle, Reset)
+
+	updated, err := services.NewPromptManagementService(database).BackfillResponseMetadata(ctx)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s✅ Updated %s responses%s\n", SuccessStyle, FormatCount(updated), Reset)
+	return nil
+}
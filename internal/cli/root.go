@@ -12,11 +12,7 @@ import (
 	"github.com/fissionx/gego/internal/config"
 	"github.com/fissionx/gego/internal/db"
 	"github.com/fissionx/gego/internal/llm"
-	"github.com/fissionx/gego/internal/llm/anthropic"
-	"github.com/fissionx/gego/internal/llm/google"
-	"github.com/fissionx/gego/internal/llm/ollama"
-	"github.com/fissionx/gego/internal/llm/openai"
-	"github.com/fissionx/gego/internal/llm/perplexity"
+	"github.com/fissionx/gego/internal/llm/providers"
 	"github.com/fissionx/gego/internal/logger"
 	"github.com/fissionx/gego/internal/models"
 	"github.com/fissionx/gego/internal/services"
@@ -29,7 +25,7 @@ var (
 	logFile      string
 	cfg          *config.Config
 	database     db.Database
-	llmRegistry  *llm.Registry
+	llmFactory   *llm.Factory
 	sched        *services.SchedulerService
 	statsService *services.StatsService
 )
@@ -44,6 +40,10 @@ and analyzes brand mentions in their responses.
 Track which brands appear most frequently, which prompts generate the most mentions,
 and compare performance across different LLM providers.`,
 	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if err := checkOutputFormat(cmd); err != nil {
+			return err
+		}
+
 		if err := initializeLogging(); err != nil {
 			return fmt.Errorf("failed to initialize logging: %w", err)
 		}
@@ -75,18 +75,31 @@ and compare performance across different LLM providers.`,
 			shared.SetExclusionFilePath(exclusionPath)
 		}
 
+		services.SetGEOJudgeLLMID(cfg.GEOJudgeLLMID)
+		configureSourceTaxonomy(cfg, cfgFile)
+		configurePlaybooks(cfg, cfgFile)
+
+		if err := configureMasterKey(cfg, cfgFile); err != nil {
+			return err
+		}
+
+		// The migrate commands work on databases at any schema version
+		migrating := cmd.Parent() == migrateCmd
+
 		sqlConfig := &models.Config{
-			Provider: cfg.SQLDatabase.Provider,
-			URI:      cfg.SQLDatabase.URI,
-			Database: cfg.SQLDatabase.Database,
-			Options:  cfg.SQLDatabase.Options,
+			Provider:        cfg.SQLDatabase.Provider,
+			URI:             cfg.SQLDatabase.URI,
+			Database:        cfg.SQLDatabase.Database,
+			Options:         cfg.SQLDatabase.Options,
+			SkipSchemaCheck: migrating,
 		}
 
 		nosqlConfig := &models.Config{
-			Provider: cfg.NoSQLDatabase.Provider,
-			URI:      cfg.NoSQLDatabase.URI,
-			Database: cfg.NoSQLDatabase.Database,
-			Options:  cfg.NoSQLDatabase.Options,
+			Provider:        cfg.NoSQLDatabase.Provider,
+			URI:             cfg.NoSQLDatabase.URI,
+			Database:        cfg.NoSQLDatabase.Database,
+			Options:         cfg.NoSQLDatabase.Options,
+			SkipSchemaCheck: migrating,
 		}
 
 		database, err = db.New(sqlConfig, nosqlConfig)
@@ -100,14 +113,9 @@ and compare performance across different LLM providers.`,
 
 		statsService = services.NewStatsService(database)
 
-		llmRegistry = llm.NewRegistry()
-		llmRegistry.Register(openai.New("", ""))
-		llmRegistry.Register(anthropic.New("", ""))
-		llmRegistry.Register(ollama.New(""))
-		llmRegistry.Register(google.New("", ""))
-		llmRegistry.Register(perplexity.New("", ""))
+		llmFactory = newLLMFactory(cfg)
 
-		sched = services.NewSchedulerService(database, llmRegistry)
+		sched = services.NewSchedulerService(database, llmFactory, services.NewJobQueueService(database))
 
 		return nil
 	},
@@ -127,12 +135,15 @@ func Execute() error {
 func init() {
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.gego/config.yaml)")
 	rootCmd.PersistentFlags().StringVar(&logLevel, "log-level", "INFO", "log level (DEBUG, INFO, WARNING, ERROR)")
-	rootCmd.PersistentFlags().StringVar(&logFile, "log-file", "", "log file path (default: stdout)")
+	rootCmd.PersistentFlags().StringVar(&logFile, "log-file", "", "log file path (default: stdout, or stderr with --output json|yaml)")
+	rootCmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", OutputTable, "Output format of listings and stats: table, json or yaml")
+	rootCmd.PersistentFlags().BoolVarP(&assumeYes, "yes", "y", false, "Answer yes to confirmation prompts")
 
 	rootCmd.CompletionOptions.DisableDefaultCmd = true
 
 	rootCmd.AddCommand(initCmd)
 	rootCmd.AddCommand(apiCmd)
+	rootCmd.AddCommand(apiKeyCmd)
 	rootCmd.AddCommand(llmCmd)
 	rootCmd.AddCommand(promptCmd)
 	rootCmd.AddCommand(scheduleCmd)
@@ -140,44 +151,24 @@ func init() {
 	rootCmd.AddCommand(statsCmd)
 	rootCmd.AddCommand(searchCmd)
 	rootCmd.AddCommand(runCmd)
-}
-
-// Helper function to initialize LLM providers from configs
-func initializeLLMProviders(ctx context.Context) error {
-	llms, err := database.ListLLMs(ctx, nil)
-	if err != nil {
-		return fmt.Errorf("failed to list LLMs: %w", err)
-	}
-
-	for _, llmConfig := range llms {
-		var provider llm.Provider
-
-		switch llmConfig.Provider {
-		case "openai":
-			provider = openai.New(llmConfig.APIKey, llmConfig.BaseURL)
-		case "anthropic":
-			provider = anthropic.New(llmConfig.APIKey, llmConfig.BaseURL)
-		case "ollama":
-			provider = ollama.New(llmConfig.BaseURL)
-		case "google":
-			provider = google.New(llmConfig.APIKey, llmConfig.BaseURL)
-		case "perplexity":
-			provider = perplexity.New(llmConfig.APIKey, llmConfig.BaseURL)
-		default:
-			continue
-		}
-
-		llmRegistry.Register(provider)
-	}
-
-	return nil
+	rootCmd.AddCommand(brandCmd)
+	rootCmd.AddCommand(exportCmd)
+	rootCmd.AddCommand(importCmd)
+	rootCmd.AddCommand(migrateCmd)
+	rootCmd.AddCommand(applyCmd)
+	rootCmd.AddCommand(sourcesCmd)
+	rootCmd.AddCommand(playbookCmd)
 }
 
 // initializeLogging sets up the logging system based on command line flags
 func initializeLogging() error {
 	level := logger.ParseLogLevel(logLevel)
 
+	// Keep stdout parseable when it carries JSON or YAML
 	var output io.Writer = os.Stdout
+	if isStructuredOutput() {
+		output = os.Stderr
+	}
 	if logFile != "" {
 		file, err := os.OpenFile(logFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
 		if err != nil {
@@ -191,9 +182,27 @@ func initializeLogging() error {
 	logger.Info("Logging initialized - Level: %s", level.String())
 	if logFile != "" {
 		logger.Info("Logging to file: %s", logFile)
+	} else if isStructuredOutput() {
+		logger.Info("Logging to stderr")
 	} else {
 		logger.Info("Logging to stdout")
 	}
 
 	return nil
 }
+
+// newLLMFactory creates the LLM provider factory. Providers serve recorded
+// responses when llm_replay_dir is set and record their responses when
+// llm_record_dir is set.
+func newLLMFactory(cfg *config.Config) *llm.Factory {
+	switch {
+	case cfg.LLMReplayDir != "":
+		logger.Info("Replaying recorded LLM responses from %s", cfg.LLMReplayDir)
+		return providers.NewReplayFactory(cfg.LLMReplayDir)
+	case cfg.LLMRecordDir != "":
+		logger.Info("Recording LLM responses to %s", cfg.LLMRecordDir)
+		return providers.NewRecordingFactory(cfg.LLMRecordDir)
+	default:
+		return providers.NewFactory()
+	}
+}
@@ -24,17 +24,31 @@ func boolPtr(b bool) *bool {
 var runCmd = &cobra.Command{
 	Use:   "run",
 	Short: "Run all prompts with all LLMs once",
-	Long:  `Execute all enabled prompts with all enabled LLMs immediately. Use 'gego scheduler start' for scheduled execution.`,
-	RunE:  runCommand,
+	Long: `Execute all enabled prompts with all enabled LLMs immediately. Use 'gego scheduler start' for scheduled execution.
+
+The run mode and temperature are asked for unless given with --mode and --temperature.
+Prompts using template variables such as {{city}} run once per combination of the values given with --var.`,
+	Example: `  gego run --mode new --temperature 0.7
+  gego run --mode all --temperature 0.7 --var city=Paris,Berlin --var persona="a startup founder"`,
+	Args: cobra.NoArgs,
+	RunE: runCommand,
+}
+
+var (
+	runMode        string
+	runTemperature string
+	runVariables   []string
+)
+
+func init() {
+	runCmd.Flags().StringVar(&runMode, "mode", "", "Prompts to run: new (never run yet) or all")
+	runCmd.Flags().StringVar(&runTemperature, "temperature", "", "Temperature (0.0-1.0) or random")
+	runCmd.Flags().StringArrayVar(&runVariables, "var", nil, "Values of a prompt template variable, as name=value1,value2 (repeatable)")
 }
 
 func runCommand(cmd *cobra.Command, args []string) error {
 	ctx := context.Background()
 
-	if err := initializeLLMProviders(ctx); err != nil {
-		return fmt.Errorf("failed to initialize LLM providers: %w", err)
-	}
-
 	return runOnceMode(ctx)
 }
 
@@ -60,10 +74,27 @@ func runOnceMode(ctx context.Context) error {
 		return fmt.Errorf("no enabled LLMs found")
 	}
 
-	reader := bufio.NewReader(os.Stdin)
-	runNewOnly, err := promptRunMode(reader)
+	variables, err := parseVariables(runVariables)
 	if err != nil {
-		return fmt.Errorf("failed to get run mode: %w", err)
+		return err
+	}
+	if err := services.ValidateVariables(variables); err != nil {
+		return err
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	var runNewOnly bool
+	switch strings.ToLower(runMode) {
+	case "new":
+		runNewOnly = true
+	case "all":
+	case "":
+		runNewOnly, err = promptRunMode(reader)
+		if err != nil {
+			return fmt.Errorf("failed to get run mode: %w", err)
+		}
+	default:
+		return fmt.Errorf("invalid --mode %q: use new or all", runMode)
 	}
 
 	var prompts []*models.Prompt
@@ -79,6 +110,14 @@ func runOnceMode(ctx context.Context) error {
 		prompts = allPrompts
 	}
 
+	totalExecutions := 0
+	for _, prompt := range prompts {
+		if err := services.ValidatePromptVariables(prompt.Template, prompt.Brand, variables); err != nil {
+			return fmt.Errorf("%w: pass them with --var", err)
+		}
+		totalExecutions += len(services.PromptBindings(prompt.Template, variables)) * len(llms)
+	}
+
 	modeText := "all"
 	if runNewOnly {
 		modeText = "new"
@@ -87,15 +126,19 @@ func runOnceMode(ctx context.Context) error {
 	fmt.Printf("%s====================================%s\n", DimStyle, Reset)
 	fmt.Printf("%sPrompts: %s%s\n", LabelStyle, FormatCount(len(prompts)), Reset)
 	fmt.Printf("%sLLMs: %s%s\n", LabelStyle, FormatCount(len(llms)), Reset)
-	fmt.Printf("%sTotal executions: %s%s\n", LabelStyle, FormatCount(len(prompts)*len(llms)), Reset)
+	fmt.Printf("%sTotal executions: %s%s\n", LabelStyle, FormatCount(totalExecutions), Reset)
 	fmt.Println()
 
-	temperature, err := promptTemperature(reader)
+	var temperature float64
+	if runTemperature != "" {
+		temperature, err = parseTemperature(runTemperature)
+	} else {
+		temperature, err = promptTemperature(reader)
+	}
 	if err != nil {
 		return fmt.Errorf("failed to get temperature: %w", err)
 	}
 
-	totalExecutions := len(prompts) * len(llms)
 	completedExecutions := 0
 
 	for _, prompt := range prompts {
@@ -104,28 +147,34 @@ func runOnceMode(ctx context.Context) error {
 			rand.Seed(time.Now().UnixNano())
 			currentTemperature = rand.Float64()
 		}
-		for _, llm := range llms {
-			fmt.Printf("%s📝 Running prompt: %s%s\n", InfoStyle, FormatValue(prompt.Template), Reset)
-			fmt.Printf("%s🤖 Using LLM: %s (%s)%s\n", InfoStyle, FormatValue(llm.Name), FormatSecondary(llm.Provider), Reset)
-			fmt.Printf("%s🌡️  Using temperature: %s%s\n", InfoStyle, FormatValue(fmt.Sprintf("%.1f", currentTemperature)), Reset)
-
-			executionService := services.NewExecutionService(database, llmRegistry)
-			config := &services.ExecutionConfig{
-				Temperature: currentTemperature,
-				MaxRetries:  3,
-				RetryDelay:  30 * time.Second,
+		for _, binding := range services.PromptBindings(prompt.Template, variables) {
+			for _, llm := range llms {
+				fmt.Printf("%s📝 Running prompt: %s%s\n", InfoStyle, FormatValue(prompt.Template), Reset)
+				if len(binding) > 0 {
+					fmt.Printf("%s🔤 With variables: %s%s\n", InfoStyle, FormatValue(services.FormatVariables(binding)), Reset)
+				}
+				fmt.Printf("%s🤖 Using LLM: %s (%s)%s\n", InfoStyle, FormatValue(llm.Name), FormatSecondary(llm.Provider), Reset)
+				fmt.Printf("%s🌡️  Using temperature: %s%s\n", InfoStyle, FormatValue(fmt.Sprintf("%.1f", currentTemperature)), Reset)
+
+				executionService := services.NewExecutionService(database, llmFactory)
+				config := &services.ExecutionConfig{
+					Temperature: currentTemperature,
+					MaxRetries:  3,
+					RetryDelay:  30 * time.Second,
+					Variables:   binding,
+				}
+
+				_, err := executionService.ExecutePromptWithLLM(ctx, prompt, llm, config)
+				if err != nil {
+					fmt.Printf("%s❌ Failed: %s%s\n", ErrorStyle, FormatValue(err.Error()), Reset)
+				} else {
+					fmt.Printf("%s✅ Success%s\n", SuccessStyle, Reset)
+				}
+
+				completedExecutions++
+				fmt.Printf("%sProgress: %s/%s%s\n", DimStyle, FormatCount(completedExecutions), FormatCount(totalExecutions), Reset)
+				fmt.Println()
 			}
-
-			_, err := executionService.ExecutePromptWithLLM(ctx, prompt, llm, config)
-			if err != nil {
-				fmt.Printf("%s❌ Failed: %s%s\n", ErrorStyle, FormatValue(err.Error()), Reset)
-			} else {
-				fmt.Printf("%s✅ Success%s\n", SuccessStyle, Reset)
-			}
-
-			completedExecutions++
-			fmt.Printf("%sProgress: %s/%s%s\n", DimStyle, FormatCount(completedExecutions), FormatCount(totalExecutions), Reset)
-			fmt.Println()
 		}
 	}
 
@@ -163,7 +212,7 @@ func promptRunMode(reader *bufio.Reader) (bool, error) {
 
 // filterNewPrompts filters prompts to only include those that haven't been run yet
 func filterNewPrompts(ctx context.Context, prompts []*models.Prompt) ([]*models.Prompt, error) {
-	executionService := services.NewExecutionService(database, llmRegistry)
+	executionService := services.NewExecutionService(database, llmFactory)
 	var newPrompts []*models.Prompt
 
 	for _, prompt := range prompts {
@@ -5,14 +5,17 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"sort"
 	"strings"
 	"text/tabwriter"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
 
 	"github.com/fissionx/gego/internal/models"
+	"github.com/fissionx/gego/internal/services"
 )
 
 var scheduleCmd = &cobra.Command{
@@ -24,13 +27,27 @@ var scheduleCmd = &cobra.Command{
 var scheduleAddCmd = &cobra.Command{
 	Use:   "add",
 	Short: "Add a new schedule",
-	RunE:  runScheduleAdd,
+	Long: `Add a new schedule. Without flags, asks for each setting. With --name, or a
+--file spec holding one schedule or a list of them, adds it without prompting:
+
+  - name: daily
+    prompts: all            # or a list of prompt IDs
+    llms: [1b9d, 7c2e]      # or all
+    cron: "0 9 * * *"
+    temperature: random     # 0.0-1.0 or random, 0.7 by default
+    enabled: true`,
+	Example: `  gego schedule add --name daily --prompt all --llm all --cron "0 9 * * *"
+  gego schedule add --name weekly --prompt 3f2a,9c1d --llm 1b9d --cron "0 9 * * MON" --temperature random
+  gego schedule add --file schedules.yaml`,
+	Args: cobra.NoArgs,
+	RunE: runScheduleAdd,
 }
 
 var scheduleListCmd = &cobra.Command{
-	Use:   "list",
-	Short: "List all schedules",
-	RunE:  runScheduleList,
+	Use:         "list",
+	Short:       "List all schedules",
+	Annotations: structuredOutput,
+	RunE:        runScheduleList,
 }
 
 var scheduleGetCmd = &cobra.Command{
@@ -43,8 +60,10 @@ var scheduleGetCmd = &cobra.Command{
 var scheduleDeleteCmd = &cobra.Command{
 	Use:   "delete [id]",
 	Short: "Delete a schedule or all schedules",
-	Args:  cobra.MaximumNArgs(1),
-	RunE:  runScheduleDelete,
+	Long: `Delete a schedule, or all schedules when no ID is given. --yes skips the
+confirmation.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runScheduleDelete,
 }
 
 var scheduleEnableCmd = &cobra.Command{
@@ -68,7 +87,25 @@ var scheduleRunCmd = &cobra.Command{
 	RunE:  runScheduleRun,
 }
 
+var (
+	scheduleName        string
+	schedulePrompts     []string
+	scheduleLLMs        []string
+	scheduleCron        string
+	scheduleTemperature string
+	scheduleVariables   []string
+	scheduleSpecFile    string
+)
+
 func init() {
+	scheduleAddCmd.Flags().StringVar(&scheduleName, "name", "", "Name of the schedule")
+	scheduleAddCmd.Flags().StringSliceVar(&schedulePrompts, "prompt", nil, "Prompt IDs to run, or all")
+	scheduleAddCmd.Flags().StringSliceVar(&scheduleLLMs, "llm", nil, "LLM IDs to run the prompts on, or all")
+	scheduleAddCmd.Flags().StringVar(&scheduleCron, "cron", "0 9 * * *", "Cron expression of the schedule")
+	scheduleAddCmd.Flags().StringVar(&scheduleTemperature, "temperature", "0.7", "Temperature (0.0-1.0) or random")
+	scheduleAddCmd.Flags().StringArrayVar(&scheduleVariables, "var", nil, "Values of a prompt template variable, as name=value1,value2 (repeatable)")
+	scheduleAddCmd.Flags().StringVarP(&scheduleSpecFile, "file", "f", "", "YAML or JSON spec file, - for standard input")
+
 	scheduleCmd.AddCommand(scheduleAddCmd)
 	scheduleCmd.AddCommand(scheduleListCmd)
 	scheduleCmd.AddCommand(scheduleGetCmd)
@@ -82,6 +119,10 @@ func runScheduleAdd(cmd *cobra.Command, args []string) error {
 	reader := bufio.NewReader(os.Stdin)
 	ctx := context.Background()
 
+	if scheduleSpecFile != "" || cmd.Flags().Changed("name") {
+		return addSchedulesFromSpecs(ctx, cmd)
+	}
+
 	fmt.Printf("%s➕ Add New Schedule%s\n", FormatHeader(""), Reset)
 	fmt.Printf("%s==================%s\n", DimStyle, Reset)
 	fmt.Println()
@@ -240,6 +281,13 @@ func runScheduleList(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to list schedules: %w", err)
 	}
 
+	if isStructuredOutput() {
+		if schedules == nil {
+			schedules = []*models.Schedule{}
+		}
+		return writeStructured(schedules)
+	}
+
 	if len(schedules) == 0 {
 		fmt.Printf("%sNo schedules configured. Use '%s' to add one.%s\n", WarningStyle, FormatSecondary("gego schedule add"), Reset)
 		return nil
@@ -317,6 +365,18 @@ func runScheduleGet(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	if len(schedule.Variables) > 0 {
+		fmt.Printf("\n%sVariables:%s\n", SuccessStyle, Reset)
+		names := make([]string, 0, len(schedule.Variables))
+		for name := range schedule.Variables {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Printf("  - %s: %s\n", FormatLabel(name), FormatValue(strings.Join(schedule.Variables[name], ", ")))
+		}
+	}
+
 	fmt.Printf("\n%sLLMs (%s):%s\n", SuccessStyle, FormatCount(len(schedule.LLMIDs)), Reset)
 	for _, llmID := range schedule.LLMIDs {
 		llm, err := database.GetLLM(ctx, llmID)
@@ -351,11 +411,12 @@ func runScheduleDelete(cmd *cobra.Command, args []string) error {
 		}
 		fmt.Println()
 
-		fmt.Printf("%sDo you want to delete ALL schedules? (y/N): %s", ErrorStyle, Reset)
-		response, _ := reader.ReadString('\n')
-		response = strings.TrimSpace(strings.ToLower(response))
+		confirmed, err := confirm(reader, fmt.Sprintf("%sDo you want to delete ALL schedules? (y/N): %s", ErrorStyle, Reset))
+		if err != nil {
+			return err
+		}
 
-		if response != "y" && response != "yes" {
+		if !confirmed {
 			fmt.Printf("%sCancelled.%s\n", WarningStyle, Reset)
 			return nil
 		}
@@ -371,11 +432,12 @@ func runScheduleDelete(cmd *cobra.Command, args []string) error {
 	}
 
 	id := args[0]
-	fmt.Printf("%sAre you sure you want to delete schedule %s? (y/N): %s", ErrorStyle, FormatValue(id), Reset)
-	response, _ := reader.ReadString('\n')
-	response = strings.TrimSpace(strings.ToLower(response))
+	confirmed, err := confirm(reader, fmt.Sprintf("%sAre you sure you want to delete schedule %s? (y/N): %s", ErrorStyle, FormatValue(id), Reset))
+	if err != nil {
+		return err
+	}
 
-	if response != "y" && response != "yes" {
+	if !confirmed {
 		fmt.Printf("%sCancelled.%s\n", WarningStyle, Reset)
 		return nil
 	}
@@ -389,6 +451,193 @@ func runScheduleDelete(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// scheduleSpec describes a schedule to add, from flags or a spec file
+type scheduleSpec struct {
+	Name        string              `yaml:"name"`
+	Prompts     stringOrAll         `yaml:"prompts"`
+	LLMs        stringOrAll         `yaml:"llms"`
+	Cron        string              `yaml:"cron"`
+	Temperature string              `yaml:"temperature"`
+	Variables   map[string][]string `yaml:"variables"` // Values of the prompt template variables, e.g. city: [Paris, Berlin]
+	Enabled     *bool               `yaml:"enabled"`
+}
+
+// stringOrAll is a list of IDs, written either as a YAML list or as the
+// single value all
+type stringOrAll []string
+
+func (s *stringOrAll) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*s = stringOrAll{node.Value}
+		return nil
+	}
+	var values []string
+	if err := node.Decode(&values); err != nil {
+		return err
+	}
+	*s = values
+	return nil
+}
+
+// addSchedulesFromSpecs adds the schedules of --file or the flags, without prompting
+func addSchedulesFromSpecs(ctx context.Context, cmd *cobra.Command) error {
+	var specs []scheduleSpec
+	if scheduleSpecFile != "" {
+		if cmd.Flags().Changed("name") {
+			return fmt.Errorf("--file and --name cannot be combined")
+		}
+		var err error
+		if specs, err = readSpecs[scheduleSpec](scheduleSpecFile); err != nil {
+			return err
+		}
+	} else {
+		variables, err := parseVariables(scheduleVariables)
+		if err != nil {
+			return err
+		}
+		specs = []scheduleSpec{{
+			Name:        scheduleName,
+			Prompts:     schedulePrompts,
+			LLMs:        scheduleLLMs,
+			Cron:        scheduleCron,
+			Temperature: scheduleTemperature,
+			Variables:   variables,
+		}}
+	}
+
+	prompts, err := database.ListPrompts(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to list prompts: %w", err)
+	}
+	llms, err := database.ListLLMs(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to list LLMs: %w", err)
+	}
+
+	var llmIDs []string
+	for _, llm := range llms {
+		llmIDs = append(llmIDs, llm.ID)
+	}
+
+	// Check every spec before adding anything
+	var schedules []*models.Schedule
+	for i, spec := range specs {
+		schedule, err := newScheduleFromSpec(spec, prompts, llmIDs)
+		if err != nil {
+			if len(specs) > 1 {
+				return fmt.Errorf("schedule spec %d: %w", i+1, err)
+			}
+			return err
+		}
+		schedules = append(schedules, schedule)
+	}
+
+	for _, schedule := range schedules {
+		if err := database.CreateSchedule(ctx, schedule); err != nil {
+			return fmt.Errorf("failed to create schedule: %w", err)
+		}
+		fmt.Printf("%s✅ Added schedule: %s (%s)%s\n", SuccessStyle, FormatValue(schedule.Name), FormatSecondary(schedule.ID), Reset)
+	}
+
+	fmt.Printf("\n%sRestart the scheduler to apply changes: %s%s\n", InfoStyle, FormatSecondary("gego scheduler start"), Reset)
+	return nil
+}
+
+// newScheduleFromSpec validates a schedule spec against the existing prompts
+// and LLM IDs
+func newScheduleFromSpec(spec scheduleSpec, prompts []*models.Prompt, llmIDs []string) (*models.Schedule, error) {
+	name := strings.TrimSpace(spec.Name)
+	if name == "" {
+		return nil, fmt.Errorf("schedule name is required")
+	}
+
+	promptIDs := make([]string, 0, len(prompts))
+	promptsByID := make(map[string]*models.Prompt, len(prompts))
+	for _, prompt := range prompts {
+		promptIDs = append(promptIDs, prompt.ID)
+		promptsByID[prompt.ID] = prompt
+	}
+
+	selectedPrompts, err := selectIDs("prompt", spec.Prompts, promptIDs)
+	if err != nil {
+		return nil, err
+	}
+	if err := services.ValidateVariables(spec.Variables); err != nil {
+		return nil, err
+	}
+	for _, id := range selectedPrompts {
+		prompt := promptsByID[id]
+		if err := services.ValidatePromptVariables(prompt.Template, prompt.Brand, spec.Variables); err != nil {
+			return nil, err
+		}
+	}
+	selectedLLMs, err := selectIDs("LLM", spec.LLMs, llmIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	cronExpr := strings.TrimSpace(spec.Cron)
+	if cronExpr == "" {
+		cronExpr = "0 9 * * *"
+	}
+	if err := services.NewScheduleService(database).ValidateCronExpression(cronExpr); err != nil {
+		return nil, err
+	}
+
+	temperature := 0.7
+	if spec.Temperature != "" {
+		if temperature, err = parseTemperature(strings.TrimSpace(spec.Temperature)); err != nil {
+			return nil, err
+		}
+	}
+
+	schedule := &models.Schedule{
+		ID:          uuid.New().String(),
+		Name:        name,
+		PromptIDs:   selectedPrompts,
+		LLMIDs:      selectedLLMs,
+		CronExpr:    cronExpr,
+		Temperature: temperature,
+		Variables:   spec.Variables,
+		Enabled:     true,
+	}
+	if spec.Enabled != nil {
+		schedule.Enabled = *spec.Enabled
+	}
+	return schedule, nil
+}
+
+// selectIDs checks that the selected IDs exist, expanding all to every ID
+func selectIDs(kind string, selection, existing []string) ([]string, error) {
+	if len(selection) == 1 && strings.EqualFold(strings.TrimSpace(selection[0]), "all") {
+		if len(existing) == 0 {
+			return nil, fmt.Errorf("no %ss available", kind)
+		}
+		return existing, nil
+	}
+
+	known := make(map[string]bool, len(existing))
+	for _, id := range existing {
+		known[id] = true
+	}
+
+	var selected []string
+	for _, id := range selection {
+		id = strings.TrimSpace(id)
+		if id == "" {
+			continue
+		}
+		if !known[id] {
+			return nil, fmt.Errorf("%s not found: %s", kind, id)
+		}
+		selected = append(selected, id)
+	}
+	if len(selected) == 0 {
+		return nil, fmt.Errorf("at least one %s is required: pass IDs or all", kind)
+	}
+	return selected, nil
+}
+
 func runScheduleEnable(cmd *cobra.Command, args []string) error {
 	ctx := context.Background()
 	id := args[0]
@@ -431,10 +680,6 @@ func runScheduleRun(cmd *cobra.Command, args []string) error {
 	ctx := context.Background()
 	id := args[0]
 
-	if err := initializeLLMProviders(ctx); err != nil {
-		return fmt.Errorf("failed to initialize LLM providers: %w", err)
-	}
-
 	fmt.Printf("%s⏳ Executing schedule %s...%s\n", InfoStyle, FormatValue(id), Reset)
 
 	if err := sched.ExecuteNow(ctx, id); err != nil {
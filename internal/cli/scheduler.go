@@ -33,10 +33,6 @@ func runSchedulerStart(cmd *cobra.Command, args []string) error {
 	fmt.Printf("%s================%s\n", DimStyle, Reset)
 	fmt.Println()
 
-	if err := initializeLLMProviders(ctx); err != nil {
-		return fmt.Errorf("failed to initialize LLM providers: %w", err)
-	}
-
 	schedules, err := database.ListSchedules(ctx, boolPtr(true))
 	if err != nil {
 		return fmt.Errorf("failed to check schedules: %w", err)
@@ -20,11 +20,12 @@ var (
 )
 
 var searchCmd = &cobra.Command{
-	Use:   "search [keyword]",
-	Short: "Search for specific keywords in all responses",
-	Long:  `Search for specific keywords in all LLM responses and display the context around each match.`,
-	Args:  cobra.ExactArgs(1),
-	RunE:  runSearch,
+	Use:         "search [keyword]",
+	Short:       "Search for specific keywords in all responses",
+	Long:        `Search for specific keywords in all LLM responses and display the context around each match.`,
+	Args:        cobra.ExactArgs(1),
+	Annotations: structuredOutput,
+	RunE:        runSearch,
 }
 
 func init() {
@@ -36,9 +37,6 @@ func runSearch(cmd *cobra.Command, args []string) error {
 	ctx := context.Background()
 	keyword := args[0]
 
-	fmt.Printf("%s🔍 Searching for keyword: \"%s\"%s\n", HeaderStyle, CountStyle+keyword+Reset, Reset)
-	fmt.Println()
-
 	filter := shared.ResponseFilter{
 		Keyword: keyword,
 		Limit:   searchLimit * 10,
@@ -49,14 +47,6 @@ func runSearch(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to search responses: %w", err)
 	}
 
-	fmt.Printf("%s📊 Found %s responses containing \"%s\"%s\n", InfoStyle, CountStyle+fmt.Sprintf("%d", len(responses))+Reset, CountStyle+keyword+Reset, Reset)
-	fmt.Println()
-
-	if len(responses) == 0 {
-		fmt.Printf("%s❌ No matches found for keyword \"%s\"%s\n", ErrorStyle, CountStyle+keyword+Reset, Reset)
-		return nil
-	}
-
 	var regex *regexp.Regexp
 	if searchCaseSensitive {
 		regex = regexp.MustCompile(regexp.QuoteMeta(keyword))
@@ -64,11 +54,24 @@ func runSearch(cmd *cobra.Command, args []string) error {
 		regex = regexp.MustCompile("(?i)" + regexp.QuoteMeta(keyword))
 	}
 
-	var matches []SearchMatch
+	matches := []SearchMatch{}
 	for _, response := range responses {
-		matches = append(matches, findMatches(response, regex, keyword)...)
+		matches = append(matches, findMatches(response, regex)...)
 	}
 
+	if isStructuredOutput() {
+		if len(matches) > searchLimit {
+			matches = matches[:searchLimit]
+		}
+		return writeStructured(matches)
+	}
+
+	fmt.Printf("%s🔍 Searching for keyword: \"%s\"%s\n", HeaderStyle, CountStyle+keyword+Reset, Reset)
+	fmt.Println()
+
+	fmt.Printf("%s📊 Found %s responses containing \"%s\"%s\n", InfoStyle, CountStyle+fmt.Sprintf("%d", len(responses))+Reset, CountStyle+keyword+Reset, Reset)
+	fmt.Println()
+
 	if len(matches) == 0 {
 		fmt.Printf("%s❌ No matches found for keyword \"%s\"%s\n", ErrorStyle, CountStyle+keyword+Reset, Reset)
 		return nil
@@ -98,7 +101,7 @@ func runSearch(cmd *cobra.Command, args []string) error {
 		fmt.Println()
 
 		fmt.Printf("   %s📝 Context:%s\n", SuccessStyle, Reset)
-		fmt.Printf("   %s\n", match.Context)
+		fmt.Printf("   %s\n", strings.ReplaceAll(match.Context, keyword, FormatHighlight(keyword)))
 		fmt.Println()
 
 		fmt.Printf("   %s📋 Full Prompt:%s\n", SuccessStyle, Reset)
@@ -114,18 +117,18 @@ func runSearch(cmd *cobra.Command, args []string) error {
 }
 
 type SearchMatch struct {
-	ResponseID  string
-	PromptID    string
-	PromptName  string
-	FullPrompt  string
-	LLMName     string
-	LLMProvider string
-	Temperature float64
-	Context     string
-	CreatedAt   time.Time
+	ResponseID  string    `json:"responseId"`
+	PromptID    string    `json:"promptId"`
+	PromptName  string    `json:"promptName"`
+	FullPrompt  string    `json:"fullPrompt"`
+	LLMName     string    `json:"llmName"`
+	LLMProvider string    `json:"llmProvider"`
+	Temperature float64   `json:"temperature"`
+	Context     string    `json:"context"`
+	CreatedAt   time.Time `json:"createdAt"`
 }
 
-func findMatches(response *models.Response, regex *regexp.Regexp, keyword string) []SearchMatch {
+func findMatches(response *models.Response, regex *regexp.Regexp) []SearchMatch {
 	var matches []SearchMatch
 
 	indices := regex.FindAllStringIndex(response.ResponseText, -1)
@@ -145,8 +148,6 @@ func findMatches(response *models.Response, regex *regexp.Regexp, keyword string
 
 		contextText := response.ResponseText[contextStart:contextEnd]
 
-		highlightedContext := strings.ReplaceAll(contextText, keyword, FormatHighlight(keyword))
-
 		promptName := "Unknown Prompt"
 		if prompt, err := database.GetPrompt(context.Background(), response.PromptID); err == nil {
 			promptName = prompt.Template
@@ -160,7 +161,7 @@ func findMatches(response *models.Response, regex *regexp.Regexp, keyword string
 			LLMName:     response.LLMName,
 			LLMProvider: response.LLMProvider,
 			Temperature: response.Temperature,
-			Context:     highlightedContext,
+			Context:     contextText,
 			CreatedAt:   response.CreatedAt,
 		})
 	}
@@ -0,0 +1,92 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/fissionx/gego/internal/config"
+	"github.com/fissionx/gego/internal/services"
+)
+
+// defaultSourceTaxonomyFile is the source taxonomy file of configs predating it
+const defaultSourceTaxonomyFile = "source_taxonomy.yaml"
+
+var sourcesCmd = &cobra.Command{
+	Use:   "sources",
+	Short: "Classify the sources cited by LLM answers",
+	Long: `Classify the domains cited by LLM answers into categories such as review_site,
+news or community.
+
+Categories come from the source taxonomy file (source_taxonomy.yaml next to the
+config file): its rules, the industry packs it enables, and the built-in
+default pack. Overrides set through the API are kept in source_overrides.yaml.`,
+}
+
+var sourcesClassifyCmd = &cobra.Command{
+	Use:         "classify [domain...]",
+	Short:       "Show the categories of domains",
+	Example:     `  gego sources classify g2.com docs.hubspot.com`,
+	Args:        cobra.MinimumNArgs(1),
+	Annotations: structuredOutput,
+	RunE:        runSourcesClassify,
+}
+
+var sourcesReclassifyCmd = &cobra.Command{
+	Use:   "reclassify",
+	Short: "Recompute the source categories of stored responses",
+	Long: `Recompute the source categories of stored responses from their cited domains.
+Run it after editing the source taxonomy file; overrides set through the API
+reclassify responses on their own.`,
+	RunE: runSourcesReclassify,
+}
+
+func init() {
+	sourcesCmd.AddCommand(sourcesClassifyCmd)
+	sourcesCmd.AddCommand(sourcesReclassifyCmd)
+}
+
+// configureSourceTaxonomy points source classification at the taxonomy file,
+// resolved relative to the config directory
+func configureSourceTaxonomy(cfg *config.Config, configPath string) {
+	taxonomyPath := cfg.SourceTaxonomyPath
+	if taxonomyPath == "" {
+		taxonomyPath = defaultSourceTaxonomyFile
+	}
+	if !filepath.IsAbs(taxonomyPath) {
+		taxonomyPath = filepath.Join(filepath.Dir(configPath), taxonomyPath)
+	}
+	services.SetSourceTaxonomyPath(taxonomyPath)
+}
+
+func runSourcesClassify(cmd *cobra.Command, args []string) error {
+	categories := services.NewSourceTaxonomyService(database).Classify(args)
+	if isStructuredOutput() {
+		return writeStructured(categories)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "%sDOMAIN\tCATEGORIES%s\n", LabelStyle, Reset)
+	fmt.Fprintf(w, "%s──────\t──────────%s\n", DimStyle, Reset)
+	for _, domain := range args {
+		fmt.Fprintf(w, "%s\t%s\n", FormatValue(domain), strings.Join(categories[domain], ", "))
+	}
+	return w.Flush()
+}
+
+func runSourcesReclassify(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	updated, err := services.NewSourceTaxonomyService(database).Reclassify(ctx)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s✅ Reclassified %s responses%s\n", SuccessStyle, FormatCount(updated), Reset)
+	return nil
+}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"github.com/fissionx/gego/internal/models"
 	"github.com/fissionx/gego/internal/shared"
 )
 
@@ -25,22 +26,24 @@ var statsCmd = &cobra.Command{
 }
 
 var statsKeywordsCmd = &cobra.Command{
-	Use:   "keywords",
-	Short: "View top keywords by mentions",
-	RunE:  runStatsKeywords,
+	Use:         "keywords",
+	Short:       "View top keywords by mentions",
+	Annotations: structuredOutput,
+	RunE:        runStatsKeywords,
 }
 
 var statsKeywordCmd = &cobra.Command{
-	Use:   "keyword [name]",
-	Short: "View statistics for a specific keyword",
-	Args:  cobra.ExactArgs(1),
-	RunE:  runStatsKeyword,
+	Use:         "keyword [name]",
+	Short:       "View statistics for a specific keyword",
+	Args:        cobra.ExactArgs(1),
+	Annotations: structuredOutput,
+	RunE:        runStatsKeyword,
 }
 
 var statsResetCmd = &cobra.Command{
 	Use:   "reset",
 	Short: "Reset all statistics by clearing all responses",
-	Long:  `Reset all statistics by deleting all responses from the database. This will clear all keyword statistics, prompt statistics, and LLM statistics. Prompts and LLMs will remain intact.`,
+	Long:  `Reset all statistics by deleting all responses from the database. This will clear all keyword statistics, prompt statistics, and LLM statistics. Prompts and LLMs will remain intact. --yes skips the confirmation.`,
 	Args:  cobra.NoArgs,
 	RunE:  runStatsReset,
 }
@@ -71,6 +74,13 @@ func runStatsKeywords(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to get top keywords: %w", err)
 	}
 
+	if isStructuredOutput() {
+		if keywords == nil {
+			keywords = []models.KeywordCount{}
+		}
+		return writeStructured(keywords)
+	}
+
 	if len(keywords) == 0 {
 		fmt.Printf("%sNo keyword statistics available yet. Run some schedules first!%s\n", WarningStyle, Reset)
 		return nil
@@ -111,6 +121,10 @@ func runStatsKeyword(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to get keyword stats: %w", err)
 	}
 
+	if isStructuredOutput() {
+		return writeStructured(stats)
+	}
+
 	fmt.Printf("%s📊 Keyword Statistics: %s%s\n", HeaderStyle, CountStyle+keywordName+Reset, Reset)
 	fmt.Printf("%s========================%s\n", DimStyle, Reset)
 	fmt.Println()
@@ -228,7 +242,7 @@ func runStatsReset(cmd *cobra.Command, args []string) error {
 	fmt.Printf("%sThis action cannot be undone!%s\n", ErrorStyle, Reset)
 	fmt.Println()
 
-	confirmed, err := promptYesNo(reader, fmt.Sprintf("%sAre you sure you want to reset all statistics? (y/N): %s", ErrorStyle, Reset))
+	confirmed, err := confirm(reader, fmt.Sprintf("%sAre you sure you want to reset all statistics? (y/N): %s", ErrorStyle, Reset))
 	if err != nil {
 		return err
 	}
@@ -9,11 +9,13 @@ import (
 	"time"
 )
 
-// promptWithRetry prompts the user for input and retries on invalid input
+// promptWithRetry prompts the user for input and retries on invalid input.
+// Once standard input is exhausted, an invalid answer is an error rather
+// than a retry, so scripts fail instead of looping forever.
 func promptWithRetry(reader *bufio.Reader, prompt string, validator func(string) (string, error)) (string, error) {
 	for {
 		fmt.Print(prompt)
-		input, _ := reader.ReadString('\n')
+		input, readErr := reader.ReadString('\n')
 		input = strings.TrimSpace(input)
 
 		result, err := validator(input)
@@ -21,6 +23,11 @@ func promptWithRetry(reader *bufio.Reader, prompt string, validator func(string)
 			return result, nil
 		}
 
+		if readErr != nil {
+			fmt.Println()
+			return "", fmt.Errorf("%w (standard input closed: pass the answers as flags to run non-interactively)", err)
+		}
+
 		fmt.Printf("❌ %s\n\n", err.Error())
 	}
 }
@@ -65,20 +72,9 @@ func promptTemperature(reader *bufio.Reader) (float64, error) {
 		if input == "" {
 			return "0.7", nil
 		}
-
-		if strings.ToLower(input) == "random" {
-			return "random", nil
-		}
-
-		temp, err := strconv.ParseFloat(input, 64)
-		if err != nil {
-			return "", fmt.Errorf("invalid temperature: %s (must be a number between 0.0 and 1.0 or 'random')", input)
+		if _, err := parseTemperature(input); err != nil {
+			return "", err
 		}
-
-		if temp < 0.0 || temp > 1.0 {
-			return "", fmt.Errorf("temperature must be between 0.0 and 1.0, got: %.2f", temp)
-		}
-
 		return input, nil
 	})
 
@@ -86,10 +82,48 @@ func promptTemperature(reader *bufio.Reader) (float64, error) {
 		return 0, err
 	}
 
-	if result == "random" {
+	return parseTemperature(result)
+}
+
+// parseTemperature parses a temperature between 0.0 and 1.0, or 'random'
+// which is returned as -1.0
+func parseTemperature(input string) (float64, error) {
+	if strings.ToLower(input) == "random" {
 		rand.Seed(time.Now().UnixNano())
 		return -1.0, nil // Special value to indicate random temperature
 	}
 
-	return strconv.ParseFloat(result, 64)
+	temp, err := strconv.ParseFloat(input, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid temperature: %s (must be a number between 0.0 and 1.0 or 'random')", input)
+	}
+
+	if temp < 0.0 || temp > 1.0 {
+		return 0, fmt.Errorf("temperature must be between 0.0 and 1.0, got: %.2f", temp)
+	}
+
+	return temp, nil
+}
+
+// parseVariables parses --var flags of the form name=value1,value2 into the
+// value sets of prompt template variables. Repeating a name adds values.
+func parseVariables(flags []string) (map[string][]string, error) {
+	if len(flags) == 0 {
+		return nil, nil
+	}
+
+	sets := make(map[string][]string)
+	for _, flag := range flags {
+		name, values, ok := strings.Cut(flag, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid --var %q: use name=value1,value2", flag)
+		}
+		for _, value := range strings.Split(values, ",") {
+			if value = strings.TrimSpace(value); value != "" {
+				sets[name] = append(sets[name], value)
+			}
+		}
+	}
+	return sets, nil
 }
@@ -12,9 +12,16 @@ import (
 // Config represents the application configuration
 type Config struct {
 	SQLDatabase           DatabaseConfig `yaml:"sql_database"`                      // SQLite for LLMs and Schedules
-	NoSQLDatabase         DatabaseConfig `yaml:"nosql_database"`                    // MongoDB for Prompts and Responses
+	NoSQLDatabase         DatabaseConfig `yaml:"nosql_database"`                    // MongoDB (or embedded SQLite) for Prompts and Responses
 	CORSOrigin            string         `yaml:"cors_origin,omitempty"`             // CORS origin for API server
 	KeywordsExclusionPath string         `yaml:"keywords_exclusion_path,omitempty"` // Path to keywords exclusion file
+	SourceTaxonomyPath    string         `yaml:"source_taxonomy_path,omitempty"`    // Path to the source taxonomy file classifying cited domains
+	PlaybookPath          string         `yaml:"playbook_path,omitempty"`           // Path to the recommendation playbook file
+	GEOJudgeLLMID         string         `yaml:"geo_judge_llm_id,omitempty"`        // LLM config used to judge answers for GEO analysis
+	APIAuthDisabled       bool           `yaml:"api_auth_disabled,omitempty"`       // Serve the REST API without API keys
+	MasterKeyFile         string         `yaml:"master_key_file,omitempty"`         // Master key encrypting LLM API keys at rest
+	LLMReplayDir          string         `yaml:"llm_replay_dir,omitempty"`          // Serve recorded LLM responses from this directory instead of calling the APIs
+	LLMRecordDir          string         `yaml:"llm_record_dir,omitempty"`          // Record every LLM response to this directory
 }
 
 // DatabaseConfig represents database configuration
@@ -42,6 +49,8 @@ func DefaultConfig() *Config {
 		},
 		CORSOrigin:            "*",
 		KeywordsExclusionPath: filepath.Join(configDir, "keywords_exclusion"),
+		SourceTaxonomyPath:    filepath.Join(configDir, "source_taxonomy.yaml"),
+		PlaybookPath:          filepath.Join(configDir, "playbooks.yaml"),
 	}
 }
 
@@ -68,11 +77,13 @@ func applyEnvironmentOverrides(cfg *Config) {
 	// Check for GEGO_ENV to determine environment (local, dev, prod)
 	env := strings.ToLower(os.Getenv("GEGO_ENV"))
 
-	// MongoDB URI override based on environment or direct variable
-	if mongoURI := os.Getenv("MONGODB_URI"); mongoURI != "" {
+	// MongoDB URI override based on environment or direct variable. The
+	// embedded SQLite store keeps its file path.
+	mongoDB := cfg.NoSQLDatabase.Provider != "sqlite"
+	if mongoURI := os.Getenv("MONGODB_URI"); mongoDB && mongoURI != "" {
 		// Direct override takes precedence
 		cfg.NoSQLDatabase.URI = mongoURI
-	} else if env != "" {
+	} else if mongoDB && env != "" {
 		// Environment-based configuration
 		switch env {
 		case "local":
@@ -104,6 +115,24 @@ func applyEnvironmentOverrides(cfg *Config) {
 	if corsOrigin := os.Getenv("CORS_ORIGIN"); corsOrigin != "" {
 		cfg.CORSOrigin = corsOrigin
 	}
+
+	// GEO judge LLM override
+	if judgeLLMID := os.Getenv("GEGO_GEO_JUDGE_LLM_ID"); judgeLLMID != "" {
+		cfg.GEOJudgeLLMID = judgeLLMID
+	}
+
+	// Master key file override (GEGO_MASTER_KEY, holding the key itself, takes precedence over both)
+	if masterKeyFile := os.Getenv("GEGO_MASTER_KEY_FILE"); masterKeyFile != "" {
+		cfg.MasterKeyFile = masterKeyFile
+	}
+
+	// Recorded LLM response overrides, mostly for tests and demos
+	if replayDir := os.Getenv("GEGO_LLM_REPLAY_DIR"); replayDir != "" {
+		cfg.LLMReplayDir = replayDir
+	}
+	if recordDir := os.Getenv("GEGO_LLM_RECORD_DIR"); recordDir != "" {
+		cfg.LLMRecordDir = recordDir
+	}
 }
 
 // Save saves configuration to file
@@ -7,6 +7,7 @@ import (
 
 	"github.com/fissionx/gego/internal/db/mongodb"
 	"github.com/fissionx/gego/internal/db/sqlite"
+	"github.com/fissionx/gego/internal/db/sqlitedoc"
 	"github.com/fissionx/gego/internal/models"
 	"github.com/fissionx/gego/internal/shared"
 )
@@ -14,7 +15,7 @@ import (
 // HybridDB implements the Database interface using both SQLite and NoSQL
 type HybridDB struct {
 	sqlDB   SQLDatabase   // SQLite for LLMs and Schedules
-	nosqlDB NoSQLDatabase // MongoDB for Prompts and Responses
+	nosqlDB NoSQLDatabase // MongoDB or embedded SQLite for Prompts and Responses
 }
 
 // New creates a new hybrid database instance
@@ -39,6 +40,11 @@ func New(sqlConfig, nosqlConfig *models.Config) (*HybridDB, error) {
 		if err != nil {
 			return nil, fmt.Errorf("failed to create NoSQL database: %w", err)
 		}
+	case "sqlite":
+		nosqlDB, err = sqlitedoc.New(nosqlConfig)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create NoSQL database: %w", err)
+		}
 	default:
 		return nil, fmt.Errorf("unsupported NoSQL database provider: %s", nosqlConfig.Provider)
 	}
@@ -140,6 +146,27 @@ func (h *HybridDB) DeleteAllSchedules(ctx context.Context) (int, error) {
 	return h.sqlDB.DeleteAllSchedules(ctx)
 }
 
+// API key operations - Use SQL
+func (h *HybridDB) CreateAPIKey(ctx context.Context, key *models.APIKey) error {
+	return h.sqlDB.CreateAPIKey(ctx, key)
+}
+
+func (h *HybridDB) GetAPIKeyByHash(ctx context.Context, keyHash string) (*models.APIKey, error) {
+	return h.sqlDB.GetAPIKeyByHash(ctx, keyHash)
+}
+
+func (h *HybridDB) ListAPIKeys(ctx context.Context) ([]*models.APIKey, error) {
+	return h.sqlDB.ListAPIKeys(ctx)
+}
+
+func (h *HybridDB) RevokeAPIKey(ctx context.Context, id string) error {
+	return h.sqlDB.RevokeAPIKey(ctx, id)
+}
+
+func (h *HybridDB) TouchAPIKey(ctx context.Context, id string, usedAt time.Time) error {
+	return h.sqlDB.TouchAPIKey(ctx, id, usedAt)
+}
+
 // Prompt operations - Use NoSQL
 func (h *HybridDB) CreatePrompt(ctx context.Context, prompt *models.Prompt) error {
 	return h.nosqlDB.CreatePrompt(ctx, prompt)
@@ -185,6 +212,30 @@ func (h *HybridDB) DeleteAllResponses(ctx context.Context) (int, error) {
 	return h.nosqlDB.DeleteAllResponses(ctx)
 }
 
+func (h *HybridDB) BackfillResponsePromptMetadata(ctx context.Context, prompt *models.Prompt) (int, error) {
+	return h.nosqlDB.BackfillResponsePromptMetadata(ctx, prompt)
+}
+
+func (h *HybridDB) ReclassifyResponseSources(ctx context.Context, classify func(domains []string) []string) (int, error) {
+	return h.nosqlDB.ReclassifyResponseSources(ctx, classify)
+}
+
+func (h *HybridDB) AggregateResponseMetrics(ctx context.Context, filter shared.ResponseFilter, groupBy shared.ResponseGroup) ([]*models.ResponseMetrics, error) {
+	return h.nosqlDB.AggregateResponseMetrics(ctx, filter, groupBy)
+}
+
+func (h *HybridDB) CountCompetitorMentions(ctx context.Context, filter shared.ResponseFilter) (map[string]int, error) {
+	return h.nosqlDB.CountCompetitorMentions(ctx, filter)
+}
+
+func (h *HybridDB) CountGroupedCompetitorMentions(ctx context.Context, filter shared.ResponseFilter, groupBy shared.ResponseGroup) (map[string]map[string]int, error) {
+	return h.nosqlDB.CountGroupedCompetitorMentions(ctx, filter, groupBy)
+}
+
+func (h *HybridDB) AggregateSourceDomains(ctx context.Context, filter shared.ResponseFilter) ([]*models.SourceDomainStats, error) {
+	return h.nosqlDB.AggregateSourceDomains(ctx, filter)
+}
+
 func (h *HybridDB) SearchKeyword(ctx context.Context, keyword string, startTime, endTime *time.Time) (*models.KeywordStats, error) {
 	return h.nosqlDB.SearchKeyword(ctx, keyword, startTime, endTime)
 }
@@ -249,6 +300,60 @@ func (h *HybridDB) ListBrandProfiles(ctx context.Context) ([]*models.BrandProfil
 	return h.nosqlDB.ListBrandProfiles(ctx)
 }
 
+// Campaign operations - Use NoSQL
+func (h *HybridDB) CreateCampaign(ctx context.Context, campaign *models.GEOCampaign) error {
+	return h.nosqlDB.CreateCampaign(ctx, campaign)
+}
+
+func (h *HybridDB) GetCampaign(ctx context.Context, id string) (*models.GEOCampaign, error) {
+	return h.nosqlDB.GetCampaign(ctx, id)
+}
+
+func (h *HybridDB) ListCampaigns(ctx context.Context, brand string) ([]*models.GEOCampaign, error) {
+	return h.nosqlDB.ListCampaigns(ctx, brand)
+}
+
+func (h *HybridDB) UpdateCampaign(ctx context.Context, campaign *models.GEOCampaign) error {
+	return h.nosqlDB.UpdateCampaign(ctx, campaign)
+}
+
+func (h *HybridDB) IncrementCampaignProgress(ctx context.Context, id string, completed, failed int) error {
+	return h.nosqlDB.IncrementCampaignProgress(ctx, id, completed, failed)
+}
+
+func (h *HybridDB) FinishCampaign(ctx context.Context, id, status, errMsg string) (*models.GEOCampaign, error) {
+	return h.nosqlDB.FinishCampaign(ctx, id, status, errMsg)
+}
+
+// Job queue operations - Use NoSQL
+func (h *HybridDB) EnqueueJobs(ctx context.Context, jobs []*models.Job) error {
+	return h.nosqlDB.EnqueueJobs(ctx, jobs)
+}
+
+func (h *HybridDB) LeaseJob(ctx context.Context, owner string, kinds []string, lease time.Duration) (*models.Job, error) {
+	return h.nosqlDB.LeaseJob(ctx, owner, kinds, lease)
+}
+
+func (h *HybridDB) RetryJob(ctx context.Context, id, owner, lastError string, availableAt time.Time) error {
+	return h.nosqlDB.RetryJob(ctx, id, owner, lastError, availableAt)
+}
+
+func (h *HybridDB) FinishJob(ctx context.Context, id, owner, status, lastError string) error {
+	return h.nosqlDB.FinishJob(ctx, id, owner, status, lastError)
+}
+
+func (h *HybridDB) CancelCampaignJobs(ctx context.Context, campaignID string) (int, error) {
+	return h.nosqlDB.CancelCampaignJobs(ctx, campaignID)
+}
+
+func (h *HybridDB) GetJob(ctx context.Context, id string) (*models.Job, error) {
+	return h.nosqlDB.GetJob(ctx, id)
+}
+
+func (h *HybridDB) ListJobs(ctx context.Context, status string, limit int) ([]*models.Job, error) {
+	return h.nosqlDB.ListJobs(ctx, status, limit)
+}
+
 func (h *HybridDB) SaveBrandLogo(ctx context.Context, logo *models.BrandLogoCache) error {
 	return h.nosqlDB.SaveBrandLogo(ctx, logo)
 }
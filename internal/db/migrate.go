@@ -5,54 +5,180 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
-	"os"
-	"path/filepath"
-	"strings"
 
 	"github.com/golang-migrate/migrate/v4"
-	"github.com/golang-migrate/migrate/v4/database/sqlite3"
-	_ "github.com/golang-migrate/migrate/v4/source/file"
+
+	"github.com/fissionx/gego/internal/db/migrations"
+)
+
+// Migration targets
+const (
+	MigrateSQL   = "sql"
+	MigrateNoSQL = "nosql"
 )
 
-func RunMigrations(ctx context.Context, db *sql.DB, migrationsDir string) error {
-	if migrationsDir == "" {
-		migrationsDir = "/migrations"
-		if _, err := os.Stat(migrationsDir); os.IsNotExist(err) {
-			workDir, _ := os.Getwd()
-			migrationsDir = filepath.Join(workDir, "internal", "db", "migrations")
+// MigrationStatus is the schema version of one of the databases
+type MigrationStatus struct {
+	Target     string // MigrateSQL or MigrateNoSQL
+	Provider   string
+	Versioned  bool // False for stores that create their schema on connect
+	Version    uint
+	Dirty      bool
+	Latest     uint
+	Migrations []migrations.Info
+}
+
+// Pending counts the migrations not applied yet
+func (s *MigrationStatus) Pending() int {
+	pending := 0
+	for _, migration := range s.Migrations {
+		if migration.Version > s.Version {
+			pending++
 		}
 	}
+	return pending
+}
 
-	absPath, err := filepath.Abs(migrationsDir)
-	if err != nil {
-		return fmt.Errorf("failed to resolve migrations path: %w", err)
+// Migrator applies the versioned migrations of both databases: the embedded
+// SQL migrations of SQLite and the Go migrations of the NoSQL store
+type Migrator struct {
+	sqlDB         *sql.DB
+	nosql         migrations.Store // nil when the NoSQL store is not versioned
+	nosqlProvider string
+}
+
+// NewMigrator creates a migrator for a connected database
+func NewMigrator(database Database) (*Migrator, error) {
+	hybridDB, ok := database.(*HybridDB)
+	if !ok {
+		return nil, fmt.Errorf("database is not a HybridDB instance")
 	}
 
-	if _, err := os.Stat(absPath); os.IsNotExist(err) {
-		return fmt.Errorf("migrations directory not found: %s", absPath)
+	sqliteDB := hybridDB.GetSQLiteDatabase()
+	if sqliteDB == nil || sqliteDB.GetDB() == nil {
+		return nil, fmt.Errorf("SQLite database not available")
 	}
 
-	driver, err := sqlite3.WithInstance(db, &sqlite3.Config{})
-	if err != nil {
-		return fmt.Errorf("failed to create sqlite driver: %w", err)
+	migrator := &Migrator{sqlDB: sqliteDB.GetDB()}
+	if mongoDB := hybridDB.GetNoSQLDatabase(); mongoDB != nil {
+		migrator.nosql = mongoDB
+		migrator.nosqlProvider = "mongodb"
+	} else {
+		migrator.nosqlProvider = "sqlite"
 	}
+	return migrator, nil
+}
 
-	sourceURL := fmt.Sprintf("file://%s", absPath)
-	if !strings.HasPrefix(absPath, "/") {
-		sourceURL = fmt.Sprintf("file:///%s", absPath)
+// RunMigrations applies all pending migrations of both databases
+func RunMigrations(ctx context.Context, database Database) error {
+	migrator, err := NewMigrator(database)
+	if err != nil {
+		return err
 	}
+	return migrator.Up(ctx)
+}
 
-	m, err := migrate.NewWithDatabaseInstance(sourceURL, "sqlite3", driver)
+// Up applies all pending migrations of both databases
+func (m *Migrator) Up(ctx context.Context) error {
+	sqlMigrate, err := migrations.NewSQL(m.sqlDB)
 	if err != nil {
-		return fmt.Errorf("failed to create migrate instance: %w", err)
+		return err
+	}
+	if err := sqlMigrate.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return fmt.Errorf("failed to run SQL migrations: %w", err)
+	}
+
+	if m.nosql != nil {
+		if _, err := migrations.Up(ctx, m.nosql); err != nil {
+			return fmt.Errorf("failed to run NoSQL migrations: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Down reverts the last steps migrations of one database
+func (m *Migrator) Down(ctx context.Context, target string, steps int) error {
+	if steps < 1 {
+		return fmt.Errorf("steps must be at least 1")
+	}
+
+	switch target {
+	case MigrateSQL:
+		sqlMigrate, err := migrations.NewSQL(m.sqlDB)
+		if err != nil {
+			return err
+		}
+		if err := sqlMigrate.Steps(-steps); err != nil {
+			return fmt.Errorf("failed to revert SQL migrations: %w", err)
+		}
+	case MigrateNoSQL:
+		if m.nosql == nil {
+			return fmt.Errorf("the %s NoSQL store has no versioned migrations", m.nosqlProvider)
+		}
+		if _, err := migrations.Down(ctx, m.nosql, steps); err != nil {
+			return fmt.Errorf("failed to revert NoSQL migrations: %w", err)
+		}
+	default:
+		return fmt.Errorf("unknown migration target %q: use %s or %s", target, MigrateSQL, MigrateNoSQL)
 	}
 
-	if err := m.Up(); err != nil {
-		if errors.Is(err, migrate.ErrNoChange) {
-			return nil
+	return nil
+}
+
+// Force records the schema version of one database without running any
+// migration, clearing the dirty flag left by a failed one
+func (m *Migrator) Force(ctx context.Context, target string, version uint) error {
+	switch target {
+	case MigrateSQL:
+		sqlMigrate, err := migrations.NewSQL(m.sqlDB)
+		if err != nil {
+			return err
+		}
+		if err := sqlMigrate.Force(int(version)); err != nil {
+			return fmt.Errorf("failed to force SQL schema version: %w", err)
 		}
-		return fmt.Errorf("failed to run migrations: %w", err)
+	case MigrateNoSQL:
+		if m.nosql == nil {
+			return fmt.Errorf("the %s NoSQL store has no versioned migrations", m.nosqlProvider)
+		}
+		if err := m.nosql.SetSchemaVersion(ctx, version, false); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unknown migration target %q: use %s or %s", target, MigrateSQL, MigrateNoSQL)
 	}
 
 	return nil
 }
+
+// Status returns the schema version of both databases
+func (m *Migrator) Status(ctx context.Context) ([]*MigrationStatus, error) {
+	sqlInfos, err := migrations.SQL()
+	if err != nil {
+		return nil, err
+	}
+	sqlStatus := &MigrationStatus{Target: MigrateSQL, Provider: "sqlite", Versioned: true, Migrations: sqlInfos}
+	if len(sqlInfos) > 0 {
+		sqlStatus.Latest = sqlInfos[len(sqlInfos)-1].Version
+	}
+	sqlStatus.Version, sqlStatus.Dirty, err = migrations.SQLVersion(ctx, m.sqlDB)
+	if err != nil {
+		return nil, err
+	}
+
+	nosqlStatus := &MigrationStatus{Target: MigrateNoSQL, Provider: m.nosqlProvider}
+	if m.nosql != nil {
+		nosqlStatus.Versioned = true
+		nosqlStatus.Latest = migrations.Latest(m.nosql)
+		for _, step := range m.nosql.Migrations() {
+			nosqlStatus.Migrations = append(nosqlStatus.Migrations, migrations.Info{Version: step.Version, Description: step.Description})
+		}
+		nosqlStatus.Version, nosqlStatus.Dirty, err = m.nosql.SchemaVersion(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return []*MigrationStatus{sqlStatus, nosqlStatus}, nil
+}
@@ -0,0 +1,5 @@
+-- Migration: 002_api_keys.down.sql
+-- Description: Rollback API keys table
+
+DROP INDEX IF EXISTS idx_api_keys_revoked_at;
+DROP TABLE IF EXISTS api_keys;
@@ -0,0 +1,15 @@
+-- Migration: 002_api_keys.up.sql
+-- Description: API keys for the REST API, stored as SHA-256 hashes
+
+CREATE TABLE IF NOT EXISTS api_keys (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    prefix TEXT NOT NULL,
+    key_hash TEXT NOT NULL UNIQUE,
+    scopes TEXT NOT NULL DEFAULT '[]', -- JSON array of scopes (read, manage, execute)
+    last_used_at DATETIME,
+    revoked_at DATETIME,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_api_keys_revoked_at ON api_keys(revoked_at);
@@ -0,0 +1,52 @@
+-- Migration: 003_relax_llm_provider_check.down.sql
+-- Description: Restore the CHECK constraint on llms.provider.
+-- LLMs of providers outside the original five are deleted.
+
+DROP VIEW IF EXISTS v_enabled_llms;
+DROP TRIGGER IF EXISTS trigger_llms_updated_at;
+
+CREATE TABLE llms_old (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    provider TEXT NOT NULL CHECK (provider IN ('openai', 'anthropic', 'ollama', 'google', 'perplexity')),
+    model TEXT NOT NULL,
+    api_key TEXT,
+    base_url TEXT,
+    config TEXT DEFAULT '{}', -- JSON string for additional provider-specific config
+    enabled BOOLEAN NOT NULL DEFAULT 1,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO llms_old (id, name, provider, model, api_key, base_url, config, enabled, created_at, updated_at)
+SELECT id, name, provider, model, api_key, base_url, config, enabled, created_at, updated_at FROM llms
+WHERE provider IN ('openai', 'anthropic', 'ollama', 'google', 'perplexity');
+
+DROP TABLE llms;
+ALTER TABLE llms_old RENAME TO llms;
+
+CREATE INDEX IF NOT EXISTS idx_llms_provider ON llms(provider);
+CREATE INDEX IF NOT EXISTS idx_llms_enabled ON llms(enabled);
+CREATE INDEX IF NOT EXISTS idx_llms_created_at ON llms(created_at);
+CREATE INDEX IF NOT EXISTS idx_llms_updated_at ON llms(updated_at);
+
+CREATE TRIGGER IF NOT EXISTS trigger_llms_updated_at 
+    AFTER UPDATE ON llms
+    FOR EACH ROW
+    BEGIN
+        UPDATE llms SET updated_at = CURRENT_TIMESTAMP WHERE id = NEW.id;
+    END;
+
+CREATE VIEW IF NOT EXISTS v_enabled_llms AS
+SELECT 
+    id,
+    name,
+    provider,
+    model,
+    base_url,
+    config,
+    created_at,
+    updated_at
+FROM llms 
+WHERE enabled = 1
+ORDER BY created_at DESC;
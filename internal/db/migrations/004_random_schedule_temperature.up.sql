@@ -0,0 +1,71 @@
+-- Migration: 004_random_schedule_temperature.up.sql
+-- Description: Allow temperature -1.0 on schedules, the value recording a random
+-- temperature for each execution. SQLite cannot alter a constraint, so the table is rebuilt.
+
+DROP VIEW IF EXISTS v_enabled_schedules;
+DROP VIEW IF EXISTS v_schedule_stats;
+DROP TRIGGER IF EXISTS trigger_schedules_updated_at;
+
+CREATE TABLE schedules_new (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    prompt_ids TEXT NOT NULL DEFAULT '[]', -- JSON array of prompt IDs
+    llm_ids TEXT NOT NULL DEFAULT '[]',    -- JSON array of LLM IDs
+    cron_expr TEXT NOT NULL,
+    temperature REAL DEFAULT 0.7 CHECK (temperature = -1.0 OR (temperature >= 0.0 AND temperature <= 1.0)),
+    enabled BOOLEAN NOT NULL DEFAULT 1,
+    last_run DATETIME,
+    next_run DATETIME,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO schedules_new (id, name, prompt_ids, llm_ids, cron_expr, temperature, enabled, last_run, next_run, created_at, updated_at)
+SELECT id, name, prompt_ids, llm_ids, cron_expr, temperature, enabled, last_run, next_run, created_at, updated_at FROM schedules;
+
+DROP TABLE schedules;
+ALTER TABLE schedules_new RENAME TO schedules;
+
+CREATE INDEX IF NOT EXISTS idx_schedules_enabled ON schedules(enabled);
+CREATE INDEX IF NOT EXISTS idx_schedules_next_run ON schedules(next_run);
+CREATE INDEX IF NOT EXISTS idx_schedules_created_at ON schedules(created_at);
+CREATE INDEX IF NOT EXISTS idx_schedules_updated_at ON schedules(updated_at);
+CREATE INDEX IF NOT EXISTS idx_schedules_cron_expr ON schedules(cron_expr);
+
+CREATE TRIGGER IF NOT EXISTS trigger_schedules_updated_at 
+    AFTER UPDATE ON schedules
+    FOR EACH ROW
+    BEGIN
+        UPDATE schedules SET updated_at = CURRENT_TIMESTAMP WHERE id = NEW.id;
+    END;
+
+CREATE VIEW IF NOT EXISTS v_enabled_schedules AS
+SELECT 
+    id,
+    name,
+    prompt_ids,
+    llm_ids,
+    cron_expr,
+    temperature,
+    last_run,
+    next_run,
+    created_at,
+    updated_at
+FROM schedules 
+WHERE enabled = 1
+ORDER BY next_run ASC;
+
+CREATE VIEW IF NOT EXISTS v_schedule_stats AS
+SELECT 
+    s.id,
+    s.name,
+    s.cron_expr,
+    s.enabled,
+    s.last_run,
+    s.next_run,
+    COUNT(DISTINCT json_extract(s.prompt_ids, '$[' || i.value || ']')) as prompt_count,
+    COUNT(DISTINCT json_extract(s.llm_ids, '$[' || j.value || ']')) as llm_count
+FROM schedules s
+LEFT JOIN json_each(s.prompt_ids) i ON 1=1
+LEFT JOIN json_each(s.llm_ids) j ON 1=1
+GROUP BY s.id, s.name, s.cron_expr, s.enabled, s.last_run, s.next_run;
@@ -0,0 +1,4 @@
+-- Migration: 005_schedule_variables.down.sql
+-- Description: Drop the template variable value sets of schedules.
+
+ALTER TABLE schedules DROP COLUMN variables;
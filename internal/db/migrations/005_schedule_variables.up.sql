@@ -0,0 +1,5 @@
+-- Migration: 005_schedule_variables.up.sql
+-- Description: Add the value sets of prompt template variables to schedules,
+-- a JSON object mapping each variable name to its values.
+
+ALTER TABLE schedules ADD COLUMN variables TEXT NOT NULL DEFAULT '{}';
@@ -0,0 +1,209 @@
+// Package migrations holds the versioned schema migrations of gego's databases.
+//
+// The SQL migrations are the *.sql files of this directory, embedded in the
+// binary and applied with golang-migrate. Document stores declare their
+// migrations in Go, as a list of Migration, and record the applied version
+// the same way: a version number and a dirty flag set while a migration runs.
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database/sqlite3"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+)
+
+//go:embed *.sql
+var sqlFiles embed.FS
+
+// sqlTable is the table golang-migrate records the SQL schema version in
+const sqlTable = "schema_migrations"
+
+// Info describes one migration
+type Info struct {
+	Version     uint
+	Description string
+}
+
+// Migration is a versioned change to a document store, written in Go
+type Migration struct {
+	Version     uint
+	Description string
+	Up          func(ctx context.Context) error
+	Down        func(ctx context.Context) error
+}
+
+// Store is a document store whose schema is versioned by its migrations
+type Store interface {
+	// Migrations lists the migrations of the store, in version order
+	Migrations() []Migration
+	// SchemaVersion returns the applied version, 0 when nothing was applied
+	SchemaVersion(ctx context.Context) (version uint, dirty bool, err error)
+	// SetSchemaVersion records the applied version
+	SetSchemaVersion(ctx context.Context, version uint, dirty bool) error
+}
+
+// NewSQL creates a golang-migrate instance applying the embedded SQL
+// migrations to db. Closing it closes db.
+func NewSQL(db *sql.DB) (*migrate.Migrate, error) {
+	source, err := iofs.New(sqlFiles, ".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read embedded migrations: %w", err)
+	}
+
+	driver, err := sqlite3.WithInstance(db, &sqlite3.Config{MigrationsTable: sqlTable})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create sqlite driver: %w", err)
+	}
+
+	m, err := migrate.NewWithInstance("iofs", source, "sqlite3", driver)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create migrate instance: %w", err)
+	}
+	return m, nil
+}
+
+// SQL lists the embedded SQL migrations, in version order
+func SQL() ([]Info, error) {
+	names, err := fs.Glob(sqlFiles, "*.up.sql")
+	if err != nil {
+		return nil, err
+	}
+
+	var infos []Info
+	for _, name := range names {
+		prefix, description, _ := strings.Cut(strings.TrimSuffix(name, ".up.sql"), "_")
+		version, err := strconv.ParseUint(prefix, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration file name %s: %w", name, err)
+		}
+		infos = append(infos, Info{Version: uint(version), Description: strings.ReplaceAll(description, "_", " ")})
+	}
+
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Version < infos[j].Version })
+	return infos, nil
+}
+
+// LatestSQL returns the version of the newest embedded SQL migration
+func LatestSQL() (uint, error) {
+	infos, err := SQL()
+	if err != nil {
+		return 0, err
+	}
+	if len(infos) == 0 {
+		return 0, nil
+	}
+	return infos[len(infos)-1].Version, nil
+}
+
+// SQLVersion returns the SQL schema version recorded in db, 0 for a database
+// no migration ran on. Unlike golang-migrate, it creates nothing.
+func SQLVersion(ctx context.Context, db *sql.DB) (version uint, dirty bool, err error) {
+	var count int
+	err = db.QueryRowContext(ctx, "SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?", sqlTable).Scan(&count)
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to look up schema version table: %w", err)
+	}
+	if count == 0 {
+		return 0, false, nil
+	}
+
+	err = db.QueryRowContext(ctx, "SELECT version, dirty FROM "+sqlTable+" LIMIT 1").Scan(&version, &dirty)
+	if err == sql.ErrNoRows {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to read schema version: %w", err)
+	}
+	return version, dirty, nil
+}
+
+// Latest returns the version of the newest migration of a document store
+func Latest(store Store) uint {
+	steps := store.Migrations()
+	if len(steps) == 0 {
+		return 0
+	}
+	return steps[len(steps)-1].Version
+}
+
+// Up applies the pending migrations of a document store and returns how many ran
+func Up(ctx context.Context, store Store) (int, error) {
+	current, err := cleanVersion(ctx, store)
+	if err != nil {
+		return 0, err
+	}
+
+	applied := 0
+	for _, step := range store.Migrations() {
+		if step.Version <= current {
+			continue
+		}
+		if err := store.SetSchemaVersion(ctx, step.Version, true); err != nil {
+			return applied, err
+		}
+		if err := step.Up(ctx); err != nil {
+			return applied, fmt.Errorf("migration %d (%s) failed: %w", step.Version, step.Description, err)
+		}
+		if err := store.SetSchemaVersion(ctx, step.Version, false); err != nil {
+			return applied, err
+		}
+		applied++
+	}
+	return applied, nil
+}
+
+// Down reverts the last steps applied migrations of a document store and
+// returns how many ran
+func Down(ctx context.Context, store Store, steps int) (int, error) {
+	current, err := cleanVersion(ctx, store)
+	if err != nil {
+		return 0, err
+	}
+
+	all := store.Migrations()
+	reverted := 0
+	for i := len(all) - 1; i >= 0 && reverted < steps; i-- {
+		step := all[i]
+		if step.Version > current {
+			continue
+		}
+
+		previous := uint(0)
+		if i > 0 {
+			previous = all[i-1].Version
+		}
+
+		if err := store.SetSchemaVersion(ctx, step.Version, true); err != nil {
+			return reverted, err
+		}
+		if err := step.Down(ctx); err != nil {
+			return reverted, fmt.Errorf("reverting migration %d (%s) failed: %w", step.Version, step.Description, err)
+		}
+		if err := store.SetSchemaVersion(ctx, previous, false); err != nil {
+			return reverted, err
+		}
+		reverted++
+	}
+	return reverted, nil
+}
+
+// cleanVersion returns the applied version of a store, refusing a dirty one
+func cleanVersion(ctx context.Context, store Store) (uint, error) {
+	version, dirty, err := store.SchemaVersion(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if dirty {
+		return 0, fmt.Errorf("migration %d did not complete: repair the database, then force the version it is at", version)
+	}
+	return version, nil
+}
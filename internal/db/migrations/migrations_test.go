@@ -0,0 +1,111 @@
+package migrations_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+
+	"github.com/fissionx/gego/internal/db/migrations"
+)
+
+// memoryStore is a document store keeping its schema version in memory
+type memoryStore struct {
+	version uint
+	dirty   bool
+	failAt  uint // Version of the migration that fails
+}
+
+func (s *memoryStore) Migrations() []migrations.Migration {
+	step := func(version uint) migrations.Migration {
+		return migrations.Migration{
+			Version: version,
+			Up: func(ctx context.Context) error {
+				if version == s.failAt {
+					return errors.New("boom")
+				}
+				return nil
+			},
+			Down: func(ctx context.Context) error { return nil },
+		}
+	}
+	return []migrations.Migration{step(1), step(2), step(5)}
+}
+
+func (s *memoryStore) SchemaVersion(ctx context.Context) (uint, bool, error) {
+	return s.version, s.dirty, nil
+}
+
+func (s *memoryStore) SetSchemaVersion(ctx context.Context, version uint, dirty bool) error {
+	s.version, s.dirty = version, dirty
+	return nil
+}
+
+func TestStoreUpDown(t *testing.T) {
+	ctx := context.Background()
+	store := &memoryStore{version: 1}
+
+	if applied, err := migrations.Up(ctx, store); err != nil || applied != 2 || store.version != 5 || store.dirty {
+		t.Fatalf("Up() = %d, %v; store at %d (dirty %v), want 2 applied, at 5", applied, err, store.version, store.dirty)
+	}
+	if applied, _ := migrations.Up(ctx, store); applied != 0 {
+		t.Errorf("second Up() applied %d, want 0", applied)
+	}
+
+	if reverted, err := migrations.Down(ctx, store, 2); err != nil || reverted != 2 || store.version != 1 {
+		t.Fatalf("Down(2) = %d, %v; store at %d, want 2 reverted, at 1", reverted, err, store.version)
+	}
+	if reverted, _ := migrations.Down(ctx, store, 5); reverted != 1 || store.version != 0 {
+		t.Errorf("Down(5) = %d; store at %d, want 1 reverted, at 0", reverted, store.version)
+	}
+}
+
+func TestStoreFailedMigrationIsDirty(t *testing.T) {
+	ctx := context.Background()
+	store := &memoryStore{failAt: 2}
+
+	if _, err := migrations.Up(ctx, store); err == nil {
+		t.Fatal("Up() with a failing migration succeeded")
+	}
+	if store.version != 2 || !store.dirty {
+		t.Fatalf("store at %d (dirty %v), want dirty at 2", store.version, store.dirty)
+	}
+
+	store.failAt = 0
+	if _, err := migrations.Up(ctx, store); err == nil {
+		t.Error("Up() on a dirty store succeeded")
+	}
+}
+
+func TestSQLMigrations(t *testing.T) {
+	ctx := context.Background()
+
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "gego.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	if version, dirty, err := migrations.SQLVersion(ctx, db); err != nil || version != 0 || dirty {
+		t.Fatalf("SQLVersion() of an empty database = %d, %v, %v", version, dirty, err)
+	}
+
+	m, err := migrations.NewSQL(db)
+	if err != nil {
+		t.Fatalf("NewSQL() error = %v", err)
+	}
+	if err := m.Up(); err != nil {
+		t.Fatalf("Up() error = %v", err)
+	}
+
+	latest, err := migrations.LatestSQL()
+	if err != nil || latest == 0 {
+		t.Fatalf("LatestSQL() = %d, %v", latest, err)
+	}
+	if version, dirty, err := migrations.SQLVersion(ctx, db); err != nil || version != latest || dirty {
+		t.Errorf("SQLVersion() after Up() = %d, %v, %v, want %d", version, dirty, err, latest)
+	}
+}
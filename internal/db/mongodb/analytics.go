@@ -0,0 +1,355 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/fissionx/gego/internal/models"
+	"github.com/fissionx/gego/internal/shared"
+)
+
+// responseQuery builds the MongoDB query for a response filter
+func responseQuery(filter shared.ResponseFilter) bson.M {
+	query := bson.M{}
+	var and bson.A
+
+	// Conditions on the same field are combined with $and
+	add := func(field string, cond interface{}) {
+		if _, exists := query[field]; exists {
+			and = append(and, bson.M{field: cond})
+			return
+		}
+		query[field] = cond
+	}
+
+	if filter.PromptID != "" {
+		add("prompt_id", filter.PromptID)
+	}
+	if len(filter.PromptIDs) > 0 {
+		add("prompt_id", bson.M{"$in": filter.PromptIDs})
+	}
+	if filter.PromptType != "" {
+		add("prompt_type", filter.PromptType)
+	}
+	if filter.LLMID != "" {
+		add("llm_id", filter.LLMID)
+	}
+	if len(filter.LLMIDs) > 0 {
+		add("llm_id", bson.M{"$in": filter.LLMIDs})
+	}
+	if filter.ScheduleID != "" {
+		add("schedule_id", filter.ScheduleID)
+	}
+	if filter.CampaignID != "" {
+		add("campaign_id", filter.CampaignID)
+	}
+	if filter.Brand != "" {
+		add("brand", filter.Brand)
+	}
+	if filter.Region != "" {
+		add("region", filter.Region)
+	}
+	if filter.Language != "" {
+		add("language", filter.Language)
+	}
+	if filter.LLMProvider != "" {
+		add("llm_provider", filter.LLMProvider)
+	}
+	if filter.SourceCategory != "" {
+		add("source_categories", filter.SourceCategory)
+	}
+	if filter.Keyword != "" {
+		add("response_text", bson.M{
+			"$regex":   filter.Keyword,
+			"$options": "i",
+		})
+	}
+	if filter.StartTime != nil || filter.EndTime != nil {
+		timeQuery := bson.M{}
+		if filter.StartTime != nil {
+			timeQuery["$gte"] = *filter.StartTime
+		}
+		if filter.EndTime != nil {
+			timeQuery["$lte"] = *filter.EndTime
+		}
+		add("created_at", timeQuery)
+	}
+
+	if len(and) > 0 {
+		query["$and"] = and
+	}
+
+	return query
+}
+
+// responseGroupID returns the expression of the group key of a response
+func responseGroupID(groupBy shared.ResponseGroup) (interface{}, error) {
+	switch groupBy {
+	case shared.GroupByNone:
+		return nil, nil
+	case shared.GroupByPrompt:
+		return "$prompt_id", nil
+	case shared.GroupByLLM:
+		return bson.M{"$concat": bson.A{
+			bson.M{"$ifNull": bson.A{"$llm_provider", ""}}, "-", bson.M{"$ifNull": bson.A{"$llm_name", ""}},
+		}}, nil
+	case shared.GroupByCategory:
+		return "$prompt_category", nil
+	case shared.GroupBySentiment:
+		return "$sentiment", nil
+	case shared.GroupByWeek, shared.GroupByMonth, shared.GroupByQuarter:
+		return "$" + string(groupBy), nil
+	}
+
+	origin, duration, ok := groupBy.Interval()
+	if !ok {
+		return nil, fmt.Errorf("unsupported response grouping: %s", groupBy)
+	}
+	// Start of the interval holding created_at, as RFC 3339 in UTC
+	originMillis, durationMillis := origin.UnixMilli(), duration.Milliseconds()
+	return bson.M{"$dateToString": bson.M{
+		"format": "%Y-%m-%dT%H:%M:%SZ",
+		"date": bson.M{"$toDate": bson.M{"$add": bson.A{originMillis, bson.M{"$multiply": bson.A{
+			bson.M{"$floor": bson.M{"$divide": bson.A{
+				bson.M{"$subtract": bson.A{bson.M{"$toLong": "$created_at"}, originMillis}}, durationMillis,
+			}}},
+			durationMillis,
+		}}}}},
+	}}, nil
+}
+
+// skipsEmptyGroups reports whether responses without a value for the grouped
+// field are left out
+func skipsEmptyGroups(groupBy shared.ResponseGroup) bool {
+	switch groupBy {
+	case shared.GroupByCategory, shared.GroupBySentiment, shared.GroupByWeek, shared.GroupByMonth, shared.GroupByQuarter:
+		return true
+	}
+	return false
+}
+
+// AggregateResponseMetrics computes visibility, mention, grounding, position and
+// sentiment metrics for the matching responses, grouped by the given dimension
+func (m *MongoDB) AggregateResponseMetrics(ctx context.Context, filter shared.ResponseFilter, groupBy shared.ResponseGroup) ([]*models.ResponseMetrics, error) {
+	query := responseQuery(filter)
+
+	group := bson.M{
+		"total_responses":  bson.M{"$sum": 1},
+		"total_visibility": bson.M{"$sum": "$visibility_score"},
+		"mention_count": bson.M{"$sum": bson.M{
+			"$cond": bson.A{bson.M{"$eq": bson.A{"$brand_mentioned", true}}, 1, 0},
+		}},
+		"grounded_count": bson.M{"$sum": bson.M{
+			"$cond": bson.A{bson.M{"$eq": bson.A{"$in_grounding_sources", true}}, 1, 0},
+		}},
+		"position_sum": bson.M{"$sum": bson.M{
+			"$cond": bson.A{bson.M{"$gt": bson.A{"$brand_position", 0}}, "$brand_position", 0},
+		}},
+		"position_count": bson.M{"$sum": bson.M{
+			"$cond": bson.A{bson.M{"$gt": bson.A{"$brand_position", 0}}, 1, 0},
+		}},
+		"top_position_count": bson.M{"$sum": bson.M{
+			"$cond": bson.A{bson.M{"$and": bson.A{
+				bson.M{"$gt": bson.A{"$brand_position", 0}},
+				bson.M{"$lte": bson.A{"$brand_position", 3}},
+			}}, 1, 0},
+		}},
+		"sentiment_score_sum": bson.M{"$sum": bson.M{
+			"$switch": bson.M{
+				"branches": bson.A{
+					bson.M{"case": bson.M{"$eq": bson.A{bson.M{"$toLower": "$sentiment"}, "positive"}}, "then": 1},
+					bson.M{"case": bson.M{"$eq": bson.A{bson.M{"$toLower": "$sentiment"}, "negative"}}, "then": -1},
+				},
+				"default": 0,
+			},
+		}},
+		"sentiment_count": bson.M{"$sum": bson.M{
+			"$cond": bson.A{bson.M{"$ne": bson.A{bson.M{"$ifNull": bson.A{"$sentiment", ""}}, ""}}, 1, 0},
+		}},
+	}
+
+	pipeline := []bson.M{{"$match": query}}
+
+	id, err := responseGroupID(groupBy)
+	if err != nil {
+		return nil, err
+	}
+	group["_id"] = id
+	if groupBy == shared.GroupByLLM {
+		group["llm_name"] = bson.M{"$first": "$llm_name"}
+		group["llm_provider"] = bson.M{"$first": "$llm_provider"}
+	}
+
+	pipeline = append(pipeline, bson.M{"$group": group})
+	if skipsEmptyGroups(groupBy) {
+		pipeline = append(pipeline, bson.M{"$match": bson.M{"_id": bson.M{"$nin": bson.A{"", nil}}}})
+	}
+	pipeline = append(pipeline, bson.M{"$sort": bson.D{{Key: "total_responses", Value: -1}, {Key: "_id", Value: 1}}})
+
+	cursor, err := m.database.Collection(collResponses).Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate response metrics: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var metrics []*models.ResponseMetrics
+	if err := cursor.All(ctx, &metrics); err != nil {
+		return nil, fmt.Errorf("failed to decode response metrics: %w", err)
+	}
+
+	return metrics, nil
+}
+
+// CountCompetitorMentions counts in how many matching responses each competitor was mentioned
+func (m *MongoDB) CountCompetitorMentions(ctx context.Context, filter shared.ResponseFilter) (map[string]int, error) {
+	query := responseQuery(filter)
+
+	pipeline := []bson.M{
+		{"$match": query},
+		{"$unwind": "$competitors_mention"},
+		{
+			"$group": bson.M{
+				"_id":   "$competitors_mention",
+				"count": bson.M{"$sum": 1},
+			},
+		},
+	}
+
+	cursor, err := m.database.Collection(collResponses).Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate competitor mentions: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	counts := make(map[string]int)
+	for cursor.Next(ctx) {
+		var result struct {
+			ID    string `bson:"_id"`
+			Count int    `bson:"count"`
+		}
+		if err := cursor.Decode(&result); err != nil {
+			continue
+		}
+		if result.ID != "" {
+			counts[result.ID] = result.Count
+		}
+	}
+
+	return counts, nil
+}
+
+// CountGroupedCompetitorMentions counts in how many matching responses each
+// competitor was mentioned, by group key then competitor
+func (m *MongoDB) CountGroupedCompetitorMentions(ctx context.Context, filter shared.ResponseFilter, groupBy shared.ResponseGroup) (map[string]map[string]int, error) {
+	query := responseQuery(filter)
+
+	id, err := responseGroupID(groupBy)
+	if err != nil {
+		return nil, err
+	}
+
+	pipeline := []bson.M{
+		{"$match": query},
+		{"$project": bson.M{"key": id, "competitors_mention": 1}},
+	}
+	if skipsEmptyGroups(groupBy) {
+		pipeline = append(pipeline, bson.M{"$match": bson.M{"key": bson.M{"$nin": bson.A{"", nil}}}})
+	}
+	pipeline = append(pipeline,
+		bson.M{"$unwind": "$competitors_mention"},
+		bson.M{"$group": bson.M{
+			"_id":   bson.M{"key": "$key", "competitor": "$competitors_mention"},
+			"count": bson.M{"$sum": 1},
+		}},
+	)
+
+	cursor, err := m.database.Collection(collResponses).Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate competitor mentions: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	groups := make(map[string]map[string]int)
+	for cursor.Next(ctx) {
+		var result struct {
+			ID struct {
+				Key        string `bson:"key"`
+				Competitor string `bson:"competitor"`
+			} `bson:"_id"`
+			Count int `bson:"count"`
+		}
+		if err := cursor.Decode(&result); err != nil {
+			continue
+		}
+		if result.ID.Competitor == "" {
+			continue
+		}
+		if groups[result.ID.Key] == nil {
+			groups[result.ID.Key] = make(map[string]int)
+		}
+		groups[result.ID.Key][result.ID.Competitor] = result.Count
+	}
+
+	return groups, nil
+}
+
+// AggregateSourceDomains counts the citations of each grounding domain across
+// the matching responses, with a per-LLM breakdown, most cited first
+func (m *MongoDB) AggregateSourceDomains(ctx context.Context, filter shared.ResponseFilter) ([]*models.SourceDomainStats, error) {
+	query := responseQuery(filter)
+
+	pipeline := []bson.M{
+		{"$match": query},
+		{"$unwind": "$grounding_domains"},
+		{"$match": bson.M{"grounding_domains": bson.M{"$ne": ""}}},
+		{
+			"$group": bson.M{
+				"_id":   bson.M{"domain": "$grounding_domains", "llm_name": "$llm_name"},
+				"count": bson.M{"$sum": 1},
+			},
+		},
+		{
+			"$group": bson.M{
+				"_id":            "$_id.domain",
+				"citation_count": bson.M{"$sum": "$count"},
+				"llms":           bson.M{"$push": bson.M{"name": "$_id.llm_name", "count": "$count"}},
+			},
+		},
+		{"$sort": bson.D{{Key: "citation_count", Value: -1}, {Key: "_id", Value: 1}}},
+	}
+
+	cursor, err := m.database.Collection(collResponses).Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate source domains: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var domains []*models.SourceDomainStats
+	for cursor.Next(ctx) {
+		var result struct {
+			Domain        string `bson:"_id"`
+			CitationCount int    `bson:"citation_count"`
+			LLMs          []struct {
+				Name  string `bson:"name"`
+				Count int    `bson:"count"`
+			} `bson:"llms"`
+		}
+		if err := cursor.Decode(&result); err != nil {
+			return nil, fmt.Errorf("failed to decode source domain stats: %w", err)
+		}
+
+		stats := &models.SourceDomainStats{
+			Domain:        result.Domain,
+			CitationCount: result.CitationCount,
+			LLMBreakdown:  make(map[string]int, len(result.LLMs)),
+		}
+		for _, llm := range result.LLMs {
+			stats.LLMBreakdown[llm.Name] += llm.Count
+		}
+		domains = append(domains, stats)
+	}
+
+	return domains, cursor.Err()
+}
@@ -0,0 +1,214 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/fissionx/gego/internal/models"
+)
+
+// EnqueueJobs inserts new jobs into the queue
+func (m *MongoDB) EnqueueJobs(ctx context.Context, jobs []*models.Job) error {
+	if len(jobs) == 0 {
+		return nil
+	}
+
+	docs := make([]interface{}, 0, len(jobs))
+	for _, job := range jobs {
+		doc := bson.M{
+			"_id":          job.ID,
+			"kind":         job.Kind,
+			"prompt_id":    job.PromptID,
+			"llm_id":       job.LLMID,
+			"brand":        job.Brand,
+			"temperature":  job.Temperature,
+			"status":       job.Status,
+			"attempts":     job.Attempts,
+			"max_attempts": job.MaxAttempts,
+			"available_at": job.AvailableAt,
+			"created_at":   job.CreatedAt,
+			"updated_at":   job.UpdatedAt,
+		}
+		if job.CampaignID != "" {
+			doc["campaign_id"] = job.CampaignID
+		}
+		if job.ScheduleID != "" {
+			doc["schedule_id"] = job.ScheduleID
+		}
+		if len(job.Variables) > 0 {
+			doc["variables"] = job.Variables
+		}
+		docs = append(docs, doc)
+	}
+
+	if _, err := m.database.Collection(collJobs).InsertMany(ctx, docs); err != nil {
+		return fmt.Errorf("failed to enqueue jobs: %w", err)
+	}
+	return nil
+}
+
+// LeaseJob atomically claims the oldest available job of the given kinds.
+// Jobs whose lease expired (their worker died) are claimed again.
+// Returns nil if no job is available.
+func (m *MongoDB) LeaseJob(ctx context.Context, owner string, kinds []string, lease time.Duration) (*models.Job, error) {
+	now := time.Now()
+
+	filter := bson.M{
+		"kind": bson.M{"$in": kinds},
+		"$or": bson.A{
+			bson.M{"status": models.JobStatusPending, "available_at": bson.M{"$lte": now}},
+			bson.M{"status": models.JobStatusLeased, "lease_expires_at": bson.M{"$lt": now}},
+		},
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			"status":           models.JobStatusLeased,
+			"lease_owner":      owner,
+			"lease_expires_at": now.Add(lease),
+			"updated_at":       now,
+		},
+		"$inc": bson.M{"attempts": 1},
+	}
+
+	opts := options.FindOneAndUpdate().
+		SetSort(bson.D{{Key: "available_at", Value: 1}}).
+		SetReturnDocument(options.After)
+
+	var job models.Job
+	err := m.database.Collection(collJobs).FindOneAndUpdate(ctx, filter, update, opts).Decode(&job)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to lease job: %w", err)
+	}
+
+	return &job, nil
+}
+
+// RetryJob releases a leased job back to the queue, available again at availableAt
+func (m *MongoDB) RetryJob(ctx context.Context, id, owner, lastError string, availableAt time.Time) error {
+	update := bson.M{
+		"$set": bson.M{
+			"status":       models.JobStatusPending,
+			"last_error":   lastError,
+			"available_at": availableAt,
+			"updated_at":   time.Now(),
+		},
+		"$unset": bson.M{
+			"lease_owner":      "",
+			"lease_expires_at": "",
+		},
+	}
+
+	return m.updateLeasedJob(ctx, id, owner, update)
+}
+
+// FinishJob moves a leased job to a final status (done, dead or cancelled)
+func (m *MongoDB) FinishJob(ctx context.Context, id, owner, status, lastError string) error {
+	now := time.Now()
+
+	set := bson.M{
+		"status":       status,
+		"completed_at": now,
+		"updated_at":   now,
+	}
+	if lastError != "" {
+		set["last_error"] = lastError
+	}
+
+	update := bson.M{
+		"$set": set,
+		"$unset": bson.M{
+			"lease_owner":      "",
+			"lease_expires_at": "",
+		},
+	}
+
+	return m.updateLeasedJob(ctx, id, owner, update)
+}
+
+// updateLeasedJob applies an update to a job still leased by owner
+func (m *MongoDB) updateLeasedJob(ctx context.Context, id, owner string, update bson.M) error {
+	result, err := m.database.Collection(collJobs).UpdateOne(ctx, bson.M{
+		"_id":         id,
+		"status":      models.JobStatusLeased,
+		"lease_owner": owner,
+	}, update)
+	if err != nil {
+		return fmt.Errorf("failed to update job: %w", err)
+	}
+
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("job %s is no longer leased by %s", id, owner)
+	}
+
+	return nil
+}
+
+// CancelCampaignJobs cancels the pending jobs of a campaign. Leased jobs finish normally.
+func (m *MongoDB) CancelCampaignJobs(ctx context.Context, campaignID string) (int, error) {
+	now := time.Now()
+
+	result, err := m.database.Collection(collJobs).UpdateMany(ctx, bson.M{
+		"campaign_id": campaignID,
+		"status":      models.JobStatusPending,
+	}, bson.M{
+		"$set": bson.M{
+			"status":       models.JobStatusCancelled,
+			"completed_at": now,
+			"updated_at":   now,
+		},
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to cancel campaign jobs: %w", err)
+	}
+
+	return int(result.ModifiedCount), nil
+}
+
+// GetJob retrieves a job by ID
+func (m *MongoDB) GetJob(ctx context.Context, id string) (*models.Job, error) {
+	var job models.Job
+	err := m.database.Collection(collJobs).FindOne(ctx, bson.M{"_id": id}).Decode(&job)
+	if err == mongo.ErrNoDocuments {
+		return nil, fmt.Errorf("job not found: %s", id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find job: %w", err)
+	}
+
+	return &job, nil
+}
+
+// ListJobs lists jobs, newest first, optionally filtered by status
+func (m *MongoDB) ListJobs(ctx context.Context, status string, limit int) ([]*models.Job, error) {
+	query := bson.M{}
+	if status != "" {
+		query["status"] = status
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+	if limit > 0 {
+		opts.SetLimit(int64(limit))
+	}
+
+	cursor, err := m.database.Collection(collJobs).Find(ctx, query, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var jobs []*models.Job
+	if err := cursor.All(ctx, &jobs); err != nil {
+		return nil, err
+	}
+
+	return jobs, nil
+}
@@ -0,0 +1,232 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/fissionx/gego/internal/db/migrations"
+	"github.com/fissionx/gego/internal/logger"
+	"github.com/fissionx/gego/internal/models"
+	"github.com/fissionx/gego/internal/shared"
+)
+
+// collMigrations holds the single document recording the schema version
+const collMigrations = "schema_migrations"
+
+const schemaVersionID = "schema"
+
+// Migrations lists the index and document migrations of the MongoDB store.
+// Append new migrations at the end, with the next version number.
+func (m *MongoDB) Migrations() []migrations.Migration {
+	return []migrations.Migration{
+		{
+			Version:     1,
+			Description: "initial indexes",
+			Up:          m.createIndexes,
+			Down:        m.dropIndexes,
+		},
+		{
+			Version:     2,
+			Description: "split GEO analysis out of response text",
+			Up:          m.splitResponseAnalysis,
+			Down:        m.joinResponseAnalysis,
+		},
+		{
+			Version:     3,
+			Description: "recompute response weeks, months and quarters",
+			Up:          m.setResponsePeriods,
+			// The recomputed periods are as valid for earlier versions
+			Down: func(ctx context.Context) error { return nil },
+		},
+	}
+}
+
+// SchemaVersion returns the applied migration version, 0 when none was applied
+func (m *MongoDB) SchemaVersion(ctx context.Context) (uint, bool, error) {
+	var doc struct {
+		Version int64 `bson:"version"`
+		Dirty   bool  `bson:"dirty"`
+	}
+	err := m.database.Collection(collMigrations).FindOne(ctx, bson.M{"_id": schemaVersionID}).Decode(&doc)
+	if err == mongo.ErrNoDocuments {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to read schema version: %w", err)
+	}
+	return uint(doc.Version), doc.Dirty, nil
+}
+
+// SetSchemaVersion records the applied migration version
+func (m *MongoDB) SetSchemaVersion(ctx context.Context, version uint, dirty bool) error {
+	_, err := m.database.Collection(collMigrations).UpdateOne(ctx,
+		bson.M{"_id": schemaVersionID},
+		bson.M{"$set": bson.M{"version": int64(version), "dirty": dirty}},
+		options.Update().SetUpsert(true),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record schema version: %w", err)
+	}
+	return nil
+}
+
+// dropIndexes reverts createIndexes
+func (m *MongoDB) dropIndexes(ctx context.Context) error {
+	for _, coll := range []string{collResponses, collPromptLibrary, collBrandProfiles, collBrandLogos, collCampaigns, collJobs} {
+		if _, err := m.database.Collection(coll).Indexes().DropAll(ctx); err != nil {
+			return fmt.Errorf("failed to drop %s indexes: %w", coll, err)
+		}
+	}
+	return nil
+}
+
+// splitResponseAnalysis moves the analysis out of responses stored with the
+// combined answer and analysis document Google used to return as its answer
+func (m *MongoDB) splitResponseAnalysis(ctx context.Context) error {
+	coll := m.database.Collection(collResponses)
+	cursor, err := coll.Find(ctx,
+		bson.M{"brand": bson.M{"$ne": ""}, "analysis_raw": bson.M{"$exists": false}},
+		options.Find().SetProjection(bson.M{"response_text": 1}),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to list responses: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	split := 0
+	for cursor.Next(ctx) {
+		var doc struct {
+			ID           string `bson:"_id"`
+			ResponseText string `bson:"response_text"`
+		}
+		if err := cursor.Decode(&doc); err != nil {
+			return fmt.Errorf("failed to decode response: %w", err)
+		}
+
+		text, _ := shared.DecompressString(doc.ResponseText)
+		response := &models.Response{ResponseText: text}
+		if !shared.SplitResponseAnalysis(response) {
+			continue
+		}
+
+		_, err := coll.UpdateOne(ctx, bson.M{"_id": doc.ID}, bson.M{"$set": bson.M{
+			"response_text": compressText(response.ResponseText),
+			"analysis":      response.Analysis,
+			"analysis_raw":  compressText(response.AnalysisRaw),
+		}})
+		if err != nil {
+			return fmt.Errorf("failed to update response %s: %w", doc.ID, err)
+		}
+		split++
+	}
+	if err := cursor.Err(); err != nil {
+		return fmt.Errorf("failed to list responses: %w", err)
+	}
+
+	logger.Info("Split the GEO analysis out of %d responses", split)
+	return nil
+}
+
+// joinResponseAnalysis reverts splitResponseAnalysis, storing the combined
+// document back as the text of the responses it was split from
+func (m *MongoDB) joinResponseAnalysis(ctx context.Context) error {
+	coll := m.database.Collection(collResponses)
+	cursor, err := coll.Find(ctx,
+		bson.M{"analysis_raw": bson.M{"$exists": true}},
+		options.Find().SetProjection(bson.M{"analysis_raw": 1}),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to list responses: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var doc struct {
+			ID          string `bson:"_id"`
+			AnalysisRaw string `bson:"analysis_raw"`
+		}
+		if err := cursor.Decode(&doc); err != nil {
+			return fmt.Errorf("failed to decode response: %w", err)
+		}
+
+		// Analyses judged separately never held the answer
+		raw, _ := shared.DecompressString(doc.AnalysisRaw)
+		if shared.SplitCombinedAnswer(raw) == nil {
+			continue
+		}
+
+		_, err := coll.UpdateOne(ctx, bson.M{"_id": doc.ID}, bson.M{
+			"$set":   bson.M{"response_text": doc.AnalysisRaw},
+			"$unset": bson.M{"analysis": "", "analysis_raw": ""},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to update response %s: %w", doc.ID, err)
+		}
+	}
+	return cursor.Err()
+}
+
+// setResponsePeriods recomputes the week, month and quarter of the responses
+// from their creation time. Weeks used to be stored with the day of the month
+// in place of the ISO week, and some responses were stored without periods.
+func (m *MongoDB) setResponsePeriods(ctx context.Context) error {
+	coll := m.database.Collection(collResponses)
+	cursor, err := coll.Find(ctx, bson.M{},
+		options.Find().SetProjection(bson.M{"created_at": 1, "week": 1, "month": 1, "quarter": 1}),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to list responses: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	updated := 0
+	for cursor.Next(ctx) {
+		var response models.Response
+		if err := cursor.Decode(&response); err != nil {
+			return fmt.Errorf("failed to decode response: %w", err)
+		}
+		if !shared.SetResponsePeriods(&response) {
+			continue
+		}
+
+		_, err := coll.UpdateOne(ctx, bson.M{"_id": response.ID}, bson.M{"$set": bson.M{
+			"week":    response.Week,
+			"month":   response.Month,
+			"quarter": response.Quarter,
+		}})
+		if err != nil {
+			return fmt.Errorf("failed to update response %s: %w", response.ID, err)
+		}
+		updated++
+	}
+	if err := cursor.Err(); err != nil {
+		return fmt.Errorf("failed to list responses: %w", err)
+	}
+
+	logger.Info("Recomputed the periods of %d responses", updated)
+	return nil
+}
+
+// checkSchemaVersion warns when migrations are pending. Missing indexes only
+// slow queries down, so unlike SQLite this does not refuse to connect.
+func (m *MongoDB) checkSchemaVersion(ctx context.Context) error {
+	version, dirty, err := m.SchemaVersion(ctx)
+	if err != nil {
+		return err
+	}
+
+	latest := migrations.Latest(m)
+	switch {
+	case dirty:
+		logger.Warning("MongoDB migration %d did not complete: repair it, then run 'gego migrate force --nosql %d'", version, version)
+	case version < latest:
+		logger.Warning("MongoDB schema is at version %d, this build expects version %d: run 'gego migrate up'", version, latest)
+	case version > latest:
+		return fmt.Errorf("MongoDB schema is at version %d, newer than this build supports (%d): upgrade gego", version, latest)
+	}
+	return nil
+}
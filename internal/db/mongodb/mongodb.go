@@ -3,6 +3,7 @@ package mongodb
 import (
 	"context"
 	"fmt"
+	"slices"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
@@ -27,6 +28,8 @@ const (
 	collPromptLibrary  = "prompt_library"
 	collBrandProfiles  = "brand_profiles"
 	collBrandLogos     = "brand_logos"
+	collCampaigns      = "campaigns"
+	collJobs           = "jobs"
 )
 
 // New creates a new MongoDB database instance
@@ -52,8 +55,12 @@ func (m *MongoDB) Connect(ctx context.Context) error {
 	m.client = client
 	m.database = client.Database(m.config.Database)
 
-	if err := m.createIndexes(ctx); err != nil {
-		return fmt.Errorf("failed to create indexes: %w", err)
+	// Indexes are created by the versioned migrations of 'gego migrate up'
+	if !m.config.SkipSchemaCheck {
+		if err := m.checkSchemaVersion(ctx); err != nil {
+			client.Disconnect(ctx)
+			return err
+		}
 	}
 
 	return nil
@@ -115,6 +122,58 @@ func (m *MongoDB) createIndexes(ctx context.Context) error {
 			},
 			Options: options.Index().SetSparse(true),
 		},
+		// Add sparse index for campaign_id (only bulk executions have one)
+		{
+			Keys: bson.D{
+				{Key: "campaign_id", Value: 1},
+			},
+			Options: options.Index().SetSparse(true),
+		},
+		// Brand analytics: per-prompt and per-provider breakdowns over a time range
+		{
+			Keys: bson.D{
+				{Key: "brand", Value: 1},
+				{Key: "prompt_id", Value: 1},
+				{Key: "created_at", Value: -1},
+			},
+		},
+		{
+			Keys: bson.D{
+				{Key: "brand", Value: 1},
+				{Key: "llm_provider", Value: 1},
+				{Key: "created_at", Value: -1},
+			},
+		},
+		// Brand analytics restricted to a region/language
+		{
+			Keys: bson.D{
+				{Key: "brand", Value: 1},
+				{Key: "region", Value: 1},
+				{Key: "language", Value: 1},
+				{Key: "created_at", Value: -1},
+			},
+		},
+		// Brand analytics by prompt type / category (snapshotted prompt metadata)
+		{
+			Keys: bson.D{
+				{Key: "brand", Value: 1},
+				{Key: "prompt_type", Value: 1},
+			},
+		},
+		{
+			Keys: bson.D{
+				{Key: "brand", Value: 1},
+				{Key: "prompt_category", Value: 1},
+			},
+		},
+		// Campaign analytics for a brand
+		{
+			Keys: bson.D{
+				{Key: "campaign_id", Value: 1},
+				{Key: "brand", Value: 1},
+			},
+			Options: options.Index().SetSparse(true),
+		},
 	}
 
 	_, err := m.database.Collection(collResponses).Indexes().CreateMany(ctx, responseIndexes)
@@ -166,15 +225,61 @@ func (m *MongoDB) createIndexes(ctx context.Context) error {
 		return fmt.Errorf("failed to create brand logo indexes: %w", err)
 	}
 
+	// Create index for campaigns (brand lookup, newest first)
+	campaignIndexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "brand", Value: 1},
+				{Key: "created_at", Value: -1},
+			},
+		},
+	}
+
+	_, err = m.database.Collection(collCampaigns).Indexes().CreateMany(ctx, campaignIndexes)
+	if err != nil {
+		return fmt.Errorf("failed to create campaign indexes: %w", err)
+	}
+
+	// Create indexes for the job queue (leasing by status/availability, lookup by campaign)
+	jobIndexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "status", Value: 1},
+				{Key: "available_at", Value: 1},
+			},
+		},
+		{
+			Keys: bson.D{
+				{Key: "campaign_id", Value: 1},
+			},
+			Options: options.Index().SetSparse(true),
+		},
+	}
+
+	_, err = m.database.Collection(collJobs).Indexes().CreateMany(ctx, jobIndexes)
+	if err != nil {
+		return fmt.Errorf("failed to create job indexes: %w", err)
+	}
+
 	return nil
 }
 
 // CreatePrompt creates a new prompt
 func (m *MongoDB) CreatePrompt(ctx context.Context, prompt *models.Prompt) error {
-	prompt.CreatedAt = time.Now()
+	// Imported prompts keep their original creation time
+	if prompt.CreatedAt.IsZero() {
+		prompt.CreatedAt = time.Now()
+	}
 	prompt.UpdatedAt = time.Now()
 
-	// Compress template if large
+	doc := promptDoc(prompt)
+
+	_, err := m.database.Collection(collPrompts).InsertOne(ctx, doc)
+	return err
+}
+
+// promptDoc builds the stored document for a prompt, compressing large templates
+func promptDoc(prompt *models.Prompt) bson.M {
 	template := prompt.Template
 	if shared.ShouldCompress(template) {
 		if compressed, err := shared.CompressString(template); err == nil {
@@ -182,30 +287,24 @@ func (m *MongoDB) CreatePrompt(ctx context.Context, prompt *models.Prompt) error
 		}
 	}
 
-	doc := bson.M{
-		"_id":        prompt.ID,
-		"template":   template,
-		"tags":       prompt.Tags,
-		"enabled":    prompt.Enabled,
-		"created_at": prompt.CreatedAt,
-		"updated_at": prompt.UpdatedAt,
+	return bson.M{
+		"_id":         prompt.ID,
+		"name":        prompt.Name,
+		"template":    template,
+		"prompt_type": string(prompt.PromptType),
+		"tags":        prompt.Tags,
+		"category":    prompt.Category,
+		"domain":      prompt.Domain,
+		"brand":       prompt.Brand,
+		"generated":   prompt.Generated,
+		"enabled":     prompt.Enabled,
+		"created_at":  prompt.CreatedAt,
+		"updated_at":  prompt.UpdatedAt,
 	}
-
-	_, err := m.database.Collection(collPrompts).InsertOne(ctx, doc)
-	return err
 }
 
-// GetPrompt retrieves a prompt by ID
-func (m *MongoDB) GetPrompt(ctx context.Context, id string) (*models.Prompt, error) {
-	var doc bson.M
-	err := m.database.Collection(collPrompts).FindOne(ctx, bson.M{"_id": id}).Decode(&doc)
-	if err == mongo.ErrNoDocuments {
-		return nil, fmt.Errorf("prompt not found: %s", id)
-	}
-	if err != nil {
-		return nil, err
-	}
-
+// promptFromDoc converts a stored prompt document back to a prompt
+func promptFromDoc(doc bson.M) (*models.Prompt, error) {
 	var promptID string
 	if id, ok := doc["_id"].(string); ok {
 		promptID = id
@@ -221,23 +320,34 @@ func (m *MongoDB) GetPrompt(ctx context.Context, id string) (*models.Prompt, err
 		template = decompressed
 	}
 
-	prompt := &models.Prompt{
-		ID:        promptID,
-		Template:  template,
-		Enabled:   getBool(doc, "enabled"),
-		CreatedAt: getTime(doc, "created_at"),
-		UpdatedAt: getTime(doc, "updated_at"),
-	}
+	return &models.Prompt{
+		ID:         promptID,
+		Name:       getString(doc, "name"),
+		Template:   template,
+		PromptType: models.PromptType(getString(doc, "prompt_type")),
+		Tags:       getStrings(doc, "tags"),
+		Category:   getString(doc, "category"),
+		Domain:     getString(doc, "domain"),
+		Brand:      getString(doc, "brand"),
+		Generated:  getBool(doc, "generated"),
+		Enabled:    getBool(doc, "enabled"),
+		CreatedAt:  getTime(doc, "created_at"),
+		UpdatedAt:  getTime(doc, "updated_at"),
+	}, nil
+}
 
-	if tags, ok := doc["tags"].([]interface{}); ok {
-		for _, t := range tags {
-			if str, ok := t.(string); ok {
-				prompt.Tags = append(prompt.Tags, str)
-			}
-		}
+// GetPrompt retrieves a prompt by ID
+func (m *MongoDB) GetPrompt(ctx context.Context, id string) (*models.Prompt, error) {
+	var doc bson.M
+	err := m.database.Collection(collPrompts).FindOne(ctx, bson.M{"_id": id}).Decode(&doc)
+	if err == mongo.ErrNoDocuments {
+		return nil, fmt.Errorf("prompt not found: %s", id)
+	}
+	if err != nil {
+		return nil, err
 	}
 
-	return prompt, nil
+	return promptFromDoc(doc)
 }
 
 // ListPrompts lists all prompts, optionally filtered by enabled status
@@ -260,37 +370,9 @@ func (m *MongoDB) ListPrompts(ctx context.Context, enabled *bool) ([]*models.Pro
 			return nil, err
 		}
 
-		// Convert BSON document to Prompt struct
-		var promptID string
-		if id, ok := doc["_id"].(string); ok {
-			promptID = id
-		} else if objectID, ok := doc["_id"].(primitive.ObjectID); ok {
-			promptID = objectID.Hex()
-		} else {
-			return nil, fmt.Errorf("invalid _id type in document")
-		}
-
-		// Decompress template if it was compressed
-		template := getString(doc, "template")
-		if decompressed, err := shared.DecompressString(template); err == nil {
-			template = decompressed
-		}
-
-		prompt := &models.Prompt{
-			ID:        promptID,
-			Template:  template,
-			Enabled:   getBool(doc, "enabled"),
-			CreatedAt: getTime(doc, "created_at"),
-			UpdatedAt: getTime(doc, "updated_at"),
-		}
-
-		// Handle optional fields
-		if tags, ok := doc["tags"].([]interface{}); ok {
-			for _, t := range tags {
-				if str, ok := t.(string); ok {
-					prompt.Tags = append(prompt.Tags, str)
-				}
-			}
+		prompt, err := promptFromDoc(doc)
+		if err != nil {
+			return nil, err
 		}
 
 		prompts = append(prompts, prompt)
@@ -303,23 +385,8 @@ func (m *MongoDB) ListPrompts(ctx context.Context, enabled *bool) ([]*models.Pro
 func (m *MongoDB) UpdatePrompt(ctx context.Context, prompt *models.Prompt) error {
 	prompt.UpdatedAt = time.Now()
 
-	// Compress template if large
-	template := prompt.Template
-	if shared.ShouldCompress(template) {
-		if compressed, err := shared.CompressString(template); err == nil {
-			template = compressed
-		}
-	}
-
 	// Convert to BSON document with explicit _id field
-	doc := bson.M{
-		"_id":        prompt.ID,
-		"template":   template,
-		"tags":       prompt.Tags,
-		"enabled":    prompt.Enabled,
-		"created_at": prompt.CreatedAt,
-		"updated_at": prompt.UpdatedAt,
-	}
+	doc := promptDoc(prompt)
 
 	result, err := m.database.Collection(collPrompts).ReplaceOne(
 		ctx,
@@ -370,7 +437,11 @@ func (m *MongoDB) DeleteAllPrompts(ctx context.Context) (int, error) {
 
 // CreateResponse creates a new response
 func (m *MongoDB) CreateResponse(ctx context.Context, response *models.Response) error {
-	response.CreatedAt = time.Now()
+	// Imported responses keep their original creation time
+	if response.CreatedAt.IsZero() {
+		response.CreatedAt = time.Now()
+	}
+	shared.SetResponsePeriods(response)
 
 	// Compress large text fields to save storage space
 	compressedResponseText := response.ResponseText
@@ -398,11 +469,18 @@ func (m *MongoDB) CreateResponse(ctx context.Context, response *models.Response)
 		"llm_model":     response.LLMModel,
 		"response_text": compressedResponseText,
 		"schedule_id":   response.ScheduleID,
+		"campaign_id":   response.CampaignID,
 		"tokens_used":   response.TokensUsed,
 		"temperature":   response.Temperature,
 		"latency_ms":    response.LatencyMs,
 		"error":         response.Error,
 		"created_at":    response.CreatedAt,
+
+		// Prompt Metadata Snapshot
+		"prompt_category": response.PromptCategory,
+		"prompt_domain":   response.PromptDomain,
+		"prompt_type":     string(response.PromptType),
+		"prompt_tags":     response.PromptTags,
 		
 		// GEO Analytics Fields
 		"brand":                response.Brand,
@@ -413,6 +491,9 @@ func (m *MongoDB) CreateResponse(ctx context.Context, response *models.Response)
 		"competitors_mention":  response.CompetitorsMention,
 		"grounding_sources":    response.GroundingSources,
 		"grounding_domains":    response.GroundingDomains,
+		"source_categories":    response.SourceCategories,
+		"citations":            response.Citations,
+		"brand_extraction":     response.BrandExtraction,
 		
 		// Position/Ranking Fields
 		"brand_position":      response.BrandPosition,
@@ -431,11 +512,32 @@ func (m *MongoDB) CreateResponse(ctx context.Context, response *models.Response)
 	if response.Metadata != nil {
 		doc["metadata"] = response.Metadata
 	}
+	if len(response.Variables) > 0 {
+		doc["variables"] = response.Variables
+	}
+	if response.Analysis != nil {
+		doc["analysis"] = response.Analysis
+	}
+	if response.AnalysisRaw != "" {
+		doc["analysis_raw"] = compressText(response.AnalysisRaw)
+	}
 
 	_, err := m.database.Collection(collResponses).InsertOne(ctx, doc)
 	return err
 }
 
+// compressText compresses large text fields to save storage space, keeping
+// them as-is when compression fails
+func compressText(text string) string {
+	if !shared.ShouldCompress(text) {
+		return text
+	}
+	if compressed, err := shared.CompressString(text); err == nil {
+		return compressed
+	}
+	return text
+}
+
 // GetResponse retrieves a response by ID
 func (m *MongoDB) GetResponse(ctx context.Context, id string) (*models.Response, error) {
 	var response models.Response
@@ -454,39 +556,16 @@ func (m *MongoDB) GetResponse(ctx context.Context, id string) (*models.Response,
 	if decompressed, err := shared.DecompressString(response.PromptText); err == nil {
 		response.PromptText = decompressed
 	}
+	if decompressed, err := shared.DecompressString(response.AnalysisRaw); err == nil {
+		response.AnalysisRaw = decompressed
+	}
 	
 	return &response, nil
 }
 
 // ListResponses lists responses with filtering
 func (m *MongoDB) ListResponses(ctx context.Context, filter shared.ResponseFilter) ([]*models.Response, error) {
-	query := bson.M{}
-
-	if filter.PromptID != "" {
-		query["prompt_id"] = filter.PromptID
-	}
-	if filter.LLMID != "" {
-		query["llm_id"] = filter.LLMID
-	}
-	if filter.ScheduleID != "" {
-		query["schedule_id"] = filter.ScheduleID
-	}
-	if filter.Keyword != "" {
-		query["response_text"] = bson.M{
-			"$regex":   filter.Keyword,
-			"$options": "i",
-		}
-	}
-	if filter.StartTime != nil || filter.EndTime != nil {
-		timeQuery := bson.M{}
-		if filter.StartTime != nil {
-			timeQuery["$gte"] = *filter.StartTime
-		}
-		if filter.EndTime != nil {
-			timeQuery["$lte"] = *filter.EndTime
-		}
-		query["created_at"] = timeQuery
-	}
+	query := responseQuery(filter)
 
 	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
 
@@ -516,6 +595,9 @@ func (m *MongoDB) ListResponses(ctx context.Context, filter shared.ResponseFilte
 		if decompressed, err := shared.DecompressString(response.PromptText); err == nil {
 			response.PromptText = decompressed
 		}
+		if decompressed, err := shared.DecompressString(response.AnalysisRaw); err == nil {
+			response.AnalysisRaw = decompressed
+		}
 	}
 
 	return responses, nil
@@ -523,36 +605,68 @@ func (m *MongoDB) ListResponses(ctx context.Context, filter shared.ResponseFilte
 
 // CountResponses counts responses matching the filter without fetching all documents
 func (m *MongoDB) CountResponses(ctx context.Context, filter shared.ResponseFilter) (int64, error) {
-	query := bson.M{}
+	query := responseQuery(filter)
+
+	return m.database.Collection(collResponses).CountDocuments(ctx, query)
+}
 
-	if filter.PromptID != "" {
-		query["prompt_id"] = filter.PromptID
+// BackfillResponsePromptMetadata snapshots the prompt's metadata onto its
+// responses that were saved without it
+func (m *MongoDB) BackfillResponsePromptMetadata(ctx context.Context, prompt *models.Prompt) (int, error) {
+	filter := bson.M{
+		"prompt_id":   prompt.ID,
+		"prompt_type": bson.M{"$exists": false},
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"prompt_category": prompt.Category,
+			"prompt_domain":   prompt.Domain,
+			"prompt_type":     string(prompt.PromptType),
+			"prompt_tags":     prompt.Tags,
+		},
 	}
-	if filter.LLMID != "" {
-		query["llm_id"] = filter.LLMID
+
+	result, err := m.database.Collection(collResponses).UpdateMany(ctx, filter, update)
+	if err != nil {
+		return 0, err
 	}
-	if filter.ScheduleID != "" {
-		query["schedule_id"] = filter.ScheduleID
+	return int(result.ModifiedCount), nil
+}
+
+// ReclassifyResponseSources recomputes the source categories of the responses
+// citing sources and returns the number of responses whose categories changed
+func (m *MongoDB) ReclassifyResponseSources(ctx context.Context, classify func(domains []string) []string) (int, error) {
+	coll := m.database.Collection(collResponses)
+	cursor, err := coll.Find(ctx,
+		bson.M{"grounding_domains.0": bson.M{"$exists": true}},
+		options.Find().SetProjection(bson.M{"grounding_domains": 1, "source_categories": 1}),
+	)
+	if err != nil {
+		return 0, err
 	}
-	if filter.Keyword != "" {
-		query["response_text"] = bson.M{
-			"$regex":   filter.Keyword,
-			"$options": "i",
+	defer cursor.Close(ctx)
+
+	updated := 0
+	for cursor.Next(ctx) {
+		var doc struct {
+			ID               string   `bson:"_id"`
+			GroundingDomains []string `bson:"grounding_domains"`
+			SourceCategories []string `bson:"source_categories"`
 		}
-	}
-	if filter.StartTime != nil || filter.EndTime != nil {
-		timeQuery := bson.M{}
-		if filter.StartTime != nil {
-			timeQuery["$gte"] = *filter.StartTime
+		if err := cursor.Decode(&doc); err != nil {
+			return updated, err
 		}
-		if filter.EndTime != nil {
-			timeQuery["$lte"] = *filter.EndTime
+
+		categories := classify(doc.GroundingDomains)
+		if slices.Equal(categories, doc.SourceCategories) {
+			continue
+		}
+		if _, err := coll.UpdateOne(ctx, bson.M{"_id": doc.ID}, bson.M{"$set": bson.M{"source_categories": categories}}); err != nil {
+			return updated, err
 		}
-		query["created_at"] = timeQuery
+		updated++
 	}
-
-	count, err := m.database.Collection(collResponses).CountDocuments(ctx, query)
-	return count, err
+	return updated, cursor.Err()
 }
 
 // GetDatabase returns the underlying MongoDB database instance
@@ -570,6 +684,24 @@ func getString(doc bson.M, key string) string {
 	return ""
 }
 
+func getStrings(doc bson.M, key string) []string {
+	var values []interface{}
+	switch val := doc[key].(type) {
+	case primitive.A:
+		values = val
+	case []interface{}:
+		values = val
+	}
+
+	var result []string
+	for _, v := range values {
+		if str, ok := v.(string); ok {
+			result = append(result, str)
+		}
+	}
+	return result
+}
+
 func getBool(doc bson.M, key string) bool {
 	if val, ok := doc[key]; ok && val != nil {
 		if b, ok := val.(bool); ok {
@@ -802,7 +934,9 @@ func (m *MongoDB) getPromptCountsForLLM(ctx context.Context, llmID string) (map[
 
 // CreatePromptLibrary creates a new prompt library entry
 func (m *MongoDB) CreatePromptLibrary(ctx context.Context, library *models.PromptLibrary) error {
-	library.CreatedAt = time.Now()
+	if library.CreatedAt.IsZero() {
+		library.CreatedAt = time.Now()
+	}
 	library.UpdatedAt = time.Now()
 
 	doc := bson.M{
@@ -895,7 +1029,9 @@ func (m *MongoDB) ListPromptLibraries(ctx context.Context) ([]*models.PromptLibr
 
 // CreateBrandProfile creates a new brand profile
 func (m *MongoDB) CreateBrandProfile(ctx context.Context, profile *models.BrandProfile) error {
-	profile.CreatedAt = time.Now()
+	if profile.CreatedAt.IsZero() {
+		profile.CreatedAt = time.Now()
+	}
 	profile.UpdatedAt = time.Now()
 
 	doc := bson.M{
@@ -908,6 +1044,12 @@ func (m *MongoDB) CreateBrandProfile(ctx context.Context, profile *models.BrandP
 		"competitors": profile.Competitors,
 		"created_at":  profile.CreatedAt,
 		"updated_at":  profile.UpdatedAt,
+
+		// Entity dictionary
+		"aliases":           profile.Aliases,
+		"product_names":     profile.ProductNames,
+		"domains":           profile.Domains,
+		"negative_patterns": profile.NegativePatterns,
 	}
 
 	_, err := m.database.Collection(collBrandProfiles).InsertOne(ctx, doc)
@@ -942,6 +1084,12 @@ func (m *MongoDB) UpdateBrandProfile(ctx context.Context, profile *models.BrandP
 		"competitors": profile.Competitors,
 		"created_at":  profile.CreatedAt,
 		"updated_at":  profile.UpdatedAt,
+
+		// Entity dictionary
+		"aliases":           profile.Aliases,
+		"product_names":     profile.ProductNames,
+		"domains":           profile.Domains,
+		"negative_patterns": profile.NegativePatterns,
 	}
 
 	result, err := m.database.Collection(collBrandProfiles).ReplaceOne(
@@ -977,6 +1125,156 @@ func (m *MongoDB) ListBrandProfiles(ctx context.Context) ([]*models.BrandProfile
 	return profiles, nil
 }
 
+// campaignDoc builds the stored document for a campaign
+func campaignDoc(campaign *models.GEOCampaign) bson.M {
+	doc := bson.M{
+		"_id":            campaign.ID,
+		"name":           campaign.Name,
+		"brand_id":       campaign.BrandID,
+		"brand":          campaign.Brand,
+		"prompt_ids":     campaign.PromptIDs,
+		"llm_ids":        campaign.LLMIDs,
+		"temperature":    campaign.Temperature,
+		"status":         campaign.Status,
+		"error":          campaign.Error,
+		"total_runs":     campaign.TotalRuns,
+		"completed_runs": campaign.CompletedRuns,
+		"failed_runs":    campaign.FailedRuns,
+		"created_at":     campaign.CreatedAt,
+		"updated_at":     campaign.UpdatedAt,
+	}
+
+	if campaign.CompletedAt != nil {
+		doc["completed_at"] = *campaign.CompletedAt
+	}
+	if len(campaign.Variables) > 0 {
+		doc["variables"] = campaign.Variables
+	}
+
+	return doc
+}
+
+// CreateCampaign creates a new campaign
+func (m *MongoDB) CreateCampaign(ctx context.Context, campaign *models.GEOCampaign) error {
+	campaign.CreatedAt = time.Now()
+	campaign.UpdatedAt = time.Now()
+
+	_, err := m.database.Collection(collCampaigns).InsertOne(ctx, campaignDoc(campaign))
+	return err
+}
+
+// GetCampaign retrieves a campaign by ID
+func (m *MongoDB) GetCampaign(ctx context.Context, id string) (*models.GEOCampaign, error) {
+	var campaign models.GEOCampaign
+	err := m.database.Collection(collCampaigns).FindOne(ctx, bson.M{"_id": id}).Decode(&campaign)
+	if err == mongo.ErrNoDocuments {
+		return nil, fmt.Errorf("campaign not found: %s", id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find campaign: %w", err)
+	}
+
+	return &campaign, nil
+}
+
+// ListCampaigns lists campaigns, newest first, optionally filtered by brand
+func (m *MongoDB) ListCampaigns(ctx context.Context, brand string) ([]*models.GEOCampaign, error) {
+	query := bson.M{}
+	if brand != "" {
+		query["brand"] = brand
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+
+	cursor, err := m.database.Collection(collCampaigns).Find(ctx, query, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var campaigns []*models.GEOCampaign
+	if err := cursor.All(ctx, &campaigns); err != nil {
+		return nil, err
+	}
+
+	return campaigns, nil
+}
+
+// UpdateCampaign updates an existing campaign
+func (m *MongoDB) UpdateCampaign(ctx context.Context, campaign *models.GEOCampaign) error {
+	campaign.UpdatedAt = time.Now()
+
+	result, err := m.database.Collection(collCampaigns).ReplaceOne(
+		ctx,
+		bson.M{"_id": campaign.ID},
+		campaignDoc(campaign),
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to update campaign: %w", err)
+	}
+
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("campaign not found: %s", campaign.ID)
+	}
+
+	return nil
+}
+
+// IncrementCampaignProgress atomically adds to the completed and failed run counters
+func (m *MongoDB) IncrementCampaignProgress(ctx context.Context, id string, completed, failed int) error {
+	update := bson.M{
+		"$inc": bson.M{
+			"completed_runs": completed,
+			"failed_runs":    failed,
+		},
+		"$set": bson.M{
+			"updated_at": time.Now(),
+		},
+	}
+
+	result, err := m.database.Collection(collCampaigns).UpdateOne(ctx, bson.M{"_id": id}, update)
+	if err != nil {
+		return fmt.Errorf("failed to update campaign progress: %w", err)
+	}
+
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("campaign not found: %s", id)
+	}
+
+	return nil
+}
+
+// FinishCampaign moves a running campaign to a final status and returns it,
+// or nil when the campaign is not running. Only the status fields are set, so
+// progress counted concurrently is kept.
+func (m *MongoDB) FinishCampaign(ctx context.Context, id, status, errMsg string) (*models.GEOCampaign, error) {
+	now := time.Now()
+	update := bson.M{
+		"$set": bson.M{
+			"status":       status,
+			"completed_at": now,
+			"error":        errMsg,
+			"updated_at":   now,
+		},
+	}
+
+	var campaign models.GEOCampaign
+	err := m.database.Collection(collCampaigns).FindOneAndUpdate(ctx,
+		bson.M{"_id": id, "status": models.CampaignStatusRunning},
+		update,
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&campaign)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to finish campaign: %w", err)
+	}
+
+	return &campaign, nil
+}
+
 // SaveBrandLogo saves or updates a brand logo in the cache
 func (m *MongoDB) SaveBrandLogo(ctx context.Context, logo *models.BrandLogoCache) error {
 	logo.UpdatedAt = time.Now()
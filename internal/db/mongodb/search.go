@@ -11,9 +11,18 @@ import (
 	"github.com/fissionx/gego/internal/shared"
 )
 
-// SearchKeyword searches for a keyword in all responses and calculates stats on-the-fly
+// SearchKeyword searches for a keyword in all responses and calculates stats on-the-fly.
+// When the keyword is a brand with a profile, its aliases, product names and
+// domains count as mentions too.
 func (m *MongoDB) SearchKeyword(ctx context.Context, keyword string, startTime, endTime *time.Time) (*models.KeywordStats, error) {
 	pattern := regexp.QuoteMeta(keyword)
+
+	var matcher *shared.BrandMatcher
+	if profile, err := m.GetBrandProfile(ctx, keyword); err == nil && profile != nil {
+		matcher = shared.BrandMatcherForProfile(keyword, profile)
+		pattern = matcher.Pattern()
+	}
+
 	regex := bson.M{"$regex": pattern, "$options": "i"}
 
 	query := bson.M{
@@ -65,6 +74,12 @@ func (m *MongoDB) SearchKeyword(ctx context.Context, keyword string, startTime,
 		createdAt := getTime(doc, "created_at")
 
 		count := shared.CountOccurrences(responseText, keyword)
+		if matcher != nil {
+			count = matcher.Count(responseText)
+			if count == 0 {
+				continue
+			}
+		}
 		stats.TotalMentions += count
 
 		stats.ByPrompt[promptID] += count
@@ -29,6 +29,14 @@ type NoSQLDatabase interface {
 	ListResponses(ctx context.Context, filter shared.ResponseFilter) ([]*models.Response, error)
 	CountResponses(ctx context.Context, filter shared.ResponseFilter) (int64, error)
 	DeleteAllResponses(ctx context.Context) (int, error)
+	BackfillResponsePromptMetadata(ctx context.Context, prompt *models.Prompt) (int, error)
+	ReclassifyResponseSources(ctx context.Context, classify func(domains []string) []string) (int, error)
+
+	// Response analytics (aggregated in the database)
+	AggregateResponseMetrics(ctx context.Context, filter shared.ResponseFilter, groupBy shared.ResponseGroup) ([]*models.ResponseMetrics, error)
+	CountCompetitorMentions(ctx context.Context, filter shared.ResponseFilter) (map[string]int, error)
+	CountGroupedCompetitorMentions(ctx context.Context, filter shared.ResponseFilter, groupBy shared.ResponseGroup) (map[string]map[string]int, error)
+	AggregateSourceDomains(ctx context.Context, filter shared.ResponseFilter) ([]*models.SourceDomainStats, error)
 
 	// Keyword search (on-demand, searches through response_text)
 	SearchKeyword(ctx context.Context, keyword string, startTime, endTime *time.Time) (*models.KeywordStats, error)
@@ -50,6 +58,23 @@ type NoSQLDatabase interface {
 	UpdateBrandProfile(ctx context.Context, profile *models.BrandProfile) error
 	ListBrandProfiles(ctx context.Context) ([]*models.BrandProfile, error)
 
+	// Campaign operations (bulk GEO executions and their progress)
+	CreateCampaign(ctx context.Context, campaign *models.GEOCampaign) error
+	GetCampaign(ctx context.Context, id string) (*models.GEOCampaign, error)
+	ListCampaigns(ctx context.Context, brand string) ([]*models.GEOCampaign, error)
+	UpdateCampaign(ctx context.Context, campaign *models.GEOCampaign) error
+	IncrementCampaignProgress(ctx context.Context, id string, completed, failed int) error
+	FinishCampaign(ctx context.Context, id, status, errMsg string) (*models.GEOCampaign, error)
+
+	// Job queue operations (durable prompt × LLM executions)
+	EnqueueJobs(ctx context.Context, jobs []*models.Job) error
+	LeaseJob(ctx context.Context, owner string, kinds []string, lease time.Duration) (*models.Job, error)
+	RetryJob(ctx context.Context, id, owner, lastError string, availableAt time.Time) error
+	FinishJob(ctx context.Context, id, owner, status, lastError string) error
+	CancelCampaignJobs(ctx context.Context, campaignID string) (int, error)
+	GetJob(ctx context.Context, id string) (*models.Job, error)
+	ListJobs(ctx context.Context, status string, limit int) ([]*models.Job, error)
+
 	// Brand Logo cache operations
 	SaveBrandLogo(ctx context.Context, logo *models.BrandLogoCache) error
 	GetBrandLogo(ctx context.Context, brandName string) (*models.BrandLogoCache, error)
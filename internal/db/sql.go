@@ -2,6 +2,7 @@ package db
 
 import (
 	"context"
+	"time"
 
 	"github.com/fissionx/gego/internal/models"
 )
@@ -28,4 +29,11 @@ type SQLDatabase interface {
 	UpdateSchedule(ctx context.Context, schedule *models.Schedule) error
 	DeleteSchedule(ctx context.Context, id string) error
 	DeleteAllSchedules(ctx context.Context) (int, error)
+
+	// API key operations
+	CreateAPIKey(ctx context.Context, key *models.APIKey) error
+	GetAPIKeyByHash(ctx context.Context, keyHash string) (*models.APIKey, error)
+	ListAPIKeys(ctx context.Context) ([]*models.APIKey, error)
+	RevokeAPIKey(ctx context.Context, id string) error
+	TouchAPIKey(ctx context.Context, id string, usedAt time.Time) error
 }
@@ -3,15 +3,20 @@ package sqlite
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	_ "github.com/mattn/go-sqlite3"
 
+	"github.com/fissionx/gego/internal/db/migrations"
+	"github.com/fissionx/gego/internal/logger"
 	"github.com/fissionx/gego/internal/models"
+	"github.com/fissionx/gego/internal/secrets"
 )
 
 // SQLite implements the Database interface for SQLite
@@ -60,6 +65,38 @@ func (s *SQLite) Connect(ctx context.Context) error {
 
 	s.db = db
 
+	if !s.config.SkipSchemaCheck {
+		if err := s.checkSchemaVersion(ctx); err != nil {
+			db.Close()
+			s.db = nil
+			return err
+		}
+	}
+
+	return nil
+}
+
+// checkSchemaVersion fails unless the schema is at the version of the
+// migrations embedded in this build
+func (s *SQLite) checkSchemaVersion(ctx context.Context) error {
+	latest, err := migrations.LatestSQL()
+	if err != nil {
+		return err
+	}
+
+	version, dirty, err := migrations.SQLVersion(ctx, s.db)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case dirty:
+		return fmt.Errorf("migration %d of the SQLite database did not complete: repair it, then run 'gego migrate force %d'", version, version)
+	case version < latest:
+		return fmt.Errorf("SQLite database schema is at version %d, this build needs version %d: run 'gego migrate up'", version, latest)
+	case version > latest:
+		return fmt.Errorf("SQLite database schema is at version %d, newer than this build supports (%d): upgrade gego", version, latest)
+	}
 	return nil
 }
 
@@ -88,24 +125,45 @@ func mapToJSON(m map[string]string) string {
 	if len(m) == 0 {
 		return "{}"
 	}
-	result := "{"
-	first := true
-	for k, v := range m {
-		if !first {
-			result += ","
-		}
-		result += fmt.Sprintf(`"%s":"%s"`, k, v)
-		first = false
+	data, err := json.Marshal(m)
+	if err != nil {
+		return "{}"
 	}
-	result += "}"
-	return result
+	return string(data)
 }
 
 func jsonToMap(jsonStr string) map[string]string {
+	result := make(map[string]string)
 	if jsonStr == "" || jsonStr == "{}" {
+		return result
+	}
+	if err := json.Unmarshal([]byte(jsonStr), &result); err != nil {
 		return make(map[string]string)
 	}
-	return make(map[string]string)
+	return result
+}
+
+// valueSetsToJSON encodes the template variable value sets of a schedule
+func valueSetsToJSON(sets map[string][]string) string {
+	if len(sets) == 0 {
+		return "{}"
+	}
+	data, err := json.Marshal(sets)
+	if err != nil {
+		return "{}"
+	}
+	return string(data)
+}
+
+func jsonToValueSets(jsonStr string) map[string][]string {
+	if jsonStr == "" || jsonStr == "{}" {
+		return nil
+	}
+	var sets map[string][]string
+	if err := json.Unmarshal([]byte(jsonStr), &sets); err != nil {
+		return nil
+	}
+	return sets
 }
 
 func sliceToJSON(slice []string) string {
@@ -151,21 +209,73 @@ func jsonToSlice(jsonStr string) []string {
 	return result
 }
 
+var plaintextWarning sync.Once
+
+// sealAPIKey returns the form an API key is stored in: encrypted with the
+// master key when one is configured. Empty keys and env:/file: references are
+// not secret and are stored as is.
+func sealAPIKey(apiKey string) (string, error) {
+	if apiKey == "" || secrets.IsReference(apiKey) || secrets.IsEncrypted(apiKey) {
+		return apiKey, nil
+	}
+
+	keyring := secrets.Default()
+	if keyring == nil {
+		plaintextWarning.Do(func() {
+			logger.Warning("No master key configured: LLM API keys are stored unencrypted. Set master_key_file or %s", secrets.MasterKeyEnv)
+		})
+		return apiKey, nil
+	}
+
+	encrypted, err := keyring.Encrypt(apiKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to encrypt API key: %w", err)
+	}
+	return encrypted, nil
+}
+
+// openAPIKey decrypts the stored API key of an LLM in place
+func openAPIKey(llm *models.LLMConfig) error {
+	if !secrets.IsEncrypted(llm.APIKey) {
+		return nil
+	}
+
+	keyring := secrets.Default()
+	if keyring == nil {
+		return fmt.Errorf("API key of LLM %s is encrypted but no master key is configured (set master_key_file or %s)", llm.ID, secrets.MasterKeyEnv)
+	}
+
+	apiKey, err := keyring.Decrypt(llm.APIKey)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt API key of LLM %s: %w", llm.ID, err)
+	}
+	llm.APIKey = apiKey
+	return nil
+}
+
 // CreateLLM creates a new LLM configuration
 func (s *SQLite) CreateLLM(ctx context.Context, llm *models.LLMConfig) error {
-	llm.CreatedAt = time.Now()
+	// Imported configurations keep their original creation time
+	if llm.CreatedAt.IsZero() {
+		llm.CreatedAt = time.Now()
+	}
 	llm.UpdatedAt = time.Now()
 
+	apiKey, err := sealAPIKey(llm.APIKey)
+	if err != nil {
+		return err
+	}
+
 	query := `
 		INSERT INTO llms (id, name, provider, model, api_key, base_url, config, enabled, created_at, updated_at)
 		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
 
-	_, err := s.db.ExecContext(ctx, query,
+	_, err = s.db.ExecContext(ctx, query,
 		llm.ID,
 		llm.Name,
 		llm.Provider,
 		llm.Model,
-		llm.APIKey,
+		apiKey,
 		llm.BaseURL,
 		mapToJSON(llm.Config),
 		llm.Enabled,
@@ -205,11 +315,16 @@ func (s *SQLite) GetLLM(ctx context.Context, id string) (*models.LLMConfig, erro
 		return nil, err
 	}
 
+	if err := openAPIKey(&llm); err != nil {
+		return nil, err
+	}
+
 	llm.Config = jsonToMap(configJSON)
 	return &llm, nil
 }
 
-// ListLLMs lists all LLM configurations, optionally filtered by enabled status
+// ListLLMs lists all LLM configurations, optionally filtered by enabled status.
+// LLMs whose API key cannot be decrypted are listed without it, with KeyError set.
 func (s *SQLite) ListLLMs(ctx context.Context, enabled *bool) ([]*models.LLMConfig, error) {
 	query := `
 		SELECT id, name, provider, model, api_key, base_url, config, enabled, created_at, updated_at
@@ -250,6 +365,12 @@ func (s *SQLite) ListLLMs(ctx context.Context, enabled *bool) ([]*models.LLMConf
 			return nil, err
 		}
 
+		// An undecryptable key makes the LLM unusable, not the listing
+		if err := openAPIKey(&llm); err != nil {
+			llm.APIKey = ""
+			llm.KeyError = err.Error()
+		}
+
 		llm.Config = jsonToMap(configJSON)
 		llms = append(llms, &llm)
 	}
@@ -261,6 +382,11 @@ func (s *SQLite) ListLLMs(ctx context.Context, enabled *bool) ([]*models.LLMConf
 func (s *SQLite) UpdateLLM(ctx context.Context, llm *models.LLMConfig) error {
 	llm.UpdatedAt = time.Now()
 
+	apiKey, err := sealAPIKey(llm.APIKey)
+	if err != nil {
+		return err
+	}
+
 	query := `
 		UPDATE llms 
 		SET name = ?, provider = ?, model = ?, api_key = ?, base_url = ?, config = ?, enabled = ?, updated_at = ?
@@ -270,7 +396,7 @@ func (s *SQLite) UpdateLLM(ctx context.Context, llm *models.LLMConfig) error {
 		llm.Name,
 		llm.Provider,
 		llm.Model,
-		llm.APIKey,
+		apiKey,
 		llm.BaseURL,
 		mapToJSON(llm.Config),
 		llm.Enabled,
@@ -330,14 +456,79 @@ func (s *SQLite) DeleteAllLLMs(ctx context.Context) (int, error) {
 	return int(rowsAffected), nil
 }
 
+// RotateLLMAPIKeys re-encrypts the stored API keys of all LLMs under a new
+// master key, in a single transaction. Keys encrypted under the current master
+// key (from, nil when none was configured) have their data key rewrapped,
+// unencrypted keys are encrypted, and references are left alone. Returns the
+// number of keys re-encrypted.
+func (s *SQLite) RotateLLMAPIKeys(ctx context.Context, from, to *secrets.Keyring) (int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, "SELECT id, api_key FROM llms")
+	if err != nil {
+		return 0, err
+	}
+
+	stored := make(map[string]string)
+	for rows.Next() {
+		var id, apiKey string
+		if err := rows.Scan(&id, &apiKey); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		stored[id] = apiKey
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	rotated := 0
+	for id, apiKey := range stored {
+		if apiKey == "" || secrets.IsReference(apiKey) {
+			continue
+		}
+		if secrets.IsEncrypted(apiKey) && from == nil {
+			return 0, fmt.Errorf("API key of LLM %s is encrypted but the current master key is not configured", id)
+		}
+
+		var rewrapped string
+		if from != nil {
+			rewrapped, err = from.Rewrap(apiKey, to)
+		} else {
+			rewrapped, err = to.Encrypt(apiKey)
+		}
+		if err != nil {
+			return 0, fmt.Errorf("failed to re-encrypt API key of LLM %s: %w", id, err)
+		}
+
+		if _, err := tx.ExecContext(ctx, "UPDATE llms SET api_key = ? WHERE id = ?", rewrapped, id); err != nil {
+			return 0, err
+		}
+		rotated++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return rotated, nil
+}
+
 // CreateSchedule creates a new schedule
 func (s *SQLite) CreateSchedule(ctx context.Context, schedule *models.Schedule) error {
-	schedule.CreatedAt = time.Now()
+	// Imported schedules keep their original creation time
+	if schedule.CreatedAt.IsZero() {
+		schedule.CreatedAt = time.Now()
+	}
 	schedule.UpdatedAt = time.Now()
 
 	query := `
-		INSERT INTO schedules (id, name, prompt_ids, llm_ids, cron_expr, temperature, enabled, last_run, next_run, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+		INSERT INTO schedules (id, name, prompt_ids, llm_ids, cron_expr, temperature, variables, enabled, last_run, next_run, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
 
 	_, err := s.db.ExecContext(ctx, query,
 		schedule.ID,
@@ -346,6 +537,7 @@ func (s *SQLite) CreateSchedule(ctx context.Context, schedule *models.Schedule)
 		sliceToJSON(schedule.LLMIDs),
 		schedule.CronExpr,
 		schedule.Temperature,
+		valueSetsToJSON(schedule.Variables),
 		schedule.Enabled,
 		schedule.LastRun,
 		schedule.NextRun,
@@ -359,11 +551,11 @@ func (s *SQLite) CreateSchedule(ctx context.Context, schedule *models.Schedule)
 // GetSchedule retrieves a schedule by ID
 func (s *SQLite) GetSchedule(ctx context.Context, id string) (*models.Schedule, error) {
 	query := `
-		SELECT id, name, prompt_ids, llm_ids, cron_expr, temperature, enabled, last_run, next_run, created_at, updated_at
+		SELECT id, name, prompt_ids, llm_ids, cron_expr, temperature, variables, enabled, last_run, next_run, created_at, updated_at
 		FROM schedules WHERE id = ?`
 
 	var schedule models.Schedule
-	var promptIDsJSON, llmIDsJSON string
+	var promptIDsJSON, llmIDsJSON, variablesJSON string
 
 	err := s.db.QueryRowContext(ctx, query, id).Scan(
 		&schedule.ID,
@@ -372,6 +564,7 @@ func (s *SQLite) GetSchedule(ctx context.Context, id string) (*models.Schedule,
 		&llmIDsJSON,
 		&schedule.CronExpr,
 		&schedule.Temperature,
+		&variablesJSON,
 		&schedule.Enabled,
 		&schedule.LastRun,
 		&schedule.NextRun,
@@ -388,13 +581,14 @@ func (s *SQLite) GetSchedule(ctx context.Context, id string) (*models.Schedule,
 
 	schedule.PromptIDs = jsonToSlice(promptIDsJSON)
 	schedule.LLMIDs = jsonToSlice(llmIDsJSON)
+	schedule.Variables = jsonToValueSets(variablesJSON)
 	return &schedule, nil
 }
 
 // ListSchedules lists all schedules, optionally filtered by enabled status
 func (s *SQLite) ListSchedules(ctx context.Context, enabled *bool) ([]*models.Schedule, error) {
 	query := `
-		SELECT id, name, prompt_ids, llm_ids, cron_expr, temperature, enabled, last_run, next_run, created_at, updated_at
+		SELECT id, name, prompt_ids, llm_ids, cron_expr, temperature, variables, enabled, last_run, next_run, created_at, updated_at
 		FROM schedules`
 	args := []interface{}{}
 
@@ -414,7 +608,7 @@ func (s *SQLite) ListSchedules(ctx context.Context, enabled *bool) ([]*models.Sc
 	var schedules []*models.Schedule
 	for rows.Next() {
 		var schedule models.Schedule
-		var promptIDsJSON, llmIDsJSON string
+		var promptIDsJSON, llmIDsJSON, variablesJSON string
 
 		err := rows.Scan(
 			&schedule.ID,
@@ -423,6 +617,7 @@ func (s *SQLite) ListSchedules(ctx context.Context, enabled *bool) ([]*models.Sc
 			&llmIDsJSON,
 			&schedule.CronExpr,
 			&schedule.Temperature,
+			&variablesJSON,
 			&schedule.Enabled,
 			&schedule.LastRun,
 			&schedule.NextRun,
@@ -435,6 +630,7 @@ func (s *SQLite) ListSchedules(ctx context.Context, enabled *bool) ([]*models.Sc
 
 		schedule.PromptIDs = jsonToSlice(promptIDsJSON)
 		schedule.LLMIDs = jsonToSlice(llmIDsJSON)
+		schedule.Variables = jsonToValueSets(variablesJSON)
 		schedules = append(schedules, &schedule)
 	}
 
@@ -447,7 +643,7 @@ func (s *SQLite) UpdateSchedule(ctx context.Context, schedule *models.Schedule)
 
 	query := `
 		UPDATE schedules 
-		SET name = ?, prompt_ids = ?, llm_ids = ?, cron_expr = ?, temperature = ?, enabled = ?, last_run = ?, next_run = ?, updated_at = ?
+		SET name = ?, prompt_ids = ?, llm_ids = ?, cron_expr = ?, temperature = ?, variables = ?, enabled = ?, last_run = ?, next_run = ?, updated_at = ?
 		WHERE id = ?`
 
 	result, err := s.db.ExecContext(ctx, query,
@@ -456,6 +652,7 @@ func (s *SQLite) UpdateSchedule(ctx context.Context, schedule *models.Schedule)
 		sliceToJSON(schedule.LLMIDs),
 		schedule.CronExpr,
 		schedule.Temperature,
+		valueSetsToJSON(schedule.Variables),
 		schedule.Enabled,
 		schedule.LastRun,
 		schedule.NextRun,
@@ -514,3 +711,113 @@ func (s *SQLite) DeleteAllSchedules(ctx context.Context) (int, error) {
 
 	return int(rowsAffected), nil
 }
+
+// CreateAPIKey stores a new API key
+func (s *SQLite) CreateAPIKey(ctx context.Context, key *models.APIKey) error {
+	key.CreatedAt = time.Now()
+
+	query := `
+		INSERT INTO api_keys (id, name, prefix, key_hash, scopes, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)`
+
+	_, err := s.db.ExecContext(ctx, query,
+		key.ID,
+		key.Name,
+		key.Prefix,
+		key.KeyHash,
+		sliceToJSON(key.Scopes),
+		key.CreatedAt,
+	)
+
+	return err
+}
+
+// GetAPIKeyByHash retrieves an API key by the hash of its secret, including revoked keys
+func (s *SQLite) GetAPIKeyByHash(ctx context.Context, keyHash string) (*models.APIKey, error) {
+	query := `
+		SELECT id, name, prefix, key_hash, scopes, last_used_at, revoked_at, created_at
+		FROM api_keys WHERE key_hash = ?`
+
+	key, err := scanAPIKey(s.db.QueryRowContext(ctx, query, keyHash))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("API key not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
+
+// ListAPIKeys lists all API keys, newest first
+func (s *SQLite) ListAPIKeys(ctx context.Context) ([]*models.APIKey, error) {
+	query := `
+		SELECT id, name, prefix, key_hash, scopes, last_used_at, revoked_at, created_at
+		FROM api_keys ORDER BY created_at DESC`
+
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []*models.APIKey
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, rows.Err()
+}
+
+// RevokeAPIKey revokes an API key. Revoked keys are kept for auditing.
+func (s *SQLite) RevokeAPIKey(ctx context.Context, id string) error {
+	query := "UPDATE api_keys SET revoked_at = ? WHERE id = ? AND revoked_at IS NULL"
+	result, err := s.db.ExecContext(ctx, query, time.Now(), id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("active API key not found: %s", id)
+	}
+
+	return nil
+}
+
+// TouchAPIKey records when an API key was last used
+func (s *SQLite) TouchAPIKey(ctx context.Context, id string, usedAt time.Time) error {
+	_, err := s.db.ExecContext(ctx, "UPDATE api_keys SET last_used_at = ? WHERE id = ?", usedAt, id)
+	return err
+}
+
+// scanAPIKey scans an api_keys row
+func scanAPIKey(row interface{ Scan(dest ...any) error }) (*models.APIKey, error) {
+	var key models.APIKey
+	var scopesJSON string
+
+	err := row.Scan(
+		&key.ID,
+		&key.Name,
+		&key.Prefix,
+		&key.KeyHash,
+		&scopesJSON,
+		&key.LastUsedAt,
+		&key.RevokedAt,
+		&key.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	key.Scopes = jsonToSlice(scopesJSON)
+	return &key, nil
+}
@@ -0,0 +1,163 @@
+package sqlite_test
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/fissionx/gego/internal/db/migrations"
+	"github.com/fissionx/gego/internal/db/sqlite"
+	"github.com/fissionx/gego/internal/models"
+	"github.com/fissionx/gego/internal/secrets"
+)
+
+func newTestDB(t *testing.T) *sqlite.SQLite {
+	t.Helper()
+	ctx := context.Background()
+
+	db, err := sqlite.New(&models.Config{URI: filepath.Join(t.TempDir(), "gego.db"), SkipSchemaCheck: true})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := db.Connect(ctx); err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	t.Cleanup(func() { db.Disconnect(ctx) })
+
+	m, err := migrations.NewSQL(db.GetDB())
+	if err != nil {
+		t.Fatalf("NewSQL() error = %v", err)
+	}
+	if err := m.Up(); err != nil {
+		t.Fatalf("Up() error = %v", err)
+	}
+	return db
+}
+
+func newTestKeyring(t *testing.T) *secrets.Keyring {
+	t.Helper()
+	masterKey, err := secrets.GenerateMasterKey()
+	if err != nil {
+		t.Fatalf("GenerateMasterKey() error = %v", err)
+	}
+	keyring, err := secrets.NewKeyring(masterKey)
+	if err != nil {
+		t.Fatalf("NewKeyring() error = %v", err)
+	}
+	return keyring
+}
+
+// useKeyring sets the default keyring for the rest of the test
+func useKeyring(t *testing.T, keyring *secrets.Keyring) {
+	t.Helper()
+	previous := secrets.Default()
+	secrets.SetDefault(keyring)
+	t.Cleanup(func() { secrets.SetDefault(previous) })
+}
+
+// storedAPIKey reads the API key of an LLM as stored, bypassing decryption
+func storedAPIKey(t *testing.T, db *sqlite.SQLite, id string) string {
+	t.Helper()
+	var apiKey string
+	if err := db.GetDB().QueryRow("SELECT api_key FROM llms WHERE id = ?", id).Scan(&apiKey); err != nil {
+		t.Fatalf("failed to read API key of %s: %v", id, err)
+	}
+	return apiKey
+}
+
+func TestRotateLLMAPIKeys(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	oldKeyring, newKeyring := newTestKeyring(t), newTestKeyring(t)
+
+	// One key stored before a master key was configured, one after
+	useKeyring(t, nil)
+	llms := []*models.LLMConfig{
+		{ID: "plain", Name: "GPT", Provider: "openai", Model: "gpt-4o", APIKey: "sk-plain"},
+		{ID: "ref", Name: "Claude", Provider: "anthropic", Model: "claude", APIKey: "env:ANTHROPIC_API_KEY"},
+		{ID: "local", Name: "Llama", Provider: "ollama", Model: "llama3"},
+	}
+	for _, llm := range llms {
+		if err := db.CreateLLM(ctx, llm); err != nil {
+			t.Fatalf("CreateLLM() error = %v", err)
+		}
+	}
+	secrets.SetDefault(oldKeyring)
+	if err := db.CreateLLM(ctx, &models.LLMConfig{ID: "sealed", Name: "Gemini", Provider: "google", Model: "gemini", APIKey: "sk-sealed"}); err != nil {
+		t.Fatalf("CreateLLM() error = %v", err)
+	}
+
+	if _, err := db.RotateLLMAPIKeys(ctx, nil, newKeyring); err == nil {
+		t.Error("RotateLLMAPIKeys() without the current key succeeded")
+	}
+	if stored := storedAPIKey(t, db, "plain"); stored != "sk-plain" {
+		t.Errorf("failed rotation changed a key to %q", stored)
+	}
+
+	rotated, err := db.RotateLLMAPIKeys(ctx, oldKeyring, newKeyring)
+	if err != nil {
+		t.Fatalf("RotateLLMAPIKeys() error = %v", err)
+	}
+	if rotated != 2 {
+		t.Errorf("RotateLLMAPIKeys() = %d, want 2", rotated)
+	}
+	for id, want := range map[string]string{"plain": "sk-plain", "sealed": "sk-sealed"} {
+		stored := storedAPIKey(t, db, id)
+		if !strings.HasPrefix(stored, "enc:v1:"+newKeyring.ID()+":") {
+			t.Errorf("stored key of %s = %q, want it encrypted with %s", id, stored, newKeyring.ID())
+		}
+		if apiKey, err := newKeyring.Decrypt(stored); err != nil || apiKey != want {
+			t.Errorf("key of %s = %q, %v, want %s", id, apiKey, err, want)
+		}
+	}
+	if stored := storedAPIKey(t, db, "ref"); stored != "env:ANTHROPIC_API_KEY" {
+		t.Errorf("stored reference = %q, want it untouched", stored)
+	}
+
+	secrets.SetDefault(newKeyring)
+	if llm, err := db.GetLLM(ctx, "sealed"); err != nil || llm.APIKey != "sk-sealed" {
+		t.Errorf("GetLLM() after rotation = %+v, %v", llm, err)
+	}
+}
+
+func TestListLLMsWithUndecryptableKey(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+
+	useKeyring(t, newTestKeyring(t))
+	for _, llm := range []*models.LLMConfig{
+		{ID: "lost", Name: "GPT", Provider: "openai", Model: "gpt-4o", APIKey: "sk-lost"},
+		{ID: "ref", Name: "Claude", Provider: "anthropic", Model: "claude", APIKey: "env:ANTHROPIC_API_KEY"},
+	} {
+		if err := db.CreateLLM(ctx, llm); err != nil {
+			t.Fatalf("CreateLLM() error = %v", err)
+		}
+	}
+
+	// The master key the first key was encrypted with is gone
+	secrets.SetDefault(newTestKeyring(t))
+	llms, err := db.ListLLMs(ctx, nil)
+	if err != nil {
+		t.Fatalf("ListLLMs() error = %v", err)
+	}
+	if len(llms) != 2 {
+		t.Fatalf("ListLLMs() = %d LLMs, want 2", len(llms))
+	}
+	for _, llm := range llms {
+		switch llm.ID {
+		case "lost":
+			if llm.APIKey != "" || !strings.Contains(llm.KeyError, "lost") {
+				t.Errorf("undecryptable LLM = key %q, error %q", llm.APIKey, llm.KeyError)
+			}
+		case "ref":
+			if llm.APIKey != "env:ANTHROPIC_API_KEY" || llm.KeyError != "" {
+				t.Errorf("LLM with a reference = key %q, error %q", llm.APIKey, llm.KeyError)
+			}
+		}
+	}
+
+	if _, err := db.GetLLM(ctx, "lost"); err == nil {
+		t.Error("GetLLM() of an undecryptable LLM succeeded")
+	}
+}
@@ -0,0 +1,318 @@
+package sqlitedoc
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fissionx/gego/internal/models"
+	"github.com/fissionx/gego/internal/shared"
+)
+
+// responseQuery builds the WHERE clause for a response filter. The keyword is
+// left to findResponses, which matches it on the response text.
+func responseQuery(filter shared.ResponseFilter) (string, []interface{}) {
+	var conds []string
+	var args []interface{}
+
+	eq := func(column, value string) {
+		if value != "" {
+			conds = append(conds, column+" = ?")
+			args = append(args, value)
+		}
+	}
+	in := func(column string, values []string) {
+		if len(values) == 0 {
+			return
+		}
+		conds = append(conds, column+" IN ("+strings.TrimSuffix(strings.Repeat("?, ", len(values)), ", ")+")")
+		for _, value := range values {
+			args = append(args, value)
+		}
+	}
+
+	eq("prompt_id", filter.PromptID)
+	in("prompt_id", filter.PromptIDs)
+	eq("prompt_type", filter.PromptType)
+	eq("llm_id", filter.LLMID)
+	in("llm_id", filter.LLMIDs)
+	eq("schedule_id", filter.ScheduleID)
+	eq("campaign_id", filter.CampaignID)
+	eq("brand", filter.Brand)
+	eq("region", filter.Region)
+	eq("language", filter.Language)
+	eq("llm_provider", filter.LLMProvider)
+	if filter.SourceCategory != "" {
+		conds = append(conds, "EXISTS (SELECT 1 FROM json_each(doc, '$.sourceCategories') WHERE value = ?)")
+		args = append(args, filter.SourceCategory)
+	}
+	if filter.StartTime != nil {
+		conds = append(conds, "created_at >= ?")
+		args = append(args, unixNano(*filter.StartTime))
+	}
+	if filter.EndTime != nil {
+		conds = append(conds, "created_at <= ?")
+		args = append(args, unixNano(*filter.EndTime))
+	}
+
+	if len(conds) == 0 {
+		return "", nil
+	}
+	return " WHERE " + strings.Join(conds, " AND "), args
+}
+
+// findResponses returns the responses matching the filter, newest first
+func (s *DocStore) findResponses(ctx context.Context, filter shared.ResponseFilter) ([]*models.Response, error) {
+	where, args := responseQuery(filter)
+	query := `SELECT doc FROM responses` + where + ` ORDER BY created_at DESC`
+
+	// Without a keyword, SQL pages the results itself
+	var keyword *regexp.Regexp
+	if filter.Keyword != "" {
+		var err error
+		keyword, err = regexp.Compile("(?i)" + filter.Keyword)
+		if err != nil {
+			return nil, fmt.Errorf("invalid keyword pattern: %w", err)
+		}
+	} else if filter.Limit > 0 || filter.Offset > 0 {
+		limit := filter.Limit
+		if limit <= 0 {
+			limit = -1
+		}
+		query += ` LIMIT ? OFFSET ?`
+		args = append(args, limit, filter.Offset)
+	}
+
+	var responses []*models.Response
+	skipped := 0
+	err := s.scanDocs(ctx, s.db, query, args, func(doc string) error {
+		if keyword != nil && filter.Limit > 0 && len(responses) >= filter.Limit {
+			return nil
+		}
+
+		var response models.Response
+		if err := decode(doc, &response); err != nil {
+			return err
+		}
+
+		if keyword != nil {
+			if !keyword.MatchString(response.ResponseText) {
+				return nil
+			}
+			if skipped < filter.Offset {
+				skipped++
+				return nil
+			}
+		}
+
+		responses = append(responses, &response)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return responses, nil
+}
+
+// matchingResponses returns all responses matching the filter, ignoring its paging
+// like the MongoDB aggregations do
+func (s *DocStore) matchingResponses(ctx context.Context, filter shared.ResponseFilter) ([]*models.Response, error) {
+	filter.Limit, filter.Offset = 0, 0
+	return s.findResponses(ctx, filter)
+}
+
+// responseGroupKey returns the key of the group of a response, and false when
+// the response has none
+func responseGroupKey(response *models.Response, groupBy shared.ResponseGroup) (string, bool) {
+	var key string
+	switch groupBy {
+	case shared.GroupByNone:
+		return "", true
+	case shared.GroupByPrompt:
+		return response.PromptID, true
+	case shared.GroupByLLM:
+		return response.LLMProvider + "-" + response.LLMName, true
+	case shared.GroupByCategory:
+		key = response.PromptCategory
+	case shared.GroupBySentiment:
+		key = response.Sentiment
+	case shared.GroupByWeek:
+		key = response.Week
+	case shared.GroupByMonth:
+		key = response.Month
+	case shared.GroupByQuarter:
+		key = response.Quarter
+	default:
+		origin, duration, _ := groupBy.Interval()
+		return shared.IntervalStart(origin, duration, response.CreatedAt).Format(time.RFC3339), true
+	}
+	return key, key != ""
+}
+
+// checkResponseGroup fails for groupings the store does not support
+func checkResponseGroup(groupBy shared.ResponseGroup) error {
+	switch groupBy {
+	case shared.GroupByNone, shared.GroupByPrompt, shared.GroupByLLM, shared.GroupByCategory, shared.GroupBySentiment,
+		shared.GroupByWeek, shared.GroupByMonth, shared.GroupByQuarter:
+		return nil
+	}
+	if _, _, ok := groupBy.Interval(); ok {
+		return nil
+	}
+	return fmt.Errorf("unsupported response grouping: %s", groupBy)
+}
+
+// AggregateResponseMetrics computes visibility, mention, grounding, position and
+// sentiment metrics for the matching responses, grouped by the given dimension
+func (s *DocStore) AggregateResponseMetrics(ctx context.Context, filter shared.ResponseFilter, groupBy shared.ResponseGroup) ([]*models.ResponseMetrics, error) {
+	if err := checkResponseGroup(groupBy); err != nil {
+		return nil, err
+	}
+
+	responses, err := s.matchingResponses(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate response metrics: %w", err)
+	}
+
+	groups := make(map[string]*models.ResponseMetrics)
+	var metrics []*models.ResponseMetrics
+	for _, response := range responses {
+		key, ok := responseGroupKey(response, groupBy)
+		if !ok {
+			continue
+		}
+
+		group, found := groups[key]
+		if !found {
+			group = &models.ResponseMetrics{Key: key}
+			if groupBy == shared.GroupByLLM {
+				group.LLMName = response.LLMName
+				group.LLMProvider = response.LLMProvider
+			}
+			groups[key] = group
+			metrics = append(metrics, group)
+		}
+
+		group.TotalResponses++
+		group.TotalVisibility += response.VisibilityScore
+		if response.BrandMentioned {
+			group.MentionCount++
+		}
+		if response.InGroundingSources {
+			group.GroundedCount++
+		}
+		if response.BrandPosition > 0 {
+			group.PositionSum += response.BrandPosition
+			group.PositionCount++
+			if response.BrandPosition <= 3 {
+				group.TopPositionCount++
+			}
+		}
+		switch strings.ToLower(response.Sentiment) {
+		case "positive":
+			group.SentimentScoreSum++
+		case "negative":
+			group.SentimentScoreSum--
+		}
+		if response.Sentiment != "" {
+			group.SentimentCount++
+		}
+	}
+
+	sort.Slice(metrics, func(i, j int) bool {
+		if metrics[i].TotalResponses != metrics[j].TotalResponses {
+			return metrics[i].TotalResponses > metrics[j].TotalResponses
+		}
+		return metrics[i].Key < metrics[j].Key
+	})
+
+	return metrics, nil
+}
+
+// CountCompetitorMentions counts in how many matching responses each competitor was mentioned
+func (s *DocStore) CountCompetitorMentions(ctx context.Context, filter shared.ResponseFilter) (map[string]int, error) {
+	groups, err := s.CountGroupedCompetitorMentions(ctx, filter, shared.GroupByNone)
+	if err != nil {
+		return nil, err
+	}
+	if counts, ok := groups[""]; ok {
+		return counts, nil
+	}
+	return make(map[string]int), nil
+}
+
+// CountGroupedCompetitorMentions counts in how many matching responses each
+// competitor was mentioned, by group key then competitor
+func (s *DocStore) CountGroupedCompetitorMentions(ctx context.Context, filter shared.ResponseFilter, groupBy shared.ResponseGroup) (map[string]map[string]int, error) {
+	if err := checkResponseGroup(groupBy); err != nil {
+		return nil, err
+	}
+
+	responses, err := s.matchingResponses(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate competitor mentions: %w", err)
+	}
+
+	groups := make(map[string]map[string]int)
+	for _, response := range responses {
+		key, ok := responseGroupKey(response, groupBy)
+		if !ok {
+			continue
+		}
+		for _, competitor := range response.CompetitorsMention {
+			if competitor == "" {
+				continue
+			}
+			if groups[key] == nil {
+				groups[key] = make(map[string]int)
+			}
+			groups[key][competitor]++
+		}
+	}
+
+	return groups, nil
+}
+
+// AggregateSourceDomains counts the citations of each grounding domain across
+// the matching responses, with a per-LLM breakdown, most cited first
+func (s *DocStore) AggregateSourceDomains(ctx context.Context, filter shared.ResponseFilter) ([]*models.SourceDomainStats, error) {
+	responses, err := s.matchingResponses(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate source domains: %w", err)
+	}
+
+	byDomain := make(map[string]*models.SourceDomainStats)
+	var domains []*models.SourceDomainStats
+	for _, response := range responses {
+		for _, domain := range response.GroundingDomains {
+			if domain == "" {
+				continue
+			}
+
+			stats, ok := byDomain[domain]
+			if !ok {
+				stats = &models.SourceDomainStats{
+					Domain:       domain,
+					LLMBreakdown: make(map[string]int),
+				}
+				byDomain[domain] = stats
+				domains = append(domains, stats)
+			}
+			stats.CitationCount++
+			stats.LLMBreakdown[response.LLMName]++
+		}
+	}
+
+	sort.Slice(domains, func(i, j int) bool {
+		if domains[i].CitationCount != domains[j].CitationCount {
+			return domains[i].CitationCount > domains[j].CitationCount
+		}
+		return domains[i].Domain < domains[j].Domain
+	})
+
+	return domains, nil
+}
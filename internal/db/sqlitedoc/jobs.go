@@ -0,0 +1,252 @@
+package sqlitedoc
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fissionx/gego/internal/models"
+)
+
+// EnqueueJobs inserts new jobs into the queue
+func (s *DocStore) EnqueueJobs(ctx context.Context, jobs []*models.Job) error {
+	if len(jobs) == 0 {
+		return nil
+	}
+
+	err := s.inTx(ctx, func(tx *sql.Tx) error {
+		for _, job := range jobs {
+			doc, err := encode(job)
+			if err != nil {
+				return err
+			}
+			if _, err := tx.ExecContext(ctx, `
+				INSERT INTO jobs (id, kind, status, campaign_id, lease_owner, lease_expires_at, available_at, created_at, doc)
+				VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+				job.ID, job.Kind, job.Status, job.CampaignID, job.LeaseOwner, leaseExpiry(job),
+				unixNano(job.AvailableAt), unixNano(job.CreatedAt), doc); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to enqueue jobs: %w", err)
+	}
+	return nil
+}
+
+// leaseExpiry is the lease_expires_at column of a job, NULL when not leased
+func leaseExpiry(job *models.Job) interface{} {
+	if job.LeaseExpiresAt == nil {
+		return nil
+	}
+	return unixNano(*job.LeaseExpiresAt)
+}
+
+// saveJob writes back a job read within the same transaction
+func saveJob(ctx context.Context, e execer, job *models.Job) error {
+	doc, err := encode(job)
+	if err != nil {
+		return err
+	}
+
+	_, err = e.ExecContext(ctx, `
+		UPDATE jobs SET status = ?, lease_owner = ?, lease_expires_at = ?, available_at = ?, doc = ?
+		WHERE id = ?`,
+		job.Status, job.LeaseOwner, leaseExpiry(job), unixNano(job.AvailableAt), doc, job.ID)
+	return err
+}
+
+// LeaseJob atomically claims the oldest available job of the given kinds.
+// Jobs whose lease expired (their worker died) are claimed again.
+// Returns nil if no job is available.
+func (s *DocStore) LeaseJob(ctx context.Context, owner string, kinds []string, lease time.Duration) (*models.Job, error) {
+	if len(kinds) == 0 {
+		return nil, nil
+	}
+
+	var leased *models.Job
+	err := s.inTx(ctx, func(tx *sql.Tx) error {
+		now := time.Now()
+
+		args := make([]interface{}, 0, len(kinds)+3)
+		for _, kind := range kinds {
+			args = append(args, kind)
+		}
+		args = append(args, models.JobStatusPending, unixNano(now), models.JobStatusLeased, unixNano(now))
+
+		var doc string
+		err := tx.QueryRowContext(ctx, `
+			SELECT doc FROM jobs
+			WHERE kind IN (`+strings.TrimSuffix(strings.Repeat("?, ", len(kinds)), ", ")+`)
+				AND ((status = ? AND available_at <= ?) OR (status = ? AND lease_expires_at < ?))
+			ORDER BY available_at
+			LIMIT 1`, args...).Scan(&doc)
+		if err == sql.ErrNoRows {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		var job models.Job
+		if err := decode(doc, &job); err != nil {
+			return err
+		}
+
+		expires := now.Add(lease)
+		job.Status = models.JobStatusLeased
+		job.LeaseOwner = owner
+		job.LeaseExpiresAt = &expires
+		job.Attempts++
+		job.UpdatedAt = now
+
+		if err := saveJob(ctx, tx, &job); err != nil {
+			return err
+		}
+		leased = &job
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to lease job: %w", err)
+	}
+
+	return leased, nil
+}
+
+// RetryJob releases a leased job back to the queue, available again at availableAt
+func (s *DocStore) RetryJob(ctx context.Context, id, owner, lastError string, availableAt time.Time) error {
+	return s.updateLeasedJob(ctx, id, owner, func(job *models.Job) {
+		job.Status = models.JobStatusPending
+		job.LastError = lastError
+		job.AvailableAt = availableAt
+		job.UpdatedAt = time.Now()
+	})
+}
+
+// FinishJob moves a leased job to a final status (done, dead or cancelled)
+func (s *DocStore) FinishJob(ctx context.Context, id, owner, status, lastError string) error {
+	return s.updateLeasedJob(ctx, id, owner, func(job *models.Job) {
+		now := time.Now()
+		job.Status = status
+		job.CompletedAt = &now
+		job.UpdatedAt = now
+		if lastError != "" {
+			job.LastError = lastError
+		}
+	})
+}
+
+// updateLeasedJob applies an update to a job still leased by owner, then
+// releases its lease
+func (s *DocStore) updateLeasedJob(ctx context.Context, id, owner string, update func(job *models.Job)) error {
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		var doc string
+		err := tx.QueryRowContext(ctx, `SELECT doc FROM jobs WHERE id = ? AND status = ? AND lease_owner = ?`,
+			id, models.JobStatusLeased, owner).Scan(&doc)
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("job %s is no longer leased by %s", id, owner)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to update job: %w", err)
+		}
+
+		var job models.Job
+		if err := decode(doc, &job); err != nil {
+			return err
+		}
+
+		update(&job)
+		job.LeaseOwner = ""
+		job.LeaseExpiresAt = nil
+
+		if err := saveJob(ctx, tx, &job); err != nil {
+			return fmt.Errorf("failed to update job: %w", err)
+		}
+		return nil
+	})
+}
+
+// CancelCampaignJobs cancels the pending jobs of a campaign. Leased jobs finish normally.
+func (s *DocStore) CancelCampaignJobs(ctx context.Context, campaignID string) (int, error) {
+	cancelled := 0
+	err := s.inTx(ctx, func(tx *sql.Tx) error {
+		var jobs []*models.Job
+		err := s.scanDocs(ctx, tx, `SELECT doc FROM jobs WHERE campaign_id = ? AND status = ?`,
+			[]interface{}{campaignID, models.JobStatusPending}, func(doc string) error {
+				var job models.Job
+				if err := decode(doc, &job); err != nil {
+					return err
+				}
+				jobs = append(jobs, &job)
+				return nil
+			})
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		for _, job := range jobs {
+			job.Status = models.JobStatusCancelled
+			job.CompletedAt = &now
+			job.UpdatedAt = now
+			if err := saveJob(ctx, tx, job); err != nil {
+				return err
+			}
+			cancelled++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to cancel campaign jobs: %w", err)
+	}
+
+	return cancelled, nil
+}
+
+// GetJob retrieves a job by ID
+func (s *DocStore) GetJob(ctx context.Context, id string) (*models.Job, error) {
+	var doc string
+	err := s.db.QueryRowContext(ctx, `SELECT doc FROM jobs WHERE id = ?`, id).Scan(&doc)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("job not found: %s", id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find job: %w", err)
+	}
+
+	var job models.Job
+	if err := decode(doc, &job); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// ListJobs lists jobs, newest first, optionally filtered by status
+func (s *DocStore) ListJobs(ctx context.Context, status string, limit int) ([]*models.Job, error) {
+	query := `SELECT doc FROM jobs`
+	var args []interface{}
+	if status != "" {
+		query += ` WHERE status = ?`
+		args = append(args, status)
+	}
+	query += ` ORDER BY created_at DESC`
+	if limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, limit)
+	}
+
+	var jobs []*models.Job
+	err := s.scanDocs(ctx, s.db, query, args, func(doc string) error {
+		var job models.Job
+		if err := decode(doc, &job); err != nil {
+			return err
+		}
+		jobs = append(jobs, &job)
+		return nil
+	})
+	return jobs, err
+}
@@ -0,0 +1,116 @@
+package sqlitedoc
+
+import (
+	"context"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/fissionx/gego/internal/models"
+	"github.com/fissionx/gego/internal/shared"
+)
+
+// SearchKeyword searches for a keyword in all responses and calculates stats on-the-fly.
+// When the keyword is a brand with a profile, its aliases, product names and
+// domains count as mentions too.
+func (s *DocStore) SearchKeyword(ctx context.Context, keyword string, startTime, endTime *time.Time) (*models.KeywordStats, error) {
+	pattern := regexp.QuoteMeta(keyword)
+
+	var matcher *shared.BrandMatcher
+	if profile, err := s.GetBrandProfile(ctx, keyword); err == nil && profile != nil {
+		matcher = shared.BrandMatcherForProfile(keyword, profile)
+		pattern = matcher.Pattern()
+	}
+
+	responses, err := s.findResponses(ctx, shared.ResponseFilter{
+		Keyword:   pattern,
+		StartTime: startTime,
+		EndTime:   endTime,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &models.KeywordStats{
+		Keyword:    keyword,
+		ByPrompt:   make(map[string]int),
+		ByLLM:      make(map[string]int),
+		ByProvider: make(map[string]int),
+	}
+
+	promptsSeen := make(map[string]bool)
+	llmsSeen := make(map[string]bool)
+
+	for _, response := range responses {
+		count := shared.CountOccurrences(response.ResponseText, keyword)
+		if matcher != nil {
+			count = matcher.Count(response.ResponseText)
+			if count == 0 {
+				continue
+			}
+		}
+		stats.TotalMentions += count
+
+		stats.ByPrompt[response.PromptID] += count
+		promptsSeen[response.PromptID] = true
+
+		stats.ByLLM[response.LLMID] += count
+		llmsSeen[response.LLMID] = true
+
+		stats.ByProvider[response.LLMProvider] += count
+
+		if stats.FirstSeen.IsZero() || response.CreatedAt.Before(stats.FirstSeen) {
+			stats.FirstSeen = response.CreatedAt
+		}
+		if stats.LastSeen.IsZero() || response.CreatedAt.After(stats.LastSeen) {
+			stats.LastSeen = response.CreatedAt
+		}
+	}
+
+	stats.UniquePrompts = len(promptsSeen)
+	stats.UniqueLLMs = len(llmsSeen)
+
+	return stats, nil
+}
+
+// GetTopKeywords returns the most common keywords across all responses
+func (s *DocStore) GetTopKeywords(ctx context.Context, limit int, startTime, endTime *time.Time) ([]models.KeywordCount, error) {
+	responses, err := s.findResponses(ctx, shared.ResponseFilter{
+		StartTime: startTime,
+		EndTime:   endTime,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	wordCounts := make(map[string]int)
+	for _, response := range responses {
+		for _, word := range shared.ExtractCapitalizedWords(response.ResponseText) {
+			wordCounts[word]++
+		}
+	}
+
+	var results []models.KeywordCount
+	for keyword, count := range wordCounts {
+		results = append(results, models.KeywordCount{
+			Keyword: keyword,
+			Count:   count,
+		})
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Count != results[j].Count {
+			return results[i].Count > results[j].Count
+		}
+		return results[i].Keyword < results[j].Keyword
+	})
+
+	if limit < 0 {
+		limit = 0
+	}
+	if len(results) > limit {
+		results = results[:limit]
+	}
+
+	return results, nil
+}
@@ -0,0 +1,957 @@
+// Package sqlitedoc implements the NoSQL database on an embedded SQLite file,
+// for deployments without a MongoDB server. Each collection is a table
+// holding the JSON document of a record, next to the columns it is queried by.
+package sqlitedoc
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+
+	"github.com/fissionx/gego/internal/models"
+	"github.com/fissionx/gego/internal/shared"
+)
+
+// DocStore implements the NoSQL Database interface on SQLite
+type DocStore struct {
+	db     *sql.DB
+	config *models.Config
+}
+
+// MemoryURI keeps the whole store in memory, for tests
+const MemoryURI = ":memory:"
+
+const (
+	tablePrompts   = "prompts"
+	tableResponses = "responses"
+)
+
+// schema creates the collection tables and their indexes. The columns besides
+// doc copy the document fields used in queries.
+var schema = []string{
+	`CREATE TABLE IF NOT EXISTS prompts (
+		id TEXT PRIMARY KEY,
+		enabled INTEGER NOT NULL,
+		doc TEXT NOT NULL
+	)`,
+	`CREATE TABLE IF NOT EXISTS responses (
+		id TEXT PRIMARY KEY,
+		prompt_id TEXT NOT NULL,
+		llm_id TEXT NOT NULL,
+		llm_provider TEXT NOT NULL,
+		schedule_id TEXT NOT NULL,
+		campaign_id TEXT NOT NULL,
+		brand TEXT NOT NULL,
+		region TEXT NOT NULL,
+		language TEXT NOT NULL,
+		prompt_type TEXT NOT NULL,
+		created_at INTEGER NOT NULL,
+		doc TEXT NOT NULL
+	)`,
+	`CREATE INDEX IF NOT EXISTS idx_responses_created_at ON responses(created_at DESC)`,
+	`CREATE INDEX IF NOT EXISTS idx_responses_prompt ON responses(prompt_id, created_at DESC)`,
+	`CREATE INDEX IF NOT EXISTS idx_responses_llm ON responses(llm_id)`,
+	`CREATE INDEX IF NOT EXISTS idx_responses_brand ON responses(brand, created_at DESC)`,
+	`CREATE INDEX IF NOT EXISTS idx_responses_campaign ON responses(campaign_id, brand)`,
+	`CREATE TABLE IF NOT EXISTS prompt_library (
+		id TEXT PRIMARY KEY,
+		brand TEXT NOT NULL,
+		domain TEXT NOT NULL,
+		category TEXT NOT NULL,
+		doc TEXT NOT NULL
+	)`,
+	`CREATE INDEX IF NOT EXISTS idx_prompt_library_domain ON prompt_library(domain, category)`,
+	`CREATE TABLE IF NOT EXISTS brand_profiles (
+		id TEXT PRIMARY KEY,
+		brand_name TEXT NOT NULL,
+		doc TEXT NOT NULL
+	)`,
+	`CREATE INDEX IF NOT EXISTS idx_brand_profiles_name ON brand_profiles(brand_name)`,
+	`CREATE TABLE IF NOT EXISTS brand_logos (
+		brand_name TEXT PRIMARY KEY,
+		doc TEXT NOT NULL
+	)`,
+	`CREATE TABLE IF NOT EXISTS campaigns (
+		id TEXT PRIMARY KEY,
+		brand TEXT NOT NULL,
+		created_at INTEGER NOT NULL,
+		doc TEXT NOT NULL
+	)`,
+	`CREATE INDEX IF NOT EXISTS idx_campaigns_brand ON campaigns(brand, created_at DESC)`,
+	`CREATE TABLE IF NOT EXISTS jobs (
+		id TEXT PRIMARY KEY,
+		kind TEXT NOT NULL,
+		status TEXT NOT NULL,
+		campaign_id TEXT NOT NULL,
+		lease_owner TEXT NOT NULL,
+		lease_expires_at INTEGER,
+		available_at INTEGER NOT NULL,
+		created_at INTEGER NOT NULL,
+		doc TEXT NOT NULL
+	)`,
+	`CREATE INDEX IF NOT EXISTS idx_jobs_status ON jobs(status, available_at)`,
+	`CREATE INDEX IF NOT EXISTS idx_jobs_campaign ON jobs(campaign_id)`,
+}
+
+// execer runs statements on the database or within a transaction
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// New creates a new SQLite document store instance
+func New(config *models.Config) (*DocStore, error) {
+	return &DocStore{
+		config: config,
+	}, nil
+}
+
+// Connect opens the SQLite file and creates the collection tables
+func (s *DocStore) Connect(ctx context.Context) error {
+	dsn := MemoryURI
+	if s.config.URI != MemoryURI {
+		dbPath, err := resolvePath(s.config.URI)
+		if err != nil {
+			return err
+		}
+		if err := os.MkdirAll(filepath.Dir(dbPath), 0755); err != nil {
+			return fmt.Errorf("failed to create database directory: %w", err)
+		}
+		// Writers take the lock when their transaction starts, so that job leases
+		// and counters stay atomic across processes sharing the file
+		dsn = dbPath + "?_busy_timeout=5000&_journal_mode=WAL&_txlock=immediate"
+	}
+
+	db, err := sql.Open("sqlite3", dsn)
+	if err != nil {
+		return fmt.Errorf("failed to open SQLite database at path '%s': %w", s.config.URI, err)
+	}
+	if s.config.URI == MemoryURI {
+		// Every connection to :memory: opens a new, empty database
+		db.SetMaxOpenConns(1)
+	}
+
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return fmt.Errorf("failed to ping SQLite database at path '%s': %w", s.config.URI, err)
+	}
+
+	for _, stmt := range schema {
+		if _, err := db.ExecContext(ctx, stmt); err != nil {
+			db.Close()
+			return fmt.Errorf("failed to create tables: %w", err)
+		}
+	}
+
+	s.db = db
+
+	if err := s.splitResponseAnalysis(ctx); err != nil {
+		db.Close()
+		return fmt.Errorf("failed to split the GEO analysis out of responses: %w", err)
+	}
+	if err := s.setResponsePeriods(ctx); err != nil {
+		db.Close()
+		return fmt.Errorf("failed to recompute response periods: %w", err)
+	}
+
+	return nil
+}
+
+// resolvePath expands ~ and makes the database path absolute
+func resolvePath(dbPath string) (string, error) {
+	if strings.HasPrefix(dbPath, "~") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to get home directory: %w", err)
+		}
+		return filepath.Join(home, dbPath[1:]), nil
+	}
+
+	absPath, err := filepath.Abs(dbPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve absolute path: %w", err)
+	}
+	return absPath, nil
+}
+
+// Disconnect closes the SQLite connection
+func (s *DocStore) Disconnect(ctx context.Context) error {
+	if s.db != nil {
+		return s.db.Close()
+	}
+	return nil
+}
+
+// Ping checks the database connection
+func (s *DocStore) Ping(ctx context.Context) error {
+	if s.db == nil {
+		return fmt.Errorf("not connected to database")
+	}
+	return s.db.PingContext(ctx)
+}
+
+// inTx runs fn within a transaction, committed when fn succeeds
+func (s *DocStore) inTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// encode marshals a document for the doc column
+func encode(v interface{}) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode document: %w", err)
+	}
+	return string(data), nil
+}
+
+// decode unmarshals the doc column into v
+func decode(doc string, v interface{}) error {
+	if err := json.Unmarshal([]byte(doc), v); err != nil {
+		return fmt.Errorf("failed to decode document: %w", err)
+	}
+	return nil
+}
+
+// unixNano stores times as integers, which sort and compare correctly
+func unixNano(t time.Time) int64 {
+	return t.UnixNano()
+}
+
+// CreatePrompt creates a new prompt
+func (s *DocStore) CreatePrompt(ctx context.Context, prompt *models.Prompt) error {
+	// Imported prompts keep their original creation time
+	if prompt.CreatedAt.IsZero() {
+		prompt.CreatedAt = time.Now()
+	}
+	prompt.UpdatedAt = time.Now()
+
+	doc, err := encode(prompt)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.db.ExecContext(ctx, `INSERT INTO prompts (id, enabled, doc) VALUES (?, ?, ?)`,
+		prompt.ID, prompt.Enabled, doc)
+	return err
+}
+
+// GetPrompt retrieves a prompt by ID
+func (s *DocStore) GetPrompt(ctx context.Context, id string) (*models.Prompt, error) {
+	var doc string
+	err := s.db.QueryRowContext(ctx, `SELECT doc FROM prompts WHERE id = ?`, id).Scan(&doc)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("prompt not found: %s", id)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var prompt models.Prompt
+	if err := decode(doc, &prompt); err != nil {
+		return nil, err
+	}
+	return &prompt, nil
+}
+
+// ListPrompts lists all prompts, optionally filtered by enabled status
+func (s *DocStore) ListPrompts(ctx context.Context, enabled *bool) ([]*models.Prompt, error) {
+	query := `SELECT doc FROM prompts`
+	var args []interface{}
+	if enabled != nil {
+		query += ` WHERE enabled = ?`
+		args = append(args, *enabled)
+	}
+	query += ` ORDER BY rowid`
+
+	var prompts []*models.Prompt
+	err := s.scanDocs(ctx, s.db, query, args, func(doc string) error {
+		var prompt models.Prompt
+		if err := decode(doc, &prompt); err != nil {
+			return err
+		}
+		prompts = append(prompts, &prompt)
+		return nil
+	})
+	return prompts, err
+}
+
+// UpdatePrompt updates an existing prompt
+func (s *DocStore) UpdatePrompt(ctx context.Context, prompt *models.Prompt) error {
+	prompt.UpdatedAt = time.Now()
+
+	doc, err := encode(prompt)
+	if err != nil {
+		return err
+	}
+
+	result, err := s.db.ExecContext(ctx, `UPDATE prompts SET enabled = ?, doc = ? WHERE id = ?`,
+		prompt.Enabled, doc, prompt.ID)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("prompt not found: %s", prompt.ID)
+	}
+
+	return nil
+}
+
+// DeletePrompt deletes a prompt by ID
+func (s *DocStore) DeletePrompt(ctx context.Context, id string) error {
+	result, err := s.db.ExecContext(ctx, `DELETE FROM prompts WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("prompt not found: %s", id)
+	}
+
+	return nil
+}
+
+// DeleteAllPrompts deletes all prompts
+func (s *DocStore) DeleteAllPrompts(ctx context.Context) (int, error) {
+	return s.deleteAll(ctx, tablePrompts)
+}
+
+// CreateResponse creates a new response
+func (s *DocStore) CreateResponse(ctx context.Context, response *models.Response) error {
+	// Imported responses keep their original creation time
+	if response.CreatedAt.IsZero() {
+		response.CreatedAt = time.Now()
+	}
+	shared.SetResponsePeriods(response)
+	return insertResponse(ctx, s.db, response)
+}
+
+// insertResponse writes a response document and its query columns
+func insertResponse(ctx context.Context, e execer, response *models.Response) error {
+	doc, err := encode(response)
+	if err != nil {
+		return err
+	}
+
+	_, err = e.ExecContext(ctx, `
+		INSERT INTO responses (id, prompt_id, llm_id, llm_provider, schedule_id, campaign_id,
+			brand, region, language, prompt_type, created_at, doc)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		response.ID, response.PromptID, response.LLMID, response.LLMProvider, response.ScheduleID, response.CampaignID,
+		response.Brand, response.Region, response.Language, string(response.PromptType), unixNano(response.CreatedAt), doc)
+	return err
+}
+
+// GetResponse retrieves a response by ID
+func (s *DocStore) GetResponse(ctx context.Context, id string) (*models.Response, error) {
+	var doc string
+	err := s.db.QueryRowContext(ctx, `SELECT doc FROM responses WHERE id = ?`, id).Scan(&doc)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("response not found: %s", id)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var response models.Response
+	if err := decode(doc, &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// ListResponses lists responses with filtering, newest first
+func (s *DocStore) ListResponses(ctx context.Context, filter shared.ResponseFilter) ([]*models.Response, error) {
+	return s.findResponses(ctx, filter)
+}
+
+// CountResponses counts responses matching the filter
+func (s *DocStore) CountResponses(ctx context.Context, filter shared.ResponseFilter) (int64, error) {
+	if filter.Keyword != "" {
+		// The keyword is matched outside SQL, on the decoded response text
+		filter.Limit, filter.Offset = 0, 0
+		responses, err := s.findResponses(ctx, filter)
+		return int64(len(responses)), err
+	}
+
+	where, args := responseQuery(filter)
+
+	var count int64
+	err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM responses`+where, args...).Scan(&count)
+	return count, err
+}
+
+// BackfillResponsePromptMetadata snapshots the prompt's metadata onto its
+// responses that were saved without it
+func (s *DocStore) BackfillResponsePromptMetadata(ctx context.Context, prompt *models.Prompt) (int, error) {
+	updated := 0
+	err := s.inTx(ctx, func(tx *sql.Tx) error {
+		var responses []*models.Response
+		err := s.scanDocs(ctx, tx, `SELECT doc FROM responses WHERE prompt_id = ? AND prompt_type = ''`, []interface{}{prompt.ID}, func(doc string) error {
+			var response models.Response
+			if err := decode(doc, &response); err != nil {
+				return err
+			}
+			responses = append(responses, &response)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, response := range responses {
+			response.PromptCategory = prompt.Category
+			response.PromptDomain = prompt.Domain
+			response.PromptType = prompt.PromptType
+			response.PromptTags = prompt.Tags
+
+			doc, err := encode(response)
+			if err != nil {
+				return err
+			}
+			if _, err := tx.ExecContext(ctx, `UPDATE responses SET prompt_type = ?, doc = ? WHERE id = ?`,
+				string(response.PromptType), doc, response.ID); err != nil {
+				return err
+			}
+			updated++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return updated, nil
+}
+
+// ReclassifyResponseSources recomputes the source categories of the responses
+// citing sources and returns the number of responses whose categories changed
+func (s *DocStore) ReclassifyResponseSources(ctx context.Context, classify func(domains []string) []string) (int, error) {
+	var responses []*models.Response
+	err := s.scanDocs(ctx, s.db, `SELECT doc FROM responses WHERE json_array_length(doc, '$.groundingDomains') > 0`, nil, func(doc string) error {
+		var response models.Response
+		if err := decode(doc, &response); err != nil {
+			return err
+		}
+		categories := classify(response.GroundingDomains)
+		if !slices.Equal(categories, response.SourceCategories) {
+			response.SourceCategories = categories
+			responses = append(responses, &response)
+		}
+		return nil
+	})
+	if err != nil || len(responses) == 0 {
+		return 0, err
+	}
+
+	err = s.inTx(ctx, func(tx *sql.Tx) error {
+		for _, response := range responses {
+			doc, err := encode(response)
+			if err != nil {
+				return err
+			}
+			if _, err := tx.ExecContext(ctx, `UPDATE responses SET doc = ? WHERE id = ?`, doc, response.ID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return len(responses), nil
+}
+
+// splitResponseAnalysis moves the analysis out of responses stored with the
+// combined answer and analysis document Google used to return as its answer.
+// The store has no versioned migrations, so this runs on every connect and
+// only reads the responses that still need it.
+func (s *DocStore) splitResponseAnalysis(ctx context.Context) error {
+	var responses []*models.Response
+	err := s.scanDocs(ctx, s.db, `SELECT doc FROM responses WHERE brand != '' AND instr(doc, 'geo_analysis') > 0 AND instr(doc, '"analysisRaw"') = 0`, nil, func(doc string) error {
+		var response models.Response
+		if err := decode(doc, &response); err != nil {
+			return err
+		}
+		if shared.SplitResponseAnalysis(&response) {
+			responses = append(responses, &response)
+		}
+		return nil
+	})
+	if err != nil || len(responses) == 0 {
+		return err
+	}
+
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		for _, response := range responses {
+			doc, err := encode(response)
+			if err != nil {
+				return err
+			}
+			if _, err := tx.ExecContext(ctx, `UPDATE responses SET doc = ? WHERE id = ?`, doc, response.ID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// periodMismatch selects the responses whose stored week, month or quarter
+// differs from the one of their creation time
+const periodMismatch = `json_extract(doc, '$.week') IS NOT strftime('%G-W%V', created_at / 1000000000, 'unixepoch')
+	OR json_extract(doc, '$.month') IS NOT strftime('%Y-%m', created_at / 1000000000, 'unixepoch')
+	OR json_extract(doc, '$.quarter') IS NOT strftime('%Y', created_at / 1000000000, 'unixepoch') || '-Q' || ((strftime('%m', created_at / 1000000000, 'unixepoch') + 2) / 3)`
+
+// setResponsePeriods recomputes the week, month and quarter of the responses
+// stored with none, or with the day of the month in place of the ISO week.
+// Like splitResponseAnalysis, this runs on every connect.
+func (s *DocStore) setResponsePeriods(ctx context.Context) error {
+	var responses []*models.Response
+	err := s.scanDocs(ctx, s.db, `SELECT doc FROM responses WHERE `+periodMismatch, nil, func(doc string) error {
+		var response models.Response
+		if err := decode(doc, &response); err != nil {
+			return err
+		}
+		if shared.SetResponsePeriods(&response) {
+			responses = append(responses, &response)
+		}
+		return nil
+	})
+	if err != nil || len(responses) == 0 {
+		return err
+	}
+
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		for _, response := range responses {
+			doc, err := encode(response)
+			if err != nil {
+				return err
+			}
+			if _, err := tx.ExecContext(ctx, `UPDATE responses SET doc = ? WHERE id = ?`, doc, response.ID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// DeleteAllResponses deletes all responses from the database
+func (s *DocStore) DeleteAllResponses(ctx context.Context) (int, error) {
+	return s.deleteAll(ctx, tableResponses)
+}
+
+// deleteAll empties a table and returns the number of deleted documents
+func (s *DocStore) deleteAll(ctx context.Context, table string) (int, error) {
+	result, err := s.db.ExecContext(ctx, `DELETE FROM `+table)
+	if err != nil {
+		return 0, err
+	}
+	n, err := result.RowsAffected()
+	return int(n), err
+}
+
+// scanDocs runs a query selecting a doc column and passes each document to fn
+func (s *DocStore) scanDocs(ctx context.Context, e execer, query string, args []interface{}, fn func(doc string) error) error {
+	rows, err := e.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var doc string
+		if err := rows.Scan(&doc); err != nil {
+			return err
+		}
+		if err := fn(doc); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// GetPromptStats calculates prompt statistics on-demand from responses
+func (s *DocStore) GetPromptStats(ctx context.Context, promptID string) (*models.PromptStats, error) {
+	responses, err := s.findResponses(ctx, shared.ResponseFilter{PromptID: promptID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate prompt stats: %w", err)
+	}
+
+	llmCounts := make(map[string]int)
+	tokens := 0
+	for _, response := range responses {
+		llmCounts[response.LLMID]++
+		tokens += response.TokensUsed
+	}
+
+	return &models.PromptStats{
+		PromptID:       promptID,
+		TotalResponses: len(responses),
+		UniqueLLMs:     len(llmCounts),
+		LLMCounts:      llmCounts,
+		AvgTokens:      average(tokens, len(responses)),
+		UpdatedAt:      time.Now(),
+	}, nil
+}
+
+// GetLLMStats calculates LLM statistics on-demand from responses
+func (s *DocStore) GetLLMStats(ctx context.Context, llmID string) (*models.LLMStats, error) {
+	responses, err := s.findResponses(ctx, shared.ResponseFilter{LLMID: llmID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate LLM stats: %w", err)
+	}
+
+	promptCounts := make(map[string]int)
+	tokens := 0
+	for _, response := range responses {
+		promptCounts[response.PromptID]++
+		tokens += response.TokensUsed
+	}
+
+	return &models.LLMStats{
+		LLMID:          llmID,
+		TotalResponses: len(responses),
+		UniquePrompts:  len(promptCounts),
+		PromptCounts:   promptCounts,
+		AvgTokens:      average(tokens, len(responses)),
+		UpdatedAt:      time.Now(),
+	}, nil
+}
+
+func average(sum, count int) float64 {
+	if count == 0 {
+		return 0
+	}
+	return float64(sum) / float64(count)
+}
+
+// CreatePromptLibrary creates a new prompt library entry
+func (s *DocStore) CreatePromptLibrary(ctx context.Context, library *models.PromptLibrary) error {
+	if library.CreatedAt.IsZero() {
+		library.CreatedAt = time.Now()
+	}
+	library.UpdatedAt = time.Now()
+
+	doc, err := encode(library)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.db.ExecContext(ctx, `INSERT INTO prompt_library (id, brand, domain, category, doc) VALUES (?, ?, ?, ?, ?)`,
+		library.ID, library.Brand, library.Domain, library.Category, doc)
+	return err
+}
+
+// GetPromptLibrary retrieves a prompt library by brand, domain, and category
+// If brand is empty, it searches by domain/category only (for cross-brand reuse)
+func (s *DocStore) GetPromptLibrary(ctx context.Context, brand, domain, category string) (*models.PromptLibrary, error) {
+	query := `SELECT doc FROM prompt_library WHERE domain = ? AND category = ?`
+	args := []interface{}{domain, category}
+	if brand != "" {
+		query += ` AND brand = ?`
+		args = append(args, brand)
+	}
+	query += ` ORDER BY rowid LIMIT 1`
+
+	var doc string
+	err := s.db.QueryRowContext(ctx, query, args...).Scan(&doc)
+	if err == sql.ErrNoRows {
+		return nil, nil // Return nil if not found (not an error)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find prompt library: %w", err)
+	}
+
+	var library models.PromptLibrary
+	if err := decode(doc, &library); err != nil {
+		return nil, err
+	}
+	return &library, nil
+}
+
+// UpdatePromptLibrary updates an existing prompt library
+func (s *DocStore) UpdatePromptLibrary(ctx context.Context, library *models.PromptLibrary) error {
+	library.UpdatedAt = time.Now()
+
+	doc, err := encode(library)
+	if err != nil {
+		return err
+	}
+
+	result, err := s.db.ExecContext(ctx, `UPDATE prompt_library SET brand = ?, domain = ?, category = ?, doc = ? WHERE id = ?`,
+		library.Brand, library.Domain, library.Category, doc, library.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update prompt library: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("prompt library not found: %s", library.ID)
+	}
+
+	return nil
+}
+
+// ListPromptLibraries lists all prompt libraries
+func (s *DocStore) ListPromptLibraries(ctx context.Context) ([]*models.PromptLibrary, error) {
+	var libraries []*models.PromptLibrary
+	err := s.scanDocs(ctx, s.db, `SELECT doc FROM prompt_library ORDER BY rowid`, nil, func(doc string) error {
+		var library models.PromptLibrary
+		if err := decode(doc, &library); err != nil {
+			return err
+		}
+		libraries = append(libraries, &library)
+		return nil
+	})
+	return libraries, err
+}
+
+// CreateBrandProfile creates a new brand profile
+func (s *DocStore) CreateBrandProfile(ctx context.Context, profile *models.BrandProfile) error {
+	if profile.CreatedAt.IsZero() {
+		profile.CreatedAt = time.Now()
+	}
+	profile.UpdatedAt = time.Now()
+
+	doc, err := encode(profile)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.db.ExecContext(ctx, `INSERT INTO brand_profiles (id, brand_name, doc) VALUES (?, ?, ?)`,
+		profile.ID, profile.BrandName, doc)
+	return err
+}
+
+// GetBrandProfile retrieves a brand profile by brand name
+func (s *DocStore) GetBrandProfile(ctx context.Context, brandName string) (*models.BrandProfile, error) {
+	var doc string
+	err := s.db.QueryRowContext(ctx, `SELECT doc FROM brand_profiles WHERE brand_name = ? ORDER BY rowid LIMIT 1`, brandName).Scan(&doc)
+	if err == sql.ErrNoRows {
+		return nil, nil // Return nil if not found (not an error)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find brand profile: %w", err)
+	}
+
+	var profile models.BrandProfile
+	if err := decode(doc, &profile); err != nil {
+		return nil, err
+	}
+	return &profile, nil
+}
+
+// UpdateBrandProfile updates an existing brand profile
+func (s *DocStore) UpdateBrandProfile(ctx context.Context, profile *models.BrandProfile) error {
+	profile.UpdatedAt = time.Now()
+
+	doc, err := encode(profile)
+	if err != nil {
+		return err
+	}
+
+	result, err := s.db.ExecContext(ctx, `UPDATE brand_profiles SET brand_name = ?, doc = ? WHERE id = ?`,
+		profile.BrandName, doc, profile.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update brand profile: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("brand profile not found: %s", profile.ID)
+	}
+
+	return nil
+}
+
+// ListBrandProfiles lists all brand profiles
+func (s *DocStore) ListBrandProfiles(ctx context.Context) ([]*models.BrandProfile, error) {
+	var profiles []*models.BrandProfile
+	err := s.scanDocs(ctx, s.db, `SELECT doc FROM brand_profiles ORDER BY rowid`, nil, func(doc string) error {
+		var profile models.BrandProfile
+		if err := decode(doc, &profile); err != nil {
+			return err
+		}
+		profiles = append(profiles, &profile)
+		return nil
+	})
+	return profiles, err
+}
+
+// CreateCampaign creates a new campaign
+func (s *DocStore) CreateCampaign(ctx context.Context, campaign *models.GEOCampaign) error {
+	campaign.CreatedAt = time.Now()
+	campaign.UpdatedAt = time.Now()
+
+	doc, err := encode(campaign)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.db.ExecContext(ctx, `INSERT INTO campaigns (id, brand, created_at, doc) VALUES (?, ?, ?, ?)`,
+		campaign.ID, campaign.Brand, unixNano(campaign.CreatedAt), doc)
+	return err
+}
+
+// GetCampaign retrieves a campaign by ID
+func (s *DocStore) GetCampaign(ctx context.Context, id string) (*models.GEOCampaign, error) {
+	return getCampaign(ctx, s.db, id)
+}
+
+func getCampaign(ctx context.Context, e execer, id string) (*models.GEOCampaign, error) {
+	var doc string
+	err := e.QueryRowContext(ctx, `SELECT doc FROM campaigns WHERE id = ?`, id).Scan(&doc)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("campaign not found: %s", id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find campaign: %w", err)
+	}
+
+	var campaign models.GEOCampaign
+	if err := decode(doc, &campaign); err != nil {
+		return nil, err
+	}
+	return &campaign, nil
+}
+
+// ListCampaigns lists campaigns, newest first, optionally filtered by brand
+func (s *DocStore) ListCampaigns(ctx context.Context, brand string) ([]*models.GEOCampaign, error) {
+	query := `SELECT doc FROM campaigns`
+	var args []interface{}
+	if brand != "" {
+		query += ` WHERE brand = ?`
+		args = append(args, brand)
+	}
+	query += ` ORDER BY created_at DESC`
+
+	var campaigns []*models.GEOCampaign
+	err := s.scanDocs(ctx, s.db, query, args, func(doc string) error {
+		var campaign models.GEOCampaign
+		if err := decode(doc, &campaign); err != nil {
+			return err
+		}
+		campaigns = append(campaigns, &campaign)
+		return nil
+	})
+	return campaigns, err
+}
+
+// UpdateCampaign updates an existing campaign
+func (s *DocStore) UpdateCampaign(ctx context.Context, campaign *models.GEOCampaign) error {
+	campaign.UpdatedAt = time.Now()
+
+	if err := updateCampaign(ctx, s.db, campaign); err != nil {
+		return fmt.Errorf("failed to update campaign: %w", err)
+	}
+	return nil
+}
+
+func updateCampaign(ctx context.Context, e execer, campaign *models.GEOCampaign) error {
+	doc, err := encode(campaign)
+	if err != nil {
+		return err
+	}
+
+	result, err := e.ExecContext(ctx, `UPDATE campaigns SET brand = ?, doc = ? WHERE id = ?`,
+		campaign.Brand, doc, campaign.ID)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("campaign not found: %s", campaign.ID)
+	}
+
+	return nil
+}
+
+// IncrementCampaignProgress atomically adds to the completed and failed run counters
+func (s *DocStore) IncrementCampaignProgress(ctx context.Context, id string, completed, failed int) error {
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		campaign, err := getCampaign(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+
+		campaign.CompletedRuns += completed
+		campaign.FailedRuns += failed
+		campaign.UpdatedAt = time.Now()
+
+		if err := updateCampaign(ctx, tx, campaign); err != nil {
+			return fmt.Errorf("failed to update campaign progress: %w", err)
+		}
+		return nil
+	})
+}
+
+// FinishCampaign moves a running campaign to a final status and returns it,
+// or nil when the campaign is not running. Only the status fields are set, so
+// progress counted concurrently is kept.
+func (s *DocStore) FinishCampaign(ctx context.Context, id, status, errMsg string) (*models.GEOCampaign, error) {
+	now := time.Now().Format(time.RFC3339Nano)
+
+	var doc string
+	err := s.db.QueryRowContext(ctx, `
+		UPDATE campaigns SET doc = json_set(doc, '$.status', ?, '$.completedAt', ?, '$.error', ?, '$.updatedAt', ?)
+		WHERE id = ? AND json_extract(doc, '$.status') = ?
+		RETURNING doc`,
+		status, now, errMsg, now, id, models.CampaignStatusRunning).Scan(&doc)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to finish campaign: %w", err)
+	}
+
+	var campaign models.GEOCampaign
+	if err := decode(doc, &campaign); err != nil {
+		return nil, err
+	}
+	return &campaign, nil
+}
+
+// SaveBrandLogo saves or updates a brand logo in the cache
+func (s *DocStore) SaveBrandLogo(ctx context.Context, logo *models.BrandLogoCache) error {
+	logo.UpdatedAt = time.Now()
+
+	doc, err := encode(logo)
+	if err != nil {
+		return err
+	}
+
+	// Upsert - update if exists, insert if not
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO brand_logos (brand_name, doc) VALUES (?, ?)
+		ON CONFLICT(brand_name) DO UPDATE SET doc = excluded.doc`,
+		logo.BrandName, doc)
+	return err
+}
+
+// GetBrandLogo retrieves a cached brand logo by brand name
+func (s *DocStore) GetBrandLogo(ctx context.Context, brandName string) (*models.BrandLogoCache, error) {
+	var doc string
+	err := s.db.QueryRowContext(ctx, `SELECT doc FROM brand_logos WHERE brand_name = ?`, brandName).Scan(&doc)
+	if err == sql.ErrNoRows {
+		return nil, nil // Not found - not an error
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find brand logo: %w", err)
+	}
+
+	var logo models.BrandLogoCache
+	if err := decode(doc, &logo); err != nil {
+		return nil, err
+	}
+	return &logo, nil
+}
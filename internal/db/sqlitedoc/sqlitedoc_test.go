@@ -0,0 +1,275 @@
+package sqlitedoc_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fissionx/gego/internal/db/sqlitedoc"
+	"github.com/fissionx/gego/internal/models"
+	"github.com/fissionx/gego/internal/shared"
+)
+
+func newStore(t *testing.T) *sqlitedoc.DocStore {
+	t.Helper()
+
+	store, err := sqlitedoc.New(&models.Config{Provider: "sqlite", URI: sqlitedoc.MemoryURI})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := store.Connect(context.Background()); err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	t.Cleanup(func() { store.Disconnect(context.Background()) })
+	return store
+}
+
+func TestPrompts(t *testing.T) {
+	ctx := context.Background()
+	store := newStore(t)
+
+	prompt := &models.Prompt{ID: "p1", Template: "best CRM?", PromptType: models.PromptTypeTopBest, Tags: []string{"crm"}, Enabled: true}
+	if err := store.CreatePrompt(ctx, prompt); err != nil {
+		t.Fatalf("CreatePrompt() error = %v", err)
+	}
+	if err := store.CreatePrompt(ctx, &models.Prompt{ID: "p2", Template: "what is GEO?"}); err != nil {
+		t.Fatalf("CreatePrompt() error = %v", err)
+	}
+
+	got, err := store.GetPrompt(ctx, "p1")
+	if err != nil {
+		t.Fatalf("GetPrompt() error = %v", err)
+	}
+	if got.Template != "best CRM?" || got.PromptType != models.PromptTypeTopBest || len(got.Tags) != 1 {
+		t.Errorf("GetPrompt() = %+v", got)
+	}
+
+	enabled := true
+	if prompts, _ := store.ListPrompts(ctx, &enabled); len(prompts) != 1 || prompts[0].ID != "p1" {
+		t.Errorf("ListPrompts(enabled) = %+v, want p1", prompts)
+	}
+
+	got.Enabled = false
+	if err := store.UpdatePrompt(ctx, got); err != nil {
+		t.Fatalf("UpdatePrompt() error = %v", err)
+	}
+	if prompts, _ := store.ListPrompts(ctx, &enabled); len(prompts) != 0 {
+		t.Errorf("ListPrompts(enabled) after disabling = %+v, want none", prompts)
+	}
+
+	if err := store.DeletePrompt(ctx, "p1"); err != nil {
+		t.Fatalf("DeletePrompt() error = %v", err)
+	}
+	if _, err := store.GetPrompt(ctx, "p1"); err == nil {
+		t.Error("GetPrompt() of a deleted prompt succeeded")
+	}
+	if err := store.DeletePrompt(ctx, "p1"); err == nil {
+		t.Error("DeletePrompt() of a deleted prompt succeeded")
+	}
+}
+
+func TestResponseFiltersAndAnalytics(t *testing.T) {
+	ctx := context.Background()
+	store := newStore(t)
+
+	responses := []*models.Response{
+		{ID: "r1", PromptID: "p1", LLMID: "l1", LLMName: "GPT", LLMProvider: "openai", Brand: "HubSpot", ResponseText: "HubSpot leads, then Salesforce.",
+			BrandMentioned: true, VisibilityScore: 8, BrandPosition: 1, Sentiment: "Positive", PromptCategory: "crm",
+			CompetitorsMention: []string{"Salesforce"}, GroundingDomains: []string{"g2.com", "hubspot.com"}},
+		{ID: "r2", PromptID: "p1", LLMID: "l2", LLMName: "Claude", LLMProvider: "anthropic", Brand: "HubSpot", ResponseText: "Salesforce is the leader.",
+			VisibilityScore: 2, Sentiment: "negative", PromptCategory: "crm",
+			CompetitorsMention: []string{"Salesforce"}, GroundingDomains: []string{"g2.com"}},
+		{ID: "r3", PromptID: "p2", LLMID: "l1", LLMName: "GPT", LLMProvider: "openai", Brand: "Zoho", ResponseText: "Zoho is affordable."},
+	}
+	for _, response := range responses {
+		if err := store.CreateResponse(ctx, response); err != nil {
+			t.Fatalf("CreateResponse() error = %v", err)
+		}
+		time.Sleep(time.Millisecond) // Distinct creation times, newest last
+	}
+
+	tests := []struct {
+		name   string
+		filter shared.ResponseFilter
+		want   []string
+	}{
+		{name: "All, newest first", want: []string{"r3", "r2", "r1"}},
+		{name: "Brand", filter: shared.ResponseFilter{Brand: "HubSpot"}, want: []string{"r2", "r1"}},
+		{name: "LLM IDs", filter: shared.ResponseFilter{LLMIDs: []string{"l2"}}, want: []string{"r2"}},
+		{name: "Keyword, case-insensitive", filter: shared.ResponseFilter{Keyword: "salesforce"}, want: []string{"r2", "r1"}},
+		{name: "Keyword paged", filter: shared.ResponseFilter{Keyword: "salesforce", Offset: 1}, want: []string{"r1"}},
+		{name: "Paged", filter: shared.ResponseFilter{Limit: 1, Offset: 1}, want: []string{"r2"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := store.ListResponses(ctx, tt.filter)
+			if err != nil {
+				t.Fatalf("ListResponses() error = %v", err)
+			}
+			var ids []string
+			for _, response := range got {
+				ids = append(ids, response.ID)
+			}
+			if len(ids) != len(tt.want) {
+				t.Fatalf("ListResponses() = %v, want %v", ids, tt.want)
+			}
+			for i := range ids {
+				if ids[i] != tt.want[i] {
+					t.Fatalf("ListResponses() = %v, want %v", ids, tt.want)
+				}
+			}
+		})
+	}
+
+	if count, _ := store.CountResponses(ctx, shared.ResponseFilter{Keyword: "salesforce"}); count != 2 {
+		t.Errorf("CountResponses(keyword) = %d, want 2", count)
+	}
+
+	metrics, err := store.AggregateResponseMetrics(ctx, shared.ResponseFilter{Brand: "HubSpot"}, shared.GroupByLLM)
+	if err != nil {
+		t.Fatalf("AggregateResponseMetrics() error = %v", err)
+	}
+	if len(metrics) != 2 || metrics[0].Key != "anthropic-Claude" || metrics[1].Key != "openai-GPT" {
+		t.Fatalf("AggregateResponseMetrics() = %+v", metrics)
+	}
+	if gpt := metrics[1]; gpt.MentionCount != 1 || gpt.TopPositionCount != 1 || gpt.SentimentScoreSum != 1 || gpt.LLMName != "GPT" {
+		t.Errorf("GPT metrics = %+v", gpt)
+	}
+
+	if categories, _ := store.AggregateResponseMetrics(ctx, shared.ResponseFilter{}, shared.GroupByCategory); len(categories) != 1 || categories[0].TotalResponses != 2 {
+		t.Errorf("metrics by category = %+v, want one crm group without uncategorised responses", categories)
+	}
+
+	if competitors, _ := store.CountCompetitorMentions(ctx, shared.ResponseFilter{Brand: "HubSpot"}); competitors["Salesforce"] != 2 {
+		t.Errorf("CountCompetitorMentions() = %v", competitors)
+	}
+
+	domains, err := store.AggregateSourceDomains(ctx, shared.ResponseFilter{})
+	if err != nil {
+		t.Fatalf("AggregateSourceDomains() error = %v", err)
+	}
+	if len(domains) != 2 || domains[0].Domain != "g2.com" || domains[0].CitationCount != 2 || domains[0].LLMBreakdown["Claude"] != 1 {
+		t.Errorf("AggregateSourceDomains() = %+v", domains)
+	}
+
+	stats, err := store.SearchKeyword(ctx, "Salesforce", nil, nil)
+	if err != nil {
+		t.Fatalf("SearchKeyword() error = %v", err)
+	}
+	if stats.TotalMentions != 2 || stats.UniquePrompts != 1 || stats.UniqueLLMs != 2 {
+		t.Errorf("SearchKeyword() = %+v", stats)
+	}
+}
+
+func TestJobQueue(t *testing.T) {
+	ctx := context.Background()
+	store := newStore(t)
+
+	now := time.Now()
+	jobs := []*models.Job{
+		{ID: "j1", Kind: models.JobKindCampaign, CampaignID: "c1", Status: models.JobStatusPending, MaxAttempts: 3, AvailableAt: now.Add(-time.Minute), CreatedAt: now},
+		{ID: "j2", Kind: models.JobKindCampaign, CampaignID: "c1", Status: models.JobStatusPending, MaxAttempts: 3, AvailableAt: now.Add(-time.Second), CreatedAt: now},
+		{ID: "j3", Kind: models.JobKindSchedule, Status: models.JobStatusPending, MaxAttempts: 3, AvailableAt: now.Add(time.Hour), CreatedAt: now},
+	}
+	if err := store.EnqueueJobs(ctx, jobs); err != nil {
+		t.Fatalf("EnqueueJobs() error = %v", err)
+	}
+
+	job, err := store.LeaseJob(ctx, "worker-1", []string{models.JobKindCampaign}, time.Minute)
+	if err != nil {
+		t.Fatalf("LeaseJob() error = %v", err)
+	}
+	if job == nil || job.ID != "j1" || job.Attempts != 1 || job.LeaseOwner != "worker-1" {
+		t.Fatalf("LeaseJob() = %+v, want j1 leased by worker-1", job)
+	}
+
+	if job, _ := store.LeaseJob(ctx, "worker-2", []string{models.JobKindSchedule}, time.Minute); job != nil {
+		t.Errorf("LeaseJob() = %+v, want nothing available", job)
+	}
+
+	if err := store.FinishJob(ctx, "j1", "worker-2", models.JobStatusDone, ""); err == nil {
+		t.Error("FinishJob() by another worker succeeded")
+	}
+	if err := store.FinishJob(ctx, "j1", "worker-1", models.JobStatusDone, ""); err != nil {
+		t.Fatalf("FinishJob() error = %v", err)
+	}
+
+	if cancelled, err := store.CancelCampaignJobs(ctx, "c1"); err != nil || cancelled != 1 {
+		t.Errorf("CancelCampaignJobs() = %d, %v, want 1", cancelled, err)
+	}
+
+	done, err := store.ListJobs(ctx, models.JobStatusDone, 0)
+	if err != nil {
+		t.Fatalf("ListJobs() error = %v", err)
+	}
+	if len(done) != 1 || done[0].CompletedAt == nil || done[0].LeaseOwner != "" {
+		t.Errorf("ListJobs(done) = %+v", done)
+	}
+}
+
+func TestCampaignProgress(t *testing.T) {
+	ctx := context.Background()
+	store := newStore(t)
+
+	if err := store.CreateCampaign(ctx, &models.GEOCampaign{ID: "c1", Brand: "HubSpot", TotalRuns: 4}); err != nil {
+		t.Fatalf("CreateCampaign() error = %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		if err := store.IncrementCampaignProgress(ctx, "c1", 1, i%2); err != nil {
+			t.Fatalf("IncrementCampaignProgress() error = %v", err)
+		}
+	}
+
+	campaign, err := store.GetCampaign(ctx, "c1")
+	if err != nil {
+		t.Fatalf("GetCampaign() error = %v", err)
+	}
+	if campaign.CompletedRuns != 3 || campaign.FailedRuns != 1 {
+		t.Errorf("progress = %d completed, %d failed, want 3 and 1", campaign.CompletedRuns, campaign.FailedRuns)
+	}
+
+	if err := store.IncrementCampaignProgress(ctx, "missing", 1, 0); err == nil {
+		t.Error("IncrementCampaignProgress() of a missing campaign succeeded")
+	}
+}
+
+func TestSplitResponseAnalysisOnConnect(t *testing.T) {
+	ctx := context.Background()
+	config := &models.Config{Provider: "sqlite", URI: t.TempDir() + "/gego.db"}
+
+	store, _ := sqlitedoc.New(config)
+	if err := store.Connect(ctx); err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	combined := `{"search_answer":"Acme and Rival lead the CRM market.","geo_analysis":{"visibility_score":7,"brand_mentioned":true,"competitors":["Rival"]}}`
+	for _, response := range []*models.Response{
+		{ID: "google", PromptID: "p1", LLMID: "l1", Brand: "Acme", ResponseText: combined},
+		{ID: "judged", PromptID: "p1", LLMID: "l1", Brand: "Acme", ResponseText: `Use {"geo_analysis": true} in your config.`},
+	} {
+		if err := store.CreateResponse(ctx, response); err != nil {
+			t.Fatalf("CreateResponse() error = %v", err)
+		}
+	}
+	store.Disconnect(ctx)
+
+	store, _ = sqlitedoc.New(config)
+	if err := store.Connect(ctx); err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	defer store.Disconnect(ctx)
+
+	got, err := store.GetResponse(ctx, "google")
+	if err != nil {
+		t.Fatalf("GetResponse() error = %v", err)
+	}
+	if got.ResponseText != "Acme and Rival lead the CRM market." || got.AnalysisRaw != combined {
+		t.Errorf("split response = %q, raw %q", got.ResponseText, got.AnalysisRaw)
+	}
+	if got.Analysis == nil || got.Analysis.VisibilityScore != 7 || len(got.Analysis.Competitors) != 1 {
+		t.Errorf("split analysis = %+v", got.Analysis)
+	}
+
+	if got, _ := store.GetResponse(ctx, "judged"); got.AnalysisRaw != "" || got.Analysis != nil {
+		t.Errorf("plain answer was split: %+v", got)
+	}
+}
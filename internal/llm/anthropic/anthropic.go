@@ -1,12 +1,14 @@
 package anthropic
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/fissionx/gego/internal/llm"
@@ -52,6 +54,148 @@ func (p *Provider) Validate(config map[string]string) error {
 func (p *Provider) Generate(ctx context.Context, prompt string, config llm.Config) (*llm.Response, error) {
 	startTime := time.Now()
 
+	req, err := p.newMessagesRequest(ctx, prompt, config, false)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("API error (HTTP %d): %s", resp.StatusCode, string(body))
+	}
+
+	var anthropicResp struct {
+		Content []contentBlock `json:"content"`
+		Usage   struct {
+			InputTokens  int `json:"input_tokens"`
+			OutputTokens int `json:"output_tokens"`
+		} `json:"usage"`
+		Model string `json:"model"`
+	}
+
+	if err := json.Unmarshal(body, &anthropicResp); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	if len(anthropicResp.Content) == 0 {
+		return nil, fmt.Errorf("no content returned from API")
+	}
+
+	text, citations := joinContent(anthropicResp.Content)
+	totalTokens := anthropicResp.Usage.InputTokens + anthropicResp.Usage.OutputTokens
+
+	return &llm.Response{
+		Text:             text,
+		TokensUsed:       totalTokens,
+		LatencyMs:        time.Since(startTime).Milliseconds(),
+		Model:            anthropicResp.Model,
+		Provider:         "anthropic",
+		GroundingSources: llm.CitationSources(citations),
+		Citations:        citations,
+	}, nil
+}
+
+// GenerateStream streams a message from Anthropic, calling onChunk with each text delta
+func (p *Provider) GenerateStream(ctx context.Context, prompt string, config llm.Config, onChunk func(chunk string) error) (*llm.Response, error) {
+	startTime := time.Now()
+
+	req, err := p.newMessagesRequest(ctx, prompt, config, true)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("API error (HTTP %d): %s", resp.StatusCode, string(body))
+	}
+
+	var (
+		model        string
+		inputTokens  int
+		outputTokens int
+		blocks       []contentBlock
+	)
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data: ")
+		if !ok {
+			continue
+		}
+
+		var event streamEvent
+		if err := json.Unmarshal([]byte(data), &event); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal stream event: %w", err)
+		}
+
+		switch event.Type {
+		case "message_start":
+			model = event.Message.Model
+			inputTokens = event.Message.Usage.InputTokens
+		case "content_block_start":
+			for len(blocks) <= event.Index {
+				blocks = append(blocks, contentBlock{})
+			}
+			blocks[event.Index].Type = event.ContentBlock.Type
+		case "content_block_delta":
+			if event.Index >= len(blocks) {
+				continue
+			}
+			switch event.Delta.Type {
+			case "text_delta":
+				blocks[event.Index].Text += event.Delta.Text
+				if err := onChunk(event.Delta.Text); err != nil {
+					return nil, err
+				}
+			case "citations_delta":
+				blocks[event.Index].Citations = append(blocks[event.Index].Citations, event.Delta.Citation)
+			}
+		case "message_delta":
+			outputTokens = event.Usage.OutputTokens
+		case "error":
+			return nil, fmt.Errorf("API error: %s", event.Error.Message)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read stream: %w", err)
+	}
+
+	if len(blocks) == 0 {
+		return nil, fmt.Errorf("no content returned from API")
+	}
+
+	text, citations := joinContent(blocks)
+
+	return &llm.Response{
+		Text:             text,
+		TokensUsed:       inputTokens + outputTokens,
+		LatencyMs:        time.Since(startTime).Milliseconds(),
+		Model:            model,
+		Provider:         "anthropic",
+		GroundingSources: llm.CitationSources(citations),
+		Citations:        citations,
+	}, nil
+}
+
+// newMessagesRequest builds a Messages API request for a prompt
+func (p *Provider) newMessagesRequest(ctx context.Context, prompt string, config llm.Config, stream bool) (*http.Request, error) {
 	model := "claude-3-7-sonnet-20250219"
 	if config.Model != "" {
 		model = config.Model
@@ -72,6 +216,20 @@ func (p *Provider) Generate(ctx context.Context, prompt string, config llm.Confi
 		"max_tokens":  maxTokens,
 	}
 
+	if stream {
+		requestBody["stream"] = true
+	}
+
+	if config.WebSearch {
+		requestBody["tools"] = []map[string]interface{}{
+			{
+				"type":     "web_search_20250305",
+				"name":     "web_search",
+				"max_uses": 5,
+			},
+		}
+	}
+
 	jsonBody, err := json.Marshal(requestBody)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
@@ -86,49 +244,79 @@ func (p *Provider) Generate(ctx context.Context, prompt string, config llm.Confi
 	req.Header.Set("x-api-key", p.apiKey)
 	req.Header.Set("anthropic-version", "2023-06-01")
 
-	resp, err := p.client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %w", err)
-	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
-	}
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API error (HTTP %d): %s", resp.StatusCode, string(body))
-	}
+	return req, nil
+}
 
-	var anthropicResp struct {
-		Content []struct {
-			Text string `json:"text"`
-		} `json:"content"`
+// streamEvent is a server-sent event of a streamed Messages API response
+type streamEvent struct {
+	Type    string `json:"type"`
+	Index   int    `json:"index"`
+	Message struct {
+		Model string `json:"model"`
 		Usage struct {
-			InputTokens  int `json:"input_tokens"`
-			OutputTokens int `json:"output_tokens"`
+			InputTokens int `json:"input_tokens"`
 		} `json:"usage"`
-		Model string `json:"model"`
-	}
+	} `json:"message"`
+	ContentBlock struct {
+		Type string `json:"type"`
+	} `json:"content_block"`
+	Delta struct {
+		Type     string   `json:"type"`
+		Text     string   `json:"text"`
+		Citation citation `json:"citation"`
+	} `json:"delta"`
+	Usage struct {
+		OutputTokens int `json:"output_tokens"`
+	} `json:"usage"`
+	Error struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
 
-	if err := json.Unmarshal(body, &anthropicResp); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
-	}
+// citation is a citation attached to a text block
+type citation struct {
+	Type  string `json:"type"`
+	URL   string `json:"url"`
+	Title string `json:"title"`
+}
 
-	if len(anthropicResp.Content) == 0 {
-		return nil, fmt.Errorf("no content returned from API")
-	}
+// contentBlock is a block of a Messages API response. With web search enabled the
+// answer is split into text blocks interleaved with tool use and search result blocks.
+type contentBlock struct {
+	Type      string     `json:"type"`
+	Text      string     `json:"text"`
+	Citations []citation `json:"citations"`
+}
 
-	totalTokens := anthropicResp.Usage.InputTokens + anthropicResp.Usage.OutputTokens
+// joinContent concatenates the text blocks of a response and collects the web
+// search citations attached to them. Each citation spans the text block it backs.
+func joinContent(blocks []contentBlock) (string, []models.Citation) {
+	var text strings.Builder
+	var citations []models.Citation
 
-	return &llm.Response{
-		Text:       anthropicResp.Content[0].Text,
-		TokensUsed: totalTokens,
-		LatencyMs:  time.Since(startTime).Milliseconds(),
-		Model:      anthropicResp.Model,
-		Provider:   "anthropic",
-	}, nil
+	for _, block := range blocks {
+		if block.Type != "text" {
+			continue
+		}
+
+		start := text.Len()
+		text.WriteString(block.Text)
+
+		for _, c := range block.Citations {
+			if c.Type != "web_search_result_location" || c.URL == "" {
+				continue
+			}
+			citations = append(citations, models.Citation{
+				URL:        c.URL,
+				Title:      c.Title,
+				CitedText:  block.Text,
+				StartIndex: start,
+				EndIndex:   text.Len(),
+			})
+		}
+	}
+
+	return text.String(), citations
 }
 
 // ListModels lists available text-to-text models from Anthropic
@@ -0,0 +1,61 @@
+package anthropic
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+
+	"github.com/fissionx/gego/internal/models"
+)
+
+func TestJoinContent(t *testing.T) {
+	tests := []struct {
+		name     string
+		payload  string
+		wantText string
+		want     []models.Citation
+	}{
+		{
+			name: "web search citations span their text block",
+			payload: `[
+				{"type": "text", "text": "Top CRMs: "},
+				{"type": "server_tool_use", "id": "srvtoolu_1", "name": "web_search", "input": {"query": "best CRM"}},
+				{"type": "web_search_tool_result", "tool_use_id": "srvtoolu_1", "content": []},
+				{"type": "text", "text": "HubSpot is popular.", "citations": [
+					{"type": "web_search_result_location", "url": "https://www.hubspot.com/", "title": "HubSpot", "cited_text": "HubSpot is a CRM platform", "encrypted_index": "x"},
+					{"type": "web_search_result_location", "url": "https://www.g2.com/categories/crm", "title": "G2", "cited_text": "Top rated CRM", "encrypted_index": "y"}
+				]},
+				{"type": "text", "text": " Salesforce too.", "citations": [
+					{"type": "char_location", "cited_text": "Salesforce", "document_index": 0}
+				]}
+			]`,
+			wantText: "Top CRMs: HubSpot is popular. Salesforce too.",
+			want: []models.Citation{
+				{URL: "https://www.hubspot.com/", Title: "HubSpot", CitedText: "HubSpot is popular.", StartIndex: 10, EndIndex: 29},
+				{URL: "https://www.g2.com/categories/crm", Title: "G2", CitedText: "HubSpot is popular.", StartIndex: 10, EndIndex: 29},
+			},
+		},
+		{
+			name:     "answer without web search",
+			payload:  `[{"type": "text", "text": "HubSpot and Salesforce."}]`,
+			wantText: "HubSpot and Salesforce.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var blocks []contentBlock
+			if err := json.Unmarshal([]byte(tt.payload), &blocks); err != nil {
+				t.Fatalf("failed to decode payload: %v", err)
+			}
+
+			text, citations := joinContent(blocks)
+			if text != tt.wantText {
+				t.Errorf("joinContent() text = %q, want %q", text, tt.wantText)
+			}
+			if !slices.Equal(citations, tt.want) {
+				t.Errorf("joinContent() citations =\n%+v\nwant\n%+v", citations, tt.want)
+			}
+		})
+	}
+}
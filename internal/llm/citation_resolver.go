@@ -0,0 +1,151 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// DefaultRedirectHosts are the hosts of the redirect links that grounded
+// answers cite in place of the publisher URL
+var DefaultRedirectHosts = []string{"vertexaisearch.cloud.google.com"}
+
+const (
+	maxCitationRedirects = 5
+	maxCachedCitations   = 10000
+)
+
+// CitationResolver resolves the redirect links an engine cites to the
+// publisher URLs behind them. Resolved links are cached, since answers to
+// similar prompts cite the same pages, and lookups are rate limited.
+type CitationResolver struct {
+	client        *http.Client
+	limiter       *rate.Limiter
+	redirectHosts []string
+
+	mu    sync.Mutex
+	cache map[string]string
+}
+
+var (
+	sharedResolver     *CitationResolver
+	sharedResolverOnce sync.Once
+)
+
+// SharedCitationResolver returns the process-wide resolver of DefaultRedirectHosts
+func SharedCitationResolver() *CitationResolver {
+	sharedResolverOnce.Do(func() {
+		sharedResolver = NewCitationResolver(DefaultRedirectHosts, 5)
+	})
+	return sharedResolver
+}
+
+// NewCitationResolver creates a resolver for links on the given redirect hosts
+// (host or host:port), making at most requestsPerSecond lookups
+func NewCitationResolver(redirectHosts []string, requestsPerSecond float64) *CitationResolver {
+	r := &CitationResolver{
+		limiter:       rate.NewLimiter(rate.Limit(requestsPerSecond), 5),
+		redirectHosts: redirectHosts,
+		cache:         make(map[string]string),
+	}
+	r.client = &http.Client{
+		Timeout: 10 * time.Second,
+		// Follow redirects until they leave the redirect hosts: the publisher
+		// page itself is never fetched
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if !r.isRedirect(req.URL) {
+				return http.ErrUseLastResponse
+			}
+			if len(via) >= maxCitationRedirects {
+				return fmt.Errorf("stopped after %d redirects", maxCitationRedirects)
+			}
+			return nil
+		},
+	}
+	return r
+}
+
+// isRedirect reports whether a link is on one of the redirect hosts
+func (r *CitationResolver) isRedirect(u *url.URL) bool {
+	for _, host := range r.redirectHosts {
+		if strings.EqualFold(u.Host, host) {
+			return true
+		}
+	}
+	return false
+}
+
+// Resolve returns the publisher URL behind a redirect link. Links on other
+// hosts are returned unchanged, without a request.
+func (r *CitationResolver) Resolve(ctx context.Context, link string) (string, error) {
+	u, err := url.Parse(link)
+	if err != nil || !r.isRedirect(u) {
+		return link, nil
+	}
+
+	r.mu.Lock()
+	canonical, ok := r.cache[link]
+	r.mu.Unlock()
+	if ok {
+		return canonical, nil
+	}
+
+	if err := r.limiter.Wait(ctx); err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, link, nil)
+	if err != nil {
+		return "", fmt.Errorf("invalid citation link: %w", err)
+	}
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s: %w", link, err)
+	}
+	resp.Body.Close()
+
+	location, err := resp.Location()
+	if err != nil {
+		return "", fmt.Errorf("%s did not redirect (status %d)", link, resp.StatusCode)
+	}
+	canonical = location.String()
+
+	r.mu.Lock()
+	if len(r.cache) >= maxCachedCitations {
+		r.cache = make(map[string]string)
+	}
+	r.cache[link] = canonical
+	r.mu.Unlock()
+
+	return canonical, nil
+}
+
+// ResolveAll resolves the given links and returns the publisher URL of each
+// redirect link that could be resolved. Failures are logged and left out.
+func (r *CitationResolver) ResolveAll(ctx context.Context, links []string) map[string]string {
+	resolved := make(map[string]string)
+	seen := make(map[string]bool)
+	for _, link := range links {
+		if link == "" || seen[link] {
+			continue
+		}
+		seen[link] = true
+
+		canonical, err := r.Resolve(ctx, link)
+		if err != nil {
+			log.Printf("Citation not resolved: %v", err)
+			continue
+		}
+		if canonical != link {
+			resolved[link] = canonical
+		}
+	}
+	return resolved
+}
@@ -0,0 +1,61 @@
+package llm
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestCitationResolver(t *testing.T) {
+	publisher := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("publisher page %s was fetched", r.URL)
+	}))
+	defer publisher.Close()
+
+	var hits atomic.Int32
+	redirector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		switch r.URL.Path {
+		case "/grounding-api-redirect/abc":
+			http.Redirect(w, r, "/grounding-api-redirect/hop", http.StatusFound)
+		case "/grounding-api-redirect/hop":
+			http.Redirect(w, r, publisher.URL+"/best-crm", http.StatusFound)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer redirector.Close()
+
+	ctx := context.Background()
+	resolver := NewCitationResolver([]string{strings.TrimPrefix(redirector.URL, "http://")}, 100)
+
+	link := redirector.URL + "/grounding-api-redirect/abc"
+	canonical, err := resolver.Resolve(ctx, link)
+	if err != nil || canonical != publisher.URL+"/best-crm" {
+		t.Fatalf("Resolve() = %q, %v", canonical, err)
+	}
+	if got := hits.Load(); got != 2 {
+		t.Errorf("redirector hits = %d, want 2", got)
+	}
+
+	if cached, _ := resolver.Resolve(ctx, link); cached != canonical || hits.Load() != 2 {
+		t.Errorf("cached Resolve() = %q after %d hits", cached, hits.Load())
+	}
+
+	if got, err := resolver.Resolve(ctx, "https://g2.com/crm"); err != nil || got != "https://g2.com/crm" {
+		t.Errorf("Resolve(publisher link) = %q, %v", got, err)
+	}
+
+	missing := redirector.URL + "/grounding-api-redirect/gone"
+	if _, err := resolver.Resolve(ctx, missing); err == nil {
+		t.Error("Resolve() of a link that does not redirect succeeded")
+	}
+
+	resolved := resolver.ResolveAll(ctx, []string{link, missing, missing, "https://g2.com/crm"})
+	if len(resolved) != 1 || resolved[link] != canonical {
+		t.Errorf("ResolveAll() = %v", resolved)
+	}
+}
@@ -0,0 +1,157 @@
+package llm
+
+import (
+	"fmt"
+	"maps"
+	"sort"
+	"sync"
+
+	"github.com/fissionx/gego/internal/models"
+	"github.com/fissionx/gego/internal/secrets"
+)
+
+// Constructor builds a provider for the given credentials and config options
+// (the Config map of an LLM configuration)
+type Constructor func(apiKey, baseURL string, options map[string]string) Provider
+
+// Factory resolves LLM configurations to provider instances. Providers are
+// built with the credentials of their configuration and cached by config ID,
+// so two configs of the same provider with different API keys, base URLs or
+// options never share an instance.
+type Factory struct {
+	constructors map[string]Constructor
+	instances    map[string]*cachedProvider
+	mu           sync.Mutex
+}
+
+// cachedProvider is a provider built for a config, with the settings it was built from
+type cachedProvider struct {
+	provider Provider
+	name     string
+	apiKey   string
+	baseURL  string
+	options  map[string]string
+}
+
+// NewFactory creates a new provider factory
+func NewFactory() *Factory {
+	return &Factory{
+		constructors: make(map[string]Constructor),
+		instances:    make(map[string]*cachedProvider),
+	}
+}
+
+// RegisterConstructor registers the constructor for a provider name
+func (f *Factory) RegisterConstructor(name string, constructor Constructor) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.constructors[name] = constructor
+}
+
+// New builds an uncached provider for the given credentials and options. The
+// API key and option values may be env:VAR_NAME or file:/path references.
+func (f *Factory) New(name, apiKey, baseURL string, options map[string]string) (Provider, error) {
+	f.mu.Lock()
+	constructor, ok := f.constructors[name]
+	f.mu.Unlock()
+
+	if !ok {
+		return nil, fmt.Errorf("unknown LLM provider: %s", name)
+	}
+
+	apiKey, err := secrets.Resolve(apiKey)
+	if err != nil {
+		return nil, err
+	}
+	options, err = resolveOptions(options)
+	if err != nil {
+		return nil, err
+	}
+	return constructor(apiKey, baseURL, options), nil
+}
+
+// Get returns the provider for an LLM configuration. The instance is cached by
+// config ID and rebuilt when the provider, API key, base URL or options change. API key
+// and option references are resolved on every call, so a changed environment
+// variable or key file is picked up.
+func (f *Factory) Get(config *models.LLMConfig) (Provider, error) {
+	if config == nil {
+		return nil, fmt.Errorf("LLM config is required")
+	}
+	if config.KeyError != "" {
+		return nil, fmt.Errorf("LLM %s is unusable: %s", config.Name, config.KeyError)
+	}
+	if config.ID == "" {
+		return f.New(config.Provider, config.APIKey, config.BaseURL, config.Config)
+	}
+
+	apiKey, err := secrets.Resolve(config.APIKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve API key of LLM %s: %w", config.Name, err)
+	}
+	options, err := resolveOptions(config.Config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve options of LLM %s: %w", config.Name, err)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if cached, ok := f.instances[config.ID]; ok &&
+		cached.name == config.Provider && cached.apiKey == apiKey && cached.baseURL == config.BaseURL &&
+		maps.Equal(cached.options, options) {
+		return cached.provider, nil
+	}
+
+	constructor, ok := f.constructors[config.Provider]
+	if !ok {
+		return nil, fmt.Errorf("unknown LLM provider: %s", config.Provider)
+	}
+
+	provider := constructor(apiKey, config.BaseURL, options)
+	f.instances[config.ID] = &cachedProvider{
+		provider: provider,
+		name:     config.Provider,
+		apiKey:   apiKey,
+		baseURL:  config.BaseURL,
+		options:  options,
+	}
+	return provider, nil
+}
+
+// resolveOptions returns the options with their env:/file: references
+// resolved, so that secret options such as request headers need not be stored
+func resolveOptions(options map[string]string) (map[string]string, error) {
+	resolved := maps.Clone(options)
+	for key, value := range resolved {
+		if !secrets.IsReference(value) {
+			continue
+		}
+		value, err := secrets.Resolve(value)
+		if err != nil {
+			return nil, fmt.Errorf("option %s: %w", key, err)
+		}
+		resolved[key] = value
+	}
+	return resolved, nil
+}
+
+// Invalidate drops the cached provider of a config
+func (f *Factory) Invalidate(configID string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.instances, configID)
+}
+
+// List returns all registered provider names
+func (f *Factory) List() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	names := make([]string, 0, len(f.constructors))
+	for name := range f.constructors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,62 @@
+package llm
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/fissionx/gego/internal/models"
+)
+
+// optionsProvider records the credentials and options it was built with
+type optionsProvider struct {
+	apiKey  string
+	options map[string]string
+}
+
+func (p *optionsProvider) Name() string                            { return "fake" }
+func (p *optionsProvider) Validate(config map[string]string) error { return nil }
+func (p *optionsProvider) Generate(ctx context.Context, prompt string, config Config) (*Response, error) {
+	return &Response{}, nil
+}
+func (p *optionsProvider) ListModels(ctx context.Context, apiKey, baseURL string) ([]models.ModelInfo, error) {
+	return nil, nil
+}
+
+func TestFactoryResolvesOptionReferences(t *testing.T) {
+	factory := NewFactory()
+	factory.RegisterConstructor("fake", func(apiKey, baseURL string, options map[string]string) Provider {
+		return &optionsProvider{apiKey: apiKey, options: options}
+	})
+
+	t.Setenv("GATEWAY_KEY", "gw-secret")
+	config := &models.LLMConfig{ID: "gateway", Name: "Gateway", Provider: "fake", APIKey: "env:GATEWAY_KEY",
+		Config: map[string]string{"header.X-Api-Key": "env:GATEWAY_KEY", "header.X-Title": "gego"}}
+
+	provider, err := factory.Get(config)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	built := provider.(*optionsProvider)
+	if built.apiKey != "gw-secret" || built.options["header.X-Api-Key"] != "gw-secret" || built.options["header.X-Title"] != "gego" {
+		t.Errorf("provider built with key %q and options %v", built.apiKey, built.options)
+	}
+	if config.Config["header.X-Api-Key"] != "env:GATEWAY_KEY" {
+		t.Errorf("Get() changed the config options: %v", config.Config)
+	}
+
+	// A changed reference rebuilds the provider
+	t.Setenv("GATEWAY_KEY", "gw-rotated")
+	provider, err = factory.Get(config)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if built := provider.(*optionsProvider); built.options["header.X-Api-Key"] != "gw-rotated" {
+		t.Errorf("provider options after rotation = %v", built.options)
+	}
+
+	config.Config["header.X-Api-Key"] = "env:GEGO_TEST_UNSET"
+	if _, err := factory.Get(config); err == nil || !strings.Contains(err.Error(), "header.X-Api-Key") {
+		t.Errorf("Get() with an unset reference = %v, want an error naming the option", err)
+	}
+}
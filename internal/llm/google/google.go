@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"net/url"
 	"strings"
 	"time"
 
@@ -16,13 +17,15 @@ import (
 
 // Provider implements the LLM Provider interface for Google AI
 type Provider struct {
-	apiKey  string
-	baseURL string
-	client  *genai.Client
+	apiKey   string
+	baseURL  string
+	client   *genai.Client
+	resolver *llm.CitationResolver
 }
 
-// New creates a new Google provider
-func New(apiKey, baseURL string) *Provider {
+// New creates a new Google provider. resolver resolves the redirect links of
+// grounding chunks to publisher URLs.
+func New(apiKey, baseURL string, resolver *llm.CitationResolver) *Provider {
 	client, err := genai.NewClient(context.Background(), &genai.ClientConfig{
 		APIKey:  apiKey,
 		Backend: genai.BackendGeminiAPI,
@@ -32,9 +35,10 @@ func New(apiKey, baseURL string) *Provider {
 	}
 
 	return &Provider{
-		apiKey:  apiKey,
-		baseURL: baseURL,
-		client:  client,
+		apiKey:   apiKey,
+		baseURL:  baseURL,
+		client:   client,
+		resolver: resolver,
 	}
 }
 
@@ -60,16 +64,9 @@ func (p *Provider) Generate(ctx context.Context, prompt string, config llm.Confi
 		model = config.Model
 	}
 
-	client := p.client
-	if client == nil {
-		var err error
-		client, err = genai.NewClient(ctx, &genai.ClientConfig{
-			APIKey:  p.apiKey,
-			Backend: genai.BackendGeminiAPI,
-		})
-		if err != nil {
-			return nil, fmt.Errorf("failed to create Google client: %w", err)
-		}
+	client, err := p.getClient(ctx)
+	if err != nil {
+		return nil, err
 	}
 
 	// Step 1: Get search results with Google Search tool
@@ -81,19 +78,7 @@ func (p *Provider) Generate(ctx context.Context, prompt string, config llm.Confi
 		},
 	}
 
-	searchConfig := &genai.GenerateContentConfig{
-		Temperature: float32Ptr(float32(config.Temperature)),
-		TopP:        float32Ptr(float32(config.TopP)),
-		TopK:        float32Ptr(float32(config.TopK)),
-		// Enable Google Search tool for web search results
-		Tools: []*genai.Tool{
-			{
-				GoogleSearch: &genai.GoogleSearch{},
-			},
-		},
-	}
-
-	result, err := client.Models.GenerateContent(ctx, model, content, searchConfig)
+	result, err := client.Models.GenerateContent(ctx, model, content, searchContentConfig(config))
 	if err != nil {
 		return nil, fmt.Errorf("Google AI API error: %v", err)
 	}
@@ -112,33 +97,10 @@ func (p *Provider) Generate(ctx context.Context, prompt string, config llm.Confi
 
 	// Extract grounding metadata (sources/URLs)
 	var groundingSources []string
+	var citations []models.Citation
 	if len(result.Candidates) > 0 && result.Candidates[0].GroundingMetadata != nil {
-		metadata := result.Candidates[0].GroundingMetadata
-
-		if len(metadata.WebSearchQueries) > 0 {
-			log.Printf("Web Search Queries: %v", metadata.WebSearchQueries)
-		}
-
-		if len(metadata.GroundingChunks) > 0 {
-			log.Printf("Found %d grounding chunks", len(metadata.GroundingChunks))
-			for i, chunk := range metadata.GroundingChunks {
-				if chunk.Web != nil && chunk.Web.Title != "" {
-					// Use Title (actual source domain) - skip if not available
-					// Title contains the real source (e.g., "forbes.com", "reddit.com")
-					// URI contains redirect URLs (vertexaisearch.cloud.google.com/...)
-					source := chunk.Web.Title
-					groundingSources = append(groundingSources, source)
-					log.Printf("  Chunk %d: %s (Source: %s)", i+1, chunk.Web.URI, source)
-				} else if chunk.Web != nil {
-					log.Printf("  Chunk %d: %s (Source: SKIPPED - no title)", i+1, chunk.Web.URI)
-				}
-			}
-		}
-
-		if len(groundingSources) > 0 {
-			log.Printf("Total unique sources: %d", len(groundingSources))
-		}
-	} else {
+		groundingSources, citations = p.extractGrounding(ctx, result.Candidates[0].GroundingMetadata)
+	} else if config.WebSearch {
 		log.Printf("No grounding metadata found in response")
 	}
 
@@ -155,6 +117,10 @@ func (p *Provider) Generate(ctx context.Context, prompt string, config llm.Confi
 			LatencyMs:  time.Since(startTime).Milliseconds(),
 			Model:      model,
 			Provider:   "google",
+			// Sources are the publisher URLs, or the chunk titles (domains) when a
+			// redirect link could not be resolved
+			GroundingSources: groundingSources,
+			Citations:        citations,
 		}, nil
 	}
 
@@ -170,8 +136,8 @@ func (p *Provider) Generate(ctx context.Context, prompt string, config llm.Confi
 		brandDomain := strings.ReplaceAll(brandLower, " ", "")
 
 		for _, source := range groundingSources {
-			sourceLower := strings.ToLower(source)
-			// Check if the source URL contains the brand name
+			sourceLower := strings.ToLower(sourceHost(source))
+			// Check if the source domain contains the brand name
 			if strings.Contains(sourceLower, brandDomain) ||
 				strings.Contains(sourceLower, strings.ReplaceAll(brandLower, ".", "")) {
 				brandInSources = true
@@ -252,6 +218,7 @@ RESPOND WITH ONLY THE JSON OBJECT, NO OTHER TEXT.`, config.Brand, prompt, search
 			Model:            model,
 			Provider:         "google",
 			GroundingSources: groundingSources,
+			Citations:        citations,
 		}, nil
 	}
 
@@ -271,17 +238,211 @@ RESPOND WITH ONLY THE JSON OBJECT, NO OTHER TEXT.`, config.Brand, prompt, search
 		totalTokens += int(geoResult.UsageMetadata.TotalTokenCount)
 	}
 
-	// Return the GEO JSON response
+	// Return the answer with the GEO JSON next to it
+	return &llm.Response{
+		Text:             searchAnswer,
+		Analysis:         geoText,
+		TokensUsed:       totalTokens,
+		LatencyMs:        time.Since(startTime).Milliseconds(),
+		Model:            model,
+		Provider:         "google",
+		GroundingSources: groundingSources,
+		Citations:        citations,
+	}, nil
+}
+
+// GenerateStream streams the answer from Google AI, calling onChunk with each piece
+// of text. Unlike Generate, it never runs the built-in GEO analysis call.
+func (p *Provider) GenerateStream(ctx context.Context, prompt string, config llm.Config, onChunk func(chunk string) error) (*llm.Response, error) {
+	startTime := time.Now()
+
+	model := "gemini-2.5-flash"
+	if config.Model != "" {
+		model = config.Model
+	}
+
+	client, err := p.getClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	content := []*genai.Content{
+		{
+			Parts: []*genai.Part{
+				{Text: prompt},
+			},
+		},
+	}
+
+	var text strings.Builder
+	var metadata *genai.GroundingMetadata
+	totalTokens := 0
+
+	for result, err := range client.Models.GenerateContentStream(ctx, model, content, searchContentConfig(config)) {
+		if err != nil {
+			return nil, fmt.Errorf("Google AI API error: %v", err)
+		}
+
+		if len(result.Candidates) > 0 && result.Candidates[0].Content != nil {
+			for _, part := range result.Candidates[0].Content.Parts {
+				if part.Text == "" || part.Thought {
+					continue
+				}
+				text.WriteString(part.Text)
+				if err := onChunk(part.Text); err != nil {
+					return nil, err
+				}
+			}
+			// Grounding metadata is sent with the final chunks
+			if result.Candidates[0].GroundingMetadata != nil {
+				metadata = result.Candidates[0].GroundingMetadata
+			}
+		}
+		if result.UsageMetadata != nil {
+			totalTokens = int(result.UsageMetadata.TotalTokenCount)
+		}
+	}
+
+	var groundingSources []string
+	var citations []models.Citation
+	if metadata != nil {
+		groundingSources, citations = p.extractGrounding(ctx, metadata)
+	}
+
 	return &llm.Response{
-		Text:             geoText,
+		Text:             text.String(),
 		TokensUsed:       totalTokens,
 		LatencyMs:        time.Since(startTime).Milliseconds(),
 		Model:            model,
 		Provider:         "google",
 		GroundingSources: groundingSources,
+		Citations:        citations,
 	}, nil
 }
 
+// getClient returns the provider's client, creating one if it could not be built up front
+func (p *Provider) getClient(ctx context.Context) (*genai.Client, error) {
+	if p.client != nil {
+		return p.client, nil
+	}
+
+	client, err := genai.NewClient(ctx, &genai.ClientConfig{
+		APIKey:  p.apiKey,
+		Backend: genai.BackendGeminiAPI,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Google client: %w", err)
+	}
+	return client, nil
+}
+
+// searchContentConfig builds the generation config of the answer call
+func searchContentConfig(config llm.Config) *genai.GenerateContentConfig {
+	searchConfig := &genai.GenerateContentConfig{
+		Temperature: float32Ptr(float32(config.Temperature)),
+		TopP:        float32Ptr(float32(config.TopP)),
+		TopK:        float32Ptr(float32(config.TopK)),
+	}
+	if config.WebSearch {
+		// Enable Google Search tool for web search results
+		searchConfig.Tools = []*genai.Tool{
+			{
+				GoogleSearch: &genai.GoogleSearch{},
+			},
+		}
+	}
+	return searchConfig
+}
+
+// extractGrounding returns the grounding sources and citations of an answer,
+// with the redirect URIs of its web chunks resolved to the publisher URLs
+func (p *Provider) extractGrounding(ctx context.Context, metadata *genai.GroundingMetadata) ([]string, []models.Citation) {
+	var links []string
+	for _, chunk := range metadata.GroundingChunks {
+		if chunk != nil && chunk.Web != nil {
+			links = append(links, chunk.Web.URI)
+		}
+	}
+	resolved := p.resolver.ResolveAll(ctx, links)
+
+	return extractGroundingSources(metadata, resolved), extractCitations(metadata, resolved)
+}
+
+// extractGroundingSources returns the source of each web grounding chunk: its
+// resolved publisher URL, else its title
+func extractGroundingSources(metadata *genai.GroundingMetadata, resolved map[string]string) []string {
+	var groundingSources []string
+
+	if len(metadata.WebSearchQueries) > 0 {
+		log.Printf("Web Search Queries: %v", metadata.WebSearchQueries)
+	}
+
+	if len(metadata.GroundingChunks) > 0 {
+		log.Printf("Found %d grounding chunks", len(metadata.GroundingChunks))
+		for i, chunk := range metadata.GroundingChunks {
+			if chunk == nil || chunk.Web == nil {
+				continue
+			}
+			if canonical := resolved[chunk.Web.URI]; canonical != "" {
+				groundingSources = append(groundingSources, canonical)
+				log.Printf("  Chunk %d: %s (Source: %s)", i+1, chunk.Web.URI, canonical)
+			} else if chunk.Web.Title != "" {
+				// Title contains the real source (e.g., "forbes.com", "reddit.com")
+				// URI contains redirect URLs (vertexaisearch.cloud.google.com/...)
+				source := chunk.Web.Title
+				groundingSources = append(groundingSources, source)
+				log.Printf("  Chunk %d: %s (Source: %s)", i+1, chunk.Web.URI, source)
+			} else {
+				log.Printf("  Chunk %d: %s (Source: SKIPPED - no title)", i+1, chunk.Web.URI)
+			}
+		}
+	}
+
+	if len(groundingSources) > 0 {
+		log.Printf("Total unique sources: %d", len(groundingSources))
+	}
+
+	return groundingSources
+}
+
+// extractCitations maps the grounding supports of the answer to the web chunks
+// backing them. Segment offsets are byte offsets into the first answer part.
+func extractCitations(metadata *genai.GroundingMetadata, resolved map[string]string) []models.Citation {
+	var citations []models.Citation
+	for _, support := range metadata.GroundingSupports {
+		if support == nil || support.Segment == nil || support.Segment.PartIndex != 0 {
+			continue
+		}
+		for _, index := range support.GroundingChunkIndices {
+			if int(index) >= len(metadata.GroundingChunks) {
+				continue
+			}
+			chunk := metadata.GroundingChunks[index]
+			if chunk == nil || chunk.Web == nil || chunk.Web.URI == "" {
+				continue
+			}
+			citations = append(citations, models.Citation{
+				URL:          chunk.Web.URI,
+				CanonicalURL: resolved[chunk.Web.URI],
+				Title:        chunk.Web.Title,
+				CitedText:    support.Segment.Text,
+				StartIndex:   int(support.Segment.StartIndex),
+				EndIndex:     int(support.Segment.EndIndex),
+			})
+		}
+	}
+	return citations
+}
+
+// sourceHost returns the host of a publisher URL, or the source itself when it
+// is a chunk title
+func sourceHost(source string) string {
+	if u, err := url.Parse(source); err == nil && u.Host != "" {
+		return u.Host
+	}
+	return source
+}
+
 // escapeJSONString escapes special characters for JSON string embedding
 func escapeJSONString(s string) string {
 	s = strings.ReplaceAll(s, "\\", "\\\\")
@@ -0,0 +1,119 @@
+package google
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"google.golang.org/genai"
+
+	"github.com/fissionx/gego/internal/llm"
+	"github.com/fissionx/gego/internal/models"
+)
+
+// groundingPayload is the grounding metadata of a recorded Gemini answer,
+// "HubSpot is a popular CRM. Zoho is cheaper.", with redirect chunk URIs
+const groundingPayload = `{
+	"webSearchQueries": ["best CRM"],
+	"groundingChunks": [
+		{"web": {"uri": "https://vertexaisearch.cloud.google.com/grounding-api-redirect/AAA", "title": "hubspot.com"}},
+		{"web": {"uri": "https://vertexaisearch.cloud.google.com/grounding-api-redirect/BBB", "title": "g2.com"}},
+		{"web": {"uri": "https://vertexaisearch.cloud.google.com/grounding-api-redirect/CCC", "title": "zoho.com"}}
+	],
+	"groundingSupports": [
+		{"segment": {"startIndex": 0, "endIndex": 25, "text": "HubSpot is a popular CRM."}, "groundingChunkIndices": [0, 1]},
+		{"segment": {"startIndex": 26, "endIndex": 42, "text": "Zoho is cheaper."}, "groundingChunkIndices": [2, 7]},
+		{"segment": {"partIndex": 1, "startIndex": 0, "endIndex": 4, "text": "Next"}, "groundingChunkIndices": [0]}
+	]
+}`
+
+func TestExtractCitations(t *testing.T) {
+	const (
+		hubspotLink = "https://vertexaisearch.cloud.google.com/grounding-api-redirect/AAA"
+		g2Link      = "https://vertexaisearch.cloud.google.com/grounding-api-redirect/BBB"
+		zohoLink    = "https://vertexaisearch.cloud.google.com/grounding-api-redirect/CCC"
+	)
+
+	tests := []struct {
+		name     string
+		resolved map[string]string
+		want     []models.Citation
+	}{
+		{
+			name: "unresolved links",
+			want: []models.Citation{
+				{URL: hubspotLink, Title: "hubspot.com", CitedText: "HubSpot is a popular CRM.", StartIndex: 0, EndIndex: 25},
+				{URL: g2Link, Title: "g2.com", CitedText: "HubSpot is a popular CRM.", StartIndex: 0, EndIndex: 25},
+				{URL: zohoLink, Title: "zoho.com", CitedText: "Zoho is cheaper.", StartIndex: 26, EndIndex: 42},
+			},
+		},
+		{
+			name: "resolved links",
+			resolved: map[string]string{
+				hubspotLink: "https://www.hubspot.com/products/crm",
+				zohoLink:    "https://www.zoho.com/crm/",
+			},
+			want: []models.Citation{
+				{URL: hubspotLink, CanonicalURL: "https://www.hubspot.com/products/crm", Title: "hubspot.com", CitedText: "HubSpot is a popular CRM.", StartIndex: 0, EndIndex: 25},
+				{URL: g2Link, Title: "g2.com", CitedText: "HubSpot is a popular CRM.", StartIndex: 0, EndIndex: 25},
+				{URL: zohoLink, CanonicalURL: "https://www.zoho.com/crm/", Title: "zoho.com", CitedText: "Zoho is cheaper.", StartIndex: 26, EndIndex: 42},
+			},
+		},
+	}
+
+	var metadata genai.GroundingMetadata
+	if err := json.Unmarshal([]byte(groundingPayload), &metadata); err != nil {
+		t.Fatalf("failed to decode payload: %v", err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := extractCitations(&metadata, tt.resolved)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("extractCitations() =\n%+v\nwant\n%+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractGrounding(t *testing.T) {
+	// Stands in for vertexaisearch.cloud.google.com: the first link redirects to
+	// a review page naming the brand in its path, the second one has expired
+	redirector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/grounding-api-redirect/AAA" {
+			http.Redirect(w, r, "https://www.g2.com/products/hubspot/reviews", http.StatusFound)
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	defer redirector.Close()
+
+	payload := strings.ReplaceAll(groundingPayload, "https://vertexaisearch.cloud.google.com", redirector.URL)
+	var metadata genai.GroundingMetadata
+	if err := json.Unmarshal([]byte(payload), &metadata); err != nil {
+		t.Fatalf("failed to decode payload: %v", err)
+	}
+
+	provider := New("", "", llm.NewCitationResolver([]string{strings.TrimPrefix(redirector.URL, "http://")}, 100))
+	sources, citations := provider.extractGrounding(context.Background(), &metadata)
+
+	// Unresolved links fall back to the chunk title
+	wantSources := []string{"https://www.g2.com/products/hubspot/reviews", "g2.com", "zoho.com"}
+	if !slices.Equal(sources, wantSources) {
+		t.Errorf("grounding sources = %v, want %v", sources, wantSources)
+	}
+
+	if len(citations) != 3 {
+		t.Fatalf("extractGrounding() = %d citations, want 3", len(citations))
+	}
+	if first := citations[0]; first.URL != redirector.URL+"/grounding-api-redirect/AAA" || first.CanonicalURL != "https://www.g2.com/products/hubspot/reviews" {
+		t.Errorf("first citation = %+v", first)
+	}
+	if second := citations[1]; second.CanonicalURL != "" || second.Title != "g2.com" {
+		t.Errorf("citation of an expired link = %+v", second)
+	}
+}
@@ -3,6 +3,7 @@ package llm
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
@@ -17,7 +18,8 @@ type Config struct {
 	TopP        float64 `json:"top_p"`
 	TopK        int     `json:"top_k"`
 	Stream      bool    `json:"stream"`
-	Brand       string  `json:"brand"` // Brand/company name for GEO analysis
+	Brand       string  `json:"brand"`      // Brand/company name for GEO analysis
+	WebSearch   bool    `json:"web_search"` // Enable the provider's web search / grounding tool
 }
 
 // DefaultConfig returns a config with sensible defaults
@@ -48,47 +50,73 @@ type Provider interface {
 	ListModels(ctx context.Context, apiKey, baseURL string) ([]models.ModelInfo, error)
 }
 
+// StreamingProvider is implemented by providers that can stream generated text
+type StreamingProvider interface {
+	Provider
+
+	// GenerateStream sends a prompt to the LLM and calls onChunk with each piece of
+	// text as it arrives. The returned response holds the complete answer, as with Generate.
+	// An error returned by onChunk aborts the generation.
+	GenerateStream(ctx context.Context, prompt string, config Config, onChunk func(chunk string) error) (*Response, error)
+}
+
+// GenerateStream streams a generation from providers that support it. Other
+// providers are called with Generate and their answer is passed to onChunk at once.
+func GenerateStream(ctx context.Context, provider Provider, prompt string, config Config, onChunk func(chunk string) error) (*Response, error) {
+	if streamer, ok := provider.(StreamingProvider); ok {
+		return streamer.GenerateStream(ctx, prompt, config, onChunk)
+	}
+
+	response, err := provider.Generate(ctx, prompt, config)
+	if err != nil {
+		return nil, err
+	}
+	if response.Text != "" {
+		if err := onChunk(response.Text); err != nil {
+			return nil, err
+		}
+	}
+	return response, nil
+}
+
 // Response represents an LLM response
 type Response struct {
 	Text             string
+	Analysis         string // Raw output of the provider's own GEO analysis call, when it ran one
 	TokensUsed       int
 	LatencyMs        int64
 	Model            string
 	Provider         string
 	Error            string
 	GroundingSources []string // NEW: Citation sources (URLs) from models that support grounding
+	Citations        []models.Citation
 }
 
-// Registry manages LLM providers
-type Registry struct {
-	providers map[string]Provider
-}
-
-// NewRegistry creates a new provider registry
-func NewRegistry() *Registry {
-	return &Registry{
-		providers: make(map[string]Provider),
+// WebSearchEnabled reports whether web search should be enabled for an LLM config.
+// It is controlled by the "web_search" config option; Google is grounded by default.
+func WebSearchEnabled(config *models.LLMConfig) bool {
+	if config == nil {
+		return false
 	}
+	if value, ok := config.Config["web_search"]; ok {
+		enabled, err := strconv.ParseBool(value)
+		return err == nil && enabled
+	}
+	return config.Provider == "google"
 }
 
-// Register registers a provider
-func (r *Registry) Register(provider Provider) {
-	r.providers[provider.Name()] = provider
-}
-
-// Get retrieves a provider by name
-func (r *Registry) Get(name string) (Provider, bool) {
-	provider, ok := r.providers[name]
-	return provider, ok
-}
-
-// List returns all registered provider names
-func (r *Registry) List() []string {
-	names := make([]string, 0, len(r.providers))
-	for name := range r.providers {
-		names = append(names, name)
+// CitationSources returns the unique URLs of the given citations, in citation order
+func CitationSources(citations []models.Citation) []string {
+	seen := make(map[string]bool)
+	var sources []string
+	for _, citation := range citations {
+		if citation.URL == "" || seen[citation.URL] {
+			continue
+		}
+		seen[citation.URL] = true
+		sources = append(sources, citation.URL)
 	}
-	return names
+	return sources
 }
 
 // GenerateRequest encapsulates a generation request
@@ -50,35 +50,12 @@ func (p *Provider) Validate(config map[string]string) error {
 func (p *Provider) Generate(ctx context.Context, prompt string, config llm.Config) (*llm.Response, error) {
 	startTime := time.Now()
 
-	model := "llama2"
-	if config.Model != "" {
-		model = config.Model
-	}
-
-	temperature := config.Temperature
-
-	logger.Info("[Ollama] 📤 Sending request to %s with model=%s", p.baseURL, model)
-
-	requestBody := map[string]interface{}{
-		"model":  model,
-		"prompt": prompt,
-		"stream": false,
-		"options": map[string]interface{}{
-			"temperature": temperature,
-		},
-	}
-
-	jsonBody, err := json.Marshal(requestBody)
+	req, model, err := p.newGenerateRequest(ctx, prompt, config, false)
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request: %w", err)
-	}
-
-	req, err := http.NewRequestWithContext(ctx, "POST", p.baseURL+"/api/generate", bytes.NewBuffer(jsonBody))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return nil, err
 	}
 
-	req.Header.Set("Content-Type", "application/json")
+	logger.Info("[Ollama] 📤 Sending request to %s with model=%s", p.baseURL, model)
 
 	logger.Info("[Ollama] ⏳ Waiting for response (this may take a while for thinking models)...")
 
@@ -127,6 +104,109 @@ func (p *Provider) Generate(ctx context.Context, prompt string, config llm.Confi
 	}, nil
 }
 
+// GenerateStream streams a generation from Ollama, calling onChunk with each piece of text
+func (p *Provider) GenerateStream(ctx context.Context, prompt string, config llm.Config, onChunk func(chunk string) error) (*llm.Response, error) {
+	startTime := time.Now()
+
+	req, model, err := p.newGenerateRequest(ctx, prompt, config, true)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Info("[Ollama] 📤 Streaming request to %s with model=%s", p.baseURL, model)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		logger.Error("[Ollama] ❌ Request failed: %v", err)
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		logger.Error("[Ollama] ❌ API error (HTTP %d): %s", resp.StatusCode, string(body))
+		return nil, fmt.Errorf("API error (HTTP %d): %s", resp.StatusCode, string(body))
+	}
+
+	// The response is a stream of JSON objects, one per line; the last one has done=true
+	var text strings.Builder
+	var tokensUsed int
+	decoder := json.NewDecoder(resp.Body)
+	for {
+		var chunk struct {
+			Model    string `json:"model"`
+			Response string `json:"response"`
+			Done     bool   `json:"done"`
+			Context  []int  `json:"context"`
+			Error    string `json:"error"`
+		}
+		if err := decoder.Decode(&chunk); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to decode stream: %w", err)
+		}
+
+		if chunk.Error != "" {
+			return nil, fmt.Errorf("API error: %s", chunk.Error)
+		}
+		if chunk.Model != "" {
+			model = chunk.Model
+		}
+		if chunk.Response != "" {
+			text.WriteString(chunk.Response)
+			if err := onChunk(chunk.Response); err != nil {
+				return nil, err
+			}
+		}
+		if chunk.Done {
+			tokensUsed = len(chunk.Context)
+			break
+		}
+	}
+
+	duration := time.Since(startTime)
+	logger.Info("[Ollama] ✅ Stream finished in %v, tokens=%d, response_length=%d chars", duration, tokensUsed, text.Len())
+
+	return &llm.Response{
+		Text:       text.String(),
+		TokensUsed: tokensUsed,
+		LatencyMs:  duration.Milliseconds(),
+		Model:      model,
+		Provider:   "ollama",
+	}, nil
+}
+
+// newGenerateRequest builds a generate API request for a prompt and returns it with the model used
+func (p *Provider) newGenerateRequest(ctx context.Context, prompt string, config llm.Config, stream bool) (*http.Request, string, error) {
+	model := "llama2"
+	if config.Model != "" {
+		model = config.Model
+	}
+
+	requestBody := map[string]interface{}{
+		"model":  model,
+		"prompt": prompt,
+		"stream": stream,
+		"options": map[string]interface{}{
+			"temperature": config.Temperature,
+		},
+	}
+
+	jsonBody, err := json.Marshal(requestBody)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", p.baseURL+"/api/generate", bytes.NewBuffer(jsonBody))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	return req, model, nil
+}
+
 // ListModels lists available models from Ollama
 func (p *Provider) ListModels(ctx context.Context, apiKey, baseURL string) ([]models.ModelInfo, error) {
 	if baseURL == "" {
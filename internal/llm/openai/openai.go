@@ -2,6 +2,7 @@ package openai
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"strings"
 	"time"
@@ -16,6 +17,7 @@ import (
 
 // Provider implements the LLM Provider interface for OpenAI
 type Provider struct {
+	name    string
 	apiKey  string
 	baseURL string
 	client  openai.Client
@@ -23,27 +25,28 @@ type Provider struct {
 
 // New creates a new OpenAI provider
 func New(apiKey, baseURL string) *Provider {
-	client := openai.NewClient(
-		option.WithAPIKey(apiKey),
-	)
-
+	opts := []option.RequestOption{option.WithAPIKey(apiKey)}
 	if baseURL != "" && baseURL != "https://api.openai.com/v1" {
-		client = openai.NewClient(
-			option.WithAPIKey(apiKey),
-			option.WithBaseURL(baseURL),
-		)
+		opts = append(opts, option.WithBaseURL(baseURL))
 	}
+	return NewWithOptions("openai", apiKey, baseURL, opts...)
+}
 
+// NewWithOptions creates a provider for an API speaking the OpenAI protocol.
+// The provider reports itself as name and its client is built from opts alone,
+// on top of the openai-go defaults.
+func NewWithOptions(name, apiKey, baseURL string, opts ...option.RequestOption) *Provider {
 	return &Provider{
+		name:    name,
 		apiKey:  apiKey,
 		baseURL: baseURL,
-		client:  client,
+		client:  openai.NewClient(opts...),
 	}
 }
 
 // Name returns the provider name
 func (p *Provider) Name() string {
-	return "openai"
+	return p.name
 }
 
 // Validate validates the provider configuration
@@ -58,7 +61,69 @@ func (p *Provider) Validate(config map[string]string) error {
 func (p *Provider) Generate(ctx context.Context, prompt string, config llm.Config) (*llm.Response, error) {
 	startTime := time.Now()
 
+	params := buildParams(prompt, config)
+
+	chatCompletion, err := p.client.Chat.Completions.New(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("OpenAI API error: %w", err)
+	}
+
+	return buildResponse(chatCompletion, params.Model, p.name, startTime), nil
+}
+
+// GenerateStream streams a completion from OpenAI, calling onChunk with each content delta
+func (p *Provider) GenerateStream(ctx context.Context, prompt string, config llm.Config, onChunk func(chunk string) error) (*llm.Response, error) {
+	startTime := time.Now()
+
+	params := buildParams(prompt, config)
+	params.StreamOptions = openai.ChatCompletionStreamOptionsParam{
+		IncludeUsage: openai.Bool(true),
+	}
+
+	stream := p.client.Chat.Completions.NewStreaming(ctx, params)
+	defer stream.Close()
+
+	acc := openai.ChatCompletionAccumulator{}
+	var annotations []openai.ChatCompletionMessageAnnotation
+	for stream.Next() {
+		chunk := stream.Current()
+		acc.AddChunk(chunk)
+
+		if len(chunk.Choices) == 0 {
+			continue
+		}
+		delta := chunk.Choices[0].Delta
+		if field, ok := delta.JSON.ExtraFields["annotations"]; ok {
+			var deltaAnnotations []openai.ChatCompletionMessageAnnotation
+			if err := json.Unmarshal([]byte(field.Raw()), &deltaAnnotations); err == nil {
+				annotations = append(annotations, deltaAnnotations...)
+			}
+		}
+		if delta.Content != "" {
+			if err := onChunk(delta.Content); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := stream.Err(); err != nil {
+		return nil, fmt.Errorf("OpenAI API error: %w", err)
+	}
+
+	// Search annotations arrive on the deltas and are not part of the typed delta,
+	// so the accumulator does not merge them
+	if len(acc.Choices) > 0 {
+		acc.Choices[0].Message.Annotations = annotations
+	}
+
+	return buildResponse(&acc.ChatCompletion, params.Model, p.name, startTime), nil
+}
+
+// buildParams builds the chat completion request for a prompt
+func buildParams(prompt string, config llm.Config) openai.ChatCompletionNewParams {
 	model := shared.ChatModelGPT3_5Turbo
+	if config.WebSearch {
+		model = shared.ChatModelGPT4oSearchPreview
+	}
 	if config.Model != "" {
 		model = shared.ChatModel(config.Model)
 	}
@@ -69,30 +134,46 @@ func (p *Provider) Generate(ctx context.Context, prompt string, config llm.Confi
 		maxTokens = 1000
 	}
 
-	chatCompletion, err := p.client.Chat.Completions.New(
-		ctx,
-		openai.ChatCompletionNewParams{
-			Model: model,
-			Messages: []openai.ChatCompletionMessageParamUnion{
-				{
-					OfUser: &openai.ChatCompletionUserMessageParam{
-						Content: openai.ChatCompletionUserMessageParamContentUnion{
-							OfString: openai.String(prompt),
-						},
+	params := openai.ChatCompletionNewParams{
+		Model: model,
+		Messages: []openai.ChatCompletionMessageParamUnion{
+			{
+				OfUser: &openai.ChatCompletionUserMessageParam{
+					Content: openai.ChatCompletionUserMessageParamContentUnion{
+						OfString: openai.String(prompt),
 					},
 				},
 			},
-			Temperature: openai.Float(temperature),
-			MaxTokens:   openai.Int(int64(maxTokens)),
 		},
-	)
-	if err != nil {
-		return nil, fmt.Errorf("OpenAI API error: %w", err)
+		MaxTokens: openai.Int(int64(maxTokens)),
 	}
 
+	if config.WebSearch && isSearchModel(model) {
+		// Search models pick their own sampling settings and reject temperature
+		params.WebSearchOptions = openai.ChatCompletionNewParamsWebSearchOptions{
+			SearchContextSize: "medium",
+		}
+	} else {
+		params.Temperature = openai.Float(temperature)
+	}
+
+	return params
+}
+
+// isSearchModel reports whether a model accepts web_search_options. Other
+// models reject them, so they answer without searching the web.
+func isSearchModel(model shared.ChatModel) bool {
+	return strings.Contains(string(model), "search")
+}
+
+// buildResponse converts a chat completion into an LLM response
+func buildResponse(chatCompletion *openai.ChatCompletion, model shared.ChatModel, provider string, startTime time.Time) *llm.Response {
 	var generatedText string
+	var citations []models.Citation
 	if len(chatCompletion.Choices) > 0 && chatCompletion.Choices[0].Message.Content != "" {
-		generatedText = chatCompletion.Choices[0].Message.Content
+		message := chatCompletion.Choices[0].Message
+		generatedText = message.Content
+		citations = extractCitations(generatedText, message.Annotations)
 	}
 
 	tokensUsed := 0
@@ -101,12 +182,54 @@ func (p *Provider) Generate(ctx context.Context, prompt string, config llm.Confi
 	}
 
 	return &llm.Response{
-		Text:       generatedText,
-		TokensUsed: tokensUsed,
-		LatencyMs:  time.Since(startTime).Milliseconds(),
-		Model:      string(model),
-		Provider:   "openai",
-	}, nil
+		Text:             generatedText,
+		TokensUsed:       tokensUsed,
+		LatencyMs:        time.Since(startTime).Milliseconds(),
+		Model:            string(model),
+		Provider:         provider,
+		GroundingSources: llm.CitationSources(citations),
+		Citations:        citations,
+	}
+}
+
+// extractCitations converts the url_citation annotations of a message into citations.
+// OpenAI reports character offsets, which are converted to byte offsets into text.
+func extractCitations(text string, annotations []openai.ChatCompletionMessageAnnotation) []models.Citation {
+	var citations []models.Citation
+	for _, annotation := range annotations {
+		urlCitation := annotation.URLCitation
+		if urlCitation.URL == "" {
+			continue
+		}
+
+		citation := models.Citation{
+			URL:   urlCitation.URL,
+			Title: urlCitation.Title,
+		}
+		start, end := byteOffset(text, int(urlCitation.StartIndex)), byteOffset(text, int(urlCitation.EndIndex))
+		if start < end {
+			citation.StartIndex = start
+			citation.EndIndex = end
+			citation.CitedText = text[start:end]
+		}
+		citations = append(citations, citation)
+	}
+	return citations
+}
+
+// byteOffset returns the byte offset of the n-th character of text
+func byteOffset(text string, n int) int {
+	if n <= 0 {
+		return 0
+	}
+	count := 0
+	for i := range text {
+		if count == n {
+			return i
+		}
+		count++
+	}
+	return len(text)
 }
 
 // ListModels lists available text-to-text models from OpenAI
@@ -0,0 +1,113 @@
+package openai
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/openai/openai-go/v3"
+
+	"github.com/fissionx/gego/internal/llm"
+	"github.com/fissionx/gego/internal/models"
+)
+
+func TestBuildResponseCitations(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload string
+		want    []models.Citation
+	}{
+		{
+			name: "character offsets become byte offsets",
+			payload: `{
+				"id": "chatcmpl-1", "object": "chat.completion", "created": 1735689600, "model": "gpt-4o-search-preview",
+				"choices": [{"index": 0, "finish_reason": "stop", "message": {
+					"role": "assistant",
+					"content": "Café guide: HubSpot leads.",
+					"annotations": [
+						{"type": "url_citation", "url_citation": {"start_index": 12, "end_index": 19, "url": "https://www.hubspot.com/", "title": "HubSpot"}},
+						{"type": "url_citation", "url_citation": {"start_index": 12, "end_index": 100, "url": "https://www.g2.com/products/hubspot", "title": "G2"}}
+					]
+				}}],
+				"usage": {"prompt_tokens": 10, "completion_tokens": 8, "total_tokens": 18}
+			}`,
+			want: []models.Citation{
+				{URL: "https://www.hubspot.com/", Title: "HubSpot", CitedText: "HubSpot", StartIndex: 13, EndIndex: 20},
+				{URL: "https://www.g2.com/products/hubspot", Title: "G2", CitedText: "HubSpot leads.", StartIndex: 13, EndIndex: 27},
+			},
+		},
+		{
+			name: "annotations without a URL or a span",
+			payload: `{
+				"id": "chatcmpl-2", "object": "chat.completion", "created": 1735689600, "model": "gpt-4o-search-preview",
+				"choices": [{"index": 0, "finish_reason": "stop", "message": {
+					"role": "assistant",
+					"content": "Salesforce is the leader.",
+					"annotations": [
+						{"type": "url_citation", "url_citation": {"start_index": 0, "end_index": 10, "url": "", "title": "untitled"}},
+						{"type": "url_citation", "url_citation": {"start_index": 5, "end_index": 5, "url": "https://www.salesforce.com/", "title": "Salesforce"}}
+					]
+				}}]
+			}`,
+			want: []models.Citation{
+				{URL: "https://www.salesforce.com/", Title: "Salesforce"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var completion openai.ChatCompletion
+			if err := json.Unmarshal([]byte(tt.payload), &completion); err != nil {
+				t.Fatalf("failed to decode payload: %v", err)
+			}
+
+			resp := buildResponse(&completion, completion.Model, "openai", time.Now())
+			if !slices.Equal(resp.Citations, tt.want) {
+				t.Errorf("citations =\n%+v\nwant\n%+v", resp.Citations, tt.want)
+			}
+			if len(resp.GroundingSources) != len(tt.want) {
+				t.Errorf("grounding sources = %v", resp.GroundingSources)
+			}
+		})
+	}
+}
+
+func TestBuildParams(t *testing.T) {
+	tests := []struct {
+		name            string
+		config          llm.Config
+		wantModel       string
+		wantWebSearch   bool
+		wantTemperature bool
+	}{
+		{"no web search", llm.Config{Model: "gpt-4o", Temperature: 0.3}, "gpt-4o", false, true},
+		{"web search without a model", llm.Config{WebSearch: true, Temperature: 0.3}, "gpt-4o-search-preview", true, false},
+		{"web search on a search model", llm.Config{Model: "gpt-4o-mini-search-preview", WebSearch: true}, "gpt-4o-mini-search-preview", true, false},
+		{"web search on a non-search model", llm.Config{Model: "gpt-4o", WebSearch: true, Temperature: 0.3}, "gpt-4o", false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(buildParams("best CRM?", tt.config))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var body map[string]any
+			if err := json.Unmarshal(data, &body); err != nil {
+				t.Fatal(err)
+			}
+
+			if body["model"] != tt.wantModel {
+				t.Errorf("model = %v, want %s", body["model"], tt.wantModel)
+			}
+			if _, ok := body["web_search_options"]; ok != tt.wantWebSearch {
+				t.Errorf("web_search_options sent = %v, want %v", ok, tt.wantWebSearch)
+			}
+			if temperature, ok := body["temperature"]; ok != tt.wantTemperature || (ok && temperature != 0.3) {
+				t.Errorf("temperature = %v (sent %v), want sent %v", temperature, ok, tt.wantTemperature)
+			}
+		})
+	}
+}
@@ -0,0 +1,217 @@
+// Package openaicompat implements a provider for self-hosted models and
+// gateways speaking the OpenAI chat completions API, such as vLLM, LiteLLM,
+// Azure OpenAI, Mistral, Groq and DeepSeek.
+package openaicompat
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/openai/openai-go/v3/option"
+
+	"github.com/fissionx/gego/internal/llm/openai"
+	"github.com/fissionx/gego/internal/models"
+)
+
+// ProviderName is the provider of OpenAI-compatible LLM configurations
+const ProviderName = "openai-compatible"
+
+// Config options of OpenAI-compatible LLM configurations
+const (
+	// HeaderOptionPrefix prefixes options sent as request headers, e.g. "header.X-Title": "gego"
+	HeaderOptionPrefix = "header."
+	// QueryOptionPrefix prefixes options sent as query parameters, e.g. "query.api-version": "2024-10-21"
+	QueryOptionPrefix = "query."
+	// AuthHeaderOption names the header carrying the API key, for endpoints that
+	// do not take "Authorization: Bearer <key>" (Azure OpenAI: "api-key")
+	AuthHeaderOption = "auth_header"
+	// ModelsEndpointOption is the model listing endpoint, relative to the base
+	// URL or absolute. "none" disables model listing.
+	ModelsEndpointOption = "models_endpoint"
+)
+
+const defaultModelsEndpoint = "models"
+
+// Provider implements the LLM Provider interface for OpenAI-compatible APIs.
+// Generation is that of the OpenAI provider; model listing and authentication
+// follow the config options.
+type Provider struct {
+	*openai.Provider
+	apiKey         string
+	baseURL        string
+	authHeader     string
+	headers        map[string]string
+	query          map[string]string
+	modelsEndpoint string
+	client         *http.Client
+}
+
+// New creates a new OpenAI-compatible provider
+func New(apiKey, baseURL string, options map[string]string) *Provider {
+	p := &Provider{
+		apiKey:         apiKey,
+		baseURL:        baseURL,
+		authHeader:     options[AuthHeaderOption],
+		headers:        make(map[string]string),
+		query:          make(map[string]string),
+		modelsEndpoint: options[ModelsEndpointOption],
+		client: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}
+	if p.modelsEndpoint == "" {
+		p.modelsEndpoint = defaultModelsEndpoint
+	}
+
+	for key, value := range options {
+		if name, ok := strings.CutPrefix(key, HeaderOptionPrefix); ok && name != "" {
+			p.headers[name] = value
+		}
+		if name, ok := strings.CutPrefix(key, QueryOptionPrefix); ok && name != "" {
+			p.query[name] = value
+		}
+	}
+
+	// The OpenAI credentials of the environment must never reach another endpoint
+	opts := []option.RequestOption{
+		option.WithHeaderDel("Authorization"),
+		option.WithHeaderDel("OpenAI-Organization"),
+		option.WithHeaderDel("OpenAI-Project"),
+	}
+	if baseURL != "" {
+		opts = append(opts, option.WithBaseURL(baseURL))
+	}
+	if apiKey != "" {
+		if p.authHeader != "" {
+			opts = append(opts, option.WithHeader(p.authHeader, apiKey))
+		} else {
+			opts = append(opts, option.WithAPIKey(apiKey))
+		}
+	}
+	for name, value := range p.headers {
+		opts = append(opts, option.WithHeader(name, value))
+	}
+	for name, value := range p.query {
+		opts = append(opts, option.WithQuery(name, value))
+	}
+
+	p.Provider = openai.NewWithOptions(ProviderName, apiKey, baseURL, opts...)
+	return p
+}
+
+// Validate validates the provider configuration
+func (p *Provider) Validate(config map[string]string) error {
+	if config["base_url"] == "" {
+		return fmt.Errorf("base_url is required")
+	}
+	return nil
+}
+
+// modelList is the response of an OpenAI-style models endpoint
+type modelList struct {
+	Data []struct {
+		ID      string `json:"id"`
+		OwnedBy string `json:"owned_by"`
+	} `json:"data"`
+}
+
+// ListModels lists the models of the models endpoint. Unlike OpenAI, every
+// model is returned: gateways only serve what their operator configured.
+func (p *Provider) ListModels(ctx context.Context, apiKey, baseURL string) ([]models.ModelInfo, error) {
+	if p.modelsEndpoint == "none" {
+		return nil, nil
+	}
+	if apiKey == "" {
+		apiKey = p.apiKey
+	}
+	if baseURL == "" {
+		baseURL = p.baseURL
+	}
+
+	endpoint, err := p.modelsURL(baseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if apiKey != "" {
+		if p.authHeader != "" {
+			req.Header.Set(p.authHeader, apiKey)
+		} else {
+			req.Header.Set("Authorization", "Bearer "+apiKey)
+		}
+	}
+	for name, value := range p.headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list models: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to list models (HTTP %d): %s", resp.StatusCode, string(body))
+	}
+
+	var list modelList
+	if err := json.Unmarshal(body, &list); err != nil {
+		return nil, fmt.Errorf("failed to parse models: %w", err)
+	}
+
+	var textModels []models.ModelInfo
+	for _, model := range list.Data {
+		if model.ID == "" {
+			continue
+		}
+		description := "OpenAI-compatible model"
+		if model.OwnedBy != "" {
+			description = fmt.Sprintf("%s (%s)", description, model.OwnedBy)
+		}
+		textModels = append(textModels, models.ModelInfo{
+			ID:          model.ID,
+			Name:        model.ID,
+			Description: description,
+		})
+	}
+
+	return textModels, nil
+}
+
+// modelsURL resolves the models endpoint against the base URL and adds the
+// configured query parameters
+func (p *Provider) modelsURL(baseURL string) (string, error) {
+	endpoint := p.modelsEndpoint
+	if !strings.HasPrefix(endpoint, "http://") && !strings.HasPrefix(endpoint, "https://") {
+		if baseURL == "" {
+			return "", fmt.Errorf("base URL is required")
+		}
+		endpoint = strings.TrimRight(baseURL, "/") + "/" + strings.TrimLeft(endpoint, "/")
+	}
+
+	parsed, err := url.Parse(endpoint)
+	if err != nil {
+		return "", fmt.Errorf("invalid models endpoint: %w", err)
+	}
+	query := parsed.Query()
+	for name, value := range p.query {
+		query.Set(name, value)
+	}
+	parsed.RawQuery = query.Encode()
+
+	return parsed.String(), nil
+}
@@ -0,0 +1,174 @@
+package openaicompat
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/fissionx/gego/internal/llm"
+)
+
+const completionPayload = `{
+	"id": "chatcmpl-1", "object": "chat.completion", "created": 1735689600, "model": "llama3",
+	"choices": [{"index": 0, "finish_reason": "stop", "message": {"role": "assistant", "content": "HubSpot."}}],
+	"usage": {"prompt_tokens": 4, "completion_tokens": 2, "total_tokens": 6}
+}`
+
+// recordedRequest is what the test server saw of a request
+type recordedRequest struct {
+	path   string
+	query  string
+	header http.Header
+	body   string
+}
+
+// newTestServer serves a chat completion and a model list, recording the requests
+func newTestServer(t *testing.T) (*httptest.Server, *[]recordedRequest) {
+	t.Helper()
+	var requests []recordedRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests = append(requests, recordedRequest{path: r.URL.Path, query: r.URL.RawQuery, header: r.Header.Clone(), body: string(body)})
+
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/chat/completions"):
+			io.WriteString(w, completionPayload)
+		case strings.HasSuffix(r.URL.Path, "models"):
+			io.WriteString(w, `{"data": [{"id": "llama3", "owned_by": "meta"}, {"id": ""}, {"id": "mistral"}]}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func TestGenerateHeadersAndQuery(t *testing.T) {
+	t.Setenv("OPENAI_API_KEY", "sk-environment")
+	t.Setenv("OPENAI_ORG_ID", "org-environment")
+
+	tests := []struct {
+		name       string
+		apiKey     string
+		options    map[string]string
+		wantHeader map[string]string
+	}{
+		{
+			name:       "bearer key",
+			apiKey:     "sk-gateway",
+			options:    map[string]string{"header.X-Title": "gego", "query.api-version": "2024-10-21"},
+			wantHeader: map[string]string{"Authorization": "Bearer sk-gateway", "X-Title": "gego", "OpenAI-Organization": ""},
+		},
+		{
+			name:       "custom auth header",
+			apiKey:     "azure-key",
+			options:    map[string]string{AuthHeaderOption: "api-key", "query.api-version": "2024-10-21"},
+			wantHeader: map[string]string{"Api-Key": "azure-key", "Authorization": "", "OpenAI-Organization": ""},
+		},
+		{
+			name:       "no key",
+			options:    map[string]string{"query.api-version": "2024-10-21"},
+			wantHeader: map[string]string{"Authorization": ""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, requests := newTestServer(t)
+			provider := New(tt.apiKey, server.URL+"/v1", tt.options)
+
+			resp, err := provider.Generate(context.Background(), "best CRM?", llm.Config{Model: "llama3"})
+			if err != nil {
+				t.Fatalf("Generate() error = %v", err)
+			}
+			if resp.Text != "HubSpot." || resp.Provider != ProviderName {
+				t.Errorf("Generate() = %q from %s", resp.Text, resp.Provider)
+			}
+
+			if len(*requests) != 1 {
+				t.Fatalf("server got %d requests, want 1", len(*requests))
+			}
+			req := (*requests)[0]
+			if req.path != "/v1/chat/completions" || req.query != "api-version=2024-10-21" {
+				t.Errorf("request = %s?%s", req.path, req.query)
+			}
+			for name, want := range tt.wantHeader {
+				if got := req.header.Get(name); got != want {
+					t.Errorf("header %s = %q, want %q", name, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestListModels(t *testing.T) {
+	server, requests := newTestServer(t)
+
+	tests := []struct {
+		name     string
+		baseURL  string
+		options  map[string]string
+		wantPath string
+		want     []string
+	}{
+		{"default endpoint", server.URL + "/v1/", nil, "/v1/models", []string{"llama3", "mistral"}},
+		{"relative endpoint", server.URL + "/v1", map[string]string{ModelsEndpointOption: "openai/models", "query.api-version": "1"}, "/v1/openai/models", []string{"llama3", "mistral"}},
+		{"absolute endpoint", "https://gateway.invalid/v1", map[string]string{ModelsEndpointOption: server.URL + "/catalog/models"}, "/catalog/models", []string{"llama3", "mistral"}},
+		{"listing disabled", server.URL, map[string]string{ModelsEndpointOption: "none"}, "", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			*requests = nil
+			options := map[string]string{AuthHeaderOption: "api-key", "header.X-Title": "gego"}
+			for key, value := range tt.options {
+				options[key] = value
+			}
+			provider := New("gateway-key", tt.baseURL, options)
+
+			list, err := provider.ListModels(context.Background(), "", "")
+			if err != nil {
+				t.Fatalf("ListModels() error = %v", err)
+			}
+			var ids []string
+			for _, model := range list {
+				ids = append(ids, model.ID)
+			}
+			if !slices.Equal(ids, tt.want) {
+				t.Errorf("ListModels() = %v, want %v", ids, tt.want)
+			}
+
+			if tt.wantPath == "" {
+				if len(*requests) != 0 {
+					t.Errorf("server got %d requests, want none", len(*requests))
+				}
+				return
+			}
+			if len(*requests) != 1 {
+				t.Fatalf("server got %d requests, want 1", len(*requests))
+			}
+			req := (*requests)[0]
+			if req.path != tt.wantPath || req.header.Get("Api-Key") != "gateway-key" || req.header.Get("X-Title") != "gego" {
+				t.Errorf("request = %s with headers %v", req.path, req.header)
+			}
+			if tt.options["query.api-version"] != "" && req.query != "api-version=1" {
+				t.Errorf("request query = %q", req.query)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	provider := New("", "", nil)
+	if err := provider.Validate(map[string]string{}); err == nil || !strings.Contains(err.Error(), "base_url") {
+		t.Errorf("Validate() without base_url = %v, want an error naming base_url", err)
+	}
+	if err := provider.Validate(map[string]string{"base_url": "http://localhost:8000/v1"}); err != nil {
+		t.Errorf("Validate() without an API key = %v, want nil", err)
+	}
+}
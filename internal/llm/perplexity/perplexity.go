@@ -3,6 +3,9 @@ package perplexity
 import (
 	"context"
 	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
 	"time"
 
 	pplx "github.com/sgaunet/perplexity-go/v2"
@@ -92,15 +95,82 @@ func (p *Provider) Generate(ctx context.Context, prompt string, config llm.Confi
 
 	tokensUsed := resp.Usage.TotalTokens
 
+	// Sonar models always search the web, so citations are returned regardless of config.WebSearch
+	citations := extractCitations(content, searchResults(resp))
+
 	return &llm.Response{
-		Text:       content,
-		TokensUsed: tokensUsed,
-		LatencyMs:  time.Since(startTime).Milliseconds(),
-		Model:      model,
-		Provider:   "perplexity",
+		Text:             content,
+		TokensUsed:       tokensUsed,
+		LatencyMs:        time.Since(startTime).Milliseconds(),
+		Model:            model,
+		Provider:         "perplexity",
+		GroundingSources: llm.CitationSources(citations),
+		Citations:        citations,
 	}, nil
 }
 
+// searchResults returns the search results of a response, else its bare citation URLs
+func searchResults(resp *pplx.CompletionResponse) []pplx.SearchResult {
+	results := resp.GetSearchResults()
+	if len(results) == 0 {
+		for _, url := range resp.GetCitations() {
+			results = append(results, pplx.SearchResult{URL: url})
+		}
+	}
+	return results
+}
+
+// citationMarker matches the numbered [n] citation markers in a Perplexity answer
+var citationMarker = regexp.MustCompile(`\[(\d+)\]`)
+
+// extractCitations maps the [n] markers of an answer to the search results they
+// reference. Each marker cites the text between the previous sentence break or
+// marker and itself. Search results that are never referenced are kept without a span.
+func extractCitations(text string, results []pplx.SearchResult) []models.Citation {
+	var citations []models.Citation
+	cited := make(map[int]bool)
+	spanStart := 0
+
+	for _, match := range citationMarker.FindAllStringSubmatchIndex(text, -1) {
+		index, err := strconv.Atoi(text[match[2]:match[3]])
+		if err != nil || index < 1 || index > len(results) {
+			continue
+		}
+
+		// The cited sentence ends at the marker; it starts after the previous break
+		sentence := strings.TrimRight(text[spanStart:match[0]], " \t\n.!?")
+		start := spanStart + strings.LastIndexAny(sentence, ".!?\n") + 1
+		span := strings.TrimSpace(text[start:match[0]])
+		start += strings.Index(text[start:match[0]], span)
+		end := start + len(span)
+
+		// Adjacent markers ([1][2]) cite the same span
+		if span == "" && len(citations) > 0 {
+			last := citations[len(citations)-1]
+			span, start, end = last.CitedText, last.StartIndex, last.EndIndex
+		}
+
+		result := results[index-1]
+		citations = append(citations, models.Citation{
+			URL:        result.URL,
+			Title:      result.Title,
+			CitedText:  span,
+			StartIndex: start,
+			EndIndex:   end,
+		})
+		cited[index-1] = true
+		spanStart = match[1]
+	}
+
+	for i, result := range results {
+		if !cited[i] && result.URL != "" {
+			citations = append(citations, models.Citation{URL: result.URL, Title: result.Title})
+		}
+	}
+
+	return citations
+}
+
 // ListModels lists available text-to-text models from Perplexity
 // Since Perplexity doesn't have a public models API, we return a curated list
 func (p *Provider) ListModels(ctx context.Context, apiKey, baseURL string) ([]models.ModelInfo, error) {
@@ -0,0 +1,67 @@
+package perplexity
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+
+	pplx "github.com/sgaunet/perplexity-go/v2"
+
+	"github.com/fissionx/gego/internal/models"
+)
+
+func TestExtractCitations(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload string
+		want    []models.Citation
+	}{
+		{
+			name: "markers cite their sentence",
+			payload: `{
+				"choices": [{"index": 0, "message": {"role": "assistant", "content": "HubSpot is a popular CRM [1]. Salesforce leads enterprise sales[2][3]."}}],
+				"search_results": [
+					{"title": "HubSpot CRM", "url": "https://www.hubspot.com/products/crm"},
+					{"title": "Salesforce", "url": "https://www.salesforce.com/"},
+					{"title": "Best CRM Software", "url": "https://www.g2.com/categories/crm"}
+				]
+			}`,
+			want: []models.Citation{
+				{URL: "https://www.hubspot.com/products/crm", Title: "HubSpot CRM", CitedText: "HubSpot is a popular CRM", StartIndex: 0, EndIndex: 24},
+				{URL: "https://www.salesforce.com/", Title: "Salesforce", CitedText: "Salesforce leads enterprise sales", StartIndex: 30, EndIndex: 63},
+				{URL: "https://www.g2.com/categories/crm", Title: "Best CRM Software", CitedText: "Salesforce leads enterprise sales", StartIndex: 30, EndIndex: 63},
+			},
+		},
+		{
+			name: "unknown markers are skipped and unreferenced results kept",
+			payload: `{
+				"choices": [{"index": 0, "message": {"role": "assistant", "content": "Try Zoho [4] or Pipedrive [1]."}}],
+				"citations": ["https://www.pipedrive.com/", "https://www.zoho.com/crm/"]
+			}`,
+			want: []models.Citation{
+				{URL: "https://www.pipedrive.com/", CitedText: "Try Zoho [4] or Pipedrive", StartIndex: 0, EndIndex: 25},
+				{URL: "https://www.zoho.com/crm/"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var resp pplx.CompletionResponse
+			if err := json.Unmarshal([]byte(tt.payload), &resp); err != nil {
+				t.Fatalf("failed to decode payload: %v", err)
+			}
+
+			text := resp.GetLastContent()
+			got := extractCitations(text, searchResults(&resp))
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("extractCitations() =\n%+v\nwant\n%+v", got, tt.want)
+			}
+			for _, c := range got {
+				if c.CitedText != text[c.StartIndex:c.EndIndex] {
+					t.Errorf("span %d:%d is %q, not %q", c.StartIndex, c.EndIndex, text[c.StartIndex:c.EndIndex], c.CitedText)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+// Package providers wires the built-in LLM providers into a factory
+package providers
+
+import (
+	"github.com/fissionx/gego/internal/llm"
+	"github.com/fissionx/gego/internal/llm/anthropic"
+	"github.com/fissionx/gego/internal/llm/google"
+	"github.com/fissionx/gego/internal/llm/ollama"
+	"github.com/fissionx/gego/internal/llm/openai"
+	"github.com/fissionx/gego/internal/llm/openaicompat"
+	"github.com/fissionx/gego/internal/llm/perplexity"
+	"github.com/fissionx/gego/internal/llm/replay"
+)
+
+// builtins are the constructors of the built-in providers, by provider name
+var builtins = map[string]llm.Constructor{
+	"openai": func(apiKey, baseURL string, options map[string]string) llm.Provider {
+		return openai.New(apiKey, baseURL)
+	},
+	"anthropic": func(apiKey, baseURL string, options map[string]string) llm.Provider {
+		return anthropic.New(apiKey, baseURL)
+	},
+	"ollama": func(apiKey, baseURL string, options map[string]string) llm.Provider {
+		return ollama.New(baseURL)
+	},
+	"google": func(apiKey, baseURL string, options map[string]string) llm.Provider {
+		return google.New(apiKey, baseURL, llm.SharedCitationResolver())
+	},
+	"perplexity": func(apiKey, baseURL string, options map[string]string) llm.Provider {
+		return perplexity.New(apiKey, baseURL)
+	},
+	openaicompat.ProviderName: func(apiKey, baseURL string, options map[string]string) llm.Provider {
+		return openaicompat.New(apiKey, baseURL, options)
+	},
+}
+
+// NewFactory creates a provider factory with all built-in providers registered
+func NewFactory() *llm.Factory {
+	factory := llm.NewFactory()
+	for name, constructor := range builtins {
+		factory.RegisterConstructor(name, constructor)
+	}
+	return factory
+}
+
+// NewReplayFactory creates a provider factory whose providers serve the
+// responses recorded in dir instead of calling the LLM APIs
+func NewReplayFactory(dir string) *llm.Factory {
+	factory := llm.NewFactory()
+	for name := range builtins {
+		factory.RegisterConstructor(name, func(apiKey, baseURL string, options map[string]string) llm.Provider {
+			return replay.New(name, dir)
+		})
+	}
+	return factory
+}
+
+// NewRecordingFactory creates a provider factory whose providers call the LLM
+// APIs and record every response to dir, for NewReplayFactory to serve
+func NewRecordingFactory(dir string) *llm.Factory {
+	factory := llm.NewFactory()
+	for name, constructor := range builtins {
+		factory.RegisterConstructor(name, func(apiKey, baseURL string, options map[string]string) llm.Provider {
+			return replay.NewRecorder(constructor(apiKey, baseURL, options), dir)
+		})
+	}
+	return factory
+}
@@ -0,0 +1,269 @@
+// Package replay serves recorded LLM responses from fixture files, so the GEO
+// pipeline can run without network access or API keys, and records those
+// fixtures from real providers.
+//
+// A fixture is a JSON file holding one response, keyed by the prompt, model
+// and temperature of the request that produced it.
+package replay
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fissionx/gego/internal/llm"
+	"github.com/fissionx/gego/internal/models"
+)
+
+// ErrFixtureNotFound is returned when no response was recorded for a request
+var ErrFixtureNotFound = errors.New("no recorded response")
+
+// Fixture is a recorded response with the request it answers
+type Fixture struct {
+	Prompt           string            `json:"prompt"`
+	Model            string            `json:"model"`
+	Temperature      float64           `json:"temperature"`
+	Provider         string            `json:"provider"`
+	Text             string            `json:"text"`
+	Analysis         string            `json:"analysis,omitempty"`
+	TokensUsed       int               `json:"tokens_used"`
+	LatencyMs        int64             `json:"latency_ms"`
+	ResponseModel    string            `json:"response_model"`
+	GroundingSources []string          `json:"grounding_sources,omitempty"`
+	Citations        []models.Citation `json:"citations,omitempty"`
+	RecordedAt       time.Time         `json:"recorded_at"`
+}
+
+// Key identifies the fixture of a request
+func Key(prompt, model string, temperature float64) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s\x00%s\x00%.2f", prompt, model, temperature)))
+	return hex.EncodeToString(sum[:8])
+}
+
+// FixturePath returns the file holding the fixture of a request. The model is
+// part of the name to keep fixture directories readable.
+func FixturePath(dir, prompt, model string, temperature float64) string {
+	name := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '.' {
+			return r
+		}
+		return '_'
+	}, model)
+	if name == "" {
+		name = "default"
+	}
+	return filepath.Join(dir, name+"-"+Key(prompt, model, temperature)+".json")
+}
+
+// Provider serves recorded responses in place of a real provider
+type Provider struct {
+	name string
+	dir  string
+}
+
+// New creates a provider serving the fixtures of dir. It reports itself as
+// name, the provider it stands in for.
+func New(name, dir string) *Provider {
+	return &Provider{
+		name: name,
+		dir:  dir,
+	}
+}
+
+// Name returns the name of the provider the fixtures were recorded from
+func (p *Provider) Name() string {
+	return p.name
+}
+
+// Validate validates the provider configuration
+func (p *Provider) Validate(config map[string]string) error {
+	return nil
+}
+
+// Generate returns the recorded response of a request. Returns an error
+// wrapping ErrFixtureNotFound when none was recorded.
+func (p *Provider) Generate(ctx context.Context, prompt string, config llm.Config) (*llm.Response, error) {
+	path := FixturePath(p.dir, prompt, config.Model, config.Temperature)
+
+	fixture, err := readFixture(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w for model %q at temperature %.2f (expected %s)", ErrFixtureNotFound, config.Model, config.Temperature, path)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	provider := fixture.Provider
+	if provider == "" {
+		provider = p.name
+	}
+
+	return &llm.Response{
+		Text:             fixture.Text,
+		Analysis:         fixture.Analysis,
+		TokensUsed:       fixture.TokensUsed,
+		LatencyMs:        fixture.LatencyMs,
+		Model:            fixture.ResponseModel,
+		Provider:         provider,
+		GroundingSources: fixture.GroundingSources,
+		Citations:        fixture.Citations,
+	}, nil
+}
+
+// ListModels lists the models fixtures were recorded for
+func (p *Provider) ListModels(ctx context.Context, apiKey, baseURL string) ([]models.ModelInfo, error) {
+	paths, err := filepath.Glob(filepath.Join(p.dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	for _, path := range paths {
+		fixture, err := readFixture(path)
+		if err != nil {
+			return nil, err
+		}
+		if fixture.Provider == p.name || fixture.Provider == "" {
+			seen[fixture.Model] = true
+		}
+	}
+
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var recorded []models.ModelInfo
+	for _, name := range names {
+		recorded = append(recorded, models.ModelInfo{
+			ID:          name,
+			Name:        name,
+			Description: "Recorded responses",
+		})
+	}
+	return recorded, nil
+}
+
+// Recorder wraps a provider and records each of its responses as a fixture
+type Recorder struct {
+	provider llm.Provider
+	dir      string
+}
+
+// NewRecorder creates a recorder writing the responses of provider to dir
+func NewRecorder(provider llm.Provider, dir string) *Recorder {
+	return &Recorder{
+		provider: provider,
+		dir:      dir,
+	}
+}
+
+// Name returns the name of the recorded provider
+func (r *Recorder) Name() string {
+	return r.provider.Name()
+}
+
+// Validate validates the configuration of the recorded provider
+func (r *Recorder) Validate(config map[string]string) error {
+	return r.provider.Validate(config)
+}
+
+// ListModels lists the models of the recorded provider
+func (r *Recorder) ListModels(ctx context.Context, apiKey, baseURL string) ([]models.ModelInfo, error) {
+	return r.provider.ListModels(ctx, apiKey, baseURL)
+}
+
+// Generate calls the recorded provider and saves its response
+func (r *Recorder) Generate(ctx context.Context, prompt string, config llm.Config) (*llm.Response, error) {
+	response, err := r.provider.Generate(ctx, prompt, config)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.record(prompt, config, response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// GenerateStream streams from the recorded provider and saves the complete response
+func (r *Recorder) GenerateStream(ctx context.Context, prompt string, config llm.Config, onChunk func(chunk string) error) (*llm.Response, error) {
+	response, err := llm.GenerateStream(ctx, r.provider, prompt, config, onChunk)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.record(prompt, config, response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// record writes the fixture of a response. The file is written next to its
+// final path and renamed, so a replaying process never reads half a fixture.
+func (r *Recorder) record(prompt string, config llm.Config, response *llm.Response) error {
+	fixture := Fixture{
+		Prompt:           prompt,
+		Model:            config.Model,
+		Temperature:      config.Temperature,
+		Provider:         response.Provider,
+		Text:             response.Text,
+		Analysis:         response.Analysis,
+		TokensUsed:       response.TokensUsed,
+		LatencyMs:        response.LatencyMs,
+		ResponseModel:    response.Model,
+		GroundingSources: response.GroundingSources,
+		Citations:        response.Citations,
+		RecordedAt:       time.Now().UTC(),
+	}
+
+	data, err := json.MarshalIndent(fixture, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode fixture: %w", err)
+	}
+
+	if err := os.MkdirAll(r.dir, 0755); err != nil {
+		return fmt.Errorf("failed to create fixture directory: %w", err)
+	}
+
+	path := FixturePath(r.dir, prompt, config.Model, config.Temperature)
+	tmp, err := os.CreateTemp(r.dir, ".fixture-*")
+	if err != nil {
+		return fmt.Errorf("failed to write fixture: %w", err)
+	}
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write fixture: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write fixture: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write fixture: %w", err)
+	}
+
+	return nil
+}
+
+func readFixture(path string) (*Fixture, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var fixture Fixture
+	if err := json.Unmarshal(data, &fixture); err != nil {
+		return nil, fmt.Errorf("invalid fixture %s: %w", path, err)
+	}
+	return &fixture, nil
+}
@@ -0,0 +1,183 @@
+package replay_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/fissionx/gego/internal/llm"
+	"github.com/fissionx/gego/internal/llm/providers"
+	"github.com/fissionx/gego/internal/llm/replay"
+	"github.com/fissionx/gego/internal/models"
+)
+
+// fakeProvider answers every prompt with a canned response and counts its calls
+type fakeProvider struct {
+	calls int
+}
+
+func (f *fakeProvider) Name() string { return "openai" }
+
+func (f *fakeProvider) Validate(config map[string]string) error { return nil }
+
+func (f *fakeProvider) ListModels(ctx context.Context, apiKey, baseURL string) ([]models.ModelInfo, error) {
+	return []models.ModelInfo{{ID: "gpt-4o", Name: "gpt-4o"}}, nil
+}
+
+func (f *fakeProvider) Generate(ctx context.Context, prompt string, config llm.Config) (*llm.Response, error) {
+	f.calls++
+	return &llm.Response{
+		Text:             "Answer to " + prompt,
+		TokensUsed:       42,
+		LatencyMs:        900,
+		Model:            config.Model + "-2024-08-06",
+		Provider:         "openai",
+		GroundingSources: []string{"https://example.com/crm"},
+		Citations: []models.Citation{
+			{URL: "https://example.com/crm", Title: "CRMs", CitedText: "Answer", StartIndex: 0, EndIndex: 6},
+		},
+	}, nil
+}
+
+func TestRecordThenReplay(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	config := llm.Config{Model: "gpt-4o", Temperature: 0.7}
+
+	live := &fakeProvider{}
+	recorded, err := replay.NewRecorder(live, dir).Generate(ctx, "best CRM?", config)
+	if err != nil {
+		t.Fatalf("Generate() while recording: %v", err)
+	}
+
+	replayed, err := replay.New("openai", dir).Generate(ctx, "best CRM?", config)
+	if err != nil {
+		t.Fatalf("Generate() while replaying: %v", err)
+	}
+
+	if live.calls != 1 {
+		t.Errorf("live provider called %d times, want 1", live.calls)
+	}
+	if replayed.Text != recorded.Text || replayed.Model != recorded.Model || replayed.Provider != recorded.Provider {
+		t.Errorf("replayed %+v, want %+v", replayed, recorded)
+	}
+	if replayed.TokensUsed != 42 || replayed.LatencyMs != 900 {
+		t.Errorf("replayed usage = %d tokens, %d ms, want 42 tokens, 900 ms", replayed.TokensUsed, replayed.LatencyMs)
+	}
+	if len(replayed.Citations) != 1 || replayed.Citations[0] != recorded.Citations[0] {
+		t.Errorf("replayed citations = %+v, want %+v", replayed.Citations, recorded.Citations)
+	}
+	if len(replayed.GroundingSources) != 1 || replayed.GroundingSources[0] != "https://example.com/crm" {
+		t.Errorf("replayed grounding sources = %v", replayed.GroundingSources)
+	}
+}
+
+func TestReplayKeyedByPromptModelAndTemperature(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	if _, err := replay.NewRecorder(&fakeProvider{}, dir).Generate(ctx, "best CRM?", llm.Config{Model: "gpt-4o", Temperature: 0.7}); err != nil {
+		t.Fatalf("Generate() while recording: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		prompt string
+		config llm.Config
+		found  bool
+	}{
+		{name: "Same request", prompt: "best CRM?", config: llm.Config{Model: "gpt-4o", Temperature: 0.7}, found: true},
+		{name: "Other settings ignored", prompt: "best CRM?", config: llm.Config{Model: "gpt-4o", Temperature: 0.7, MaxTokens: 50, Brand: "HubSpot"}, found: true},
+		{name: "Other prompt", prompt: "best ERP?", config: llm.Config{Model: "gpt-4o", Temperature: 0.7}},
+		{name: "Other model", prompt: "best CRM?", config: llm.Config{Model: "gpt-4o-mini", Temperature: 0.7}},
+		{name: "Other temperature", prompt: "best CRM?", config: llm.Config{Model: "gpt-4o", Temperature: 0.2}},
+	}
+
+	provider := replay.New("openai", dir)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := provider.Generate(ctx, tt.prompt, tt.config)
+			if tt.found && err != nil {
+				t.Errorf("Generate() error = %v, want a recorded response", err)
+			}
+			if !tt.found && !errors.Is(err, replay.ErrFixtureNotFound) {
+				t.Errorf("Generate() error = %v, want ErrFixtureNotFound", err)
+			}
+		})
+	}
+}
+
+func TestRecordStream(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	config := llm.Config{Model: "gpt-4o", Temperature: 0.7}
+
+	var streamed strings.Builder
+	_, err := replay.NewRecorder(&fakeProvider{}, dir).GenerateStream(ctx, "best CRM?", config, func(chunk string) error {
+		streamed.WriteString(chunk)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("GenerateStream() while recording: %v", err)
+	}
+
+	replayed, err := replay.New("openai", dir).Generate(ctx, "best CRM?", config)
+	if err != nil {
+		t.Fatalf("Generate() while replaying: %v", err)
+	}
+	if replayed.Text != streamed.String() {
+		t.Errorf("replayed %q, streamed %q", replayed.Text, streamed.String())
+	}
+}
+
+func TestReplayListModels(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	recorder := replay.NewRecorder(&fakeProvider{}, dir)
+	for _, model := range []string{"gpt-4o-mini", "gpt-4o", "gpt-4o-mini"} {
+		if _, err := recorder.Generate(ctx, "best CRM for "+model+"?", llm.Config{Model: model}); err != nil {
+			t.Fatalf("Generate() while recording: %v", err)
+		}
+	}
+
+	recordedModels, err := replay.New("openai", dir).ListModels(ctx, "", "")
+	if err != nil {
+		t.Fatalf("ListModels() error = %v", err)
+	}
+	if len(recordedModels) != 2 || recordedModels[0].ID != "gpt-4o" || recordedModels[1].ID != "gpt-4o-mini" {
+		t.Errorf("ListModels() = %+v, want gpt-4o and gpt-4o-mini", recordedModels)
+	}
+
+	if others, _ := replay.New("anthropic", dir).ListModels(ctx, "", ""); len(others) != 0 {
+		t.Errorf("ListModels() of another provider = %+v, want none", others)
+	}
+}
+
+func TestReplayFactory(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	config := llm.Config{Model: "gpt-4o", Temperature: 0.7}
+
+	if _, err := replay.NewRecorder(&fakeProvider{}, dir).Generate(ctx, "best CRM?", config); err != nil {
+		t.Fatalf("Generate() while recording: %v", err)
+	}
+
+	// No API key: replayed providers never reach the network
+	provider, err := providers.NewReplayFactory(dir).Get(&models.LLMConfig{ID: "llm-1", Provider: "openai", Model: "gpt-4o"})
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if provider.Name() != "openai" {
+		t.Errorf("Name() = %q, want openai", provider.Name())
+	}
+
+	response, err := provider.Generate(ctx, "best CRM?", config)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if response.Text != "Answer to best CRM?" {
+		t.Errorf("Generate() = %q", response.Text)
+	}
+}
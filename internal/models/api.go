@@ -88,37 +88,40 @@ type PromptResponse struct {
 
 // CreateScheduleRequest represents the request to create a new schedule
 type CreateScheduleRequest struct {
-	Name        string   `json:"name" binding:"required"`
-	PromptIDs   []string `json:"promptIds" binding:"required"`
-	LLMIDs      []string `json:"llmIds" binding:"required"`
-	CronExpr    string   `json:"cronExpr" binding:"required"`
-	Temperature float64  `json:"temperature,omitempty"`
-	Enabled     bool     `json:"enabled"`
+	Name        string              `json:"name" binding:"required"`
+	PromptIDs   []string            `json:"promptIds" binding:"required"`
+	LLMIDs      []string            `json:"llmIds" binding:"required"`
+	CronExpr    string              `json:"cronExpr" binding:"required"`
+	Temperature float64             `json:"temperature,omitempty"`
+	Variables   map[string][]string `json:"variables,omitempty"` // Value sets of the prompt template variables
+	Enabled     bool                `json:"enabled"`
 }
 
 // UpdateScheduleRequest represents the request to update an existing schedule
 type UpdateScheduleRequest struct {
-	Name        string   `json:"name,omitempty"`
-	PromptIDs   []string `json:"promptIds,omitempty"`
-	LLMIDs      []string `json:"llmIds,omitempty"`
-	CronExpr    string   `json:"cronExpr,omitempty"`
-	Temperature *float64 `json:"temperature,omitempty"`
-	Enabled     *bool    `json:"enabled,omitempty"`
+	Name        string              `json:"name,omitempty"`
+	PromptIDs   []string            `json:"promptIds,omitempty"`
+	LLMIDs      []string            `json:"llmIds,omitempty"`
+	CronExpr    string              `json:"cronExpr,omitempty"`
+	Temperature *float64            `json:"temperature,omitempty"`
+	Variables   map[string][]string `json:"variables,omitempty"` // Replaces the value sets; an empty object clears them
+	Enabled     *bool               `json:"enabled,omitempty"`
 }
 
 // ScheduleResponse represents the response for schedule operations
 type ScheduleResponse struct {
-	ID          string     `json:"id"`
-	Name        string     `json:"name"`
-	PromptIDs   []string   `json:"promptIds"`
-	LLMIDs      []string   `json:"llmIds"`
-	CronExpr    string     `json:"cronExpr"`
-	Temperature float64    `json:"temperature"`
-	Enabled     bool       `json:"enabled"`
-	LastRun     *time.Time `json:"lastRun,omitempty"`
-	NextRun     *time.Time `json:"nextRun,omitempty"`
-	CreatedAt   time.Time  `json:"createdAt"`
-	UpdatedAt   time.Time  `json:"updatedAt"`
+	ID          string              `json:"id"`
+	Name        string              `json:"name"`
+	PromptIDs   []string            `json:"promptIds"`
+	LLMIDs      []string            `json:"llmIds"`
+	CronExpr    string              `json:"cronExpr"`
+	Temperature float64             `json:"temperature"`
+	Variables   map[string][]string `json:"variables,omitempty"`
+	Enabled     bool                `json:"enabled"`
+	LastRun     *time.Time          `json:"lastRun,omitempty"`
+	NextRun     *time.Time          `json:"nextRun,omitempty"`
+	CreatedAt   time.Time           `json:"createdAt"`
+	UpdatedAt   time.Time           `json:"updatedAt"`
 }
 
 // StatsResponse represents the response for statistics
@@ -170,33 +173,34 @@ type ExecuteRequest struct {
 
 // ExecuteResponse represents the response from executing a prompt
 type ExecuteResponse struct {
-	ResponseID  string       `json:"responseId"`
-	PromptID    string       `json:"promptId,omitempty"`
-	Prompt      string       `json:"prompt"`
-	Brand       string       `json:"brand,omitempty"`
-	Response    string       `json:"response"`
-	GEOAnalysis *GEOAnalysis `json:"geoAnalysis,omitempty"`
-	LLMName     string       `json:"llmName"`
-	LLMProvider string       `json:"llmProvider"`
-	LLMModel    string       `json:"llmModel"`
-	Temperature float64      `json:"temperature"`
-	TokensUsed  int          `json:"tokensUsed"`
-	LatencyMs   int64        `json:"latencyMs"`
-	CreatedAt   time.Time    `json:"createdAt"`
+	ResponseID      string           `json:"responseId"`
+	PromptID        string           `json:"promptId,omitempty"`
+	Prompt          string           `json:"prompt"`
+	Brand           string           `json:"brand,omitempty"`
+	Response        string           `json:"response"`
+	GEOAnalysis     *GEOAnalysis     `json:"geoAnalysis,omitempty"`
+	BrandExtraction *BrandExtraction `json:"brandExtraction,omitempty"`
+	LLMName         string           `json:"llmName"`
+	LLMProvider     string           `json:"llmProvider"`
+	LLMModel        string           `json:"llmModel"`
+	Temperature     float64          `json:"temperature"`
+	TokensUsed      int              `json:"tokensUsed"`
+	LatencyMs       int64            `json:"latencyMs"`
+	CreatedAt       time.Time        `json:"createdAt"`
 }
 
 // GEOAnalysis represents the GEO (Generative Engine Optimization) analysis results
 type GEOAnalysis struct {
-	VisibilityScore    int      `json:"visibilityScore"`
-	BrandMentioned     bool     `json:"brandMentioned"`
-	InGroundingSources bool     `json:"inGroundingSources"`
-	MentionStatus      string   `json:"mentionStatus"`
-	Reason             string   `json:"reason"`
-	Insights           []string `json:"insights"`
-	Actions            []string `json:"actions"`
-	CompetitorInfo     string   `json:"competitorInfo,omitempty"`
-	Competitors        []string `json:"competitors,omitempty"`
-	Sentiment          string   `json:"sentiment,omitempty"`
+	VisibilityScore    int      `json:"visibilityScore" bson:"visibility_score"`
+	BrandMentioned     bool     `json:"brandMentioned" bson:"brand_mentioned"`
+	InGroundingSources bool     `json:"inGroundingSources" bson:"in_grounding_sources"`
+	MentionStatus      string   `json:"mentionStatus" bson:"mention_status"`
+	Reason             string   `json:"reason" bson:"reason"`
+	Insights           []string `json:"insights" bson:"insights"`
+	Actions            []string `json:"actions" bson:"actions"`
+	CompetitorInfo     string   `json:"competitorInfo,omitempty" bson:"competitor_info,omitempty"`
+	Competitors        []string `json:"competitors,omitempty" bson:"competitors,omitempty"`
+	Sentiment          string   `json:"sentiment,omitempty" bson:"sentiment,omitempty"`
 }
 
 // GeneratePromptsRequest represents the request to generate prompts for a brand
@@ -232,11 +236,12 @@ type PromptPreview struct {
 
 // BulkExecuteRequest represents the request to execute multiple prompts across multiple LLMs
 type BulkExecuteRequest struct {
-	CampaignName string   `json:"campaignName" binding:"required"`
-	Brand        string   `json:"brand" binding:"required"`
-	PromptIDs    []string `json:"promptIds" binding:"required"`
-	LLMIDs       []string `json:"llmIds" binding:"required"`
-	Temperature  float64  `json:"temperature,omitempty"`
+	CampaignName string              `json:"campaignName" binding:"required"`
+	Brand        string              `json:"brand" binding:"required"`
+	PromptIDs    []string            `json:"promptIds" binding:"required"`
+	LLMIDs       []string            `json:"llmIds" binding:"required"`
+	Temperature  float64             `json:"temperature,omitempty"`
+	Variables    map[string][]string `json:"variables,omitempty"` // Value sets of the prompt template variables, e.g. {"city": ["Paris", "Berlin"]}
 }
 
 // BulkExecuteResponse represents the response from bulk execution
@@ -300,7 +305,8 @@ type CategoryPerformance struct {
 	ResponseCount int     `json:"responseCount"`
 }
 
-// TrendPoint represents a time-series data point
+// TrendPoint represents a time-series data point: the metrics of a brand over
+// an ISO week such as 2025-W07
 type TrendPoint struct {
 	Date       string  `json:"date"`
 	Visibility float64 `json:"visibility"`
@@ -336,6 +342,7 @@ type SourceAnalyticsResponse struct {
 	Recommendations []Recommendation `json:"recommendations"`
 	TotalSources    int              `json:"totalSources"`
 	TotalCitations  int              `json:"totalCitations"`
+	BrandInSources  bool             `json:"brandInSources"` // Brand appears in the grounding sources of a response
 }
 
 // CompetitiveBenchmarkRequest represents request for competitive analysis
@@ -376,6 +383,51 @@ type CompetitiveBenchmarkResponse struct {
 	AnalyzedAt      time.Time                   `json:"analyzedAt"`
 }
 
+// ShareOfVoiceTrendsRequest requests the share of voice of a brand and its
+// competitors over time
+type ShareOfVoiceTrendsRequest struct {
+	MainBrand   string     `json:"mainBrand" binding:"required"`
+	Competitors []string   `json:"competitors,omitempty"` // The most mentioned competitors when empty
+	Interval    string     `json:"interval,omitempty"`    // week (default), month, quarter, or a duration such as 7d or 12h
+	PromptIDs   []string   `json:"promptIds,omitempty"`
+	LLMIDs      []string   `json:"llmIds,omitempty"`
+	StartTime   *time.Time `json:"startTime,omitempty"` // Duration buckets start here, or on the Unix epoch
+	EndTime     *time.Time `json:"endTime,omitempty"`
+	Region      string     `json:"region,omitempty"`
+}
+
+// ShareOfVoiceTrendsResponse lists the metrics of the brands per period, oldest
+// first. Periods without responses are omitted.
+type ShareOfVoiceTrendsResponse struct {
+	MainBrand   string        `json:"mainBrand"`
+	Competitors []string      `json:"competitors"`
+	Interval    string        `json:"interval"`
+	Buckets     []TrendBucket `json:"buckets"`
+}
+
+// TrendBucket holds the metrics of the brands over one period
+type TrendBucket struct {
+	Period         string            `json:"period"` // e.g. 2025-W07, 2025-02, 2025-Q1, or the start time of duration buckets
+	Start          time.Time         `json:"start"`
+	End            time.Time         `json:"end"`
+	TotalResponses int               `json:"totalResponses"` // Responses of the main brand
+	Brands         []BrandTrendPoint `json:"brands"`         // Main brand first, then the competitors
+}
+
+// BrandTrendPoint holds the metrics of a brand over one period. Mentions,
+// mention rate and share of voice are counted in the responses of the main
+// brand; visibility and position come from the brand's own responses, so
+// they are zero for competitors that are not tracked.
+type BrandTrendPoint struct {
+	Brand           string  `json:"brand"`
+	Mentions        int     `json:"mentions"`
+	MentionRate     float64 `json:"mentionRate"`
+	ShareOfVoice    float64 `json:"shareOfVoice"` // Share of the mentions of all the brands
+	Visibility      float64 `json:"visibility"`
+	AveragePosition float64 `json:"averagePosition"`
+	ResponseCount   int     `json:"responseCount"` // Responses of the brand itself
+}
+
 // PromptCompetitiveAnalysis shows competitive performance for a specific prompt
 type PromptCompetitiveAnalysis struct {
 	PromptID             string                    `json:"promptId"`
@@ -469,3 +521,11 @@ type PromptPerformance struct {
 	Status             string  `json:"status"`
 	Recommendation     string  `json:"recommendation"`
 }
+
+// BrandEntitiesRequest represents the alias and entity dictionary of a brand profile
+type BrandEntitiesRequest struct {
+	Aliases          []string `json:"aliases"`
+	ProductNames     []string `json:"productNames"`
+	Domains          []string `json:"domains"`
+	NegativePatterns []string `json:"negativePatterns"`
+}
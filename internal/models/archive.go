@@ -0,0 +1,43 @@
+package models
+
+import (
+	"time"
+)
+
+// ArchiveFormat identifies gego archives
+const ArchiveFormat = "gego-archive"
+
+// ArchiveVersion is the version of the archive layout written by this build
+const ArchiveVersion = 1
+
+// Archive is a portable snapshot of the data of both databases, written by
+// `gego export` and read by `gego import`
+type Archive struct {
+	Format          string           `json:"format"`
+	Version         int              `json:"version"`
+	ExportedAt      time.Time        `json:"exportedAt"`
+	IncludesSecrets bool             `json:"includesSecrets"` // LLM API keys are stored in plain text
+	LLMs            []*LLMConfig     `json:"llms"`
+	Schedules       []*Schedule      `json:"schedules"`
+	Prompts         []*Prompt        `json:"prompts"`
+	PromptLibraries []*PromptLibrary `json:"promptLibraries"`
+	BrandProfiles   []*BrandProfile  `json:"brandProfiles"`
+	Responses       []*Response      `json:"responses"`
+}
+
+// ImportCounts counts the records of one kind handled by an import
+type ImportCounts struct {
+	Created  int `json:"created"`
+	Existing int `json:"existing"` // Already present, or merged into a matching record
+}
+
+// ImportResult summarises an import
+type ImportResult struct {
+	LLMs            ImportCounts `json:"llms"`
+	Schedules       ImportCounts `json:"schedules"`
+	Prompts         ImportCounts `json:"prompts"`
+	PromptLibraries ImportCounts `json:"promptLibraries"`
+	BrandProfiles   ImportCounts `json:"brandProfiles"`
+	Responses       ImportCounts `json:"responses"`
+	Warnings        []string     `json:"warnings,omitempty"`
+}
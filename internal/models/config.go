@@ -4,8 +4,9 @@ package models
 
 // Config holds database configuration
 type Config struct {
-	Provider string            // sqlite, mongodb, cassandra
-	URI      string            // Connection URI
-	Database string            // Database name
-	Options  map[string]string // Provider-specific options
+	Provider        string            // sqlite, mongodb, cassandra
+	URI             string            // Connection URI
+	Database        string            // Database name
+	Options         map[string]string // Provider-specific options
+	SkipSchemaCheck bool              // Connect to a database whose schema is not at the latest version, to migrate it
 }
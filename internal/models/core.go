@@ -29,11 +29,13 @@ type LLMConfig struct {
 	Enabled   bool              `json:"enabled"`
 	CreatedAt time.Time         `json:"createdAt"`
 	UpdatedAt time.Time         `json:"updatedAt"`
+	KeyError  string            `json:"keyError,omitempty"` // Set when listed with an API key that cannot be decrypted
 }
 
 // Prompt represents a prompt template
 type Prompt struct {
 	ID         string     `json:"id" bson:"_id"`
+	Name       string     `json:"name,omitempty" bson:"name,omitempty"` // Stable name of prompts managed by a workspace file
 	Template   string     `json:"template" bson:"template"`
 	PromptType PromptType `json:"promptType,omitempty" bson:"prompt_type,omitempty"`
 	Tags       []string   `json:"tags,omitempty" bson:"tags,omitempty"`
@@ -48,17 +50,50 @@ type Prompt struct {
 
 // Schedule represents a scheduler configuration
 type Schedule struct {
-	ID          string     `json:"id"`
-	Name        string     `json:"name"`
-	PromptIDs   []string   `json:"promptIds"`
-	LLMIDs      []string   `json:"llmIds"`
-	CronExpr    string     `json:"cronExpr"`
-	Temperature float64    `json:"temperature,omitempty"`
-	Enabled     bool       `json:"enabled"`
-	LastRun     *time.Time `json:"lastRun,omitempty"`
-	NextRun     *time.Time `json:"nextRun,omitempty"`
-	CreatedAt   time.Time  `json:"createdAt"`
-	UpdatedAt   time.Time  `json:"updatedAt"`
+	ID          string              `json:"id"`
+	Name        string              `json:"name"`
+	PromptIDs   []string            `json:"promptIds"`
+	LLMIDs      []string            `json:"llmIds"`
+	CronExpr    string              `json:"cronExpr"`
+	Temperature float64             `json:"temperature,omitempty"`
+	Variables   map[string][]string `json:"variables,omitempty"` // Value sets of the prompt template variables, expanded into one run per combination
+	Enabled     bool                `json:"enabled"`
+	LastRun     *time.Time          `json:"lastRun,omitempty"`
+	NextRun     *time.Time          `json:"nextRun,omitempty"`
+	CreatedAt   time.Time           `json:"createdAt"`
+	UpdatedAt   time.Time           `json:"updatedAt"`
+}
+
+// API key scopes
+const (
+	APIKeyScopeRead    = "read"    // Listings, stats, search and analytics
+	APIKeyScopeManage  = "manage"  // Create, update and delete LLMs, prompts, schedules and brand profiles
+	APIKeyScopeExecute = "execute" // Run prompts against LLMs, which spends provider budget
+)
+
+// APIKeyScopes lists all API key scopes
+var APIKeyScopes = []string{APIKeyScopeRead, APIKeyScopeManage, APIKeyScopeExecute}
+
+// APIKey represents a key for the REST API. Only the SHA-256 hash of the key is stored.
+type APIKey struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"` // Leading characters of the key, to recognise it
+	KeyHash    string     `json:"-"`
+	Scopes     []string   `json:"scopes"`
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
+	RevokedAt  *time.Time `json:"revokedAt,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt"`
+}
+
+// HasScope reports whether the key grants the given scope
+func (k *APIKey) HasScope(scope string) bool {
+	for _, s := range k.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
 }
 
 // Response represents an LLM response to a prompt
@@ -75,10 +110,18 @@ type Response struct {
 	Temperature  float64                `json:"temperature,omitempty" bson:"temperature,omitempty"`
 	Metadata     map[string]interface{} `json:"metadata,omitempty" bson:"metadata,omitempty"`
 	ScheduleID   string                 `json:"scheduleId,omitempty" bson:"schedule_id,omitempty"`
+	CampaignID   string                 `json:"campaignId,omitempty" bson:"campaign_id,omitempty"`
+	Variables    map[string]string      `json:"variables,omitempty" bson:"variables,omitempty"` // Template variables bound in PromptText
 	TokensUsed   int                    `json:"tokensUsed,omitempty" bson:"tokens_used,omitempty"`
 	LatencyMs    int64                  `json:"latencyMs,omitempty" bson:"latency_ms,omitempty"`
 	Error        string                 `json:"error,omitempty" bson:"error,omitempty"`
 
+	// Prompt metadata, snapshotted when the prompt was executed
+	PromptCategory string     `json:"promptCategory,omitempty" bson:"prompt_category,omitempty"`
+	PromptDomain   string     `json:"promptDomain,omitempty" bson:"prompt_domain,omitempty"`
+	PromptType     PromptType `json:"promptType,omitempty" bson:"prompt_type,omitempty"`
+	PromptTags     []string   `json:"promptTags,omitempty" bson:"prompt_tags,omitempty"`
+
 	// GEO Analysis fields
 	VisibilityScore    int      `json:"visibilityScore,omitempty" bson:"visibility_score,omitempty"`
 	BrandMentioned     bool     `json:"brandMentioned,omitempty" bson:"brand_mentioned,omitempty"`
@@ -87,14 +130,24 @@ type Response struct {
 	Sentiment          string   `json:"sentiment,omitempty" bson:"sentiment,omitempty"`
 	CompetitorsMention []string `json:"competitorsMention,omitempty" bson:"competitors_mention,omitempty"`
 
+	// Structured GEO analysis, and the analyser output it was parsed from.
+	// ResponseText only ever holds the answer itself.
+	Analysis    *GEOAnalysis `json:"analysis,omitempty" bson:"analysis,omitempty"`
+	AnalysisRaw string       `json:"analysisRaw,omitempty" bson:"analysis_raw,omitempty"`
+
 	// Position/Ranking tracking
 	BrandPosition     int `json:"brandPosition,omitempty" bson:"brand_position,omitempty"`
 	TotalBrandsListed int `json:"totalBrandsListed,omitempty" bson:"total_brands_listed,omitempty"`
 
 	// Enhanced source analytics
-	GroundingDomains []string `json:"groundingDomains,omitempty" bson:"grounding_domains,omitempty"`
+	GroundingDomains []string   `json:"groundingDomains,omitempty" bson:"grounding_domains,omitempty"`
+	SourceCategories []string   `json:"sourceCategories,omitempty" bson:"source_categories,omitempty"` // Categories of the grounding domains, from the source taxonomy
+	Citations        []Citation `json:"citations,omitempty" bson:"citations,omitempty"`
 
-	// Time-series support
+	// Rule-based brand detection, stored alongside the LLM judgement
+	BrandExtraction *BrandExtraction `json:"brandExtraction,omitempty" bson:"brand_extraction,omitempty"`
+
+	// Calendar periods of CreatedAt in UTC, set when the response is stored
 	Week    string `json:"week,omitempty" bson:"week,omitempty"`
 	Month   string `json:"month,omitempty" bson:"month,omitempty"`
 	Quarter string `json:"quarter,omitempty" bson:"quarter,omitempty"`
@@ -106,6 +159,26 @@ type Response struct {
 	CreatedAt time.Time `json:"createdAt" bson:"created_at"`
 }
 
+// BrandExtraction represents the deterministic (non-LLM) brand detection for a response
+type BrandExtraction struct {
+	Mentioned          bool     `json:"mentioned" bson:"mentioned"`
+	MentionCount       int      `json:"mentionCount" bson:"mention_count"`
+	FirstMentionOffset int      `json:"firstMentionOffset" bson:"first_mention_offset"` // Character offset, -1 when not mentioned
+	ListRank           int      `json:"listRank,omitempty" bson:"list_rank,omitempty"`  // 1-based rank in the first list/table mentioning the brand
+	ListSize           int      `json:"listSize,omitempty" bson:"list_size,omitempty"`  // Number of items in that list/table
+	MatchedAliases     []string `json:"matchedAliases,omitempty" bson:"matched_aliases,omitempty"`
+}
+
+// Citation is a web source an LLM cited in its answer, normalised across providers
+type Citation struct {
+	URL          string `json:"url" bson:"url"`
+	CanonicalURL string `json:"canonicalUrl,omitempty" bson:"canonical_url,omitempty"` // Publisher URL behind a redirect URL
+	Title        string `json:"title,omitempty" bson:"title,omitempty"`
+	CitedText    string `json:"citedText,omitempty" bson:"cited_text,omitempty"`   // Span of the answer backed by the source
+	StartIndex   int    `json:"startIndex,omitempty" bson:"start_index,omitempty"` // Byte offset of the span in the answer
+	EndIndex     int    `json:"endIndex,omitempty" bson:"end_index,omitempty"`     // Exclusive byte offset of the span end
+}
+
 // ModelInfo represents information about an available model from a provider
 type ModelInfo struct {
 	ID          string `json:"id"`
@@ -140,28 +213,86 @@ type PromptLibrary struct {
 
 // BrandProfile represents metadata about a brand for better categorization
 type BrandProfile struct {
-	ID          string    `json:"id" bson:"_id"`
-	BrandName   string    `json:"brandName" bson:"brand_name"`
-	Domain      string    `json:"domain" bson:"domain"`
-	Category    string    `json:"category" bson:"category"`
-	Website     string    `json:"website,omitempty" bson:"website,omitempty"`
-	Description string    `json:"description,omitempty" bson:"description,omitempty"`
-	Competitors []string  `json:"competitors,omitempty" bson:"competitors,omitempty"`
-	CreatedAt   time.Time `json:"createdAt" bson:"created_at"`
-	UpdatedAt   time.Time `json:"updatedAt" bson:"updated_at"`
+	ID          string   `json:"id" bson:"_id"`
+	BrandName   string   `json:"brandName" bson:"brand_name"`
+	Domain      string   `json:"domain" bson:"domain"`
+	Category    string   `json:"category" bson:"category"`
+	Website     string   `json:"website,omitempty" bson:"website,omitempty"`
+	Description string   `json:"description,omitempty" bson:"description,omitempty"`
+	Competitors []string `json:"competitors,omitempty" bson:"competitors,omitempty"`
+
+	// Entity dictionary used by every brand detection path
+	Aliases          []string `json:"aliases,omitempty" bson:"aliases,omitempty"`
+	ProductNames     []string `json:"productNames,omitempty" bson:"product_names,omitempty"`
+	Domains          []string `json:"domains,omitempty" bson:"domains,omitempty"`
+	NegativePatterns []string `json:"negativePatterns,omitempty" bson:"negative_patterns,omitempty"` // Case-insensitive regexes that must not count as a mention
+
+	CreatedAt time.Time `json:"createdAt" bson:"created_at"`
+	UpdatedAt time.Time `json:"updatedAt" bson:"updated_at"`
+}
+
+// Job kinds, one per service that executes queued work
+const (
+	JobKindCampaign = "campaign"
+	JobKindSchedule = "schedule"
+)
+
+// Job statuses
+const (
+	JobStatusPending   = "pending"
+	JobStatusLeased    = "leased"
+	JobStatusDone      = "done"
+	JobStatusDead      = "dead"
+	JobStatusCancelled = "cancelled"
+)
+
+// Job is a unit of work in the durable execution queue: one prompt run against one LLM
+type Job struct {
+	ID             string            `json:"id" bson:"_id"`
+	Kind           string            `json:"kind" bson:"kind"`
+	PromptID       string            `json:"promptId" bson:"prompt_id"`
+	LLMID          string            `json:"llmId" bson:"llm_id"`
+	CampaignID     string            `json:"campaignId,omitempty" bson:"campaign_id,omitempty"`
+	ScheduleID     string            `json:"scheduleId,omitempty" bson:"schedule_id,omitempty"`
+	Brand          string            `json:"brand,omitempty" bson:"brand,omitempty"`
+	Temperature    float64           `json:"temperature" bson:"temperature"`
+	Variables      map[string]string `json:"variables,omitempty" bson:"variables,omitempty"` // Template variables bound for this run
+	Status         string            `json:"status" bson:"status"`
+	Attempts       int               `json:"attempts" bson:"attempts"`
+	MaxAttempts    int               `json:"maxAttempts" bson:"max_attempts"`
+	LastError      string            `json:"lastError,omitempty" bson:"last_error,omitempty"`
+	LeaseOwner     string            `json:"leaseOwner,omitempty" bson:"lease_owner,omitempty"`
+	LeaseExpiresAt *time.Time        `json:"leaseExpiresAt,omitempty" bson:"lease_expires_at,omitempty"`
+	AvailableAt    time.Time         `json:"availableAt" bson:"available_at"`
+	CompletedAt    *time.Time        `json:"completedAt,omitempty" bson:"completed_at,omitempty"`
+	CreatedAt      time.Time         `json:"createdAt" bson:"created_at"`
+	UpdatedAt      time.Time         `json:"updatedAt" bson:"updated_at"`
 }
 
+// Campaign statuses
+const (
+	CampaignStatusRunning   = "running"
+	CampaignStatusCompleted = "completed"
+	CampaignStatusFailed    = "failed"
+	CampaignStatusCancelled = "cancelled"
+)
+
 // GEOCampaign represents a GEO analysis campaign for a brand
 type GEOCampaign struct {
-	ID          string     `json:"id" bson:"_id"`
-	Name        string     `json:"name" bson:"name"`
-	BrandID     string     `json:"brandId" bson:"brand_id"`
-	Brand       string     `json:"brand" bson:"brand"`
-	PromptIDs   []string   `json:"promptIds" bson:"prompt_ids"`
-	LLMIDs      []string   `json:"llmIds" bson:"llm_ids"`
-	Status      string     `json:"status" bson:"status"`
-	TotalRuns   int        `json:"totalRuns" bson:"total_runs"`
-	CompletedAt *time.Time `json:"completedAt,omitempty" bson:"completed_at,omitempty"`
-	CreatedAt   time.Time  `json:"createdAt" bson:"created_at"`
-	UpdatedAt   time.Time  `json:"updatedAt" bson:"updated_at"`
+	ID            string              `json:"id" bson:"_id"`
+	Name          string              `json:"name" bson:"name"`
+	BrandID       string              `json:"brandId" bson:"brand_id"`
+	Brand         string              `json:"brand" bson:"brand"`
+	PromptIDs     []string            `json:"promptIds" bson:"prompt_ids"`
+	LLMIDs        []string            `json:"llmIds" bson:"llm_ids"`
+	Temperature   float64             `json:"temperature" bson:"temperature"`
+	Variables     map[string][]string `json:"variables,omitempty" bson:"variables,omitempty"` // Value sets of the prompt template variables
+	Status        string              `json:"status" bson:"status"`
+	Error         string              `json:"error,omitempty" bson:"error,omitempty"`
+	TotalRuns     int                 `json:"totalRuns" bson:"total_runs"`
+	CompletedRuns int                 `json:"completedRuns" bson:"completed_runs"`
+	FailedRuns    int                 `json:"failedRuns" bson:"failed_runs"`
+	CompletedAt   *time.Time          `json:"completedAt,omitempty" bson:"completed_at,omitempty"`
+	CreatedAt     time.Time           `json:"createdAt" bson:"created_at"`
+	UpdatedAt     time.Time           `json:"updatedAt" bson:"updated_at"`
 }
@@ -0,0 +1,102 @@
+package models
+
+import "time"
+
+// Playbook scopes: the metrics a rule is evaluated against
+const (
+	PlaybookScopeSource      = "source"      // Each cited source of a source analysis
+	PlaybookScopeSources     = "sources"     // All cited sources of a source analysis
+	PlaybookScopeCompetitive = "competitive" // A competitive benchmark
+	PlaybookScopePosition    = "position"    // The list positions of a brand
+)
+
+// PlaybookFile is the user-editable recommendation playbook file. Each of its
+// playbooks patches the built-in rules.
+type PlaybookFile struct {
+	Playbooks []Playbook `yaml:"playbooks" json:"playbooks"`
+}
+
+// Playbook is a set of recommendation rules. A playbook without brands
+// applies to every brand; one listing brands is applied on top of it for
+// those brands only.
+type Playbook struct {
+	Name   string         `yaml:"name" json:"name"`
+	Brands []string       `yaml:"brands,omitempty" json:"brands,omitempty"`
+	Rules  []PlaybookRule `yaml:"rules" json:"rules"`
+}
+
+// PlaybookRule recommends an action when all of its conditions hold. The text
+// fields are Go templates over the metrics of the scope. A rule with the id of
+// an earlier rule replaces the fields it sets; Disabled removes the rule.
+type PlaybookRule struct {
+	ID          string              `yaml:"id" json:"id"`
+	Scope       string              `yaml:"scope,omitempty" json:"scope,omitempty"`
+	When        []PlaybookCondition `yaml:"when,omitempty" json:"when,omitempty"`
+	Disabled    bool                `yaml:"disabled,omitempty" json:"disabled,omitempty"`
+	Type        string              `yaml:"type,omitempty" json:"type,omitempty"`
+	Priority    string              `yaml:"priority,omitempty" json:"priority,omitempty"`
+	Impact      string              `yaml:"impact,omitempty" json:"impact,omitempty"`
+	Title       string              `yaml:"title,omitempty" json:"title,omitempty"`
+	Description string              `yaml:"description,omitempty" json:"description,omitempty"`
+	Action      string              `yaml:"action,omitempty" json:"action,omitempty"`
+}
+
+// PlaybookCondition compares a metric with a value. Op is one of eq, ne, gt,
+// gte, lt, lte, contains (a substring, or an element of a list metric) and in
+// (one of a list of values).
+type PlaybookCondition struct {
+	Field string      `yaml:"field" json:"field"`
+	Op    string      `yaml:"op" json:"op"`
+	Value interface{} `yaml:"value" json:"value"`
+}
+
+// PlaybooksResponse lists the recommendation playbooks
+type PlaybooksResponse struct {
+	Playbooks []Playbook        `json:"playbooks"` // Built-in playbook, then those of the playbook file
+	Effective EffectivePlaybook `json:"effective"` // Rules in effect for the requested brand
+}
+
+// EffectivePlaybook is the result of applying the playbooks of a brand
+type EffectivePlaybook struct {
+	Brand     string         `json:"brand,omitempty"`
+	Playbooks []string       `json:"playbooks"` // Playbooks applied, built-in first
+	Rules     []PlaybookRule `json:"rules"`
+}
+
+// PlaybookDryRunRequest evaluates the playbook of a brand against its data
+type PlaybookDryRunRequest struct {
+	Brand       string     `json:"brand" binding:"required"`
+	Competitors []string   `json:"competitors,omitempty"` // Evaluates the competitive rules when set
+	StartTime   *time.Time `json:"startTime,omitempty"`
+	EndTime     *time.Time `json:"endTime,omitempty"`
+
+	// Rules applied on top of the brand's playbook, to try changes before
+	// saving them
+	Rules []PlaybookRule `json:"rules,omitempty"`
+}
+
+// PlaybookDryRunResponse reports every rule evaluation of a dry run
+type PlaybookDryRunResponse struct {
+	Brand           string               `json:"brand"`
+	Playbooks       []string             `json:"playbooks"` // Playbooks applied, built-in first
+	Results         []PlaybookRuleResult `json:"results"`
+	Recommendations []Recommendation     `json:"recommendations"`
+}
+
+// PlaybookRuleResult is the evaluation of a rule against one set of metrics
+type PlaybookRuleResult struct {
+	RuleID         string                    `json:"ruleId"`
+	Scope          string                    `json:"scope"`
+	Subject        string                    `json:"subject,omitempty"` // Source domain of source rules
+	Fired          bool                      `json:"fired"`
+	Conditions     []PlaybookConditionResult `json:"conditions"`
+	Recommendation *Recommendation           `json:"recommendation,omitempty"`
+	Error          string                    `json:"error,omitempty"`
+}
+
+// PlaybookConditionResult is the evaluation of a condition
+type PlaybookConditionResult struct {
+	PlaybookCondition
+	Actual interface{} `json:"actual"`
+	Passed bool        `json:"passed"`
+}
@@ -0,0 +1,47 @@
+package models
+
+// SourceTaxonomy is the user-editable classification of cited domains, read
+// from the source taxonomy file. Its rules come before those of the enabled
+// industry packs and of the default pack, which is always enabled.
+type SourceTaxonomy struct {
+	Packs []string     `yaml:"packs" json:"packs"`
+	Rules []SourceRule `yaml:"rules" json:"rules"`
+}
+
+// SourceRule assigns a category to the domains it matches. Suffix matches the
+// domain and its subdomains (g2.com matches www.g2.com but not myg2.com);
+// Regex is matched against the whole domain. A rule sets one of the two.
+type SourceRule struct {
+	Suffix   string `yaml:"suffix,omitempty" json:"suffix,omitempty"`
+	Regex    string `yaml:"regex,omitempty" json:"regex,omitempty"`
+	Category string `yaml:"category" json:"category"`
+}
+
+// SourceOverrides replaces the categories of domains and their subdomains,
+// whatever the rules say. It is the file managed through the API.
+type SourceOverrides struct {
+	Overrides map[string][]string `yaml:"overrides" json:"overrides"`
+}
+
+// SourceTaxonomyResponse describes the active source classification
+type SourceTaxonomyResponse struct {
+	Packs          []string            `json:"packs"`          // Enabled industry packs
+	AvailablePacks []string            `json:"availablePacks"` // Built-in industry packs
+	Rules          []SourceRule        `json:"rules"`          // Rules of the taxonomy file
+	Overrides      map[string][]string `json:"overrides"`
+}
+
+// SetSourceOverrideRequest sets the categories of a domain
+type SetSourceOverrideRequest struct {
+	Categories []string `json:"categories" binding:"required,min=1"`
+}
+
+// ClassifySourcesRequest lists the domains to classify
+type ClassifySourcesRequest struct {
+	Domains []string `json:"domains" binding:"required,min=1"`
+}
+
+// ReclassifySourcesResponse reports a reclassification of stored responses
+type ReclassifySourcesResponse struct {
+	Updated int `json:"updated"` // Responses whose source categories changed
+}
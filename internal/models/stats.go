@@ -48,3 +48,26 @@ type KeywordStats struct {
 	FirstSeen     time.Time      `json:"firstSeen"`
 	LastSeen      time.Time      `json:"lastSeen"`
 }
+
+// ResponseMetrics holds GEO metrics aggregated over a group of responses
+type ResponseMetrics struct {
+	Key               string  `json:"key" bson:"_id"` // Group key (prompt ID, category, sentiment...), empty for totals
+	LLMName           string  `json:"llmName,omitempty" bson:"llm_name,omitempty"`
+	LLMProvider       string  `json:"llmProvider,omitempty" bson:"llm_provider,omitempty"`
+	TotalResponses    int     `json:"totalResponses" bson:"total_responses"`
+	TotalVisibility   int     `json:"totalVisibility" bson:"total_visibility"`
+	MentionCount      int     `json:"mentionCount" bson:"mention_count"`
+	GroundedCount     int     `json:"groundedCount" bson:"grounded_count"`
+	PositionSum       int     `json:"positionSum" bson:"position_sum"`
+	PositionCount     int     `json:"positionCount" bson:"position_count"`
+	TopPositionCount  int     `json:"topPositionCount" bson:"top_position_count"` // Positions 1-3
+	SentimentScoreSum float64 `json:"sentimentScoreSum" bson:"sentiment_score_sum"`
+	SentimentCount    int     `json:"sentimentCount" bson:"sentiment_count"`
+}
+
+// SourceDomainStats represents how often a domain was cited across responses
+type SourceDomainStats struct {
+	Domain        string         `json:"domain" bson:"_id"`
+	CitationCount int            `json:"citationCount" bson:"citation_count"`
+	LLMBreakdown  map[string]int `json:"llmBreakdown" bson:"-"`
+}
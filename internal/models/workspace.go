@@ -0,0 +1,92 @@
+package models
+
+// Workspace is the desired state of a gego setup, read by `gego apply` from a
+// YAML or JSON file. Records are matched against the databases by stable name.
+// A nil section leaves the records of its kind untouched. In a declared one,
+// the LLMs, prompts and schedules missing from the file are disabled; prompt
+// libraries and brand profiles have no enabled flag and are kept.
+type Workspace struct {
+	LLMs            []WorkspaceLLM           `yaml:"llms"`
+	Prompts         []WorkspacePrompt        `yaml:"prompts"`
+	PromptLibraries []WorkspacePromptLibrary `yaml:"prompt_libraries"`
+	BrandProfiles   []WorkspaceBrandProfile  `yaml:"brand_profiles"`
+	Schedules       []WorkspaceSchedule      `yaml:"schedules"`
+}
+
+// WorkspaceLLM is an LLM configuration, matched by name
+type WorkspaceLLM struct {
+	Name           string            `yaml:"name"`
+	Provider       string            `yaml:"provider"`
+	Model          string            `yaml:"model"`
+	APIKey         string            `yaml:"api_key"` // Kept as stored when empty; env: and file: references keep secrets out of the file
+	BaseURL        string            `yaml:"base_url"`
+	Headers        map[string]string `yaml:"headers"`
+	ModelsEndpoint string            `yaml:"models_endpoint"`
+	Enabled        *bool             `yaml:"enabled"` // Defaults to true
+}
+
+// WorkspacePrompt is a prompt, matched by name
+type WorkspacePrompt struct {
+	Name     string   `yaml:"name"`
+	Template string   `yaml:"template"`
+	Tags     []string `yaml:"tags"`
+	Category string   `yaml:"category"`
+	Domain   string   `yaml:"domain"`
+	Brand    string   `yaml:"brand"`
+	Enabled  *bool    `yaml:"enabled"` // Defaults to true
+}
+
+// WorkspacePromptLibrary is a prompt library, matched by brand, domain and category
+type WorkspacePromptLibrary struct {
+	Brand    string   `yaml:"brand"`
+	Domain   string   `yaml:"domain"`
+	Category string   `yaml:"category"`
+	Prompts  []string `yaml:"prompts"` // Prompt names
+}
+
+// WorkspaceBrandProfile is a brand profile, matched by brand name
+type WorkspaceBrandProfile struct {
+	BrandName        string   `yaml:"brand_name"`
+	Domain           string   `yaml:"domain"`
+	Category         string   `yaml:"category"`
+	Website          string   `yaml:"website"`
+	Description      string   `yaml:"description"`
+	Competitors      []string `yaml:"competitors"`
+	Aliases          []string `yaml:"aliases"`
+	ProductNames     []string `yaml:"product_names"`
+	Domains          []string `yaml:"domains"`
+	NegativePatterns []string `yaml:"negative_patterns"`
+}
+
+// WorkspaceSchedule is a schedule, matched by name
+type WorkspaceSchedule struct {
+	Name        string              `yaml:"name"`
+	Prompts     []string            `yaml:"prompts"` // Prompt names
+	LLMs        []string            `yaml:"llms"`    // LLM names
+	Cron        string              `yaml:"cron"`
+	Temperature string              `yaml:"temperature"` // 0.0-1.0 or random, 0.7 by default
+	Variables   map[string][]string `yaml:"variables"`   // Values of the prompt template variables
+	Enabled     *bool               `yaml:"enabled"`     // Defaults to true
+}
+
+// Workspace change actions
+const (
+	WorkspaceCreate  = "create"
+	WorkspaceUpdate  = "update"
+	WorkspaceDisable = "disable"
+)
+
+// WorkspaceChange is one change applying a workspace makes
+type WorkspaceChange struct {
+	Kind   string        `json:"kind"` // llm, prompt, prompt_library, brand_profile or schedule
+	Name   string        `json:"name"`
+	Action string        `json:"action"`
+	Fields []FieldChange `json:"fields,omitempty"`
+}
+
+// FieldChange is the old and new value of a field, formatted for display
+type FieldChange struct {
+	Field string `json:"field"`
+	Old   string `json:"old,omitempty"`
+	New   string `json:"new,omitempty"`
+}
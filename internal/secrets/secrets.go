@@ -0,0 +1,321 @@
+// Package secrets encrypts provider API keys at rest and resolves API key
+// references.
+//
+// Keys are envelope encrypted: every value gets its own random data key, which
+// encrypts the value and is itself encrypted ("wrapped") by the master key.
+// Rotating the master key only rewraps the data keys.
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+// MasterKeyEnv holds a base64 encoded master key. It takes precedence over
+// the master key file.
+const MasterKeyEnv = "GEGO_MASTER_KEY"
+
+// MasterKeySize is the size of master and data keys (AES-256)
+const MasterKeySize = 32
+
+// Stored value and reference prefixes
+const (
+	encryptedPrefix = "enc:v1:"
+	envPrefix       = "env:"
+	filePrefix      = "file:"
+)
+
+var (
+	defaultKeyring *Keyring
+	defaultMu      sync.RWMutex
+)
+
+// Keyring encrypts and decrypts values with a master key
+type Keyring struct {
+	id   string
+	aead cipher.AEAD
+}
+
+// NewKeyring creates a keyring for a 32 byte master key
+func NewKeyring(masterKey []byte) (*Keyring, error) {
+	if len(masterKey) != MasterKeySize {
+		return nil, fmt.Errorf("master key must be %d bytes, got %d", MasterKeySize, len(masterKey))
+	}
+
+	aead, err := newAEAD(masterKey)
+	if err != nil {
+		return nil, err
+	}
+
+	sum := sha256.Sum256(masterKey)
+	return &Keyring{
+		id:   hex.EncodeToString(sum[:4]),
+		aead: aead,
+	}, nil
+}
+
+// ID identifies the master key of the keyring without revealing it
+func (k *Keyring) ID() string {
+	return k.id
+}
+
+// Encrypt encrypts a value under a fresh data key
+func (k *Keyring) Encrypt(plaintext string) (string, error) {
+	dataKey := make([]byte, MasterKeySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return "", fmt.Errorf("failed to generate data key: %w", err)
+	}
+
+	dataAEAD, err := newAEAD(dataKey)
+	if err != nil {
+		return "", err
+	}
+
+	ciphertext, err := seal(dataAEAD, []byte(plaintext), nil)
+	if err != nil {
+		return "", err
+	}
+
+	return k.format(dataKey, ciphertext)
+}
+
+// Decrypt decrypts a value produced by Encrypt. Values that are not
+// encrypted are returned unchanged.
+func (k *Keyring) Decrypt(value string) (string, error) {
+	if !IsEncrypted(value) {
+		return value, nil
+	}
+
+	dataKey, ciphertext, err := k.unwrap(value)
+	if err != nil {
+		return "", err
+	}
+
+	dataAEAD, err := newAEAD(dataKey)
+	if err != nil {
+		return "", err
+	}
+
+	plaintext, err := open(dataAEAD, ciphertext, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt value: %w", err)
+	}
+	return string(plaintext), nil
+}
+
+// Rewrap re-encrypts the data key of a value under another keyring, leaving
+// the encrypted value itself untouched. Values that are not encrypted are
+// encrypted under the other keyring.
+func (k *Keyring) Rewrap(value string, to *Keyring) (string, error) {
+	if !IsEncrypted(value) {
+		return to.Encrypt(value)
+	}
+
+	dataKey, ciphertext, err := k.unwrap(value)
+	if err != nil {
+		return "", err
+	}
+	return to.format(dataKey, ciphertext)
+}
+
+// format builds the stored form "enc:v1:<key id>:<wrapped data key>:<ciphertext>"
+func (k *Keyring) format(dataKey, ciphertext []byte) (string, error) {
+	wrappedKey, err := seal(k.aead, dataKey, []byte(k.id))
+	if err != nil {
+		return "", err
+	}
+
+	return encryptedPrefix + k.id + ":" +
+		base64.RawStdEncoding.EncodeToString(wrappedKey) + ":" +
+		base64.RawStdEncoding.EncodeToString(ciphertext), nil
+}
+
+// unwrap parses a stored value and decrypts its data key
+func (k *Keyring) unwrap(value string) ([]byte, []byte, error) {
+	parts := strings.Split(strings.TrimPrefix(value, encryptedPrefix), ":")
+	if len(parts) != 3 {
+		return nil, nil, fmt.Errorf("malformed encrypted value")
+	}
+
+	keyID := parts[0]
+	if keyID != k.id {
+		return nil, nil, fmt.Errorf("value was encrypted with master key %s, but the configured master key is %s", keyID, k.id)
+	}
+
+	wrappedKey, err := base64.RawStdEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, nil, fmt.Errorf("malformed encrypted value: %w", err)
+	}
+	ciphertext, err := base64.RawStdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, nil, fmt.Errorf("malformed encrypted value: %w", err)
+	}
+
+	dataKey, err := open(k.aead, wrappedKey, []byte(keyID))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to unwrap data key: %w", err)
+	}
+	return dataKey, ciphertext, nil
+}
+
+// IsEncrypted reports whether a stored value was produced by Encrypt
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, encryptedPrefix)
+}
+
+// IsReference reports whether a value is an env: or file: reference rather
+// than a literal key
+func IsReference(value string) bool {
+	return strings.HasPrefix(value, envPrefix) || strings.HasPrefix(value, filePrefix)
+}
+
+// Resolve returns the key a value stands for: the content of the environment
+// variable of "env:VAR_NAME", the trimmed content of the file of "file:/path",
+// or the value itself for literal keys.
+func Resolve(value string) (string, error) {
+	switch {
+	case strings.HasPrefix(value, envPrefix):
+		name := strings.TrimPrefix(value, envPrefix)
+		if name == "" {
+			return "", fmt.Errorf("API key reference %q has no variable name", value)
+		}
+		key, ok := os.LookupEnv(name)
+		if !ok || key == "" {
+			return "", fmt.Errorf("environment variable %s referenced by the API key is not set", name)
+		}
+		return key, nil
+
+	case strings.HasPrefix(value, filePrefix):
+		path := strings.TrimPrefix(value, filePrefix)
+		if path == "" {
+			return "", fmt.Errorf("API key reference %q has no file path", value)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("failed to read API key file: %w", err)
+		}
+		key := strings.TrimSpace(string(data))
+		if key == "" {
+			return "", fmt.Errorf("API key file %s is empty", path)
+		}
+		return key, nil
+	}
+
+	return value, nil
+}
+
+// GenerateMasterKey returns a random master key
+func GenerateMasterKey() ([]byte, error) {
+	key := make([]byte, MasterKeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("failed to generate master key: %w", err)
+	}
+	return key, nil
+}
+
+// WriteMasterKeyFile writes a base64 encoded master key to a new file that
+// only the current user can read. Existing files are never overwritten.
+func WriteMasterKeyFile(path string, masterKey []byte) error {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to create master key file: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := file.WriteString(base64.StdEncoding.EncodeToString(masterKey) + "\n"); err != nil {
+		return fmt.Errorf("failed to write master key file: %w", err)
+	}
+	return nil
+}
+
+// LoadMasterKeyFile loads a keyring from a file written by WriteMasterKeyFile
+func LoadMasterKeyFile(path string) (*Keyring, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read master key file: %w", err)
+	}
+
+	keyring, err := parseMasterKey(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("invalid master key in %s: %w", path, err)
+	}
+	return keyring, nil
+}
+
+// Load loads the master key from GEGO_MASTER_KEY or, when unset, from
+// keyFile. Returns nil when neither is configured.
+func Load(keyFile string) (*Keyring, error) {
+	if encoded := os.Getenv(MasterKeyEnv); encoded != "" {
+		keyring, err := parseMasterKey(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", MasterKeyEnv, err)
+		}
+		return keyring, nil
+	}
+
+	if keyFile == "" {
+		return nil, nil
+	}
+	return LoadMasterKeyFile(keyFile)
+}
+
+// SetDefault sets the keyring used to encrypt stored API keys
+func SetDefault(keyring *Keyring) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	defaultKeyring = keyring
+}
+
+// Default returns the keyring used to encrypt stored API keys, or nil when
+// no master key is configured
+func Default() *Keyring {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+	return defaultKeyring
+}
+
+func parseMasterKey(encoded string) (*Keyring, error) {
+	masterKey, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, fmt.Errorf("master key must be base64 encoded: %w", err)
+	}
+	return NewKeyring(masterKey)
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	return aead, nil
+}
+
+// seal encrypts with a random nonce, which is prepended to the ciphertext
+func seal(aead cipher.AEAD, plaintext, additionalData []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	return aead.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+// open decrypts the output of seal
+func open(aead cipher.AEAD, ciphertext, additionalData []byte) ([]byte, error) {
+	if len(ciphertext) < aead.NonceSize() {
+		return nil, fmt.Errorf("ciphertext too short")
+	}
+	nonce, sealed := ciphertext[:aead.NonceSize()], ciphertext[aead.NonceSize():]
+	return aead.Open(nil, nonce, sealed, additionalData)
+}
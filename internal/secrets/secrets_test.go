@@ -0,0 +1,218 @@
+package secrets
+
+import (
+	"bytes"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newTestKeyring(t *testing.T) *Keyring {
+	t.Helper()
+	masterKey, err := GenerateMasterKey()
+	if err != nil {
+		t.Fatalf("GenerateMasterKey() error = %v", err)
+	}
+	keyring, err := NewKeyring(masterKey)
+	if err != nil {
+		t.Fatalf("NewKeyring() error = %v", err)
+	}
+	return keyring
+}
+
+func TestEncryptDecrypt(t *testing.T) {
+	keyring := newTestKeyring(t)
+
+	encrypted, err := keyring.Encrypt("sk-secret")
+	if err != nil {
+		t.Fatalf("Encrypt() error = %v", err)
+	}
+	if !IsEncrypted(encrypted) || strings.Contains(encrypted, "sk-secret") || !strings.Contains(encrypted, keyring.ID()) {
+		t.Errorf("Encrypt() = %q", encrypted)
+	}
+	if again, _ := keyring.Encrypt("sk-secret"); again == encrypted {
+		t.Error("Encrypt() twice gave the same value, want a fresh data key and nonce")
+	}
+
+	if decrypted, err := keyring.Decrypt(encrypted); err != nil || decrypted != "sk-secret" {
+		t.Errorf("Decrypt() = %q, %v, want sk-secret", decrypted, err)
+	}
+	if decrypted, err := keyring.Decrypt("sk-plain"); err != nil || decrypted != "sk-plain" {
+		t.Errorf("Decrypt() of a plain value = %q, %v", decrypted, err)
+	}
+
+	// Another master key cannot decrypt, even when it claims the same key ID
+	other := newTestKeyring(t)
+	if _, err := other.Decrypt(encrypted); err == nil {
+		t.Error("Decrypt() with another master key succeeded")
+	}
+	other.id = keyring.id
+	if _, err := other.Decrypt(encrypted); err == nil {
+		t.Error("Decrypt() with another master key under the same ID succeeded")
+	}
+}
+
+func TestDecryptTampered(t *testing.T) {
+	keyring := newTestKeyring(t)
+	encrypted, err := keyring.Encrypt("sk-secret")
+	if err != nil {
+		t.Fatalf("Encrypt() error = %v", err)
+	}
+	parts := strings.Split(strings.TrimPrefix(encrypted, encryptedPrefix), ":")
+
+	// flip changes the last byte of a base64 encoded part
+	flip := func(part string) string {
+		data, _ := base64.RawStdEncoding.DecodeString(part)
+		data[len(data)-1] ^= 1
+		return base64.RawStdEncoding.EncodeToString(data)
+	}
+
+	tests := []struct {
+		name  string
+		value string
+	}{
+		{"tampered ciphertext", encryptedPrefix + parts[0] + ":" + parts[1] + ":" + flip(parts[2])},
+		{"tampered data key", encryptedPrefix + parts[0] + ":" + flip(parts[1]) + ":" + parts[2]},
+		{"truncated ciphertext", encryptedPrefix + parts[0] + ":" + parts[1] + ":AAAA"},
+		{"missing part", encryptedPrefix + parts[0] + ":" + parts[1]},
+		{"not base64", encryptedPrefix + parts[0] + ":" + parts[1] + ":%%%"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if decrypted, err := keyring.Decrypt(tt.value); err == nil {
+				t.Errorf("Decrypt() = %q, want an error", decrypted)
+			}
+		})
+	}
+}
+
+func TestRewrap(t *testing.T) {
+	from, to := newTestKeyring(t), newTestKeyring(t)
+
+	encrypted, err := from.Encrypt("sk-secret")
+	if err != nil {
+		t.Fatalf("Encrypt() error = %v", err)
+	}
+	rewrapped, err := from.Rewrap(encrypted, to)
+	if err != nil {
+		t.Fatalf("Rewrap() error = %v", err)
+	}
+
+	// Only the wrapped data key changes
+	oldParts := strings.Split(encrypted, ":")
+	newParts := strings.Split(rewrapped, ":")
+	if newParts[2] != to.ID() || newParts[4] != oldParts[4] {
+		t.Errorf("Rewrap() = %q, want the ciphertext of %q under key %s", rewrapped, encrypted, to.ID())
+	}
+	if decrypted, err := to.Decrypt(rewrapped); err != nil || decrypted != "sk-secret" {
+		t.Errorf("Decrypt() after Rewrap() = %q, %v", decrypted, err)
+	}
+	if _, err := from.Decrypt(rewrapped); err == nil {
+		t.Error("old master key decrypted a rewrapped value")
+	}
+
+	// Plain values are encrypted under the new key
+	rewrapped, err = from.Rewrap("sk-plain", to)
+	if err != nil {
+		t.Fatalf("Rewrap() of a plain value error = %v", err)
+	}
+	if decrypted, err := to.Decrypt(rewrapped); err != nil || decrypted != "sk-plain" {
+		t.Errorf("Decrypt() of a rewrapped plain value = %q, %v", decrypted, err)
+	}
+
+	if _, err := to.Rewrap(encrypted, from); err == nil {
+		t.Error("Rewrap() with the wrong current key succeeded")
+	}
+}
+
+func TestResolve(t *testing.T) {
+	dir := t.TempDir()
+	keyFile := filepath.Join(dir, "key")
+	if err := os.WriteFile(keyFile, []byte("  sk-from-file\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	emptyFile := filepath.Join(dir, "empty")
+	if err := os.WriteFile(emptyFile, []byte("\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("GEGO_TEST_API_KEY", "sk-from-env")
+	t.Setenv("GEGO_TEST_EMPTY_KEY", "")
+
+	tests := []struct {
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{value: "sk-literal", want: "sk-literal"},
+		{value: "", want: ""},
+		{value: "env:GEGO_TEST_API_KEY", want: "sk-from-env"},
+		{value: "env:GEGO_TEST_EMPTY_KEY", wantErr: true},
+		{value: "env:GEGO_TEST_UNSET_KEY", wantErr: true},
+		{value: "env:", wantErr: true},
+		{value: "file:" + keyFile, want: "sk-from-file"},
+		{value: "file:" + emptyFile, wantErr: true},
+		{value: "file:" + filepath.Join(dir, "missing"), wantErr: true},
+		{value: "file:", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := Resolve(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Resolve() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Resolve() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	masterKey, err := GenerateMasterKey()
+	if err != nil {
+		t.Fatalf("GenerateMasterKey() error = %v", err)
+	}
+	want, _ := NewKeyring(masterKey)
+
+	t.Setenv(MasterKeyEnv, "")
+	if keyring, err := Load(""); keyring != nil || err != nil {
+		t.Errorf("Load() without a key = %v, %v, want nil", keyring, err)
+	}
+
+	keyFile := filepath.Join(t.TempDir(), "master.key")
+	if err := WriteMasterKeyFile(keyFile, masterKey); err != nil {
+		t.Fatalf("WriteMasterKeyFile() error = %v", err)
+	}
+	info, err := os.Stat(keyFile)
+	if err != nil {
+		t.Fatalf("Stat() error = %v", err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("master key file mode = %v, want 0600", info.Mode().Perm())
+	}
+	if err := WriteMasterKeyFile(keyFile, bytes.Repeat([]byte{1}, MasterKeySize)); err == nil {
+		t.Error("WriteMasterKeyFile() overwrote an existing file")
+	}
+	if keyring, err := Load(keyFile); err != nil || keyring.ID() != want.ID() {
+		t.Errorf("Load(file) = %v, %v, want key %s", keyring, err, want.ID())
+	}
+
+	// The environment variable takes precedence over the file
+	other := bytes.Repeat([]byte{7}, MasterKeySize)
+	t.Setenv(MasterKeyEnv, base64.StdEncoding.EncodeToString(other))
+	otherKeyring, _ := NewKeyring(other)
+	if keyring, err := Load(keyFile); err != nil || keyring.ID() != otherKeyring.ID() {
+		t.Errorf("Load() with %s = %v, %v, want key %s", MasterKeyEnv, keyring, err, otherKeyring.ID())
+	}
+
+	for _, encoded := range []string{"not base64!", base64.StdEncoding.EncodeToString([]byte("short"))} {
+		t.Setenv(MasterKeyEnv, encoded)
+		if _, err := Load(keyFile); err == nil {
+			t.Errorf("Load() with %s=%q succeeded", MasterKeyEnv, encoded)
+		}
+	}
+}
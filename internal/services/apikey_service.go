@@ -0,0 +1,159 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/fissionx/gego/internal/db"
+	"github.com/fissionx/gego/internal/logger"
+	"github.com/fissionx/gego/internal/models"
+)
+
+// API key format
+const (
+	apiKeyPrefix       = "gego_"
+	apiKeyDisplayChars = 12
+	apiKeyTouchEvery   = time.Minute
+)
+
+// ErrInvalidAPIKey is returned when a key is unknown or revoked
+var ErrInvalidAPIKey = errors.New("invalid API key")
+
+// APIKeyService manages the keys that authenticate REST API clients
+type APIKeyService struct {
+	db db.Database
+}
+
+// NewAPIKeyService creates a new API key service
+func NewAPIKeyService(database db.Database) *APIKeyService {
+	return &APIKeyService{db: database}
+}
+
+// CreateKey creates an API key with the given scopes. The returned secret is
+// only available here; the database keeps its hash.
+func (s *APIKeyService) CreateKey(ctx context.Context, name string, scopes []string) (*models.APIKey, string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, "", fmt.Errorf("name is required")
+	}
+
+	scopes, err := normalizeScopes(scopes)
+	if err != nil {
+		return nil, "", err
+	}
+
+	random := make([]byte, 32)
+	if _, err := rand.Read(random); err != nil {
+		return nil, "", fmt.Errorf("failed to generate API key: %w", err)
+	}
+	secret := apiKeyPrefix + base64.RawURLEncoding.EncodeToString(random)
+
+	key := &models.APIKey{
+		ID:      uuid.New().String(),
+		Name:    name,
+		Prefix:  secret[:apiKeyDisplayChars],
+		KeyHash: hashAPIKey(secret),
+		Scopes:  scopes,
+	}
+
+	if err := s.db.CreateAPIKey(ctx, key); err != nil {
+		return nil, "", fmt.Errorf("failed to save API key: %w", err)
+	}
+
+	return key, secret, nil
+}
+
+// Authenticate resolves a secret to its active API key. Returns ErrInvalidAPIKey
+// for unknown and revoked keys.
+func (s *APIKeyService) Authenticate(ctx context.Context, secret string) (*models.APIKey, error) {
+	if !strings.HasPrefix(secret, apiKeyPrefix) {
+		return nil, ErrInvalidAPIKey
+	}
+
+	key, err := s.db.GetAPIKeyByHash(ctx, hashAPIKey(secret))
+	if err != nil {
+		return nil, ErrInvalidAPIKey
+	}
+	if key.RevokedAt != nil {
+		return nil, ErrInvalidAPIKey
+	}
+
+	// Last use is only tracked to the minute, to avoid a write per request
+	now := time.Now()
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= apiKeyTouchEvery {
+		if err := s.db.TouchAPIKey(ctx, key.ID, now); err != nil {
+			logger.Warning("Failed to record use of API key %s: %v", key.Prefix, err)
+		}
+		key.LastUsedAt = &now
+	}
+
+	return key, nil
+}
+
+// ListKeys lists all API keys, including revoked ones
+func (s *APIKeyService) ListKeys(ctx context.Context) ([]*models.APIKey, error) {
+	return s.db.ListAPIKeys(ctx)
+}
+
+// HasActiveKeys reports whether at least one API key can be used
+func (s *APIKeyService) HasActiveKeys(ctx context.Context) (bool, error) {
+	keys, err := s.db.ListAPIKeys(ctx)
+	if err != nil {
+		return false, err
+	}
+	for _, key := range keys {
+		if key.RevokedAt == nil {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// RevokeKey revokes an API key
+func (s *APIKeyService) RevokeKey(ctx context.Context, id string) error {
+	return s.db.RevokeAPIKey(ctx, id)
+}
+
+// normalizeScopes validates and deduplicates scopes. An empty list grants read access.
+func normalizeScopes(scopes []string) ([]string, error) {
+	if len(scopes) == 0 {
+		return []string{models.APIKeyScopeRead}, nil
+	}
+
+	seen := make(map[string]bool)
+	var normalized []string
+	for _, scope := range scopes {
+		scope = strings.ToLower(strings.TrimSpace(scope))
+		valid := false
+		for _, known := range models.APIKeyScopes {
+			if scope == known {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return nil, fmt.Errorf("unknown scope %q (valid scopes: %s)", scope, strings.Join(models.APIKeyScopes, ", "))
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			normalized = append(normalized, scope)
+		}
+	}
+
+	return normalized, nil
+}
+
+// hashAPIKey returns the hex SHA-256 hash of a secret. Keys are random, so no salt is needed.
+func hashAPIKey(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,596 @@
+package services
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/fissionx/gego/internal/db"
+	"github.com/fissionx/gego/internal/llm/openaicompat"
+	"github.com/fissionx/gego/internal/models"
+	"github.com/fissionx/gego/internal/secrets"
+	"github.com/fissionx/gego/internal/shared"
+)
+
+// ImportMode decides how the IDs of imported records meet those of the target
+type ImportMode string
+
+const (
+	// ImportMerge keeps the archive IDs. Records already present under the same
+	// ID, or matching an existing record (same LLM provider, model and name, same
+	// prompt template), are not imported again; references are pointed at them.
+	ImportMerge ImportMode = "merge"
+	// ImportRemap gives every imported record a new ID and rewrites the
+	// references, so an archive can be loaded next to the data it came from.
+	ImportRemap ImportMode = "remap"
+)
+
+// ExportOptions controls what goes into an archive
+type ExportOptions struct {
+	IncludeSecrets   bool // Export LLM API keys in plain text
+	IncludeResponses bool
+}
+
+// ImportOptions controls how an archive is imported
+type ImportOptions struct {
+	Mode   ImportMode
+	DryRun bool // Check the archive and count what would be imported, without writing
+}
+
+// ArchiveService exports the data of both databases into a single archive and imports it back
+type ArchiveService struct {
+	db db.Database
+}
+
+// NewArchiveService creates a new archive service
+func NewArchiveService(database db.Database) *ArchiveService {
+	return &ArchiveService{db: database}
+}
+
+// WriteArchive encodes an archive as JSON, gzip-compressed when compress is set
+func WriteArchive(w io.Writer, archive *models.Archive, compress bool) error {
+	if compress {
+		gz := gzip.NewWriter(w)
+		if err := writeArchiveJSON(gz, archive); err != nil {
+			return err
+		}
+		return gz.Close()
+	}
+	return writeArchiveJSON(w, archive)
+}
+
+func writeArchiveJSON(w io.Writer, archive *models.Archive) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(archive); err != nil {
+		return fmt.Errorf("failed to encode archive: %w", err)
+	}
+	return nil
+}
+
+// ReadArchive decodes an archive written by WriteArchive, compressed or not,
+// and rejects archives of another format or a newer version
+func ReadArchive(r io.Reader) (*models.Archive, error) {
+	buffered := bufio.NewReader(r)
+	if magic, _ := buffered.Peek(2); len(magic) == 2 && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(buffered)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress archive: %w", err)
+		}
+		defer gz.Close()
+		r = gz
+	} else {
+		r = buffered
+	}
+
+	var archive models.Archive
+	if err := json.NewDecoder(r).Decode(&archive); err != nil {
+		return nil, fmt.Errorf("failed to decode archive: %w", err)
+	}
+
+	if archive.Format != models.ArchiveFormat {
+		return nil, fmt.Errorf("not a gego archive")
+	}
+	if archive.Version < 1 || archive.Version > models.ArchiveVersion {
+		return nil, fmt.Errorf("unsupported archive version %d (this build reads up to version %d)", archive.Version, models.ArchiveVersion)
+	}
+
+	return &archive, nil
+}
+
+// Export snapshots both databases. The returned warnings list the references
+// that were already broken in the databases and were left out.
+func (s *ArchiveService) Export(ctx context.Context, opts ExportOptions) (*models.Archive, []string, error) {
+	archive := &models.Archive{
+		Format:          models.ArchiveFormat,
+		Version:         models.ArchiveVersion,
+		ExportedAt:      time.Now().UTC(),
+		IncludesSecrets: opts.IncludeSecrets,
+	}
+
+	var err error
+	if archive.LLMs, err = s.db.ListLLMs(ctx, nil); err != nil {
+		return nil, nil, fmt.Errorf("failed to list LLMs: %w", err)
+	}
+	if archive.Schedules, err = s.db.ListSchedules(ctx, nil); err != nil {
+		return nil, nil, fmt.Errorf("failed to list schedules: %w", err)
+	}
+	if archive.Prompts, err = s.db.ListPrompts(ctx, nil); err != nil {
+		return nil, nil, fmt.Errorf("failed to list prompts: %w", err)
+	}
+	if archive.PromptLibraries, err = s.db.ListPromptLibraries(ctx); err != nil {
+		return nil, nil, fmt.Errorf("failed to list prompt libraries: %w", err)
+	}
+	if archive.BrandProfiles, err = s.db.ListBrandProfiles(ctx); err != nil {
+		return nil, nil, fmt.Errorf("failed to list brand profiles: %w", err)
+	}
+	if opts.IncludeResponses {
+		if archive.Responses, err = s.db.ListResponses(ctx, shared.ResponseFilter{}); err != nil {
+			return nil, nil, fmt.Errorf("failed to list responses: %w", err)
+		}
+	}
+
+	if opts.IncludeSecrets {
+		for _, llm := range archive.LLMs {
+			if llm.KeyError != "" {
+				return nil, nil, fmt.Errorf("cannot export the API key of LLM %s: %s", llm.Name, llm.KeyError)
+			}
+		}
+	} else {
+		for _, llm := range archive.LLMs {
+			// env: and file: references name a secret without holding it
+			if !secrets.IsReference(llm.APIKey) {
+				llm.APIKey = ""
+			}
+			for key, value := range llm.Config {
+				if strings.HasPrefix(key, openaicompat.HeaderOptionPrefix) && !secrets.IsReference(value) {
+					delete(llm.Config, key)
+				}
+			}
+		}
+	}
+
+	return archive, pruneDanglingReferences(archive), nil
+}
+
+// pruneDanglingReferences drops the prompts and LLMs that schedules and prompt
+// libraries reference but that no longer exist, so that the archive imports
+// cleanly. Returns a warning per dropped reference.
+func pruneDanglingReferences(archive *models.Archive) []string {
+	prompts := make(map[string]bool, len(archive.Prompts))
+	for _, prompt := range archive.Prompts {
+		prompts[prompt.ID] = true
+	}
+	llms := make(map[string]bool, len(archive.LLMs))
+	for _, llm := range archive.LLMs {
+		llms[llm.ID] = true
+	}
+
+	var warnings []string
+	keep := func(ids []string, known map[string]bool, describe func(id string) string) []string {
+		var kept []string
+		for _, id := range ids {
+			if known[id] {
+				kept = append(kept, id)
+			} else {
+				warnings = append(warnings, describe(id))
+			}
+		}
+		return kept
+	}
+
+	for _, schedule := range archive.Schedules {
+		schedule.PromptIDs = keep(schedule.PromptIDs, prompts, func(id string) string {
+			return fmt.Sprintf("schedule %q references deleted prompt %s, left out", schedule.Name, id)
+		})
+		schedule.LLMIDs = keep(schedule.LLMIDs, llms, func(id string) string {
+			return fmt.Sprintf("schedule %q references deleted LLM %s, left out", schedule.Name, id)
+		})
+	}
+	for _, library := range archive.PromptLibraries {
+		library.PromptIDs = keep(library.PromptIDs, prompts, func(id string) string {
+			return fmt.Sprintf("prompt library %s/%s/%s references deleted prompt %s, left out", library.Brand, library.Domain, library.Category, id)
+		})
+	}
+
+	return warnings
+}
+
+// checkArchive lists the duplicate IDs of an archive, and the prompts and LLMs
+// that schedules and prompt libraries reference but that are neither in the
+// archive nor among the existing ones. Responses keep a snapshot of their
+// prompt and LLM, so theirs may dangle.
+func checkArchive(archive *models.Archive, existingPrompts, existingLLMs map[string]bool) []string {
+	var problems []string
+
+	duplicates := func(kind string, ids []string) map[string]bool {
+		seen := make(map[string]bool, len(ids))
+		for _, id := range ids {
+			if seen[id] {
+				problems = append(problems, fmt.Sprintf("duplicate %s ID %s", kind, id))
+			}
+			seen[id] = true
+		}
+		return seen
+	}
+
+	var ids []string
+	for _, llm := range archive.LLMs {
+		ids = append(ids, llm.ID)
+	}
+	llms := duplicates("LLM", ids)
+
+	ids = nil
+	for _, prompt := range archive.Prompts {
+		ids = append(ids, prompt.ID)
+	}
+	prompts := duplicates("prompt", ids)
+
+	ids = nil
+	for _, schedule := range archive.Schedules {
+		ids = append(ids, schedule.ID)
+	}
+	duplicates("schedule", ids)
+
+	ids = nil
+	for _, response := range archive.Responses {
+		ids = append(ids, response.ID)
+	}
+	duplicates("response", ids)
+
+	for _, schedule := range archive.Schedules {
+		for _, id := range schedule.PromptIDs {
+			if !prompts[id] && !existingPrompts[id] {
+				problems = append(problems, fmt.Sprintf("schedule %q references unknown prompt %s", schedule.Name, id))
+			}
+		}
+		for _, id := range schedule.LLMIDs {
+			if !llms[id] && !existingLLMs[id] {
+				problems = append(problems, fmt.Sprintf("schedule %q references unknown LLM %s", schedule.Name, id))
+			}
+		}
+	}
+	for _, library := range archive.PromptLibraries {
+		for _, id := range library.PromptIDs {
+			if !prompts[id] && !existingPrompts[id] {
+				problems = append(problems, fmt.Sprintf("prompt library %s/%s/%s references unknown prompt %s", library.Brand, library.Domain, library.Category, id))
+			}
+		}
+	}
+
+	return problems
+}
+
+// Import loads an archive into the databases. The archive is checked before
+// anything is written; a failure midway leaves the records imported so far.
+func (s *ArchiveService) Import(ctx context.Context, archive *models.Archive, opts ImportOptions) (*models.ImportResult, error) {
+	if opts.Mode == "" {
+		opts.Mode = ImportMerge
+	}
+	if opts.Mode != ImportMerge && opts.Mode != ImportRemap {
+		return nil, fmt.Errorf("invalid import mode %q (use %s or %s)", opts.Mode, ImportMerge, ImportRemap)
+	}
+
+	existingLLMs, err := s.db.ListLLMs(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list LLMs: %w", err)
+	}
+	existingPrompts, err := s.db.ListPrompts(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list prompts: %w", err)
+	}
+	existingSchedules, err := s.db.ListSchedules(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list schedules: %w", err)
+	}
+
+	// Archive references may only point at existing records when IDs are kept
+	llmIDs := make(map[string]bool)
+	promptIDs := make(map[string]bool)
+	if opts.Mode == ImportMerge {
+		for _, llm := range existingLLMs {
+			llmIDs[llm.ID] = true
+		}
+		for _, prompt := range existingPrompts {
+			promptIDs[prompt.ID] = true
+		}
+	}
+	if problems := checkArchive(archive, promptIDs, llmIDs); len(problems) > 0 {
+		return nil, fmt.Errorf("archive has broken references:\n  - %s", strings.Join(problems, "\n  - "))
+	}
+
+	imp := &archiveImport{
+		ctx:       ctx,
+		db:        s.db,
+		opts:      opts,
+		result:    &models.ImportResult{},
+		llmIDs:    make(map[string]string),
+		promptIDs: make(map[string]string),
+		schedIDs:  make(map[string]string),
+	}
+
+	steps := []func() error{
+		func() error { return imp.llms(archive, existingLLMs) },
+		func() error { return imp.prompts(archive.Prompts, existingPrompts) },
+		func() error { return imp.schedules(archive.Schedules, existingSchedules) },
+		func() error { return imp.promptLibraries(archive.PromptLibraries) },
+		func() error { return imp.brandProfiles(archive.BrandProfiles) },
+		func() error { return imp.responses(archive.Responses) },
+	}
+	for _, step := range steps {
+		if err := step(); err != nil {
+			return imp.result, err
+		}
+	}
+
+	return imp.result, nil
+}
+
+// archiveImport holds the state of one import: the IDs archive records were given
+type archiveImport struct {
+	ctx       context.Context
+	db        db.Database
+	opts      ImportOptions
+	result    *models.ImportResult
+	llmIDs    map[string]string
+	promptIDs map[string]string
+	schedIDs  map[string]string
+}
+
+// newID returns the ID an imported record is created under
+func (imp *archiveImport) newID(id string) string {
+	if imp.opts.Mode == ImportRemap {
+		return uuid.New().String()
+	}
+	return id
+}
+
+// remap rewrites IDs through a mapping, keeping those it does not know
+func remap(ids []string, mapping map[string]string) []string {
+	if ids == nil {
+		return nil
+	}
+	remapped := make([]string, 0, len(ids))
+	for _, id := range ids {
+		remapped = append(remapped, remapID(id, mapping))
+	}
+	return remapped
+}
+
+func remapID(id string, mapping map[string]string) string {
+	if mapped, ok := mapping[id]; ok {
+		return mapped
+	}
+	return id
+}
+
+func (imp *archiveImport) llms(archive *models.Archive, existing []*models.LLMConfig) error {
+	byID := make(map[string]*models.LLMConfig)
+	byKey := make(map[string]*models.LLMConfig)
+	key := func(llm *models.LLMConfig) string {
+		return llm.Provider + "\x00" + llm.Model + "\x00" + llm.Name
+	}
+	for _, llm := range existing {
+		byID[llm.ID] = llm
+		byKey[key(llm)] = llm
+	}
+
+	for _, llm := range archive.LLMs {
+		if imp.opts.Mode == ImportMerge {
+			match := byID[llm.ID]
+			if match == nil {
+				match = byKey[key(llm)]
+			}
+			if match != nil {
+				imp.llmIDs[llm.ID] = match.ID
+				imp.result.LLMs.Existing++
+				continue
+			}
+		}
+
+		imported := *llm
+		imported.ID = imp.newID(llm.ID)
+		imp.llmIDs[llm.ID] = imported.ID
+
+		provider := FromString(imported.Provider)
+		if imported.APIKey == "" && provider != Ollama && provider != OpenAICompatible {
+			imported.Enabled = false
+			imp.result.Warnings = append(imp.result.Warnings, fmt.Sprintf(
+				"LLM %q was imported disabled, without an API key: set one with 'gego llm update %s' and enable it", imported.Name, imported.ID))
+		}
+
+		if !imp.opts.DryRun {
+			if err := imp.db.CreateLLM(imp.ctx, &imported); err != nil {
+				return fmt.Errorf("failed to import LLM %q: %w", llm.Name, err)
+			}
+		}
+		imp.result.LLMs.Created++
+	}
+
+	return nil
+}
+
+func (imp *archiveImport) prompts(prompts []*models.Prompt, existing []*models.Prompt) error {
+	byID := make(map[string]*models.Prompt)
+	byTemplate := make(map[string]*models.Prompt)
+	for _, prompt := range existing {
+		byID[prompt.ID] = prompt
+		byTemplate[strings.TrimSpace(prompt.Template)] = prompt
+	}
+
+	for _, prompt := range prompts {
+		if imp.opts.Mode == ImportMerge {
+			match := byID[prompt.ID]
+			if match == nil {
+				match = byTemplate[strings.TrimSpace(prompt.Template)]
+			}
+			if match != nil {
+				imp.promptIDs[prompt.ID] = match.ID
+				imp.result.Prompts.Existing++
+				continue
+			}
+		}
+
+		imported := *prompt
+		imported.ID = imp.newID(prompt.ID)
+		imp.promptIDs[prompt.ID] = imported.ID
+
+		if !imp.opts.DryRun {
+			if err := imp.db.CreatePrompt(imp.ctx, &imported); err != nil {
+				return fmt.Errorf("failed to import prompt %s: %w", prompt.ID, err)
+			}
+		}
+		imp.result.Prompts.Created++
+	}
+
+	return nil
+}
+
+func (imp *archiveImport) schedules(schedules []*models.Schedule, existing []*models.Schedule) error {
+	byID := make(map[string]bool)
+	for _, schedule := range existing {
+		byID[schedule.ID] = true
+	}
+
+	for _, schedule := range schedules {
+		if imp.opts.Mode == ImportMerge && byID[schedule.ID] {
+			imp.schedIDs[schedule.ID] = schedule.ID
+			imp.result.Schedules.Existing++
+			continue
+		}
+
+		imported := *schedule
+		imported.ID = imp.newID(schedule.ID)
+		imported.PromptIDs = remap(schedule.PromptIDs, imp.promptIDs)
+		imported.LLMIDs = remap(schedule.LLMIDs, imp.llmIDs)
+		imp.schedIDs[schedule.ID] = imported.ID
+
+		if !imp.opts.DryRun {
+			if err := imp.db.CreateSchedule(imp.ctx, &imported); err != nil {
+				return fmt.Errorf("failed to import schedule %q: %w", schedule.Name, err)
+			}
+		}
+		imp.result.Schedules.Created++
+	}
+
+	return nil
+}
+
+// promptLibraries imports prompt libraries. They are looked up by brand,
+// domain and category, so in both modes a matching library gains the
+// archived prompts instead of being duplicated.
+func (imp *archiveImport) promptLibraries(libraries []*models.PromptLibrary) error {
+	for _, library := range libraries {
+		promptIDs := remap(library.PromptIDs, imp.promptIDs)
+
+		existing, err := imp.db.GetPromptLibrary(imp.ctx, library.Brand, library.Domain, library.Category)
+		if err != nil {
+			return fmt.Errorf("failed to look up prompt library: %w", err)
+		}
+		if existing != nil && existing.Brand == library.Brand {
+			imp.result.PromptLibraries.Existing++
+			merged := mergeIDs(existing.PromptIDs, promptIDs)
+			if len(merged) == len(existing.PromptIDs) || imp.opts.DryRun {
+				continue
+			}
+			existing.PromptIDs = merged
+			if err := imp.db.UpdatePromptLibrary(imp.ctx, existing); err != nil {
+				return fmt.Errorf("failed to merge prompt library %s/%s/%s: %w", library.Brand, library.Domain, library.Category, err)
+			}
+			continue
+		}
+
+		imported := *library
+		imported.ID = imp.newID(library.ID)
+		imported.PromptIDs = promptIDs
+
+		if !imp.opts.DryRun {
+			if err := imp.db.CreatePromptLibrary(imp.ctx, &imported); err != nil {
+				return fmt.Errorf("failed to import prompt library %s/%s/%s: %w", library.Brand, library.Domain, library.Category, err)
+			}
+		}
+		imp.result.PromptLibraries.Created++
+	}
+
+	return nil
+}
+
+// mergeIDs appends the IDs of extra missing from ids
+func mergeIDs(ids, extra []string) []string {
+	seen := make(map[string]bool, len(ids))
+	merged := append([]string(nil), ids...)
+	for _, id := range ids {
+		seen[id] = true
+	}
+	for _, id := range extra {
+		if !seen[id] {
+			merged = append(merged, id)
+			seen[id] = true
+		}
+	}
+	return merged
+}
+
+// brandProfiles imports brand profiles. Profiles are looked up by brand name,
+// so in both modes an existing profile of the brand is kept as is.
+func (imp *archiveImport) brandProfiles(profiles []*models.BrandProfile) error {
+	for _, profile := range profiles {
+		existing, err := imp.db.GetBrandProfile(imp.ctx, profile.BrandName)
+		if err != nil {
+			return fmt.Errorf("failed to look up brand profile: %w", err)
+		}
+		if existing != nil {
+			imp.result.BrandProfiles.Existing++
+			continue
+		}
+
+		imported := *profile
+		imported.ID = imp.newID(profile.ID)
+
+		if !imp.opts.DryRun {
+			if err := imp.db.CreateBrandProfile(imp.ctx, &imported); err != nil {
+				return fmt.Errorf("failed to import brand profile %q: %w", profile.BrandName, err)
+			}
+		}
+		imp.result.BrandProfiles.Created++
+	}
+
+	return nil
+}
+
+func (imp *archiveImport) responses(responses []*models.Response) error {
+	for _, response := range responses {
+		if imp.opts.Mode == ImportMerge {
+			if existing, err := imp.db.GetResponse(imp.ctx, response.ID); err == nil && existing != nil {
+				imp.result.Responses.Existing++
+				continue
+			}
+		}
+
+		imported := *response
+		imported.ID = imp.newID(response.ID)
+		imported.PromptID = remapID(response.PromptID, imp.promptIDs)
+		imported.LLMID = remapID(response.LLMID, imp.llmIDs)
+		if imported.ScheduleID != "" {
+			imported.ScheduleID = remapID(response.ScheduleID, imp.schedIDs)
+		}
+		// Archives exported before the analysis was stored apart from the answer
+		shared.SplitResponseAnalysis(&imported)
+
+		if !imp.opts.DryRun {
+			if err := imp.db.CreateResponse(imp.ctx, &imported); err != nil {
+				return fmt.Errorf("failed to import response %s: %w", response.ID, err)
+			}
+		}
+		imp.result.Responses.Created++
+	}
+
+	return nil
+}
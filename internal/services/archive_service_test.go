@@ -0,0 +1,183 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fissionx/gego/internal/db"
+	"github.com/fissionx/gego/internal/models"
+	"github.com/fissionx/gego/internal/shared"
+)
+
+// seedArchiveTestDB creates an LLM, two prompts, a schedule, a library, a profile and a response
+func seedArchiveTestDB(t *testing.T, database db.Database) time.Time {
+	t.Helper()
+	ctx := context.Background()
+
+	must := func(err error) {
+		t.Helper()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	must(database.CreateLLM(ctx, &models.LLMConfig{ID: "llm-1", Name: "GPT", Provider: "openai", Model: "gpt-4o", APIKey: "sk-secret", Enabled: true}))
+	must(database.CreatePrompt(ctx, &models.Prompt{ID: "prompt-1", Template: "best CRM?", Enabled: true}))
+	must(database.CreatePrompt(ctx, &models.Prompt{ID: "prompt-2", Template: "what is a CRM?", Enabled: true}))
+	must(database.CreateSchedule(ctx, &models.Schedule{ID: "sched-1", Name: "daily", PromptIDs: []string{"prompt-1", "prompt-2"}, LLMIDs: []string{"llm-1"}, CronExpr: "0 9 * * *"}))
+	must(database.CreatePromptLibrary(ctx, &models.PromptLibrary{ID: "lib-1", Brand: "HubSpot", Domain: "hubspot.com", Category: "CRM", PromptIDs: []string{"prompt-1"}}))
+	must(database.CreateBrandProfile(ctx, &models.BrandProfile{ID: "profile-1", BrandName: "HubSpot", Aliases: []string{"Hubspot CRM"}}))
+
+	createdAt := time.Date(2025, 3, 14, 9, 0, 0, 0, time.UTC)
+	must(database.CreateResponse(ctx, &models.Response{ID: "resp-1", PromptID: "prompt-1", LLMID: "llm-1", ScheduleID: "sched-1", ResponseText: "HubSpot", CreatedAt: createdAt}))
+	return createdAt
+}
+
+func exportArchive(t *testing.T, database db.Database, opts ExportOptions) *models.Archive {
+	t.Helper()
+
+	archive, warnings, err := NewArchiveService(database).Export(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+	if len(warnings) != 0 {
+		t.Errorf("Export() warnings = %v", warnings)
+	}
+
+	// Round-trip through the file format
+	var buf bytes.Buffer
+	if err := WriteArchive(&buf, archive, true); err != nil {
+		t.Fatalf("WriteArchive() error = %v", err)
+	}
+	read, err := ReadArchive(&buf)
+	if err != nil {
+		t.Fatalf("ReadArchive() error = %v", err)
+	}
+	return read
+}
+
+func TestArchiveExportImport(t *testing.T) {
+	ctx := context.Background()
+	source := newTestDB(t)
+	createdAt := seedArchiveTestDB(t, source)
+
+	archive := exportArchive(t, source, ExportOptions{IncludeResponses: true})
+	if archive.LLMs[0].APIKey != "" {
+		t.Errorf("exported API key = %q, want it left out", archive.LLMs[0].APIKey)
+	}
+
+	target := newTestDB(t)
+	result, err := NewArchiveService(target).Import(ctx, archive, ImportOptions{Mode: ImportMerge})
+	if err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+	if result.LLMs.Created != 1 || result.Prompts.Created != 2 || result.Schedules.Created != 1 ||
+		result.PromptLibraries.Created != 1 || result.BrandProfiles.Created != 1 || result.Responses.Created != 1 {
+		t.Errorf("Import() = %+v, want everything created", result)
+	}
+	if len(result.Warnings) != 1 || !strings.Contains(result.Warnings[0], "without an API key") {
+		t.Errorf("Import() warnings = %v, want the missing API key", result.Warnings)
+	}
+
+	llm, err := target.GetLLM(ctx, "llm-1")
+	if err != nil {
+		t.Fatalf("GetLLM() error = %v", err)
+	}
+	if llm.Enabled {
+		t.Error("LLM imported without an API key is enabled")
+	}
+
+	response, err := target.GetResponse(ctx, "resp-1")
+	if err != nil {
+		t.Fatalf("GetResponse() error = %v", err)
+	}
+	if !response.CreatedAt.Equal(createdAt) {
+		t.Errorf("imported response created at %v, want %v", response.CreatedAt, createdAt)
+	}
+
+	// Importing again merges everything into what is there
+	result, err = NewArchiveService(target).Import(ctx, archive, ImportOptions{Mode: ImportMerge})
+	if err != nil {
+		t.Fatalf("second Import() error = %v", err)
+	}
+	if result.LLMs.Created+result.Prompts.Created+result.Schedules.Created+result.PromptLibraries.Created+
+		result.BrandProfiles.Created+result.Responses.Created != 0 {
+		t.Errorf("second Import() = %+v, want nothing created", result)
+	}
+}
+
+func TestArchiveImportRemap(t *testing.T) {
+	ctx := context.Background()
+	database := newTestDB(t)
+	seedArchiveTestDB(t, database)
+
+	archive := exportArchive(t, database, ExportOptions{IncludeSecrets: true, IncludeResponses: true})
+	if archive.LLMs[0].APIKey != "sk-secret" {
+		t.Errorf("exported API key = %q, want it included", archive.LLMs[0].APIKey)
+	}
+
+	// Loading the archive next to its own data duplicates every record under new IDs
+	result, err := NewArchiveService(database).Import(ctx, archive, ImportOptions{Mode: ImportRemap})
+	if err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+	if result.Prompts.Created != 2 || result.Responses.Created != 1 || len(result.Warnings) != 0 {
+		t.Errorf("Import() = %+v", result)
+	}
+	// Libraries and profiles are looked up by name, so they merge
+	if result.PromptLibraries.Existing != 1 || result.BrandProfiles.Existing != 1 {
+		t.Errorf("Import() = %+v, want the library and profile merged", result)
+	}
+
+	schedules, err := database.ListSchedules(ctx, nil)
+	if err != nil {
+		t.Fatalf("ListSchedules() error = %v", err)
+	}
+	if len(schedules) != 2 {
+		t.Fatalf("%d schedules, want 2", len(schedules))
+	}
+	for _, schedule := range schedules {
+		if schedule.ID == "sched-1" {
+			continue
+		}
+		for _, id := range append(schedule.PromptIDs, schedule.LLMIDs...) {
+			if id == "prompt-1" || id == "prompt-2" || id == "llm-1" {
+				t.Errorf("remapped schedule references original record %s", id)
+			}
+		}
+		responses, err := database.ListResponses(ctx, shared.ResponseFilter{ScheduleID: schedule.ID})
+		if err != nil || len(responses) != 1 || responses[0].PromptID != schedule.PromptIDs[0] {
+			t.Errorf("responses of remapped schedule = %+v, %v", responses, err)
+		}
+	}
+
+	library, err := database.GetPromptLibrary(ctx, "HubSpot", "hubspot.com", "CRM")
+	if err != nil || library == nil || len(library.PromptIDs) != 2 {
+		t.Errorf("merged library = %+v, %v, want the original and the remapped prompt", library, err)
+	}
+}
+
+func TestArchiveImportRejectsBrokenReferences(t *testing.T) {
+	ctx := context.Background()
+	database := newTestDB(t)
+
+	archive := &models.Archive{
+		Format:  models.ArchiveFormat,
+		Version: models.ArchiveVersion,
+		Prompts: []*models.Prompt{{ID: "prompt-1", Template: "best CRM?"}},
+		Schedules: []*models.Schedule{
+			{ID: "sched-1", Name: "daily", PromptIDs: []string{"prompt-1", "prompt-9"}, LLMIDs: []string{"llm-9"}, CronExpr: "0 9 * * *"},
+		},
+	}
+
+	_, err := NewArchiveService(database).Import(ctx, archive, ImportOptions{Mode: ImportMerge})
+	if err == nil || !strings.Contains(err.Error(), "prompt-9") || !strings.Contains(err.Error(), "llm-9") {
+		t.Fatalf("Import() error = %v, want the unknown prompt and LLM", err)
+	}
+
+	if prompts, _ := database.ListPrompts(ctx, nil); len(prompts) != 0 {
+		t.Errorf("%d prompts imported from a broken archive, want none", len(prompts))
+	}
+}
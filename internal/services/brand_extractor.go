@@ -0,0 +1,170 @@
+package services
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/fissionx/gego/internal/models"
+	"github.com/fissionx/gego/internal/shared"
+)
+
+var (
+	mdHeadingItemPattern = regexp.MustCompile(`^#{1,6}\s+(?:\*\*)?\d+[\.\)]\s+(.+)`)
+	mdHeadingPattern     = regexp.MustCompile(`^#{1,6}\s+`)
+	mdNumberedPattern    = regexp.MustCompile(`^(\s*)\d+[\.\)]\s+(.+)`)
+	mdBulletPattern      = regexp.MustCompile(`^(\s*)[-*+•]\s+(.+)`)
+	mdTableSepPattern    = regexp.MustCompile(`^\|?\s*:?-{2,}:?\s*(\|\s*:?-{2,}:?\s*)*\|?$`)
+)
+
+// ExtractBrandMentions runs the rule-based brand extractor over an answer.
+// It counts mentions, records the first mention offset and finds the brand's
+// rank in the first markdown list or table that mentions it.
+func ExtractBrandMentions(text string, matcher *shared.BrandMatcher) *models.BrandExtraction {
+	extraction := &models.BrandExtraction{FirstMentionOffset: -1}
+
+	matches := matcher.FindAll(text)
+	if len(matches) == 0 {
+		return extraction
+	}
+
+	extraction.Mentioned = true
+	extraction.MentionCount = len(matches)
+	extraction.FirstMentionOffset = utf8.RuneCountInString(text[:matches[0][0]])
+	extraction.MatchedAliases = matcher.MatchedTerms(text)
+
+	for _, list := range parseMarkdownLists(text) {
+		for i, item := range list {
+			if matcher.Matches(item) {
+				extraction.ListRank = i + 1
+				extraction.ListSize = len(list)
+				return extraction
+			}
+		}
+	}
+
+	return extraction
+}
+
+// ApplyBrandExtraction runs the rule-based extractor for the response's brand
+// and stores the result on the response
+func ApplyBrandExtraction(response *models.Response, answerText string, matcher *shared.BrandMatcher) {
+	if response == nil || response.Brand == "" {
+		return
+	}
+	if answerText == "" {
+		answerText = response.ResponseText
+	}
+	if matcher == nil {
+		matcher = shared.NewBrandMatcher([]string{response.Brand}, nil)
+	}
+	response.BrandExtraction = ExtractBrandMentions(answerText, matcher)
+}
+
+// parseMarkdownLists splits text into top-level markdown lists and tables.
+// Each list is returned as the text of its items in order; nested items and
+// table header rows are skipped.
+func parseMarkdownLists(text string) [][]string {
+	const (
+		kindNone = iota
+		kindNumbered
+		kindBullet
+		kindHeading
+		kindTable
+	)
+
+	var lists [][]string
+	var current []string
+	kind := kindNone
+	indent := 0
+	tableHeader := false
+
+	flush := func() {
+		if len(current) > 0 {
+			lists = append(lists, current)
+		}
+		current = nil
+		kind = kindNone
+		tableHeader = false
+	}
+
+	start := func(newKind, newIndent int) {
+		flush()
+		kind = newKind
+		indent = newIndent
+	}
+
+	for _, rawLine := range strings.Split(text, "\n") {
+		line := strings.TrimRight(rawLine, " \t\r")
+		trimmed := strings.TrimSpace(line)
+
+		if trimmed == "" {
+			continue
+		}
+
+		if strings.HasPrefix(trimmed, "|") {
+			if kind != kindTable {
+				start(kindTable, 0)
+				tableHeader = true
+			}
+			if mdTableSepPattern.MatchString(trimmed) {
+				continue
+			}
+			if tableHeader {
+				// The first row of a table is its header
+				tableHeader = false
+				continue
+			}
+			current = append(current, trimmed)
+			continue
+		}
+		if kind == kindTable {
+			flush()
+		}
+
+		if matches := mdHeadingItemPattern.FindStringSubmatch(trimmed); matches != nil {
+			if kind != kindHeading {
+				start(kindHeading, 0)
+			}
+			current = append(current, matches[1])
+			continue
+		}
+		if mdHeadingPattern.MatchString(trimmed) {
+			flush()
+			continue
+		}
+
+		itemKind := kindNone
+		itemIndent := 0
+		itemText := ""
+		if matches := mdNumberedPattern.FindStringSubmatch(line); matches != nil {
+			itemKind, itemIndent, itemText = kindNumbered, len(matches[1]), matches[2]
+		} else if matches := mdBulletPattern.FindStringSubmatch(line); matches != nil {
+			itemKind, itemIndent, itemText = kindBullet, len(matches[1]), matches[2]
+		}
+
+		if itemKind == kindNone {
+			// Indented lines continue the current item; paragraphs between
+			// numbered headings are normal, anything else ends the list
+			if kind != kindHeading && len(line) == len(strings.TrimLeft(line, " \t")) {
+				flush()
+			}
+			continue
+		}
+
+		switch {
+		case kind == kindHeading:
+			// Lists under a numbered heading describe that item
+		case kind == itemKind && itemIndent == indent:
+			current = append(current, itemText)
+		case kind != kindNone && itemIndent > indent:
+			// Nested item of the current list
+		default:
+			start(itemKind, itemIndent)
+			current = append(current, itemText)
+		}
+	}
+	flush()
+
+	return lists
+}
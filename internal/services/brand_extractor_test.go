@@ -0,0 +1,99 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/fissionx/gego/internal/shared"
+)
+
+func TestExtractBrandMentions(t *testing.T) {
+	tests := []struct {
+		name         string
+		text         string
+		brand        string
+		aliases      []string
+		negatives    []string
+		wantCount    int
+		wantOffset   int
+		wantListRank int
+		wantListSize int
+	}{
+		{
+			name:       "Not mentioned",
+			text:       "Salesforce and Zoho are popular CRMs.",
+			brand:      "HubSpot",
+			wantCount:  0,
+			wantOffset: -1,
+		},
+		{
+			name:       "Word boundaries",
+			text:       "I like pineapple. Apple makes phones.",
+			brand:      "Apple",
+			wantCount:  1,
+			wantOffset: 18,
+		},
+		{
+			name:       "Aliases counted once",
+			text:       "HubSpot CRM is free. See hubspot.com for details.",
+			brand:      "HubSpot",
+			aliases:    []string{"HubSpot CRM", "hubspot.com"},
+			wantCount:  2,
+			wantOffset: 0,
+		},
+		{
+			name:       "Negative patterns",
+			text:       "Mercury the planet is hot. Mercury Bank offers checking.",
+			brand:      "Mercury",
+			negatives:  []string{`Mercury the planet`},
+			wantCount:  1,
+			wantOffset: 27,
+		},
+		{
+			name:         "Numbered list with nested items",
+			text:         "Top CRMs:\n\n1. **Salesforce** - enterprise\n   - Mentions HubSpot integration\n2. **HubSpot** - free tier\n3. Zoho",
+			brand:        "HubSpot",
+			wantCount:    2,
+			wantOffset:   56,
+			wantListRank: 2,
+			wantListSize: 3,
+		},
+		{
+			name:         "Numbered headings",
+			text:         "### 1. Salesforce\nGreat for enterprise.\n\n### 2. Zoho\nCheap.\n\n### 3. HubSpot\nFree tier.",
+			brand:        "HubSpot",
+			wantCount:    1,
+			wantOffset:   68,
+			wantListRank: 3,
+			wantListSize: 3,
+		},
+		{
+			name:         "Markdown table",
+			text:         "| Tool | Price |\n|------|-------|\n| HubSpot | Free |\n| Salesforce | $25 |",
+			brand:        "HubSpot",
+			wantCount:    1,
+			wantOffset:   36,
+			wantListRank: 1,
+			wantListSize: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matcher := shared.NewBrandMatcher(append([]string{tt.brand}, tt.aliases...), tt.negatives)
+			got := ExtractBrandMentions(tt.text, matcher)
+
+			if got.Mentioned != (tt.wantCount > 0) {
+				t.Errorf("Mentioned = %v, want %v", got.Mentioned, tt.wantCount > 0)
+			}
+			if got.MentionCount != tt.wantCount {
+				t.Errorf("MentionCount = %d, want %d", got.MentionCount, tt.wantCount)
+			}
+			if got.FirstMentionOffset != tt.wantOffset {
+				t.Errorf("FirstMentionOffset = %d, want %d", got.FirstMentionOffset, tt.wantOffset)
+			}
+			if got.ListRank != tt.wantListRank || got.ListSize != tt.wantListSize {
+				t.Errorf("ListRank/ListSize = %d/%d, want %d/%d", got.ListRank, got.ListSize, tt.wantListRank, tt.wantListSize)
+			}
+		})
+	}
+}
@@ -0,0 +1,178 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/fissionx/gego/internal/db"
+	"github.com/fissionx/gego/internal/models"
+	"github.com/fissionx/gego/internal/shared"
+)
+
+// BrandProfileService manages brand profiles and their alias/entity dictionaries
+type BrandProfileService struct {
+	db db.Database
+}
+
+// NewBrandProfileService creates a new brand profile service
+func NewBrandProfileService(database db.Database) *BrandProfileService {
+	return &BrandProfileService{db: database}
+}
+
+// ListProfiles lists all brand profiles
+func (s *BrandProfileService) ListProfiles(ctx context.Context) ([]*models.BrandProfile, error) {
+	return s.db.ListBrandProfiles(ctx)
+}
+
+// GetProfile retrieves a brand profile, returning nil if the brand has none
+func (s *BrandProfileService) GetProfile(ctx context.Context, brand string) (*models.BrandProfile, error) {
+	return s.db.GetBrandProfile(ctx, brand)
+}
+
+// SetEntities replaces the entity dictionary of a brand, creating the profile if needed
+func (s *BrandProfileService) SetEntities(ctx context.Context, brand string, entities models.BrandEntitiesRequest) (*models.BrandProfile, error) {
+	return s.updateEntities(ctx, brand, func(profile *models.BrandProfile) {
+		profile.Aliases = normalizeEntityList(entities.Aliases)
+		profile.ProductNames = normalizeEntityList(entities.ProductNames)
+		profile.Domains = normalizeDomainList(entities.Domains)
+		profile.NegativePatterns = normalizeEntityList(entities.NegativePatterns)
+	}, entities.NegativePatterns)
+}
+
+// AddEntities appends entries to the entity dictionary of a brand, creating the profile if needed
+func (s *BrandProfileService) AddEntities(ctx context.Context, brand string, entities models.BrandEntitiesRequest) (*models.BrandProfile, error) {
+	return s.updateEntities(ctx, brand, func(profile *models.BrandProfile) {
+		profile.Aliases = normalizeEntityList(append(profile.Aliases, entities.Aliases...))
+		profile.ProductNames = normalizeEntityList(append(profile.ProductNames, entities.ProductNames...))
+		profile.Domains = normalizeDomainList(append(profile.Domains, entities.Domains...))
+		profile.NegativePatterns = normalizeEntityList(append(profile.NegativePatterns, entities.NegativePatterns...))
+	}, entities.NegativePatterns)
+}
+
+// RemoveEntities removes entries from the entity dictionary of a brand
+func (s *BrandProfileService) RemoveEntities(ctx context.Context, brand string, entities models.BrandEntitiesRequest) (*models.BrandProfile, error) {
+	profile, err := s.db.GetBrandProfile(ctx, brand)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get brand profile: %w", err)
+	}
+	if profile == nil {
+		return nil, fmt.Errorf("brand profile not found: %s", brand)
+	}
+
+	profile.Aliases = removeEntities(profile.Aliases, entities.Aliases)
+	profile.ProductNames = removeEntities(profile.ProductNames, entities.ProductNames)
+	profile.Domains = removeEntities(profile.Domains, normalizeDomainList(entities.Domains))
+	profile.NegativePatterns = removeEntities(profile.NegativePatterns, entities.NegativePatterns)
+
+	if err := s.db.UpdateBrandProfile(ctx, profile); err != nil {
+		return nil, fmt.Errorf("failed to update brand profile: %w", err)
+	}
+	return profile, nil
+}
+
+// GetMatcher returns a matcher for the brand built from its entity dictionary.
+// Brands without a profile match on their name only.
+func (s *BrandProfileService) GetMatcher(ctx context.Context, brand string) *shared.BrandMatcher {
+	profile, err := s.db.GetBrandProfile(ctx, brand)
+	if err != nil {
+		profile = nil
+	}
+	return shared.BrandMatcherForProfile(brand, profile)
+}
+
+// GetMatchers returns matchers for several brands, keyed by brand name
+func (s *BrandProfileService) GetMatchers(ctx context.Context, brands []string) map[string]*shared.BrandMatcher {
+	matchers := make(map[string]*shared.BrandMatcher, len(brands))
+	for _, brand := range brands {
+		if _, ok := matchers[brand]; !ok {
+			matchers[brand] = s.GetMatcher(ctx, brand)
+		}
+	}
+	return matchers
+}
+
+// updateEntities loads or creates a profile, applies the change and saves it
+func (s *BrandProfileService) updateEntities(ctx context.Context, brand string, apply func(*models.BrandProfile), negativePatterns []string) (*models.BrandProfile, error) {
+	brand = strings.TrimSpace(brand)
+	if brand == "" {
+		return nil, fmt.Errorf("brand is required")
+	}
+
+	for _, pattern := range negativePatterns {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return nil, fmt.Errorf("invalid negative pattern %q: %w", pattern, err)
+		}
+	}
+
+	profile, err := s.db.GetBrandProfile(ctx, brand)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get brand profile: %w", err)
+	}
+
+	if profile == nil {
+		profile = &models.BrandProfile{
+			ID:        uuid.New().String(),
+			BrandName: brand,
+			CreatedAt: time.Now(),
+		}
+		apply(profile)
+		if err := s.db.CreateBrandProfile(ctx, profile); err != nil {
+			return nil, fmt.Errorf("failed to create brand profile: %w", err)
+		}
+		return profile, nil
+	}
+
+	apply(profile)
+	if err := s.db.UpdateBrandProfile(ctx, profile); err != nil {
+		return nil, fmt.Errorf("failed to update brand profile: %w", err)
+	}
+	return profile, nil
+}
+
+// normalizeEntityList trims entries and removes blanks and case-insensitive duplicates
+func normalizeEntityList(entries []string) []string {
+	seen := make(map[string]bool)
+	var result []string
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		key := strings.ToLower(entry)
+		if entry == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		result = append(result, entry)
+	}
+	return result
+}
+
+// normalizeDomainList reduces URLs to bare domains before de-duplicating
+func normalizeDomainList(domains []string) []string {
+	normalized := make([]string, 0, len(domains))
+	for _, domain := range domains {
+		if d := shared.ExtractDomainFromURL(strings.TrimSpace(domain)); d != "" {
+			normalized = append(normalized, strings.ToLower(d))
+		}
+	}
+	return normalizeEntityList(normalized)
+}
+
+// removeEntities returns entries without any of the removals (case-insensitive)
+func removeEntities(entries, removals []string) []string {
+	drop := make(map[string]bool, len(removals))
+	for _, r := range removals {
+		drop[strings.ToLower(strings.TrimSpace(r))] = true
+	}
+
+	var result []string
+	for _, entry := range entries {
+		if !drop[strings.ToLower(entry)] {
+			result = append(result, entry)
+		}
+	}
+	return result
+}
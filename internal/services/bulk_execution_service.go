@@ -2,12 +2,9 @@ package services
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"log"
-	"regexp"
 	"strings"
-	"sync"
 	"time"
 
 	"github.com/google/uuid"
@@ -15,205 +12,474 @@ import (
 	"github.com/fissionx/gego/internal/db"
 	"github.com/fissionx/gego/internal/llm"
 	"github.com/fissionx/gego/internal/models"
+	"github.com/fissionx/gego/internal/shared"
 )
 
-// BulkExecutionService handles batch execution of prompts across multiple LLMs
+// BulkExecutionService handles batch execution of prompts across multiple LLMs.
+// Each prompt × LLM pair of a campaign is a job on the durable queue.
 type BulkExecutionService struct {
-	db          db.Database
-	llmRegistry *llm.Registry
+	db            db.Database
+	llmFactory    *llm.Factory
+	queue         *JobQueueService
+	geoAnalyzer   *GEOAnalysisService
+	brandProfiles *BrandProfileService
 }
 
-// NewBulkExecutionService creates a new bulk execution service
-func NewBulkExecutionService(database db.Database, registry *llm.Registry) *BulkExecutionService {
-	return &BulkExecutionService{
-		db:          database,
-		llmRegistry: registry,
+// NewBulkExecutionService creates a new bulk execution service and registers it
+// as the handler for campaign jobs
+func NewBulkExecutionService(database db.Database, factory *llm.Factory, queue *JobQueueService) *BulkExecutionService {
+	s := &BulkExecutionService{
+		db:            database,
+		llmFactory:    factory,
+		queue:         queue,
+		geoAnalyzer:   NewGEOAnalysisService(database, factory),
+		brandProfiles: NewBrandProfileService(database),
 	}
+	queue.RegisterHandler(models.JobKindCampaign, s)
+	return s
 }
 
-// ExecuteCampaign executes all prompts across all LLMs for a GEO campaign
-func (s *BulkExecutionService) ExecuteCampaign(ctx context.Context, campaignName, brand string, promptIDs, llmIDs []string, temperature float64) (*models.GEOCampaign, error) {
+// campaignRun is a single prompt/LLM pair of a campaign, with one binding of
+// the prompt's template variables
+type campaignRun struct {
+	prompt    *models.Prompt
+	variables map[string]string
+	llm       *models.LLMConfig
+}
+
+// key identifies the run among the responses of its campaign
+func (r campaignRun) key() string {
+	return runKey(r.prompt.ID, r.llm.ID, r.variables)
+}
+
+func runKey(promptID, llmID string, variables map[string]string) string {
+	return promptID + "|" + llmID + "|" + FormatVariables(variables)
+}
+
+// ExecuteCampaign executes all prompts across all LLMs for a GEO campaign.
+// Prompts using template variables run once per combination of the values in
+// variables.
+func (s *BulkExecutionService) ExecuteCampaign(ctx context.Context, campaignName, brand string, promptIDs, llmIDs []string, temperature float64, variables map[string][]string) (*models.GEOCampaign, error) {
 	if temperature == 0 {
 		temperature = 0.7
 	}
+	if err := ValidateVariables(variables); err != nil {
+		return nil, err
+	}
+
+	// Invalid or disabled prompts and LLMs are skipped
+	runs, err := s.getRuns(ctx, promptIDs, llmIDs, variables, brand)
+	if err != nil {
+		return nil, err
+	}
 
 	// Create campaign
 	campaign := &models.GEOCampaign{
-		ID:        uuid.New().String(),
-		Name:      campaignName,
-		Brand:     brand,
-		PromptIDs: promptIDs,
-		LLMIDs:    llmIDs,
-		Status:    "running",
-		TotalRuns: len(promptIDs) * len(llmIDs),
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+		ID:          uuid.New().String(),
+		Name:        campaignName,
+		Brand:       brand,
+		PromptIDs:   promptIDs,
+		LLMIDs:      llmIDs,
+		Temperature: temperature,
+		Variables:   variables,
+		Status:      models.CampaignStatusRunning,
+		TotalRuns:   len(runs),
+	}
+
+	if err := s.db.CreateCampaign(ctx, campaign); err != nil {
+		return nil, fmt.Errorf("failed to create campaign: %w", err)
+	}
+
+	if err := s.enqueueRuns(ctx, campaign, runs); err != nil {
+		s.failCampaign(ctx, campaign, err)
+		return nil, err
 	}
 
-	// Start execution in background
-	go s.executeInBackground(context.Background(), campaign, temperature)
+	log.Printf("========== QUEUED CAMPAIGN: %s ==========", campaign.Name)
+	log.Printf("Brand: %s, Prompts: %d, LLMs: %d, Total Runs: %d",
+		campaign.Brand, len(campaign.PromptIDs), len(campaign.LLMIDs), campaign.TotalRuns)
 
 	return campaign, nil
 }
 
-// executeInBackground runs the campaign execution asynchronously
-func (s *BulkExecutionService) executeInBackground(ctx context.Context, campaign *models.GEOCampaign, temperature float64) {
-	log.Printf("========== STARTING CAMPAIGN: %s ==========", campaign.Name)
-	log.Printf("Brand: %s, Prompts: %d, LLMs: %d, Total Runs: %d", 
-		campaign.Brand, len(campaign.PromptIDs), len(campaign.LLMIDs), campaign.TotalRuns)
+// GetCampaign retrieves a campaign with its current progress
+func (s *BulkExecutionService) GetCampaign(ctx context.Context, id string) (*models.GEOCampaign, error) {
+	return s.db.GetCampaign(ctx, id)
+}
+
+// ListCampaigns lists campaigns, optionally filtered by brand
+func (s *BulkExecutionService) ListCampaigns(ctx context.Context, brand string) ([]*models.GEOCampaign, error) {
+	return s.db.ListCampaigns(ctx, brand)
+}
+
+// CancelCampaign stops a running campaign. Queued runs are cancelled; runs
+// already in flight finish normally.
+func (s *BulkExecutionService) CancelCampaign(ctx context.Context, id string) (*models.GEOCampaign, error) {
+	campaign, err := s.db.FinishCampaign(ctx, id, models.CampaignStatusCancelled, "")
+	if err != nil {
+		return nil, err
+	}
+	if campaign == nil {
+		current, err := s.db.GetCampaign(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("campaign %s is not running (status: %s)", id, current.Status)
+	}
+
+	cancelled, err := s.db.CancelCampaignJobs(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("Campaign %s cancelled, %d queued runs dropped", campaign.Name, cancelled)
+	return campaign, nil
+}
+
+// RetryFailedRuns re-queues the prompt/LLM pairs of a campaign that failed
+// and never succeeded since
+func (s *BulkExecutionService) RetryFailedRuns(ctx context.Context, id string) (*models.GEOCampaign, error) {
+	campaign, err := s.db.GetCampaign(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if campaign.Status == models.CampaignStatusRunning {
+		return nil, fmt.Errorf("campaign %s is still running", id)
+	}
+
+	responses, err := s.db.ListResponses(ctx, shared.ResponseFilter{CampaignID: id})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch campaign responses: %w", err)
+	}
+
+	// Responses record every variable they bound, defaults included; runs
+	// are told apart by the variables with a value set only
+	responseRunKey := func(resp *models.Response) string {
+		variables := make(map[string]string)
+		for name, value := range resp.Variables {
+			if len(campaign.Variables[name]) > 0 {
+				variables[name] = value
+			}
+		}
+		return runKey(resp.PromptID, resp.LLMID, variables)
+	}
+
+	succeeded := make(map[string]bool)
+	for _, resp := range responses {
+		if resp.Error == "" {
+			succeeded[responseRunKey(resp)] = true
+		}
+	}
+
+	failed := make(map[string]bool)
+	var promptIDs, llmIDs []string
+	for _, resp := range responses {
+		key := responseRunKey(resp)
+		if resp.Error == "" || succeeded[key] || failed[key] {
+			continue
+		}
+		failed[key] = true
+		if !contains(promptIDs, resp.PromptID) {
+			promptIDs = append(promptIDs, resp.PromptID)
+		}
+		if !contains(llmIDs, resp.LLMID) {
+			llmIDs = append(llmIDs, resp.LLMID)
+		}
+	}
+
+	if len(failed) == 0 {
+		return nil, fmt.Errorf("campaign %s has no failed runs to retry", id)
+	}
+
+	allRuns, err := s.getRuns(ctx, promptIDs, llmIDs, campaign.Variables, campaign.Brand)
+	if err != nil {
+		return nil, err
+	}
+
+	var runs []campaignRun
+	for _, run := range allRuns {
+		if failed[run.key()] {
+			runs = append(runs, run)
+		}
+	}
+	if len(runs) == 0 {
+		return nil, fmt.Errorf("failed runs of campaign %s reference prompts or LLMs that no longer exist", id)
+	}
+
+	// The retried runs are counted again as they finish
+	campaign.FailedRuns -= len(runs)
+	if campaign.FailedRuns < 0 {
+		campaign.FailedRuns = 0
+	}
+	campaign.Status = models.CampaignStatusRunning
+	campaign.Error = ""
+	campaign.CompletedAt = nil
+	if err := s.db.UpdateCampaign(ctx, campaign); err != nil {
+		return nil, err
+	}
+
+	if err := s.enqueueRuns(ctx, campaign, runs); err != nil {
+		s.failCampaign(ctx, campaign, err)
+		return nil, err
+	}
+
+	log.Printf("Retrying %d failed runs of campaign %s", len(runs), campaign.Name)
+	return campaign, nil
+}
+
+// ExecuteJob runs one prompt/LLM pair of a campaign
+func (s *BulkExecutionService) ExecuteJob(ctx context.Context, job *models.Job) error {
+	campaign, err := s.db.GetCampaign(ctx, job.CampaignID)
+	if err != nil {
+		return err
+	}
+	if campaign.Status == models.CampaignStatusCancelled {
+		return ErrJobSkipped
+	}
+
+	prompt, err := s.db.GetPrompt(ctx, job.PromptID)
+	if err != nil {
+		return fmt.Errorf("failed to get prompt: %w", err)
+	}
 
-	// Fetch prompts and LLMs
-	prompts, err := s.getPrompts(ctx, campaign.PromptIDs)
+	llmConfig, err := NewLLMService(s.db).GetLLM(ctx, job.LLMID)
 	if err != nil {
-		log.Printf("Failed to fetch prompts: %v", err)
-		campaign.Status = "failed"
+		return fmt.Errorf("failed to get LLM: %w", err)
+	}
+
+	return s.executeSingle(ctx, prompt, job.Variables, llmConfig, campaign)
+}
+
+// JobFinished records the outcome of a campaign run and completes the campaign
+// once every run has finished
+func (s *BulkExecutionService) JobFinished(ctx context.Context, job *models.Job, jobErr error) {
+	succeeded, failed := 1, 0
+	if jobErr != nil {
+		succeeded, failed = 0, 1
+		log.Printf("Execution failed for prompt %s with LLM %s: %v", job.PromptID, job.LLMID, jobErr)
+		s.saveErrorResponse(ctx, job, jobErr)
+	}
+
+	if err := s.db.IncrementCampaignProgress(ctx, job.CampaignID, succeeded, failed); err != nil {
+		log.Printf("Failed to update progress of campaign %s: %v", job.CampaignID, err)
 		return
 	}
 
-	llms, err := s.getLLMs(ctx, campaign.LLMIDs)
+	campaign, err := s.db.GetCampaign(ctx, job.CampaignID)
 	if err != nil {
-		log.Printf("Failed to fetch LLMs: %v", err)
-		campaign.Status = "failed"
+		log.Printf("Failed to reload campaign %s: %v", job.CampaignID, err)
 		return
 	}
 
-	// Execute with concurrency control
-	var wg sync.WaitGroup
-	semaphore := make(chan struct{}, 3) // Max 3 concurrent executions
-	completed := 0
-	mu := sync.Mutex{}
+	done := campaign.CompletedRuns + campaign.FailedRuns
+	if done%10 == 0 || done >= campaign.TotalRuns {
+		log.Printf("Campaign %s: %d/%d completed", campaign.Name, done, campaign.TotalRuns)
+	}
 
-	for _, prompt := range prompts {
-		for _, llmConfig := range llms {
-			wg.Add(1)
-			
-			go func(p *models.Prompt, llm *models.LLMConfig) {
-				defer wg.Done()
-				
-				// Acquire semaphore
-				semaphore <- struct{}{}
-				defer func() { <-semaphore }()
-
-				// Execute single prompt-LLM pair
-				err := s.executeSingle(ctx, p, llm, campaign.Brand, temperature)
-				
-				mu.Lock()
-				completed++
-				if completed%10 == 0 || completed == campaign.TotalRuns {
-					log.Printf("Campaign %s: %d/%d completed", campaign.Name, completed, campaign.TotalRuns)
-				}
-				mu.Unlock()
-
-				if err != nil {
-					log.Printf("Execution failed for prompt %s with LLM %s: %v", p.ID, llm.ID, err)
-				}
-			}(prompt, llmConfig)
-		}
+	if campaign.Status != models.CampaignStatusRunning || done < campaign.TotalRuns {
+		return
 	}
 
-	wg.Wait()
+	status, errMsg := models.CampaignStatusCompleted, ""
+	if campaign.TotalRuns > 0 && campaign.FailedRuns >= campaign.TotalRuns {
+		status, errMsg = models.CampaignStatusFailed, "all runs failed"
+	}
+	campaign, err = s.db.FinishCampaign(ctx, campaign.ID, status, errMsg)
+	if err != nil {
+		log.Printf("Failed to update campaign %s: %v", job.CampaignID, err)
+		return
+	}
+	if campaign == nil {
+		// Cancelled, or completed by the run that finished alongside
+		return
+	}
 
-	completedTime := time.Now()
-	campaign.Status = "completed"
-	campaign.CompletedAt = &completedTime
-	campaign.UpdatedAt = completedTime
+	log.Printf("========== CAMPAIGN %s: %s ==========", strings.ToUpper(campaign.Status), campaign.Name)
+	log.Printf("Completed: %d, Failed: %d", campaign.CompletedRuns, campaign.FailedRuns)
+}
 
-	log.Printf("========== CAMPAIGN COMPLETED: %s ==========", campaign.Name)
-	log.Printf("Total executions: %d", completed)
+// enqueueRuns queues one job per prompt/LLM pair of a campaign
+func (s *BulkExecutionService) enqueueRuns(ctx context.Context, campaign *models.GEOCampaign, runs []campaignRun) error {
+	jobs := make([]*models.Job, 0, len(runs))
+	for _, run := range runs {
+		jobs = append(jobs, &models.Job{
+			Kind:        models.JobKindCampaign,
+			PromptID:    run.prompt.ID,
+			LLMID:       run.llm.ID,
+			CampaignID:  campaign.ID,
+			Brand:       boundBrand(campaign.Brand, run.variables),
+			Temperature: campaign.Temperature,
+			Variables:   run.variables,
+		})
+	}
+
+	if err := s.queue.Enqueue(ctx, jobs); err != nil {
+		return fmt.Errorf("failed to queue campaign runs: %w", err)
+	}
+	return nil
 }
 
-// executeSingle executes a single prompt with a single LLM
-func (s *BulkExecutionService) executeSingle(ctx context.Context, prompt *models.Prompt, llmConfig *models.LLMConfig, brand string, temperature float64) error {
+// failCampaign marks a campaign as failed when its runs could not be queued
+func (s *BulkExecutionService) failCampaign(ctx context.Context, campaign *models.GEOCampaign, cause error) {
+	log.Printf("Campaign %s failed: %v", campaign.Name, cause)
+
+	if _, err := s.db.FinishCampaign(ctx, campaign.ID, models.CampaignStatusFailed, cause.Error()); err != nil {
+		log.Printf("Failed to update campaign %s: %v", campaign.ID, err)
+	}
+}
+
+// saveErrorResponse records a dead-lettered run as an error response
+func (s *BulkExecutionService) saveErrorResponse(ctx context.Context, job *models.Job, jobErr error) {
+	errorResponse := &models.Response{
+		ID:          uuid.New().String(),
+		PromptID:    job.PromptID,
+		LLMID:       job.LLMID,
+		Brand:       job.Brand,
+		CampaignID:  job.CampaignID,
+		Temperature: job.Temperature,
+		Variables:   job.Variables,
+		Error:       jobErr.Error(),
+		CreatedAt:   time.Now(),
+	}
+
+	if prompt, err := s.db.GetPrompt(ctx, job.PromptID); err == nil {
+		errorResponse.PromptText = prompt.Template
+		if text, _, err := BindPrompt(prompt.Template, job.Variables, job.Brand); err == nil {
+			errorResponse.PromptText = text
+		}
+		ApplyPromptMetadata(errorResponse, prompt)
+	}
+	if llmConfig, err := s.db.GetLLM(ctx, job.LLMID); err == nil {
+		errorResponse.LLMName = llmConfig.Name
+		errorResponse.LLMProvider = llmConfig.Provider
+		errorResponse.LLMModel = llmConfig.Model
+	}
+
+	if err := s.db.CreateResponse(ctx, errorResponse); err != nil {
+		log.Printf("Failed to save error response for campaign %s: %v", job.CampaignID, err)
+	}
+}
+
+// executeSingle executes a single prompt with a single LLM, binding the
+// prompt's template variables to the given values
+func (s *BulkExecutionService) executeSingle(ctx context.Context, prompt *models.Prompt, variables map[string]string, llmConfig *models.LLMConfig, campaign *models.GEOCampaign) error {
+	brand := boundBrand(campaign.Brand, variables)
+	temperature := campaign.Temperature
+
+	promptText, variables, err := BindPrompt(prompt.Template, variables, brand)
+	if err != nil {
+		return err
+	}
+
 	// Create LLM provider
-	provider, ok := s.llmRegistry.Get(llmConfig.Provider)
-	if !ok || provider == nil {
-		return fmt.Errorf("provider not available: %s", llmConfig.Provider)
+	provider, err := s.llmFactory.Get(llmConfig)
+	if err != nil {
+		return fmt.Errorf("provider not available: %w", err)
 	}
 
 	// Execute prompt
-	response, err := provider.Generate(ctx, prompt.Template, llm.Config{
+	response, err := provider.Generate(ctx, promptText, llm.Config{
 		Model:       llmConfig.Model,
 		Temperature: temperature,
 		MaxTokens:   4096,
 		Brand:       brand,
+		WebSearch:   llm.WebSearchEnabled(llmConfig),
 	})
 	if err != nil {
-		// Save error response
-		errorResponse := &models.Response{
-			ID:           uuid.New().String(),
-			PromptID:     prompt.ID,
-			PromptText:   prompt.Template,
-			LLMID:        llmConfig.ID,
-			LLMName:      llmConfig.Name,
-			LLMProvider:  llmConfig.Provider,
-			LLMModel:     llmConfig.Model,
-			Brand:        brand,
-			Temperature:  temperature,
-			Error:        err.Error(),
-			CreatedAt:    time.Now(),
-		}
-		s.db.CreateResponse(ctx, errorResponse)
 		return err
 	}
 
-	// Parse GEO analysis from response (if brand was provided)
+	// Build the response record
 	responseModel := &models.Response{
 		ID:           uuid.New().String(),
 		PromptID:     prompt.ID,
-		PromptText:   prompt.Template,
+		PromptText:   promptText,
 		LLMID:        llmConfig.ID,
 		LLMName:      llmConfig.Name,
 		LLMProvider:  llmConfig.Provider,
 		LLMModel:     llmConfig.Model,
 		ResponseText: response.Text,
 		Brand:        brand,
+		CampaignID:   campaign.ID,
 		Temperature:  temperature,
+		Variables:    variables,
 		TokensUsed:   response.TokensUsed,
 		LatencyMs:    response.LatencyMs,
 		CreatedAt:    time.Now(),
 	}
+	ApplyPromptMetadata(responseModel, prompt)
+	ApplyCitations(responseModel, response)
 
-	// Parse GEO metrics if brand was provided
+	// Run the GEO analysis stage if brand was provided
 	if brand != "" {
-		geoAnalysis := parseGEOAnalysis(response.Text)
-		if geoAnalysis != nil {
-			// Access nested GEOAnalysis struct
-			geo := geoAnalysis.GEOAnalysis
-			responseModel.VisibilityScore = geo.VisibilityScore
-			responseModel.BrandMentioned = geo.BrandMentioned
-			responseModel.InGroundingSources = geo.InGroundingSources
-			responseModel.Sentiment = geo.Sentiment
-			responseModel.CompetitorsMention = geo.Competitors
-			responseModel.GroundingSources = response.GroundingSources
-			
-			// Extract position/ranking from the search_answer text
-			searchAnswer := geoAnalysis.SearchAnswer
-			if searchAnswer == "" {
-				searchAnswer = response.Text
-			}
-			
-			if geo.BrandMentioned {
-				position, totalBrands := ExtractBrandPosition(searchAnswer, brand)
-				responseModel.BrandPosition = position
-				responseModel.TotalBrandsListed = totalBrands
-			}
-			
-			// Extract domains from grounding sources
-			if len(response.GroundingSources) > 0 {
-				responseModel.GroundingDomains = ExtractDomainsFromSources(response.GroundingSources)
-			}
+		matcher := s.brandProfiles.GetMatcher(ctx, brand)
+		geoAnalysis, err := s.geoAnalyzer.Analyze(ctx, promptText, brand, response, llmConfig)
+		if err != nil {
+			log.Printf("GEO analysis failed for prompt %s with LLM %s: %v", prompt.ID, llmConfig.ID, err)
+		} else {
+			ApplyGEOAnalysis(responseModel, geoAnalysis, response.GroundingSources, matcher)
 		}
+
+		// Rule-based baseline alongside the LLM judgement
+		ApplyBrandExtraction(responseModel, responseModel.ResponseText, matcher)
 	}
-	
-	// Add time-series fields
-	now := time.Now()
-	responseModel.Week = now.Format("2006-W02")
-	responseModel.Month = now.Format("2006-01")
-	quarter := (int(now.Month()) - 1) / 3 + 1
-	responseModel.Quarter = fmt.Sprintf("%d-Q%d", now.Year(), quarter)
 
 	// Save response
-	return s.db.CreateResponse(ctx, responseModel)
+	return saveGeneratedResponse(ctx, s.db, responseModel)
+}
+
+// Saving the response of a successful LLM call is retried on its own: a
+// failed job attempt would pay for the LLM call again
+var (
+	responseSaveAttempts = 3
+	responseSaveDelay    = 2 * time.Second
+)
+
+// saveGeneratedResponse saves the response of a successful LLM call, retrying
+// the write before giving up. The write is not interrupted by a shutdown.
+func saveGeneratedResponse(ctx context.Context, database db.Database, response *models.Response) error {
+	ctx = context.WithoutCancel(ctx)
+
+	var err error
+	for attempt := 1; attempt <= responseSaveAttempts; attempt++ {
+		if err = database.CreateResponse(ctx, response); err == nil {
+			return nil
+		}
+		if attempt < responseSaveAttempts {
+			log.Printf("Failed to save response %s (attempt %d/%d), retrying: %v", response.ID, attempt, responseSaveAttempts, err)
+			time.Sleep(responseSaveDelay * time.Duration(attempt))
+		}
+	}
+	return fmt.Errorf("failed to save response after %d attempts: %w", responseSaveAttempts, err)
+}
+
+// getRuns resolves the prompt/LLM pairs to execute, expanding the value sets
+// of the variables each prompt uses
+func (s *BulkExecutionService) getRuns(ctx context.Context, promptIDs, llmIDs []string, variables map[string][]string, brand string) ([]campaignRun, error) {
+	prompts, err := s.getPrompts(ctx, promptIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch prompts: %w", err)
+	}
+	for _, prompt := range prompts {
+		if err := ValidatePromptVariables(prompt.Template, brand, variables); err != nil {
+			return nil, err
+		}
+	}
+
+	llms, err := s.getLLMs(ctx, llmIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch LLMs: %w", err)
+	}
+
+	runs := make([]campaignRun, 0, len(prompts)*len(llms))
+	for _, prompt := range prompts {
+		for _, binding := range PromptBindings(prompt.Template, variables) {
+			for _, llmConfig := range llms {
+				runs = append(runs, campaignRun{prompt: prompt, variables: binding, llm: llmConfig})
+			}
+		}
+	}
+	return runs, nil
 }
 
 // getPrompts fetches prompts by IDs
@@ -250,71 +516,3 @@ func (s *BulkExecutionService) getLLMs(ctx context.Context, llmIDs []string) ([]
 	}
 	return llms, nil
 }
-
-// GEOAnalysisResult represents the parsed GEO analysis from LLM
-type GEOAnalysisResult struct {
-	SearchAnswer string `json:"search_answer"`
-	GEOAnalysis  struct {
-		VisibilityScore    int      `json:"visibility_score"`
-		BrandMentioned     bool     `json:"brand_mentioned"`
-		InGroundingSources bool     `json:"in_grounding_sources"`
-		MentionStatus      string   `json:"mention_status"`
-		Reason             string   `json:"reason"`
-		Sentiment          string   `json:"sentiment"`
-		Competitors        []string `json:"competitors"`
-		Insights           []string `json:"insights"`
-		Actions            []string `json:"actions"`
-		CompetitorInfo     string   `json:"competitor_info"`
-	} `json:"geo_analysis"`
-}
-
-// parseGEOAnalysis extracts and parses JSON from the LLM response
-func parseGEOAnalysis(text string) *GEOAnalysisResult {
-	// Clean up the response - remove markdown code blocks if present
-	cleanedText := strings.TrimSpace(text)
-	
-	// Remove markdown code block wrappers (```json ... ``` or ``` ... ```)
-	jsonBlockRegex := regexp.MustCompile("(?s)```(?:json)?\\s*(.+?)\\s*```")
-	if matches := jsonBlockRegex.FindStringSubmatch(cleanedText); len(matches) > 1 {
-		cleanedText = strings.TrimSpace(matches[1])
-	} else {
-		// Try simple prefix/suffix removal
-		cleanedText = strings.TrimPrefix(cleanedText, "```json")
-		cleanedText = strings.TrimPrefix(cleanedText, "```")
-		cleanedText = strings.TrimSuffix(cleanedText, "```")
-		cleanedText = strings.TrimSpace(cleanedText)
-	}
-
-	// Try to find JSON object in the text if it's mixed with other content
-	if !strings.HasPrefix(cleanedText, "{") {
-		jsonStartIdx := strings.Index(cleanedText, "{")
-		jsonEndIdx := strings.LastIndex(cleanedText, "}")
-		if jsonStartIdx != -1 && jsonEndIdx != -1 && jsonEndIdx > jsonStartIdx {
-			cleanedText = cleanedText[jsonStartIdx : jsonEndIdx+1]
-		}
-	}
-	
-	var result GEOAnalysisResult
-	if err := json.Unmarshal([]byte(cleanedText), &result); err != nil {
-		log.Printf("❌ Failed to parse GEO analysis JSON: %v", err)
-		log.Printf("Cleaned text (first 500 chars): %s", truncateForLog(cleanedText, 500))
-		return nil
-	}
-	
-	log.Printf("✅ Parsed GEO: Score=%d, Mentioned=%v, Sentiment=%s, Competitors=%v", 
-		result.GEOAnalysis.VisibilityScore, 
-		result.GEOAnalysis.BrandMentioned,
-		result.GEOAnalysis.Sentiment,
-		result.GEOAnalysis.Competitors)
-	
-	return &result
-}
-
-// truncateForLog truncates a string for logging
-func truncateForLog(s string, maxLen int) string {
-	if len(s) <= maxLen {
-		return s
-	}
-	return s[:maxLen] + "..."
-}
-
@@ -0,0 +1,199 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/fissionx/gego/internal/db"
+	"github.com/fissionx/gego/internal/llm"
+	"github.com/fissionx/gego/internal/llm/providers"
+	"github.com/fissionx/gego/internal/models"
+	"github.com/fissionx/gego/internal/shared"
+)
+
+func TestCancelCampaignDuringProgress(t *testing.T) {
+	ctx := context.Background()
+	database := newTestDB(t)
+	service := NewBulkExecutionService(database, llm.NewFactory(), NewJobQueueService(database))
+
+	campaign := &models.GEOCampaign{ID: "c1", Name: "launch", Brand: "HubSpot", Status: models.CampaignStatusRunning, TotalRuns: 1000}
+	if err := database.CreateCampaign(ctx, campaign); err != nil {
+		t.Fatalf("CreateCampaign() error = %v", err)
+	}
+
+	// Runs in flight keep counting while the campaign is cancelled
+	const workers, increments = 4, 50
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < increments; i++ {
+				if err := database.IncrementCampaignProgress(ctx, "c1", 1, 0); err != nil {
+					t.Errorf("IncrementCampaignProgress() error = %v", err)
+					return
+				}
+			}
+		}()
+	}
+	cancelled, err := service.CancelCampaign(ctx, "c1")
+	wg.Wait()
+	if err != nil {
+		t.Fatalf("CancelCampaign() error = %v", err)
+	}
+	if cancelled.Status != models.CampaignStatusCancelled || cancelled.CompletedAt == nil {
+		t.Errorf("CancelCampaign() = %+v", cancelled)
+	}
+
+	stored, err := database.GetCampaign(ctx, "c1")
+	if err != nil {
+		t.Fatalf("GetCampaign() error = %v", err)
+	}
+	if stored.CompletedRuns != workers*increments || stored.Status != models.CampaignStatusCancelled {
+		t.Errorf("campaign = %d completed runs, %s; want %d, cancelled", stored.CompletedRuns, stored.Status, workers*increments)
+	}
+
+	if _, err := service.CancelCampaign(ctx, "c1"); err == nil {
+		t.Error("CancelCampaign() of a cancelled campaign succeeded")
+	}
+
+	// The last run finishing after a cancel does not complete the campaign
+	if err := database.CreateCampaign(ctx, &models.GEOCampaign{ID: "c2", Name: "single", Status: models.CampaignStatusRunning, TotalRuns: 1}); err != nil {
+		t.Fatalf("CreateCampaign() error = %v", err)
+	}
+	if _, err := service.CancelCampaign(ctx, "c2"); err != nil {
+		t.Fatalf("CancelCampaign() error = %v", err)
+	}
+	service.JobFinished(ctx, &models.Job{CampaignID: "c2"}, nil)
+	if stored, _ := database.GetCampaign(ctx, "c2"); stored.Status != models.CampaignStatusCancelled || stored.CompletedRuns != 1 {
+		t.Errorf("campaign after its last run = %d completed runs, %s; want 1, cancelled", stored.CompletedRuns, stored.Status)
+	}
+}
+
+func TestExecuteCampaignFromFixtures(t *testing.T) {
+	ctx := context.Background()
+	database := newTestDB(t)
+	queue := NewJobQueueService(database)
+	service := NewBulkExecutionService(database, providers.NewReplayFactory("testdata/replay"), queue)
+
+	if err := database.CreateLLM(ctx, &models.LLMConfig{ID: "gpt", Name: "GPT", Provider: "openai", Model: "gpt-4o", Enabled: true}); err != nil {
+		t.Fatalf("CreateLLM() error = %v", err)
+	}
+	if err := database.CreatePrompt(ctx, &models.Prompt{ID: "best-crm", Template: "Best CRM in {{city}}?", Enabled: true}); err != nil {
+		t.Fatalf("CreatePrompt() error = %v", err)
+	}
+
+	campaign, err := service.ExecuteCampaign(ctx, "cities", "HubSpot", []string{"best-crm"}, []string{"gpt"}, 0.7,
+		map[string][]string{"city": {"Austin", "Berlin"}})
+	if err != nil {
+		t.Fatalf("ExecuteCampaign() error = %v", err)
+	}
+	if campaign.TotalRuns != 2 {
+		t.Fatalf("ExecuteCampaign() = %d runs, want 2", campaign.TotalRuns)
+	}
+
+	for queue.processNext(ctx) {
+	}
+
+	stored, err := database.GetCampaign(ctx, campaign.ID)
+	if err != nil {
+		t.Fatalf("GetCampaign() error = %v", err)
+	}
+	if stored.Status != models.CampaignStatusCompleted || stored.CompletedRuns != 2 || stored.FailedRuns != 0 {
+		t.Errorf("campaign = %s with %d completed and %d failed runs, want completed with 2", stored.Status, stored.CompletedRuns, stored.FailedRuns)
+	}
+
+	responses, err := database.ListResponses(ctx, shared.ResponseFilter{CampaignID: campaign.ID})
+	if err != nil {
+		t.Fatalf("ListResponses() error = %v", err)
+	}
+	if len(responses) != 2 {
+		t.Fatalf("ListResponses() = %d responses, want 2", len(responses))
+	}
+	byCity := make(map[string]*models.Response)
+	for _, response := range responses {
+		byCity[response.Variables["city"]] = response
+	}
+
+	austin := byCity["Austin"]
+	if austin == nil {
+		t.Fatal("no response for Austin")
+	}
+	if austin.PromptText != "Best CRM in Austin?" || austin.LLMProvider != "openai" || austin.TokensUsed != 180 || austin.Error != "" {
+		t.Errorf("Austin response = %+v", austin)
+	}
+	if !austin.BrandMentioned || austin.VisibilityScore != 8 || austin.BrandPosition != 1 || austin.Sentiment != "positive" {
+		t.Errorf("Austin analysis = mentioned %v, visibility %d, position %d, sentiment %s",
+			austin.BrandMentioned, austin.VisibilityScore, austin.BrandPosition, austin.Sentiment)
+	}
+	if !slices.Equal(austin.GroundingDomains, []string{"hubspot.com", "g2.com"}) {
+		t.Errorf("Austin grounding domains = %v", austin.GroundingDomains)
+	}
+	if len(austin.Citations) != 1 || austin.Citations[0].CitedText != "HubSpot - free plan and strong marketing tools" {
+		t.Errorf("Austin citations = %+v", austin.Citations)
+	}
+
+	berlin := byCity["Berlin"]
+	if berlin == nil {
+		t.Fatal("no response for Berlin")
+	}
+	if berlin.BrandMentioned || !slices.Equal(berlin.CompetitorsMention, []string{"Pipedrive", "Salesforce"}) {
+		t.Errorf("Berlin analysis = mentioned %v, competitors %v", berlin.BrandMentioned, berlin.CompetitorsMention)
+	}
+}
+
+// flakyResponsesDB fails the first response writes
+type flakyResponsesDB struct {
+	db.Database
+	failures int
+}
+
+func (d *flakyResponsesDB) CreateResponse(ctx context.Context, response *models.Response) error {
+	if d.failures > 0 {
+		d.failures--
+		return errors.New("database is locked")
+	}
+	return d.Database.CreateResponse(ctx, response)
+}
+
+func TestExecuteCampaignRetriesOnlyTheSave(t *testing.T) {
+	ctx := context.Background()
+	previous := responseSaveDelay
+	responseSaveDelay = 0
+	t.Cleanup(func() { responseSaveDelay = previous })
+
+	database := &flakyResponsesDB{Database: newTestDB(t), failures: responseSaveAttempts - 1}
+	queue := NewJobQueueService(database)
+	service := NewBulkExecutionService(database, providers.NewReplayFactory("testdata/replay"), queue)
+
+	if err := database.CreateLLM(ctx, &models.LLMConfig{ID: "gpt", Name: "GPT", Provider: "openai", Model: "gpt-4o", Enabled: true}); err != nil {
+		t.Fatalf("CreateLLM() error = %v", err)
+	}
+	if err := database.CreatePrompt(ctx, &models.Prompt{ID: "best-crm", Template: "Best CRM in {{city}}?", Enabled: true}); err != nil {
+		t.Fatalf("CreatePrompt() error = %v", err)
+	}
+
+	campaign, err := service.ExecuteCampaign(ctx, "cities", "HubSpot", []string{"best-crm"}, []string{"gpt"}, 0.7,
+		map[string][]string{"city": {"Austin"}})
+	if err != nil {
+		t.Fatalf("ExecuteCampaign() error = %v", err)
+	}
+	for queue.processNext(ctx) {
+	}
+
+	// The failed writes are retried within the first attempt of the job
+	jobs, err := database.ListJobs(ctx, "", 0)
+	if err != nil || len(jobs) != 1 {
+		t.Fatalf("ListJobs() = %d jobs, %v", len(jobs), err)
+	}
+	if jobs[0].Status != models.JobStatusDone || jobs[0].Attempts != 1 {
+		t.Errorf("job = %s after %d attempts, want done after 1", jobs[0].Status, jobs[0].Attempts)
+	}
+	responses, err := database.ListResponses(ctx, shared.ResponseFilter{CampaignID: campaign.ID})
+	if err != nil || len(responses) != 1 || responses[0].Error != "" {
+		t.Errorf("ListResponses() = %+v, %v; want one response", responses, err)
+	}
+}
@@ -12,11 +12,16 @@ import (
 	"github.com/fissionx/gego/internal/shared"
 )
 
+// promptBreakdownLimit is how many of the most recent responses the
+// prompt-level breakdown covers
+const promptBreakdownLimit = 100
+
 // CompetitiveBenchmarkService provides competitive analysis
 type CompetitiveBenchmarkService struct {
 	db                    db.Database
 	recommendationsEngine *RecommendationsEngine
 	logoService           *LogoService
+	brandProfiles         *BrandProfileService
 }
 
 // NewCompetitiveBenchmarkService creates a new competitive benchmark service
@@ -25,6 +30,7 @@ func NewCompetitiveBenchmarkService(database db.Database) *CompetitiveBenchmarkS
 		db:                    database,
 		recommendationsEngine: NewRecommendationsEngine(),
 		logoService:           NewLogoService(database),
+		brandProfiles:         NewBrandProfileService(database),
 	}
 }
 
@@ -38,111 +44,71 @@ func (s *CompetitiveBenchmarkService) GetCompetitiveBenchmark(
 	startTime, endTime *time.Time,
 	region string,
 ) (*models.CompetitiveBenchmarkResponse, error) {
-	// Fetch all responses for the main brand's campaigns
+	// Aggregate the main brand's responses in the database
 	filter := shared.ResponseFilter{
+		Brand:     mainBrand,
+		Region:    region,
+		PromptIDs: promptIDs,
+		LLMIDs:    llmIDs,
 		StartTime: startTime,
 		EndTime:   endTime,
-		Limit:     10000,
 	}
 
-	allResponses, err := s.db.ListResponses(ctx, filter)
+	totals, err := s.db.AggregateResponseMetrics(ctx, filter, shared.GroupByNone)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch responses: %w", err)
+		return nil, fmt.Errorf("failed to aggregate responses: %w", err)
 	}
-
-	// Filter to main brand's campaign responses
-	var responses []*models.Response
-	for _, resp := range allResponses {
-		// Must match main brand
-		if resp.Brand != mainBrand {
-			continue
-		}
-
-		// Region filter: only apply if BOTH are specified
-		// If response has no region, or request has no region filter, include it
-		if region != "" && resp.Region != "" && !strings.EqualFold(resp.Region, region) {
-			continue
-		}
-
-		// Filter by prompt IDs if specified
-		if len(promptIDs) > 0 && !contains(promptIDs, resp.PromptID) {
-			continue
-		}
-
-		// Filter by LLM IDs if specified
-		if len(llmIDs) > 0 && !contains(llmIDs, resp.LLMID) {
-			continue
-		}
-
-		responses = append(responses, resp)
+	if len(totals) == 0 || totals[0].TotalResponses == 0 {
+		return nil, fmt.Errorf("no responses found for brand %s", mainBrand)
 	}
+	total := totals[0]
 
-	if len(responses) == 0 {
-		return nil, fmt.Errorf("no responses found for brand %s", mainBrand)
+	competitorCounts, err := s.db.CountCompetitorMentions(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count competitor mentions: %w", err)
 	}
 
+	mainMatcher := s.brandProfiles.GetMatcher(ctx, mainBrand)
+
 	// If competitors not specified, auto-detect from responses
 	if len(competitors) == 0 {
 		competitorSet := make(map[string]bool)
-		for _, resp := range responses {
-			for _, comp := range resp.CompetitorsMention {
-				// Normalize competitor name
-				normalized := strings.TrimSpace(comp)
-				if normalized != "" && !strings.EqualFold(normalized, mainBrand) {
-					competitorSet[normalized] = true
-				}
+		for comp := range competitorCounts {
+			// Normalize competitor name, skipping aliases of the main brand
+			normalized := strings.TrimSpace(comp)
+			if normalized != "" && !strings.EqualFold(normalized, mainBrand) && !mainMatcher.Matches(normalized) {
+				competitorSet[normalized] = true
 			}
 		}
 		for comp := range competitorSet {
 			competitors = append(competitors, comp)
 		}
+		sort.Strings(competitors)
 	}
 
 	// Analyze ALL brands mentioned across all responses
 	// This gives us the real "share of voice" in AI responses
 	allBrands := append([]string{mainBrand}, competitors...)
-	brandStats := make(map[string]*brandMentionStats)
+	matchers := s.brandProfiles.GetMatchers(ctx, competitors)
+	mentionCounts := make(map[string]int)
 
-	for _, brand := range allBrands {
-		brandStats[brand] = &brandMentionStats{
-			brand:           brand,
-			mentionCount:    0,
-			totalVisibility: 0,
-			totalPosition:   0,
-			positionCount:   0,
-			sentimentScores: []float64{},
-		}
-	}
-
-	// Analyze each response
-	for _, resp := range responses {
-		// Main brand stats (from the actual analysis)
-		if stats, ok := brandStats[mainBrand]; ok {
-			if resp.BrandMentioned {
-				stats.mentionCount++
-				stats.totalVisibility += float64(resp.VisibilityScore)
-
-				if resp.BrandPosition > 0 {
-					stats.totalPosition += float64(resp.BrandPosition)
-					stats.positionCount++
-				}
+	// Main brand mentions come from the actual analysis
+	mentionCounts[mainBrand] = total.MentionCount
 
-				if resp.Sentiment != "" {
-					stats.sentimentScores = append(stats.sentimentScores, calculateSentimentScore(resp.Sentiment))
-				}
-			}
+	// Competitor mentions come from the competitors_mention field, summed over
+	// the names matching each competitor's aliases
+	for _, compName := range competitors {
+		if compName == mainBrand {
+			continue
 		}
-
-		// Competitor stats (from competitors_mention field)
-		for _, mentioned := range resp.CompetitorsMention {
-			// Check against all known competitors (case-insensitive)
-			for compName, stats := range brandStats {
-				if compName != mainBrand && strings.EqualFold(mentioned, compName) {
-					stats.mentionCount++
-					break
-				}
+		for name, count := range competitorCounts {
+			if matchers[compName].Matches(name) {
+				mentionCounts[compName] += count
 			}
 		}
+		if mentionCounts[compName] > total.TotalResponses {
+			mentionCounts[compName] = total.TotalResponses
+		}
 	}
 
 	// Build performance objects for all brands
@@ -150,8 +116,7 @@ func (s *CompetitiveBenchmarkService) GetCompetitiveBenchmark(
 	totalMentions := 0
 
 	for _, brand := range allBrands {
-		stats := brandStats[brand]
-		totalMentions += stats.mentionCount
+		totalMentions += mentionCounts[brand]
 	}
 
 	// Get logo URLs for all brands
@@ -169,40 +134,32 @@ func (s *CompetitiveBenchmarkService) GetCompetitiveBenchmark(
 	}
 
 	for _, brand := range allBrands {
-		stats := brandStats[brand]
+		mentionCount := mentionCounts[brand]
 		logo := logoMap[brand]
 
 		perf := models.BrandPerformance{
 			Brand:           brand,
 			LogoURL:         logo.LogoURL,
 			FallbackLogoURL: logo.FallbackLogoURL,
-			ResponseCount:   stats.mentionCount,
-		}
-
-		// Calculate rates
-		if len(responses) > 0 {
-			perf.MentionRate = float64(stats.mentionCount) / float64(len(responses)) * 100
+			ResponseCount:   mentionCount,
+			MentionRate:     float64(mentionCount) / float64(total.TotalResponses) * 100,
 		}
 
 		// Market share (share of total mentions)
 		if totalMentions > 0 {
-			perf.MarketSharePct = float64(stats.mentionCount) / float64(totalMentions) * 100
+			perf.MarketSharePct = float64(mentionCount) / float64(totalMentions) * 100
 		}
 
 		// Main brand gets additional metrics from actual analysis
-		if brand == mainBrand && stats.mentionCount > 0 {
-			perf.Visibility = stats.totalVisibility / float64(stats.mentionCount)
+		if brand == mainBrand && mentionCount > 0 {
+			perf.Visibility = float64(total.TotalVisibility) / float64(mentionCount)
 
-			if stats.positionCount > 0 {
-				perf.AveragePosition = stats.totalPosition / float64(stats.positionCount)
+			if total.PositionCount > 0 {
+				perf.AveragePosition = float64(total.PositionSum) / float64(total.PositionCount)
 			}
 
-			if len(stats.sentimentScores) > 0 {
-				sum := 0.0
-				for _, s := range stats.sentimentScores {
-					sum += s
-				}
-				perf.SentimentScore = sum / float64(len(stats.sentimentScores))
+			if total.SentimentCount > 0 {
+				perf.SentimentScore = total.SentimentScoreSum / float64(total.SentimentCount)
 			}
 		}
 
@@ -234,8 +191,14 @@ func (s *CompetitiveBenchmarkService) GetCompetitiveBenchmark(
 	// Market leader
 	marketLeader := allPerformances[0].Brand
 
-	// Generate prompt-level breakdown
-	promptBreakdown := s.generatePromptBreakdown(responses, mainBrand, competitors)
+	// Generate prompt-level breakdown from the most recent responses
+	breakdownFilter := filter
+	breakdownFilter.Limit = promptBreakdownLimit
+	responses, err := s.db.ListResponses(ctx, breakdownFilter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch responses: %w", err)
+	}
+	promptBreakdown := s.generatePromptBreakdown(responses, mainBrand, competitors, matchers)
 
 	// Generate recommendations
 	recommendations := s.recommendationsEngine.GenerateCompetitiveRecommendations(
@@ -261,6 +224,7 @@ func (s *CompetitiveBenchmarkService) generatePromptBreakdown(
 	responses []*models.Response,
 	mainBrand string,
 	competitors []string,
+	matchers map[string]*shared.BrandMatcher,
 ) []models.PromptCompetitiveAnalysis {
 	var breakdown []models.PromptCompetitiveAnalysis
 
@@ -276,14 +240,9 @@ func (s *CompetitiveBenchmarkService) generatePromptBreakdown(
 
 		// Competitor mentions
 		var competitorMentions []models.PromptCompetitorMention
-		mentionedBrands := make(map[string]bool)
-
-		for _, mentioned := range resp.CompetitorsMention {
-			mentionedBrands[strings.ToLower(mentioned)] = true
-		}
 
 		for _, comp := range competitors {
-			isMentioned := mentionedBrands[strings.ToLower(comp)]
+			isMentioned := mentionsBrand(resp.CompetitorsMention, matchers[comp])
 			competitorMentions = append(competitorMentions, models.PromptCompetitorMention{
 				Brand:     comp,
 				Mentioned: isMentioned,
@@ -306,12 +265,6 @@ func (s *CompetitiveBenchmarkService) generatePromptBreakdown(
 			winner = resp.CompetitorsMention[0] // First mentioned competitor wins
 		}
 
-		// Get prompt type from database
-		promptType := ""
-		if prompt, err := s.db.GetPrompt(context.Background(), resp.PromptID); err == nil {
-			promptType = string(prompt.PromptType)
-		}
-
 		// Count total brands mentioned
 		totalBrands := 0
 		if resp.BrandMentioned {
@@ -322,7 +275,7 @@ func (s *CompetitiveBenchmarkService) generatePromptBreakdown(
 		breakdown = append(breakdown, models.PromptCompetitiveAnalysis{
 			PromptID:             resp.PromptID,
 			PromptText:           resp.PromptText,
-			PromptType:           promptType,
+			PromptType:           string(resp.PromptType),
 			MainBrandResult:      mainResult,
 			CompetitorsMentioned: competitorMentions,
 			Winner:               winner,
@@ -334,16 +287,6 @@ func (s *CompetitiveBenchmarkService) generatePromptBreakdown(
 	return breakdown
 }
 
-// brandMentionStats tracks mention statistics for a brand
-type brandMentionStats struct {
-	brand           string
-	mentionCount    int
-	totalVisibility float64
-	totalPosition   float64
-	positionCount   int
-	sentimentScores []float64
-}
-
 // analyzeBrandPerformance analyzes performance for a single brand
 func (s *CompetitiveBenchmarkService) analyzeBrandPerformance(
 	ctx context.Context,
@@ -352,107 +295,60 @@ func (s *CompetitiveBenchmarkService) analyzeBrandPerformance(
 	startTime, endTime *time.Time,
 	region string,
 ) (models.BrandPerformance, error) {
-	// Fetch responses
+	// Aggregate the brand's responses in the database
 	filter := shared.ResponseFilter{
+		Brand:     brand,
+		Region:    region,
+		PromptIDs: promptIDs,
+		LLMIDs:    llmIDs,
 		StartTime: startTime,
 		EndTime:   endTime,
-		Limit:     10000,
 	}
 
-	allResponses, err := s.db.ListResponses(ctx, filter)
+	totals, err := s.db.AggregateResponseMetrics(ctx, filter, shared.GroupByNone)
 	if err != nil {
 		return models.BrandPerformance{}, err
 	}
 
-	// Filter responses
-	var filteredResponses []*models.Response
-	for _, resp := range allResponses {
-		// Filter by brand
-		if resp.Brand != brand {
-			continue
-		}
-
-		// Filter by region if specified
-		if region != "" && resp.Region != region {
-			continue
-		}
-
-		// Filter by prompt IDs if specified
-		if len(promptIDs) > 0 && !contains(promptIDs, resp.PromptID) {
-			continue
-		}
-
-		// Filter by LLM IDs if specified
-		if len(llmIDs) > 0 && !contains(llmIDs, resp.LLMID) {
-			continue
-		}
-
-		filteredResponses = append(filteredResponses, resp)
-	}
-
-	if len(filteredResponses) == 0 {
+	if len(totals) == 0 || totals[0].TotalResponses == 0 {
 		return models.BrandPerformance{
 			Brand:         brand,
 			ResponseCount: 0,
 		}, nil
 	}
-
-	// Calculate metrics
-	totalVisibility := 0.0
-	mentionCount := 0
-	groundingCount := 0
-	totalPosition := 0.0
-	positionCount := 0
-	topPositionCount := 0
-	sentimentSum := 0.0
-	sentimentCount := 0
-
-	for _, resp := range filteredResponses {
-		totalVisibility += float64(resp.VisibilityScore)
-
-		if resp.BrandMentioned {
-			mentionCount++
-		}
-
-		if resp.InGroundingSources {
-			groundingCount++
-		}
-
-		if resp.BrandPosition > 0 {
-			totalPosition += float64(resp.BrandPosition)
-			positionCount++
-
-			if resp.BrandPosition <= 3 {
-				topPositionCount++
-			}
-		}
-
-		if resp.Sentiment != "" {
-			sentimentSum += calculateSentimentScore(resp.Sentiment)
-			sentimentCount++
-		}
-	}
+	metrics := totals[0]
+	totalResponses := float64(metrics.TotalResponses)
 
 	perf := models.BrandPerformance{
 		Brand:         brand,
-		Visibility:    totalVisibility / float64(len(filteredResponses)),
-		MentionRate:   float64(mentionCount) / float64(len(filteredResponses)) * 100,
-		GroundingRate: float64(groundingCount) / float64(len(filteredResponses)) * 100,
-		ResponseCount: len(filteredResponses),
+		Visibility:    float64(metrics.TotalVisibility) / totalResponses,
+		MentionRate:   float64(metrics.MentionCount) / totalResponses * 100,
+		GroundingRate: float64(metrics.GroundedCount) / totalResponses * 100,
+		ResponseCount: metrics.TotalResponses,
 	}
 
-	if positionCount > 0 {
-		perf.AveragePosition = totalPosition / float64(positionCount)
-		perf.TopPositionRate = float64(topPositionCount) / float64(positionCount) * 100
+	if metrics.PositionCount > 0 {
+		perf.AveragePosition = float64(metrics.PositionSum) / float64(metrics.PositionCount)
+		perf.TopPositionRate = float64(metrics.TopPositionCount) / float64(metrics.PositionCount) * 100
 	}
 
-	if sentimentCount > 0 {
-		perf.SentimentScore = sentimentSum / float64(sentimentCount)
+	if metrics.SentimentCount > 0 {
+		perf.SentimentScore = metrics.SentimentScoreSum / float64(metrics.SentimentCount)
 	}
 
 	return perf, nil
 }
 
+// mentionsBrand reports whether any of the mentioned names matches the brand's matcher
+func mentionsBrand(mentioned []string, matcher *shared.BrandMatcher) bool {
+	for _, name := range mentioned {
+		if matcher.Matches(name) {
+			return true
+		}
+	}
+	return false
+}
+
 func contains(slice []string, item string) bool {
 	for _, s := range slice {
 		if s == item {
@@ -0,0 +1,49 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/fissionx/gego/internal/models"
+)
+
+func TestCompetitiveBenchmark(t *testing.T) {
+	ctx := context.Background()
+	database := newTestDB(t)
+
+	responses := []*models.Response{
+		{Brand: "HubSpot", Region: "US", BrandMentioned: true, VisibilityScore: 8, BrandPosition: 2, Sentiment: "positive",
+			CompetitorsMention: []string{"Salesforce"}},
+		{Brand: "HubSpot", Region: "US", CompetitorsMention: []string{"Salesforce", "Zoho"}},
+		{Brand: "HubSpot", Region: "US", BrandMentioned: true, VisibilityScore: 6, BrandPosition: 1},
+		{Brand: "HubSpot", Region: "EU", CompetitorsMention: []string{"Pipedrive"}},
+	}
+	for i, response := range responses {
+		response.ID = fmt.Sprintf("r%d", i)
+		if err := database.CreateResponse(ctx, response); err != nil {
+			t.Fatalf("CreateResponse() error = %v", err)
+		}
+	}
+
+	service := NewCompetitiveBenchmarkService(database)
+	benchmark, err := service.GetCompetitiveBenchmark(ctx, "HubSpot", nil, nil, nil, nil, nil, "US")
+	if err != nil {
+		t.Fatalf("GetCompetitiveBenchmark() error = %v", err)
+	}
+
+	hubspot := benchmark.MainBrand
+	if hubspot.ResponseCount != 2 || hubspot.Visibility != 7 || hubspot.AveragePosition != 1.5 || hubspot.MarketSharePct != 40 {
+		t.Errorf("main brand = %+v", hubspot)
+	}
+	if len(benchmark.Competitors) != 2 || benchmark.Competitors[0].Brand != "Salesforce" || benchmark.Competitors[0].ResponseCount != 2 {
+		t.Fatalf("competitors = %+v, want Salesforce and Zoho from the US responses", benchmark.Competitors)
+	}
+	if len(benchmark.PromptBreakdown) != 3 {
+		t.Errorf("prompt breakdown has %d responses, want 3", len(benchmark.PromptBreakdown))
+	}
+
+	if _, err := service.GetCompetitiveBenchmark(ctx, "HubSpot", nil, nil, nil, nil, nil, "APAC"); err == nil {
+		t.Error("GetCompetitiveBenchmark() in a region without responses succeeded")
+	}
+}
@@ -15,23 +15,24 @@ import (
 
 // ExecutionService provides business logic for prompt execution
 type ExecutionService struct {
-	db          db.Database
-	llmRegistry *llm.Registry
+	db         db.Database
+	llmFactory *llm.Factory
 }
 
 // NewExecutionService creates a new execution service
-func NewExecutionService(database db.Database, registry *llm.Registry) *ExecutionService {
+func NewExecutionService(database db.Database, factory *llm.Factory) *ExecutionService {
 	return &ExecutionService{
-		db:          database,
-		llmRegistry: registry,
+		db:         database,
+		llmFactory: factory,
 	}
 }
 
 // ExecutionConfig represents configuration for prompt execution
 type ExecutionConfig struct {
-	Temperature float64       `json:"temperature"`
-	MaxRetries  int           `json:"max_retries"`
-	RetryDelay  time.Duration `json:"retry_delay"`
+	Temperature float64           `json:"temperature"`
+	MaxRetries  int               `json:"max_retries"`
+	RetryDelay  time.Duration     `json:"retry_delay"`
+	Variables   map[string]string `json:"variables,omitempty"` // Binding of the prompt template variables
 }
 
 // DefaultExecutionConfig returns default execution configuration
@@ -49,17 +50,23 @@ func (s *ExecutionService) ExecutePromptWithLLM(ctx context.Context, prompt *mod
 		config = DefaultExecutionConfig()
 	}
 
-	provider, ok := s.llmRegistry.Get(llmConfig.Provider)
-	if !ok {
-		return nil, fmt.Errorf("LLM provider %s not found", llmConfig.Provider)
+	promptText, variables, err := BindPrompt(prompt.Template, config.Variables, prompt.Brand)
+	if err != nil {
+		return nil, err
+	}
+
+	provider, err := s.llmFactory.Get(llmConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get LLM provider: %w", err)
 	}
 
 	var lastErr error
 	for attempt := 1; attempt <= config.MaxRetries; attempt++ {
-		response, err := provider.Generate(ctx, prompt.Template, llm.Config{
+		response, err := provider.Generate(ctx, promptText, llm.Config{
 			Model:       llmConfig.Model,
 			Temperature: config.Temperature,
 			MaxTokens:   1000,
+			WebSearch:   llm.WebSearchEnabled(llmConfig),
 		})
 
 		if err != nil {
@@ -84,7 +91,8 @@ func (s *ExecutionService) ExecutePromptWithLLM(ctx context.Context, prompt *mod
 			ID:           uuid.New().String(),
 			PromptID:     prompt.ID,
 			LLMID:        llmConfig.ID,
-			PromptText:   prompt.Template,
+			PromptText:   promptText,
+			Variables:    variables,
 			ResponseText: response.Text,
 			LLMName:      llmConfig.Name,
 			LLMProvider:  llmConfig.Provider,
@@ -94,6 +102,8 @@ func (s *ExecutionService) ExecutePromptWithLLM(ctx context.Context, prompt *mod
 			LatencyMs:    response.LatencyMs,
 			CreatedAt:    time.Now(),
 		}
+		ApplyPromptMetadata(responseModel, prompt)
+		ApplyCitations(responseModel, response)
 
 		if err := s.db.CreateResponse(ctx, responseModel); err != nil {
 			return nil, fmt.Errorf("failed to save response: %w", err)
@@ -125,29 +135,32 @@ func (s *ExecutionService) ExecuteSchedule(ctx context.Context, scheduleID strin
 	}
 
 	for _, prompt := range plan.Prompts {
-		for _, llmConfig := range plan.LLMs {
-			temperature := plan.Temperature
-			if config != nil {
-				temperature = config.Temperature
-			}
-
-			execConfig := &ExecutionConfig{
-				Temperature: temperature,
-				MaxRetries:  config.MaxRetries,
-				RetryDelay:  config.RetryDelay,
-			}
-
-			response, err := s.ExecutePromptWithLLM(ctx, prompt, llmConfig, execConfig)
-			if err != nil {
-				result.FailedExecutions++
-				result.Errors = append(result.Errors, ExecutionError{
-					PromptID: prompt.ID,
-					LLMID:    llmConfig.ID,
-					Error:    err.Error(),
-				})
-			} else {
-				result.SuccessfulExecutions++
-				result.Responses = append(result.Responses, response)
+		for _, variables := range PromptBindings(prompt.Template, plan.Variables) {
+			for _, llmConfig := range plan.LLMs {
+				temperature := plan.Temperature
+				if config != nil {
+					temperature = config.Temperature
+				}
+
+				execConfig := &ExecutionConfig{
+					Temperature: temperature,
+					MaxRetries:  config.MaxRetries,
+					RetryDelay:  config.RetryDelay,
+					Variables:   variables,
+				}
+
+				response, err := s.ExecutePromptWithLLM(ctx, prompt, llmConfig, execConfig)
+				if err != nil {
+					result.FailedExecutions++
+					result.Errors = append(result.Errors, ExecutionError{
+						PromptID: prompt.ID,
+						LLMID:    llmConfig.ID,
+						Error:    err.Error(),
+					})
+				} else {
+					result.SuccessfulExecutions++
+					result.Responses = append(result.Responses, response)
+				}
 			}
 		}
 	}
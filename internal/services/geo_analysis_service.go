@@ -0,0 +1,263 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+
+	"github.com/fissionx/gego/internal/db"
+	"github.com/fissionx/gego/internal/llm"
+	"github.com/fissionx/gego/internal/models"
+	"github.com/fissionx/gego/internal/shared"
+)
+
+var (
+	geoJudgeLLMID   string
+	geoJudgeLLMIDMu sync.RWMutex
+)
+
+// SetGEOJudgeLLMID sets the LLM config used to judge answers for GEO analysis.
+// When empty, each answer is judged by the LLM that produced it.
+func SetGEOJudgeLLMID(id string) {
+	geoJudgeLLMIDMu.Lock()
+	defer geoJudgeLLMIDMu.Unlock()
+	geoJudgeLLMID = strings.TrimSpace(id)
+}
+
+// getGEOJudgeLLMID returns the configured judge LLM ID
+func getGEOJudgeLLMID() string {
+	geoJudgeLLMIDMu.RLock()
+	defer geoJudgeLLMIDMu.RUnlock()
+	return geoJudgeLLMID
+}
+
+// GEOAnalysisService runs the brand visibility analysis stage on raw LLM answers,
+// independently of the provider that produced them
+type GEOAnalysisService struct {
+	db            db.Database
+	llmFactory    *llm.Factory
+	brandProfiles *BrandProfileService
+}
+
+// NewGEOAnalysisService creates a new GEO analysis service
+func NewGEOAnalysisService(database db.Database, factory *llm.Factory) *GEOAnalysisService {
+	return &GEOAnalysisService{
+		db:            database,
+		llmFactory:    factory,
+		brandProfiles: NewBrandProfileService(database),
+	}
+}
+
+// Analyze produces GEO metrics for the given answer and brand. Answers that come
+// with their own analysis (Google runs a separate analysis call) have it parsed
+// as-is; everything else is sent to the judge LLM. answeringLLM is used as the
+// judge when no judge LLM is configured.
+func (s *GEOAnalysisService) Analyze(ctx context.Context, prompt, brand string, response *llm.Response, answeringLLM *models.LLMConfig) (*shared.GEOAnalysisResult, error) {
+	if brand == "" {
+		return nil, fmt.Errorf("brand is required for GEO analysis")
+	}
+	if response == nil || strings.TrimSpace(response.Text) == "" {
+		return nil, fmt.Errorf("empty response, nothing to analyze")
+	}
+
+	matcher := s.brandProfiles.GetMatcher(ctx, brand)
+
+	if response.Analysis != "" {
+		if result := shared.ParseGEOAnalysis(response.Analysis); result != nil {
+			result.SearchAnswer = response.Text
+			s.applyGroundingCheck(result, brand, matcher, response.GroundingSources)
+			return result, nil
+		}
+	}
+
+	// Fixtures recorded when Google returned the answer inside its analysis
+	if result := shared.SplitCombinedAnswer(response.Text); result != nil {
+		s.applyGroundingCheck(result, brand, matcher, response.GroundingSources)
+		return result, nil
+	}
+
+	judgeConfig, provider, err := s.getJudge(ctx, answeringLLM)
+	if err != nil {
+		return nil, err
+	}
+
+	judgeResponse, err := provider.Generate(ctx, buildGEOJudgePrompt(prompt, brand, matcher.Terms(), response.Text, response.GroundingSources), llm.Config{
+		Model:       judgeConfig.Model,
+		Temperature: 0.1,
+		MaxTokens:   2048,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("judge LLM %s failed: %w", judgeConfig.Name, err)
+	}
+	if judgeResponse.Error != "" {
+		return nil, fmt.Errorf("judge LLM %s returned error: %s", judgeConfig.Name, judgeResponse.Error)
+	}
+
+	result := shared.ParseGEOAnalysis(judgeResponse.Text)
+	if result == nil {
+		return nil, fmt.Errorf("judge LLM %s returned unparseable analysis", judgeConfig.Name)
+	}
+
+	// The judge only returns the analysis; the answer itself is the original text
+	result.SearchAnswer = response.Text
+	s.applyGroundingCheck(result, brand, matcher, response.GroundingSources)
+
+	log.Printf("GEO analysis for brand %s judged by %s (%s)", brand, judgeConfig.Name, judgeConfig.Provider)
+	return result, nil
+}
+
+// getJudge resolves the LLM config and provider used to judge answers
+func (s *GEOAnalysisService) getJudge(ctx context.Context, answeringLLM *models.LLMConfig) (*models.LLMConfig, llm.Provider, error) {
+	judgeConfig := answeringLLM
+
+	if judgeID := getGEOJudgeLLMID(); judgeID != "" {
+		configured, err := s.db.GetLLM(ctx, judgeID)
+		if err != nil {
+			log.Printf("Judge LLM %s not available, falling back to answering LLM: %v", judgeID, err)
+		} else if !configured.Enabled {
+			log.Printf("Judge LLM %s is disabled, falling back to answering LLM", configured.Name)
+		} else {
+			judgeConfig = configured
+		}
+	}
+
+	if judgeConfig == nil {
+		return nil, nil, fmt.Errorf("no judge LLM available for GEO analysis")
+	}
+
+	provider, err := s.llmFactory.Get(judgeConfig)
+	if err != nil {
+		return nil, nil, fmt.Errorf("judge provider not available: %w", err)
+	}
+
+	return judgeConfig, provider, nil
+}
+
+// applyGroundingCheck marks the brand as grounded when the host of one of the
+// cited sources matches the brand or its entity dictionary, regardless of what
+// the judge concluded. Paths are ignored: review sites and directories name
+// the brands they cover in theirs.
+func (s *GEOAnalysisService) applyGroundingCheck(result *shared.GEOAnalysisResult, brand string, matcher *shared.BrandMatcher, groundingSources []string) {
+	brandKey := strings.ReplaceAll(strings.ToLower(brand), " ", "")
+	if brandKey == "" {
+		return
+	}
+
+	for _, source := range groundingSources {
+		host := shared.ExtractDomainFromURL(source)
+		if host == "" {
+			continue
+		}
+		if matcher.Matches(host) || strings.Contains(strings.ToLower(host), brandKey) {
+			result.GEOAnalysis.InGroundingSources = true
+			result.GEOAnalysis.BrandMentioned = true
+			return
+		}
+	}
+}
+
+// ApplyGEOAnalysis copies the analysis, GEO metrics, ranking and source domains
+// onto a response, whose text becomes the answer the analysis was made on.
+// matcher may be nil, in which case only the plain brand name is matched.
+func ApplyGEOAnalysis(response *models.Response, result *shared.GEOAnalysisResult, groundingSources []string, matcher *shared.BrandMatcher) {
+	if response == nil || result == nil {
+		return
+	}
+
+	geo := result.GEOAnalysis
+	response.VisibilityScore = geo.VisibilityScore
+	response.BrandMentioned = geo.BrandMentioned
+	response.InGroundingSources = geo.InGroundingSources
+	response.Sentiment = geo.Sentiment
+	response.CompetitorsMention = geo.Competitors
+	response.GroundingSources = groundingSources
+	response.Analysis = result.ToModel()
+	response.AnalysisRaw = result.Raw
+
+	if result.SearchAnswer != "" {
+		response.ResponseText = result.SearchAnswer
+	}
+	searchAnswer := response.ResponseText
+
+	if geo.BrandMentioned && response.Brand != "" {
+		if matcher == nil {
+			matcher = shared.NewBrandMatcher([]string{response.Brand}, nil)
+		}
+		position, totalBrands := ExtractBrandPosition(searchAnswer, matcher)
+		response.BrandPosition = position
+		response.TotalBrandsListed = totalBrands
+	}
+
+	if len(groundingSources) > 0 {
+		response.GroundingDomains = ExtractDomainsFromSources(groundingSources)
+		response.SourceCategories = classifySources(response.GroundingDomains)
+	}
+}
+
+// ApplyCitations copies the citations and grounding sources of an LLM answer onto
+// a response, so source analytics cover every search-enabled engine
+func ApplyCitations(response *models.Response, llmResponse *llm.Response) {
+	if response == nil || llmResponse == nil {
+		return
+	}
+
+	response.Citations = llmResponse.Citations
+	response.GroundingSources = llmResponse.GroundingSources
+	if len(llmResponse.GroundingSources) > 0 {
+		response.GroundingDomains = ExtractDomainsFromSources(llmResponse.GroundingSources)
+		response.SourceCategories = classifySources(response.GroundingDomains)
+	}
+}
+
+// buildGEOJudgePrompt builds the provider-neutral analysis prompt sent to the judge LLM
+func buildGEOJudgePrompt(query, brand string, brandTerms []string, answer string, groundingSources []string) string {
+	sourcesInfo := ""
+	if len(groundingSources) > 0 {
+		sourcesInfo = fmt.Sprintf("\n\nGROUNDING SOURCES (URLs cited by the AI):\n%s", strings.Join(groundingSources, "\n"))
+	}
+
+	aliasInfo := ""
+	if len(brandTerms) > 1 {
+		aliasInfo = fmt.Sprintf("\nALSO COUNTS AS THE BRAND: %s", strings.Join(brandTerms[1:], ", "))
+	}
+
+	return fmt.Sprintf(`Analyze the following AI assistant answer for brand visibility, sentiment, and competitors.
+
+BRAND TO ANALYZE: %s%s
+
+USER QUERY: %s
+
+ANSWER:
+%s%s
+
+---
+
+CRITICAL ANALYSIS INSTRUCTIONS:
+1. Check if "%s" is mentioned in the answer text
+2. Check if the brand's domain appears in the GROUNDING SOURCES (if any)
+3. Identify ALL competitor brands/products mentioned in the answer
+4. If brand is mentioned, analyze the sentiment (positive/neutral/negative)
+5. Scoring:
+   - Score 0: Not in text, not in sources
+   - Score 1-3: In sources but not in text (low visibility)
+   - Score 4-6: Mentioned in text with context
+   - Score 7-10: Prominently featured in text AND sources
+
+You MUST respond with ONLY a valid JSON object (no markdown, no code blocks):
+
+{"geo_analysis":{"visibility_score":0,"brand_mentioned":false,"in_grounding_sources":false,"mention_status":"Where/how brand appeared or why absent","reason":"Why brand is/isn't cited","sentiment":"positive|neutral|negative (only if brand mentioned)","competitors":["Competitor1","Competitor2"],"insights":["Insight 1","Insight 2","Insight 3"],"actions":["Action 1","Action 2","Action 3","Action 4","Action 5"],"competitor_info":"What competitors are doing to get cited"}}
+
+Rules:
+- visibility_score: integer 0-10
+- brand_mentioned: true if in text OR sources
+- in_grounding_sources: true if brand domain in cited URLs
+- sentiment: "positive" (recommended/praised), "neutral" (just mentioned), "negative" (criticized), or empty string if not mentioned
+- competitors: array of competitor names mentioned (empty array if none)
+- insights: 3-5 insights about visibility
+- actions: 5 specific actionable recommendations
+- competitor_info: what competitors do well
+
+RESPOND WITH ONLY THE JSON OBJECT, NO OTHER TEXT.`, brand, aliasInfo, query, answer, sourcesInfo, brand)
+}
@@ -0,0 +1,36 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/fissionx/gego/internal/shared"
+)
+
+func TestApplyGroundingCheck(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   bool
+	}{
+		{"brand site", "https://www.acme.com/pricing", true},
+		{"brand subdomain", "https://docs.acme.io/start", true},
+		{"domain title", "acme.com", true},
+		{"review page of the brand", "https://www.g2.com/products/acme/reviews", false},
+		{"directory page of the brand", "https://www.capterra.com/p/123/Acme-CRM/", false},
+		{"query naming the brand", "https://news.example.com/search?q=acme", false},
+		{"title naming the brand", "Acme reviews", false},
+	}
+
+	service := &GEOAnalysisService{}
+	matcher := shared.NewBrandMatcher([]string{"Acme"}, nil)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := &shared.GEOAnalysisResult{}
+			service.applyGroundingCheck(result, "Acme", matcher, []string{tt.source})
+			if result.GEOAnalysis.InGroundingSources != tt.want || result.GEOAnalysis.BrandMentioned != tt.want {
+				t.Errorf("applyGroundingCheck(%s) = grounded %v, mentioned %v, want %v",
+					tt.source, result.GEOAnalysis.InGroundingSources, result.GEOAnalysis.BrandMentioned, tt.want)
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ package services
 import (
 	"context"
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/fissionx/gego/internal/db"
@@ -24,111 +25,75 @@ func NewGEOAnalyticsService(database db.Database) *GEOAnalyticsService {
 	}
 }
 
-// GetGEOInsights computes comprehensive GEO insights for a brand
-func (s *GEOAnalyticsService) GetGEOInsights(ctx context.Context, brand string, startTime, endTime *time.Time) (*models.GEOInsightsResponse, error) {
+// GetGEOInsights computes comprehensive GEO insights for a brand, optionally
+// restricted to the responses of a single campaign
+func (s *GEOAnalyticsService) GetGEOInsights(ctx context.Context, brand, campaignID string, startTime, endTime *time.Time) (*models.GEOInsightsResponse, error) {
 	if brand == "" {
 		return nil, fmt.Errorf("brand is required")
 	}
 
-	// Fetch all responses for the brand
+	// Aggregate the brand's responses in the database
 	filter := shared.ResponseFilter{
-		StartTime: startTime,
-		EndTime:   endTime,
-		Limit:     10000, // Get all responses
+		Brand:      brand,
+		CampaignID: campaignID,
+		StartTime:  startTime,
+		EndTime:    endTime,
 	}
 
-	allResponses, err := s.db.ListResponses(ctx, filter)
+	totals, err := s.db.AggregateResponseMetrics(ctx, filter, shared.GroupByNone)
 	if err != nil {
 		return nil, err
 	}
 
-	// Filter responses for this brand
-	var brandResponses []*models.Response
-	for _, resp := range allResponses {
-		if resp.Brand == brand {
-			brandResponses = append(brandResponses, resp)
-		}
-	}
-
-	if len(brandResponses) == 0 {
+	if len(totals) == 0 || totals[0].TotalResponses == 0 {
 		return &models.GEOInsightsResponse{
 			Brand:          brand,
 			TotalResponses: 0,
 		}, nil
 	}
+	total := totals[0]
+
+	sentiments, err := s.db.AggregateResponseMetrics(ctx, filter, shared.GroupBySentiment)
+	if err != nil {
+		return nil, err
+	}
+	competitorCounts, err := s.db.CountCompetitorMentions(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	llmPerformance, err := s.db.AggregateResponseMetrics(ctx, filter, shared.GroupByLLM)
+	if err != nil {
+		return nil, err
+	}
+	categoryPerformance, err := s.db.AggregateResponseMetrics(ctx, filter, shared.GroupByCategory)
+	if err != nil {
+		return nil, err
+	}
+	weeks, err := s.db.AggregateResponseMetrics(ctx, filter, shared.GroupByWeek)
+	if err != nil {
+		return nil, err
+	}
 
 	// Get brand logo
 	brandLogo := s.logoService.GetBrandLogo(ctx, brand, "")
-	
+
 	// Calculate metrics
 	insights := &models.GEOInsightsResponse{
 		Brand:              brand,
 		LogoURL:            brandLogo.LogoURL,
 		FallbackLogoURL:    brandLogo.FallbackLogoURL,
-		TotalResponses:     len(brandResponses),
+		TotalResponses:     total.TotalResponses,
 		SentimentBreakdown: make(map[string]int),
 	}
 
-	// Aggregate data
-	totalVisibility := 0
-	mentionedCount := 0
-	groundedCount := 0
-	competitorCounts := make(map[string]int)
-	llmPerformance := make(map[string]*llmStats)
-	categoryPerformance := make(map[string]*categoryStats)
-
-	for _, resp := range brandResponses {
-		// Visibility
-		totalVisibility += resp.VisibilityScore
-		if resp.BrandMentioned {
-			mentionedCount++
-		}
-		if resp.InGroundingSources {
-			groundedCount++
-		}
-
-		// Sentiment
-		if resp.Sentiment != "" {
-			insights.SentimentBreakdown[resp.Sentiment]++
-		}
-
-		// Competitors
-		for _, comp := range resp.CompetitorsMention {
-			competitorCounts[comp]++
-		}
-
-		// LLM performance
-		llmKey := fmt.Sprintf("%s-%s", resp.LLMProvider, resp.LLMName)
-		if _, exists := llmPerformance[llmKey]; !exists {
-			llmPerformance[llmKey] = &llmStats{
-				name:     resp.LLMName,
-				provider: resp.LLMProvider,
-			}
-		}
-		llmPerformance[llmKey].totalVisibility += resp.VisibilityScore
-		llmPerformance[llmKey].totalResponses++
-		if resp.BrandMentioned {
-			llmPerformance[llmKey].mentionCount++
-		}
-
-		// Category performance
-		prompt, err := s.db.GetPrompt(ctx, resp.PromptID)
-		if err == nil && prompt.Category != "" {
-			if _, exists := categoryPerformance[prompt.Category]; !exists {
-				categoryPerformance[prompt.Category] = &categoryStats{}
-			}
-			categoryPerformance[prompt.Category].totalVisibility += resp.VisibilityScore
-			categoryPerformance[prompt.Category].totalResponses++
-			if resp.BrandMentioned {
-				categoryPerformance[prompt.Category].mentionCount++
-			}
-		}
+	for _, sentiment := range sentiments {
+		insights.SentimentBreakdown[sentiment.Key] = sentiment.TotalResponses
 	}
 
 	// Calculate averages
-	insights.AverageVisibility = float64(totalVisibility) / float64(len(brandResponses))
-	insights.MentionRate = float64(mentionedCount) / float64(len(brandResponses)) * 100
-	insights.GroundingRate = float64(groundedCount) / float64(len(brandResponses)) * 100
+	insights.AverageVisibility = float64(total.TotalVisibility) / float64(total.TotalResponses)
+	insights.MentionRate = float64(total.MentionCount) / float64(total.TotalResponses) * 100
+	insights.GroundingRate = float64(total.GroundedCount) / float64(total.TotalResponses) * 100
 
 	// Top competitors (with logos)
 	competitorLogos := make([]BrandLogoRequest, 0, len(competitorCounts))
@@ -157,38 +122,35 @@ func (s *GEOAnalyticsService) GetGEOInsights(ctx context.Context, brand string,
 	// LLM performance
 	for _, stats := range llmPerformance {
 		insights.PerformanceByLLM = append(insights.PerformanceByLLM, models.LLMPerformance{
-			LLMName:       stats.name,
-			LLMProvider:   stats.provider,
-			Visibility:    float64(stats.totalVisibility) / float64(stats.totalResponses),
-			MentionRate:   float64(stats.mentionCount) / float64(stats.totalResponses) * 100,
-			ResponseCount: stats.totalResponses,
+			LLMName:       stats.LLMName,
+			LLMProvider:   stats.LLMProvider,
+			Visibility:    float64(stats.TotalVisibility) / float64(stats.TotalResponses),
+			MentionRate:   float64(stats.MentionCount) / float64(stats.TotalResponses) * 100,
+			ResponseCount: stats.TotalResponses,
 		})
 	}
 
 	// Category performance
-	for category, stats := range categoryPerformance {
+	for _, stats := range categoryPerformance {
 		insights.PerformanceByCategory = append(insights.PerformanceByCategory, models.CategoryPerformance{
-			Category:      category,
-			Visibility:    float64(stats.totalVisibility) / float64(stats.totalResponses),
-			MentionRate:   float64(stats.mentionCount) / float64(stats.totalResponses) * 100,
-			ResponseCount: stats.totalResponses,
+			Category:      stats.Key,
+			Visibility:    float64(stats.TotalVisibility) / float64(stats.TotalResponses),
+			MentionRate:   float64(stats.MentionCount) / float64(stats.TotalResponses) * 100,
+			ResponseCount: stats.TotalResponses,
 		})
 	}
 
-	return insights, nil
-}
-
-type llmStats struct {
-	name            string
-	provider        string
-	totalVisibility int
-	totalResponses  int
-	mentionCount    int
-}
+	// Weekly trend, oldest first
+	sort.Slice(weeks, func(i, j int) bool {
+		return weeks[i].Key < weeks[j].Key
+	})
+	for _, stats := range weeks {
+		insights.Trends = append(insights.Trends, models.TrendPoint{
+			Date:       stats.Key,
+			Visibility: float64(stats.TotalVisibility) / float64(stats.TotalResponses),
+			Mentions:   stats.MentionCount,
+		})
+	}
 
-type categoryStats struct {
-	totalVisibility int
-	totalResponses  int
-	mentionCount    int
+	return insights, nil
 }
-
@@ -0,0 +1,318 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/fissionx/gego/internal/db"
+	"github.com/fissionx/gego/internal/logger"
+	"github.com/fissionx/gego/internal/models"
+)
+
+// Job queue configuration
+const (
+	DefaultJobWorkers = 3
+	DefaultJobLease   = 10 * time.Minute
+	jobPollInterval   = 2 * time.Second
+)
+
+// jobDrainTimeout is how long Stop waits for running jobs to finish before
+// interrupting them
+var jobDrainTimeout = 30 * time.Second
+
+// ErrJobSkipped is returned by a JobHandler for jobs that should not run anymore
+// (e.g. their campaign was cancelled). Skipped jobs are cancelled, not retried.
+var ErrJobSkipped = errors.New("job skipped")
+
+// JobHandler executes queued jobs of one kind
+type JobHandler interface {
+	// ExecuteJob runs the job. Errors are retried until the job runs out of attempts.
+	ExecuteJob(ctx context.Context, job *models.Job) error
+	// JobFinished is called once per job, when it succeeded (err is nil) or was dead-lettered
+	JobFinished(ctx context.Context, job *models.Job, err error)
+}
+
+// JobQueueService runs prompt × LLM executions from a durable queue. Jobs are
+// leased by workers, retried with backoff and dead-lettered after their last
+// attempt. Work left behind by a stopped process is picked up once its lease expires.
+type JobQueueService struct {
+	db       db.Database
+	owner    string
+	handlers map[string]JobHandler
+	mu       sync.RWMutex
+
+	stopLeasing context.CancelFunc
+	interrupt   context.CancelFunc
+	wg          sync.WaitGroup
+	running     bool
+	runMu       sync.Mutex
+}
+
+// NewJobQueueService creates a new job queue service
+func NewJobQueueService(database db.Database) *JobQueueService {
+	hostname, _ := os.Hostname()
+	return &JobQueueService{
+		db:       database,
+		owner:    fmt.Sprintf("%s-%d-%s", hostname, os.Getpid(), uuid.New().String()[:8]),
+		handlers: make(map[string]JobHandler),
+	}
+}
+
+// RegisterHandler registers the handler for a job kind. Workers only lease
+// jobs of registered kinds.
+func (q *JobQueueService) RegisterHandler(kind string, handler JobHandler) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.handlers[kind] = handler
+}
+
+// Enqueue adds jobs to the queue
+func (q *JobQueueService) Enqueue(ctx context.Context, jobs []*models.Job) error {
+	now := time.Now()
+	for _, job := range jobs {
+		if job.ID == "" {
+			job.ID = uuid.New().String()
+		}
+		if job.MaxAttempts <= 0 {
+			job.MaxAttempts = DefaultMaxRetries
+		}
+		job.Status = models.JobStatusPending
+		job.Attempts = 0
+		job.AvailableAt = now
+		job.CreatedAt = now
+		job.UpdatedAt = now
+	}
+
+	return q.db.EnqueueJobs(ctx, jobs)
+}
+
+// ListJobs lists queued jobs, optionally filtered by status
+func (q *JobQueueService) ListJobs(ctx context.Context, status string, limit int) ([]*models.Job, error) {
+	return q.db.ListJobs(ctx, status, limit)
+}
+
+// Start starts the queue workers. Returns false when they were already running.
+func (q *JobQueueService) Start(workers int) bool {
+	q.runMu.Lock()
+	defer q.runMu.Unlock()
+
+	if q.running {
+		return false
+	}
+	if workers <= 0 {
+		workers = DefaultJobWorkers
+	}
+
+	stopping, stopLeasing := context.WithCancel(context.Background())
+	ctx, interrupt := context.WithCancel(context.Background())
+	q.stopLeasing = stopLeasing
+	q.interrupt = interrupt
+	q.running = true
+
+	for i := 0; i < workers; i++ {
+		q.wg.Add(1)
+		go q.worker(stopping, ctx)
+	}
+
+	logger.Info("Job queue started with %d worker(s) for kinds: %s", workers, strings.Join(q.kinds(), ", "))
+	return true
+}
+
+// Stop stops leasing jobs, waits for the running ones to finish and for the
+// workers to exit. Jobs still running after jobDrainTimeout are interrupted
+// and released back to the queue.
+func (q *JobQueueService) Stop() {
+	q.runMu.Lock()
+	defer q.runMu.Unlock()
+
+	if !q.running {
+		return
+	}
+
+	q.stopLeasing()
+	drained := make(chan struct{})
+	go func() {
+		q.wg.Wait()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+	case <-time.After(jobDrainTimeout):
+		logger.Warning("Jobs still running after %v, interrupting them", jobDrainTimeout)
+		q.interrupt()
+		<-drained
+	}
+	q.interrupt()
+	q.running = false
+
+	logger.Info("Job queue stopped")
+}
+
+// Wait waits until the given jobs are done, dead or cancelled. It returns an
+// error when some of them were dead-lettered.
+func (q *JobQueueService) Wait(ctx context.Context, jobs []*models.Job) error {
+	pending := make([]string, 0, len(jobs))
+	for _, job := range jobs {
+		pending = append(pending, job.ID)
+	}
+
+	dead := 0
+	for len(pending) > 0 {
+		remaining := pending[:0]
+		for _, id := range pending {
+			job, err := q.db.GetJob(ctx, id)
+			if err != nil {
+				return err
+			}
+			switch job.Status {
+			case models.JobStatusDone, models.JobStatusCancelled:
+			case models.JobStatusDead:
+				dead++
+			default:
+				remaining = append(remaining, id)
+			}
+		}
+		pending = remaining
+		if len(pending) == 0 {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(jobPollInterval):
+		}
+	}
+
+	if dead > 0 {
+		return fmt.Errorf("%d of %d executions failed", dead, len(jobs))
+	}
+	return nil
+}
+
+// worker leases and executes jobs with ctx until stopping is cancelled
+func (q *JobQueueService) worker(stopping, ctx context.Context) {
+	defer q.wg.Done()
+
+	for stopping.Err() == nil && ctx.Err() == nil {
+		if q.processNext(ctx) {
+			continue
+		}
+
+		select {
+		case <-stopping.Done():
+		case <-ctx.Done():
+		case <-time.After(jobPollInterval):
+		}
+	}
+}
+
+// processNext leases and executes a single job. Returns false when no job was available.
+func (q *JobQueueService) processNext(ctx context.Context) bool {
+	kinds := q.kinds()
+	if len(kinds) == 0 {
+		return false
+	}
+
+	job, err := q.db.LeaseJob(ctx, q.owner, kinds, DefaultJobLease)
+	if err != nil {
+		if ctx.Err() == nil {
+			logger.Error("Failed to lease job: %v", err)
+		}
+		return false
+	}
+	if job == nil {
+		return false
+	}
+
+	handler := q.handler(job.Kind)
+
+	// Queue bookkeeping must survive a shutdown in the middle of a job
+	dbCtx := context.WithoutCancel(ctx)
+
+	// The previous worker died on the last attempt
+	if job.Attempts > job.MaxAttempts {
+		q.deadLetter(dbCtx, job, handler, fmt.Errorf("lease expired after %d attempts", job.MaxAttempts))
+		return true
+	}
+
+	logger.Debug("Running %s job %s (attempt %d/%d)", job.Kind, job.ID, job.Attempts, job.MaxAttempts)
+	err = handler.ExecuteJob(ctx, job)
+
+	switch {
+	case err == nil:
+		if err := q.db.FinishJob(dbCtx, job.ID, q.owner, models.JobStatusDone, ""); err != nil {
+			logger.Error("Failed to complete job %s: %v", job.ID, err)
+		}
+		handler.JobFinished(dbCtx, job, nil)
+
+	case errors.Is(err, ErrJobSkipped):
+		if err := q.db.FinishJob(dbCtx, job.ID, q.owner, models.JobStatusCancelled, ""); err != nil {
+			logger.Error("Failed to cancel job %s: %v", job.ID, err)
+		}
+
+	case ctx.Err() != nil:
+		// Shutting down: hand the job back so the next worker picks it up right away
+		if err := q.db.RetryJob(dbCtx, job.ID, q.owner, "interrupted by shutdown", time.Now()); err != nil {
+			logger.Error("Failed to release job %s: %v", job.ID, err)
+		}
+
+	case job.Attempts >= job.MaxAttempts:
+		q.deadLetter(dbCtx, job, handler, err)
+
+	default:
+		delay := jobRetryDelay(job.Attempts, err)
+		logger.Warning("%s job %s failed (attempt %d/%d), retrying in %v: %v", job.Kind, job.ID, job.Attempts, job.MaxAttempts, delay, err)
+		if err := q.db.RetryJob(dbCtx, job.ID, q.owner, err.Error(), time.Now().Add(delay)); err != nil {
+			logger.Error("Failed to reschedule job %s: %v", job.ID, err)
+		}
+	}
+
+	return true
+}
+
+// deadLetter marks a job as dead and notifies its handler
+func (q *JobQueueService) deadLetter(ctx context.Context, job *models.Job, handler JobHandler, cause error) {
+	logger.Error("💥 %s job %s dead-lettered after %d attempts: %v", job.Kind, job.ID, job.MaxAttempts, cause)
+
+	if err := q.db.FinishJob(ctx, job.ID, q.owner, models.JobStatusDead, cause.Error()); err != nil {
+		logger.Error("Failed to dead-letter job %s: %v", job.ID, err)
+	}
+	handler.JobFinished(ctx, job, cause)
+}
+
+// kinds returns the job kinds this queue has handlers for
+func (q *JobQueueService) kinds() []string {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	kinds := make([]string, 0, len(q.handlers))
+	for kind := range q.handlers {
+		kinds = append(kinds, kind)
+	}
+	return kinds
+}
+
+func (q *JobQueueService) handler(kind string) JobHandler {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	return q.handlers[kind]
+}
+
+// jobRetryDelay returns the backoff before the next attempt, with a longer
+// wait for rate limit errors
+func jobRetryDelay(attempts int, err error) time.Duration {
+	msg := err.Error()
+	if strings.Contains(msg, "429") || strings.Contains(msg, "quota") || strings.Contains(msg, "rate limit") {
+		return 2 * time.Minute
+	}
+	return DefaultRetryDelay * time.Duration(attempts)
+}
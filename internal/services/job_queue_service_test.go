@@ -0,0 +1,85 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fissionx/gego/internal/models"
+)
+
+// blockingHandler runs jobs until released, recording how they ended
+type blockingHandler struct {
+	started  chan struct{}
+	release  chan struct{}
+	finished chan error
+}
+
+func (h *blockingHandler) ExecuteJob(ctx context.Context, job *models.Job) error {
+	close(h.started)
+	select {
+	case <-h.release:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (h *blockingHandler) JobFinished(ctx context.Context, job *models.Job, err error) {
+	h.finished <- err
+}
+
+func TestJobQueueStopDrainsRunningJobs(t *testing.T) {
+	ctx := context.Background()
+	database := newTestDB(t)
+	queue := NewJobQueueService(database)
+	handler := &blockingHandler{started: make(chan struct{}), release: make(chan struct{}), finished: make(chan error, 1)}
+	queue.RegisterHandler(models.JobKindSchedule, handler)
+
+	job := &models.Job{Kind: models.JobKindSchedule, PromptID: "prompt-1", LLMID: "llm-1"}
+	if err := queue.Enqueue(ctx, []*models.Job{job}); err != nil {
+		t.Fatalf("Enqueue() error = %v", err)
+	}
+	queue.Start(1)
+	<-handler.started
+
+	stopped := make(chan struct{})
+	go func() {
+		queue.Stop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+		t.Fatal("Stop() returned while a job was running")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(handler.release)
+	<-stopped
+	if err := <-handler.finished; err != nil {
+		t.Errorf("job finished with %v, want it to complete", err)
+	}
+	if stored, err := database.GetJob(ctx, job.ID); err != nil || stored.Status != models.JobStatusDone {
+		t.Errorf("GetJob() = %+v, %v; want a done job", stored, err)
+	}
+
+	// A job running past the drain timeout is interrupted and released
+	previous := jobDrainTimeout
+	jobDrainTimeout = 0
+	t.Cleanup(func() { jobDrainTimeout = previous })
+
+	handler.started = make(chan struct{})
+	job = &models.Job{Kind: models.JobKindSchedule, PromptID: "prompt-1", LLMID: "llm-1"}
+	if err := queue.Enqueue(ctx, []*models.Job{job}); err != nil {
+		t.Fatalf("Enqueue() error = %v", err)
+	}
+	handler.release = make(chan struct{})
+	queue.Start(1)
+	<-handler.started
+	queue.Stop()
+
+	if stored, err := database.GetJob(ctx, job.ID); err != nil || stored.Status != models.JobStatusPending || stored.LeaseOwner != "" {
+		t.Errorf("GetJob() = %+v, %v; want a released pending job", stored, err)
+	}
+}
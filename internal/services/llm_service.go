@@ -3,9 +3,12 @@ package services
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/fissionx/gego/internal/db"
+	"github.com/fissionx/gego/internal/llm/openaicompat"
 	"github.com/fissionx/gego/internal/models"
+	"github.com/fissionx/gego/internal/secrets"
 )
 
 // LLMService provides business logic for LLM management
@@ -27,6 +30,7 @@ const (
 	Ollama
 	Google
 	Perplexity
+	OpenAICompatible
 )
 
 // String returns the string representation of the provider
@@ -42,6 +46,8 @@ func (p Provider) String() string {
 		return "google"
 	case Perplexity:
 		return "perplexity"
+	case OpenAICompatible:
+		return "openai-compatible"
 	default:
 		return "unknown"
 	}
@@ -60,6 +66,8 @@ func FromString(s string) Provider {
 		return Google
 	case "perplexity":
 		return Perplexity
+	case "openai-compatible":
+		return OpenAICompatible
 	default:
 		return 0 // Unknown provider
 	}
@@ -78,6 +86,8 @@ func (p Provider) DisplayName() string {
 		return "Google (Gemini)"
 	case Perplexity:
 		return "Perplexity (Sonar)"
+	case OpenAICompatible:
+		return "OpenAI-compatible (vLLM, LiteLLM, Azure, Mistral, Groq, DeepSeek...)"
 	default:
 		return "Unknown"
 	}
@@ -85,7 +95,7 @@ func (p Provider) DisplayName() string {
 
 // AllProviders returns a slice of all available providers
 func AllProviders() []Provider {
-	return []Provider{OpenAI, Anthropic, Ollama, Google, Perplexity}
+	return []Provider{OpenAI, Anthropic, Ollama, Google, Perplexity, OpenAICompatible}
 }
 
 // GetConsoleURL returns the console URL where API keys can be generated for the provider
@@ -111,12 +121,48 @@ func MaskAPIKey(apiKey string) string {
 	if apiKey == "" {
 		return "(not set)"
 	}
+	if secrets.IsReference(apiKey) {
+		return apiKey
+	}
 	if len(apiKey) <= 8 {
 		return "***"
 	}
 	return apiKey[:4] + "..." + apiKey[len(apiKey)-4:]
 }
 
+// MaskLLMConfig returns the config options of an LLM for display, with the
+// values of secret options masked like API keys: the request headers of
+// OpenAI-compatible endpoints, which often carry gateway keys, and the API
+// key header
+func MaskLLMConfig(config map[string]string) map[string]string {
+	if config == nil {
+		return nil
+	}
+	masked := make(map[string]string, len(config))
+	for key, value := range config {
+		if value != "" && isSecretLLMOption(key) {
+			value = MaskAPIKey(value)
+		}
+		masked[key] = value
+	}
+	return masked
+}
+
+// UnmaskLLMConfig puts back the stored values of secret options that a client
+// sent back masked, as read from MaskLLMConfig
+func UnmaskLLMConfig(config, stored map[string]string) {
+	for key, value := range config {
+		if previous := stored[key]; previous != "" && isSecretLLMOption(key) && value == MaskAPIKey(previous) {
+			config[key] = previous
+		}
+	}
+}
+
+// isSecretLLMOption reports whether an LLM config option may hold a secret
+func isSecretLLMOption(key string) bool {
+	return strings.HasPrefix(key, openaicompat.HeaderOptionPrefix) || key == openaicompat.AuthHeaderOption
+}
+
 // GetExistingAPIKeysForProvider returns existing API keys for a given provider
 func (s *LLMService) GetExistingAPIKeysForProvider(ctx context.Context, provider string) ([]string, error) {
 	llms, err := s.db.ListLLMs(ctx, nil)
@@ -156,9 +202,13 @@ func (s *LLMService) ValidateLLMConfig(config *models.LLMConfig) error {
 		return fmt.Errorf("unknown provider: %s", config.Provider)
 	}
 
-	if provider != Ollama && config.APIKey == "" {
+	// Self-hosted OpenAI-compatible servers often run without API keys
+	if provider != Ollama && provider != OpenAICompatible && config.APIKey == "" {
 		return fmt.Errorf("API key is required for %s", provider.DisplayName())
 	}
+	if provider == OpenAICompatible && config.BaseURL == "" {
+		return fmt.Errorf("base URL is required for %s", provider.DisplayName())
+	}
 
 	return nil
 }
@@ -0,0 +1,495 @@
+package services
+
+import (
+	_ "embed"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/fissionx/gego/internal/models"
+)
+
+// BuiltinPlaybook is the name of the playbook shipped with gego
+const BuiltinPlaybook = "builtin"
+
+//go:embed default_playbook.yaml
+var builtinPlaybookYAML []byte
+
+// playbookFields are the metrics of each scope, with a value of their type
+var playbookFields = map[string]map[string]interface{}{
+	models.PlaybookScopeSource: {
+		"brand":          "",
+		"domain":         "",
+		"rank":           0,
+		"citationCount":  0,
+		"mentionRate":    0.0,
+		"categories":     []string{},
+		"totalCitations": 0,
+		"sourceCount":    0,
+		"brandInSources": false,
+	},
+	models.PlaybookScopeSources: {
+		"brand":            "",
+		"totalCitations":   0,
+		"sourceCount":      0,
+		"brandInSources":   false,
+		"topDomain":        "",
+		"topDomains":       "",
+		"topCitationCount": 0,
+		"topMentionRate":   0.0,
+		"topCitationShare": 0.0,
+	},
+	models.PlaybookScopeCompetitive: {
+		"brand":                   "",
+		"visibility":              0.0,
+		"mentionRate":             0.0,
+		"groundingRate":           0.0,
+		"averagePosition":         0.0,
+		"topPositionRate":         0.0,
+		"sentimentScore":          0.0,
+		"isLeader":                false,
+		"marketLeader":            "",
+		"topCompetitor":           "",
+		"topCompetitorVisibility": 0.0,
+		"visibilityGap":           0.0,
+		"visibilityGapPercent":    0.0,
+		"competitorCount":         0,
+		"competitorSentiment":     0.0,
+		"sentimentGap":            0.0,
+	},
+	models.PlaybookScopePosition: {
+		"brand":           "",
+		"averagePosition": 0.0,
+		"topPositionRate": 0.0,
+		"totalMentions":   0,
+	},
+}
+
+// playbookPlaceholder matches {{field}} and {{field|%.2f}} in rule texts.
+// Without a format, decimals are shown with one digit.
+var playbookPlaceholder = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_]*)\s*(?:\|\s*(%[^}\s]+)\s*)?\}\}`)
+
+var builtinPlaybook = mustParseBuiltinPlaybook()
+
+// playbooks is the playbook file
+var playbooks = newReloadableConfig(loadPlaybookFile, func() *models.PlaybookFile {
+	return &models.PlaybookFile{}
+})
+
+func mustParseBuiltinPlaybook() models.Playbook {
+	var playbook models.Playbook
+	if err := yaml.Unmarshal(builtinPlaybookYAML, &playbook); err != nil {
+		panic(fmt.Sprintf("invalid built-in playbook: %v", err))
+	}
+	playbook.Name = BuiltinPlaybook
+	if err := validatePlaybookRules(playbook.Rules); err != nil {
+		panic(fmt.Sprintf("invalid built-in playbook: %v", err))
+	}
+	return playbook
+}
+
+// SetPlaybookPath sets the path of the recommendation playbook file from
+// config. Without a path, the built-in playbook is used alone.
+func SetPlaybookPath(path string) {
+	playbooks.setPaths(path)
+}
+
+// loadPlaybookFile reads and validates the playbook file. A missing file
+// holds no playbooks.
+func loadPlaybookFile(paths []string) (*models.PlaybookFile, error) {
+	file := &models.PlaybookFile{}
+	if len(paths) == 0 {
+		return file, nil
+	}
+	if err := readYAMLFile(paths[0], file); err != nil {
+		return nil, err
+	}
+	if err := validatePlaybookFile(file); err != nil {
+		return nil, fmt.Errorf("invalid playbook file %s: %w", paths[0], err)
+	}
+	return file, nil
+}
+
+// currentPlaybookFile returns the playbook file
+func currentPlaybookFile() *models.PlaybookFile {
+	return playbooks.get()
+}
+
+// validatePlaybookFile checks every playbook on top of the ones it patches
+func validatePlaybookFile(file *models.PlaybookFile) error {
+	names := make(map[string]bool)
+	for _, playbook := range file.Playbooks {
+		if playbook.Name == "" || playbook.Name == BuiltinPlaybook {
+			return fmt.Errorf("playbook names must be set and differ from %q", BuiltinPlaybook)
+		}
+		if names[playbook.Name] {
+			return fmt.Errorf("duplicate playbook %q", playbook.Name)
+		}
+		names[playbook.Name] = true
+
+		_, rules := resolvePlaybook(file, playbook.Brands, nil)
+		if err := validatePlaybookRules(rules); err != nil {
+			return fmt.Errorf("playbook %q: %w", playbook.Name, err)
+		}
+	}
+	return nil
+}
+
+// resolvePlaybook returns the names and rules of the playbooks applying to a
+// brand: the built-in one, those of every brand, then those listing the brand
+func resolvePlaybook(file *models.PlaybookFile, brands []string, extra []models.PlaybookRule) ([]string, []models.PlaybookRule) {
+	names := []string{BuiltinPlaybook}
+	rules := builtinPlaybook.Rules
+	for _, playbook := range file.Playbooks {
+		if len(playbook.Brands) == 0 {
+			names = append(names, playbook.Name)
+			rules = mergePlaybookRules(rules, playbook.Rules)
+		}
+	}
+	for _, playbook := range file.Playbooks {
+		if len(playbook.Brands) > 0 && appliesToBrands(playbook, brands) {
+			names = append(names, playbook.Name)
+			rules = mergePlaybookRules(rules, playbook.Rules)
+		}
+	}
+	if len(extra) > 0 {
+		rules = mergePlaybookRules(rules, extra)
+	}
+	return names, rules
+}
+
+func appliesToBrands(playbook models.Playbook, brands []string) bool {
+	for _, brand := range brands {
+		for _, target := range playbook.Brands {
+			if strings.EqualFold(brand, target) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// mergePlaybookRules patches rules by id: a patch replaces the fields it sets,
+// removes the rule when disabled, and is appended when its id is new
+func mergePlaybookRules(rules, patches []models.PlaybookRule) []models.PlaybookRule {
+	merged := append([]models.PlaybookRule(nil), rules...)
+	for _, patch := range patches {
+		i := -1
+		for j := range merged {
+			if merged[j].ID == patch.ID {
+				i = j
+				break
+			}
+		}
+
+		switch {
+		case i < 0 && !patch.Disabled:
+			merged = append(merged, patch)
+		case i >= 0 && patch.Disabled:
+			merged = append(merged[:i], merged[i+1:]...)
+		case i >= 0:
+			rule := &merged[i]
+			if patch.Scope != "" {
+				rule.Scope = patch.Scope
+			}
+			if patch.When != nil {
+				rule.When = patch.When
+			}
+			for _, field := range []struct{ dst, src *string }{
+				{&rule.Type, &patch.Type},
+				{&rule.Priority, &patch.Priority},
+				{&rule.Impact, &patch.Impact},
+				{&rule.Title, &patch.Title},
+				{&rule.Description, &patch.Description},
+				{&rule.Action, &patch.Action},
+			} {
+				if *field.src != "" {
+					*field.dst = *field.src
+				}
+			}
+		}
+	}
+	return merged
+}
+
+// validatePlaybookRules checks the scope, conditions and texts of rules
+func validatePlaybookRules(rules []models.PlaybookRule) error {
+	for _, rule := range rules {
+		if rule.ID == "" {
+			return fmt.Errorf("a rule has no id")
+		}
+		fields, ok := playbookFields[rule.Scope]
+		if !ok {
+			return fmt.Errorf("rule %s: invalid scope %q: use source, sources, competitive or position", rule.ID, rule.Scope)
+		}
+		if rule.Title == "" {
+			return fmt.Errorf("rule %s: title is required", rule.ID)
+		}
+
+		for _, cond := range rule.When {
+			sample, ok := fields[cond.Field]
+			if !ok {
+				return fmt.Errorf("rule %s: unknown %s metric %q", rule.ID, rule.Scope, cond.Field)
+			}
+			if _, err := evaluateCondition(cond, sample); err != nil {
+				return fmt.Errorf("rule %s: condition on %s: %w", rule.ID, cond.Field, err)
+			}
+		}
+
+		for _, text := range playbookTexts(&rule) {
+			if _, err := renderPlaybookText(*text, fields); err != nil {
+				return fmt.Errorf("rule %s: %w", rule.ID, err)
+			}
+		}
+	}
+	return nil
+}
+
+func playbookTexts(rule *models.PlaybookRule) []*string {
+	return []*string{&rule.Type, &rule.Priority, &rule.Impact, &rule.Title, &rule.Description, &rule.Action}
+}
+
+// renderPlaybookText fills the placeholders of a rule text with metrics
+func renderPlaybookText(text string, facts map[string]interface{}) (string, error) {
+	var err error
+	rendered := playbookPlaceholder.ReplaceAllStringFunc(text, func(placeholder string) string {
+		match := playbookPlaceholder.FindStringSubmatch(placeholder)
+		value, ok := facts[match[1]]
+		if !ok {
+			err = fmt.Errorf("unknown metric %q in %q", match[1], text)
+			return placeholder
+		}
+
+		format := match[2]
+		switch v := value.(type) {
+		case []string:
+			value = strings.Join(v, ", ")
+		case float64:
+			if format == "" {
+				format = "%.1f"
+			}
+		}
+		if format == "" {
+			format = "%v"
+		}
+
+		formatted := fmt.Sprintf(format, value)
+		if strings.Contains(formatted, "%!") {
+			err = fmt.Errorf("invalid format %q for %s in %q", format, match[1], text)
+		}
+		return formatted
+	})
+	return rendered, err
+}
+
+// evaluateCondition compares a metric with the value of a condition
+func evaluateCondition(cond models.PlaybookCondition, actual interface{}) (bool, error) {
+	switch cond.Op {
+	case "eq", "ne":
+		equal, err := playbookEqual(actual, cond.Value)
+		if err != nil {
+			return false, err
+		}
+		return equal == (cond.Op == "eq"), nil
+
+	case "gt", "gte", "lt", "lte":
+		a, okA := toFloat(actual)
+		b, okB := toFloat(cond.Value)
+		if !okA || !okB {
+			return false, fmt.Errorf("%s compares numbers", cond.Op)
+		}
+		switch cond.Op {
+		case "gt":
+			return a > b, nil
+		case "gte":
+			return a >= b, nil
+		case "lt":
+			return a < b, nil
+		default:
+			return a <= b, nil
+		}
+
+	case "contains":
+		want, ok := cond.Value.(string)
+		if !ok {
+			return false, fmt.Errorf("contains takes a string")
+		}
+		switch v := actual.(type) {
+		case string:
+			return strings.Contains(strings.ToLower(v), strings.ToLower(want)), nil
+		case []string:
+			return containsFold(v, want), nil
+		}
+		return false, fmt.Errorf("contains applies to text and lists")
+
+	case "in":
+		values, ok := toStrings(cond.Value)
+		if !ok {
+			return false, fmt.Errorf("in takes a list of strings")
+		}
+		switch v := actual.(type) {
+		case string:
+			return containsFold(values, v), nil
+		case []string:
+			for _, item := range v {
+				if containsFold(values, item) {
+					return true, nil
+				}
+			}
+			return false, nil
+		}
+		return false, fmt.Errorf("in applies to text and lists")
+
+	default:
+		return false, fmt.Errorf("invalid op %q: use eq, ne, gt, gte, lt, lte, contains or in", cond.Op)
+	}
+}
+
+func playbookEqual(actual, want interface{}) (bool, error) {
+	switch v := actual.(type) {
+	case bool:
+		b, ok := want.(bool)
+		if !ok {
+			return false, fmt.Errorf("compare with true or false")
+		}
+		return v == b, nil
+	case string:
+		s, ok := want.(string)
+		if !ok {
+			return false, fmt.Errorf("compare with a string")
+		}
+		return strings.EqualFold(v, s), nil
+	}
+
+	a, okA := toFloat(actual)
+	b, okB := toFloat(want)
+	if !okA || !okB {
+		return false, fmt.Errorf("compare with a number")
+	}
+	return a == b, nil
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
+
+func toStrings(v interface{}) ([]string, bool) {
+	items, ok := v.([]interface{})
+	if !ok {
+		return nil, false
+	}
+	values := make([]string, 0, len(items))
+	for _, item := range items {
+		s, ok := item.(string)
+		if !ok {
+			return nil, false
+		}
+		values = append(values, s)
+	}
+	return values, true
+}
+
+func containsFold(values []string, want string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, want) {
+			return true
+		}
+	}
+	return false
+}
+
+// playbookFacts are the metrics one evaluation of a scope's rules runs on
+type playbookFacts struct {
+	scope   string
+	subject string
+	values  map[string]interface{}
+}
+
+// evaluatePlaybook evaluates the rules of each scope against its metrics, in
+// the order of the metrics and then of the rules
+func evaluatePlaybook(rules []models.PlaybookRule, facts []playbookFacts) []models.PlaybookRuleResult {
+	var results []models.PlaybookRuleResult
+	for _, f := range facts {
+		for _, rule := range rules {
+			if rule.Scope != f.scope {
+				continue
+			}
+			results = append(results, evaluateRule(rule, f))
+		}
+	}
+	return results
+}
+
+func evaluateRule(rule models.PlaybookRule, f playbookFacts) models.PlaybookRuleResult {
+	result := models.PlaybookRuleResult{
+		RuleID:     rule.ID,
+		Scope:      rule.Scope,
+		Subject:    f.subject,
+		Fired:      true,
+		Conditions: []models.PlaybookConditionResult{},
+	}
+
+	for _, cond := range rule.When {
+		actual := f.values[cond.Field]
+		passed, err := evaluateCondition(cond, actual)
+		if err != nil {
+			result.Error = err.Error()
+		}
+		result.Fired = result.Fired && passed
+		result.Conditions = append(result.Conditions, models.PlaybookConditionResult{
+			PlaybookCondition: cond,
+			Actual:            actual,
+			Passed:            passed,
+		})
+	}
+	if !result.Fired {
+		return result
+	}
+
+	recommendation := models.Recommendation{}
+	texts := []*string{&recommendation.Type, &recommendation.Priority, &recommendation.Impact,
+		&recommendation.Title, &recommendation.Description, &recommendation.Action}
+	for i, text := range playbookTexts(&rule) {
+		rendered, err := renderPlaybookText(*text, f.values)
+		if err != nil {
+			result.Fired = false
+			result.Error = err.Error()
+			return result
+		}
+		*texts[i] = rendered
+	}
+	result.Recommendation = &recommendation
+	return result
+}
+
+// firedRecommendations returns the recommendations of the rules that fired
+func firedRecommendations(results []models.PlaybookRuleResult) []models.Recommendation {
+	var recommendations []models.Recommendation
+	for _, result := range results {
+		if result.Recommendation != nil {
+			recommendations = append(recommendations, *result.Recommendation)
+		}
+	}
+	return recommendations
+}
+
+// CheckPlaybookFile validates the playbook file and returns its path. Unlike
+// recommendations, which fall back to the built-in playbook, it reports why
+// the file is invalid.
+func CheckPlaybookFile() (string, error) {
+	_, paths, err := playbooks.loadNow()
+	if len(paths) == 0 {
+		return "", err
+	}
+	return paths[0], err
+}
@@ -0,0 +1,203 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/fissionx/gego/internal/models"
+)
+
+func TestRecommendationPlaybooks(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "playbooks.yaml")
+	SetPlaybookPath(path)
+	t.Cleanup(func() { SetPlaybookPath("") })
+	setConfigCheckInterval(t, 0)
+
+	engine := NewRecommendationsEngine()
+	sources := []models.SourceInsight{
+		{Domain: "g2.com", CitationCount: 6, MentionRate: 60, Categories: []string{"review_site"}},
+		{Domain: "hubspot.com", CitationCount: 2, MentionRate: 20, Categories: []string{"company_website"}},
+	}
+	titles := func(recommendations []models.Recommendation) []string {
+		var titles []string
+		for _, r := range recommendations {
+			titles = append(titles, r.Priority+": "+r.Title)
+		}
+		return titles
+	}
+
+	// The built-in playbook
+	got := titles(engine.GenerateSourceRecommendations("HubSpot", sources, 8, false))
+	want := []string{
+		"high: Optimize presence on g2.com",
+		"critical: Brand not appearing in cited sources",
+		"medium: Low citation diversity",
+	}
+	if !slices.Equal(got, want) {
+		t.Fatalf("built-in recommendations = %q, want %q", got, want)
+	}
+
+	err := os.WriteFile(path, []byte(`playbooks:
+  - name: agency
+    rules:
+      - id: review_site_presence
+        priority: critical
+        when:
+          - {field: citationCount, op: gte, value: 10}
+  - name: acme
+    brands: [Acme]
+    rules:
+      - id: brand_missing_from_sources
+        disabled: true
+      - id: review_site_dominance
+        scope: source
+        when:
+          - {field: categories, op: in, value: [review_site, community]}
+          - {field: mentionRate, op: gt, value: 50}
+        priority: high
+        title: 'Defend {{domain}} ({{mentionRate|%.0f}}%)'
+`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got = titles(engine.GenerateSourceRecommendations("HubSpot", sources, 8, false))
+	want = []string{"critical: Brand not appearing in cited sources", "medium: Low citation diversity"}
+	if !slices.Equal(got, want) {
+		t.Errorf("agency recommendations = %q, want %q", got, want)
+	}
+	got = titles(engine.GenerateSourceRecommendations("acme", sources, 8, false))
+	want = []string{"high: Defend g2.com (60%)", "medium: Low citation diversity"}
+	if !slices.Equal(got, want) {
+		t.Errorf("acme recommendations = %q, want %q", got, want)
+	}
+
+	effective := engine.Playbooks("Acme").Effective
+	if !slices.Equal(effective.Playbooks, []string{BuiltinPlaybook, "agency", "acme"}) {
+		t.Errorf("effective playbooks = %v", effective.Playbooks)
+	}
+
+	// An invalid file is reported and the last valid playbooks stay in use
+	if err := os.WriteFile(path, []byte("playbooks:\n  - name: broken\n    rules:\n      - {id: x, scope: source, title: t, when: [{field: domain, op: gt, value: 1}]}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := CheckPlaybookFile(); err == nil {
+		t.Error("CheckPlaybookFile() of an invalid file succeeded")
+	}
+	got = titles(engine.GenerateSourceRecommendations("acme", sources, 8, false))
+	if len(got) == 0 || got[0] != "high: Defend g2.com (60%)" {
+		t.Errorf("recommendations with an invalid file = %q", got)
+	}
+}
+
+func TestPlaybookDryRun(t *testing.T) {
+	engine := NewRecommendationsEngine()
+
+	run, err := engine.DryRun("HubSpot", []models.PlaybookRule{{ID: "review_site_presence", When: []models.PlaybookCondition{}}},
+		PlaybookInputs{Position: &models.PositionAnalyticsResponse{AveragePosition: 6.5, TotalMentions: 4}})
+	if err != nil {
+		t.Fatalf("DryRun() error = %v", err)
+	}
+	fired := map[string]bool{}
+	for _, result := range run.Results {
+		fired[result.RuleID] = result.Fired
+		if result.RuleID == "low_average_position" && (len(result.Conditions) != 2 || result.Conditions[1].Actual != 6.5) {
+			t.Errorf("low_average_position conditions = %+v", result.Conditions)
+		}
+	}
+	if !fired["low_average_position"] || fired["brand_not_mentioned"] || fired["rare_top_positions"] {
+		t.Errorf("fired position rules = %v", fired)
+	}
+	if len(run.Recommendations) != 1 || run.Recommendations[0].Description != "Average position is 6.5. You're mentioned but ranked low in lists." {
+		t.Errorf("dry run recommendations = %+v", run.Recommendations)
+	}
+
+	if _, err := engine.DryRun("HubSpot", []models.PlaybookRule{{ID: "typo", Scope: "position", Title: "{{avgPosition}}"}}, PlaybookInputs{}); err == nil {
+		t.Error("DryRun() with an unknown metric succeeded")
+	}
+}
+
+func TestEvaluateCondition(t *testing.T) {
+	tests := []struct {
+		name    string
+		cond    models.PlaybookCondition
+		actual  interface{}
+		want    bool
+		wantErr bool
+	}{
+		{"int against a decimal", models.PlaybookCondition{Op: "gte", Value: 5.0}, 5, true, false},
+		{"decimal below", models.PlaybookCondition{Op: "lt", Value: 3}, 2.5, true, false},
+		{"bool", models.PlaybookCondition{Op: "eq", Value: false}, false, true, false},
+		{"text ignores case", models.PlaybookCondition{Op: "ne", Value: "G2.com"}, "g2.com", false, false},
+		{"substring", models.PlaybookCondition{Op: "contains", Value: "Reddit"}, "www.reddit.com", true, false},
+		{"list element", models.PlaybookCondition{Op: "contains", Value: "community"}, []string{"social_media", "community"}, true, false},
+		{"one of", models.PlaybookCondition{Op: "in", Value: []interface{}{"news", "blog"}}, []string{"review_site"}, false, false},
+		{"number against text", models.PlaybookCondition{Op: "gt", Value: "high"}, 3, false, true},
+		{"bool against a number", models.PlaybookCondition{Op: "eq", Value: 1}, true, false, true},
+		{"in without a list", models.PlaybookCondition{Op: "in", Value: "news"}, "news", false, true},
+		{"unknown op", models.PlaybookCondition{Op: "between", Value: 1}, 2, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := evaluateCondition(tt.cond, tt.actual)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("evaluateCondition() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("evaluateCondition() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergePlaybookRules(t *testing.T) {
+	base := []models.PlaybookRule{
+		{ID: "a", Scope: "source", Priority: "high", Title: "A"},
+		{ID: "b", Scope: "sources", Priority: "low", Title: "B"},
+	}
+
+	tests := []struct {
+		name    string
+		patches []models.PlaybookRule
+		want    []models.PlaybookRule
+	}{
+		{
+			name:    "patch keeps unset fields",
+			patches: []models.PlaybookRule{{ID: "a", Priority: "critical"}},
+			want: []models.PlaybookRule{
+				{ID: "a", Scope: "source", Priority: "critical", Title: "A"},
+				{ID: "b", Scope: "sources", Priority: "low", Title: "B"},
+			},
+		},
+		{
+			name:    "disable",
+			patches: []models.PlaybookRule{{ID: "a", Disabled: true}},
+			want:    []models.PlaybookRule{{ID: "b", Scope: "sources", Priority: "low", Title: "B"}},
+		},
+		{
+			name:    "new rule is appended",
+			patches: []models.PlaybookRule{{ID: "c", Scope: "position", Title: "C"}, {ID: "d", Disabled: true}},
+			want: []models.PlaybookRule{
+				{ID: "a", Scope: "source", Priority: "high", Title: "A"},
+				{ID: "b", Scope: "sources", Priority: "low", Title: "B"},
+				{ID: "c", Scope: "position", Title: "C"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := mergePlaybookRules(base, tt.patches)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("mergePlaybookRules() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+	if base[0].Priority != "high" || len(base) != 2 {
+		t.Errorf("mergePlaybookRules() changed its input: %+v", base)
+	}
+}
@@ -15,17 +15,17 @@ import (
 
 // PromptGenerationService handles intelligent prompt generation and reuse
 type PromptGenerationService struct {
-	db          db.Database
-	llmRegistry *llm.Registry
-	scraper     *WebScraperService
+	db         db.Database
+	llmFactory *llm.Factory
+	scraper    *WebScraperService
 }
 
 // NewPromptGenerationService creates a new prompt generation service
-func NewPromptGenerationService(database db.Database, registry *llm.Registry) *PromptGenerationService {
+func NewPromptGenerationService(database db.Database, factory *llm.Factory) *PromptGenerationService {
 	return &PromptGenerationService{
-		db:          database,
-		llmRegistry: registry,
-		scraper:     NewWebScraperService(),
+		db:         database,
+		llmFactory: factory,
+		scraper:    NewWebScraperService(),
 	}
 }
 
@@ -238,13 +238,9 @@ func (s *PromptGenerationService) getOrCreateBrandProfile(ctx context.Context, b
 // deriveBrandMetadata uses LLM to derive domain and category for a brand
 func (s *PromptGenerationService) deriveBrandMetadata(ctx context.Context, brand, description string, websiteContent *WebsiteContent) (string, string, error) {
 	// Get an LLM for metadata derivation
-	provider, ok := s.llmRegistry.Get("google")
-	if !ok {
-		providers := s.llmRegistry.List()
-		if len(providers) == 0 {
-			return "", "", fmt.Errorf("no LLM providers available")
-		}
-		provider, _ = s.llmRegistry.Get(providers[0])
+	llmConfig, provider, err := s.generationLLM(ctx)
+	if err != nil {
+		return "", "", err
 	}
 
 	// Build rich context from available sources
@@ -304,6 +300,7 @@ Examples of BAD (too specific) categories:
 Choose the BROADEST category that accurately describes what this organization does.`, brandContext)
 
 	response, err := provider.Generate(ctx, derivationPrompt, llm.Config{
+		Model:       llmConfig.Model,
 		Temperature: 0.3, // Low temperature for consistent categorization
 		MaxTokens:   200,
 	})
@@ -334,6 +331,32 @@ Choose the BROADEST category that accurately describes what this organization do
 	return domain, category, nil
 }
 
+// generationLLM picks an enabled LLM for prompt generation, preferring Google for latest info
+func (s *PromptGenerationService) generationLLM(ctx context.Context) (*models.LLMConfig, llm.Provider, error) {
+	enabled := true
+	llms, err := s.db.ListLLMs(ctx, &enabled)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list LLMs: %w", err)
+	}
+	if len(llms) == 0 {
+		return nil, nil, fmt.Errorf("no LLM providers available")
+	}
+
+	llmConfig := llms[0]
+	for _, candidate := range llms {
+		if candidate.Provider == "google" {
+			llmConfig = candidate
+			break
+		}
+	}
+
+	provider, err := s.llmFactory.Get(llmConfig)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get LLM provider: %w", err)
+	}
+	return llmConfig, provider, nil
+}
+
 // normalizeCategory standardizes common category variations for consistent reuse
 func normalizeCategory(cat string) string {
 	cat = strings.TrimSpace(strings.ToLower(cat))
@@ -491,14 +514,9 @@ func calculatePromptTypeDistribution(total int) map[string]int {
 // generateNewPrompts generates new prompts using an LLM
 func (s *PromptGenerationService) generateNewPrompts(ctx context.Context, brand, category, domain, description string, websiteContent *WebsiteContent, count int, existingPrompts []models.Prompt) ([]string, error) {
 	// Get a capable LLM for generation (prefer Google for latest info)
-	provider, ok := s.llmRegistry.Get("google")
-	if !ok {
-		// Fallback to any available provider
-		providers := s.llmRegistry.List()
-		if len(providers) == 0 {
-			return nil, fmt.Errorf("no LLM providers available")
-		}
-		provider, _ = s.llmRegistry.Get(providers[0])
+	llmConfig, provider, err := s.generationLLM(ctx)
+	if err != nil {
+		return nil, err
 	}
 
 	// Build the generation prompt
@@ -592,7 +610,7 @@ Generate exactly %d questions in this format:`, count, brandInfo, existingText,
 		distribution["what"], distribution["how"], distribution["comparison"], distribution["top_best"], distribution["brand"], count)
 
 	response, err := provider.Generate(ctx, generationPrompt, llm.Config{
-		Model:       "",
+		Model:       llmConfig.Model,
 		Temperature: 0.9, // High creativity for diverse prompts
 		MaxTokens:   4096,
 	})
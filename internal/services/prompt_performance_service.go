@@ -35,53 +35,35 @@ func (s *PromptPerformanceService) GetPromptPerformance(
 		minResponses = 3 // Minimum responses to have meaningful data
 	}
 
-	// Fetch all responses for the brand
+	// Aggregate the brand's responses per prompt in the database
 	filter := shared.ResponseFilter{
+		Brand:     brand,
 		StartTime: startTime,
 		EndTime:   endTime,
-		Limit:     10000,
 	}
 
-	allResponses, err := s.db.ListResponses(ctx, filter)
+	promptMetrics, err := s.db.AggregateResponseMetrics(ctx, filter, shared.GroupByPrompt)
 	if err != nil {
 		return nil, err
 	}
 
-	// Filter for brand and group by prompt
-	promptData := make(map[string]*promptPerformanceData)
-
-	for _, resp := range allResponses {
-		if resp.Brand != brand {
-			continue
-		}
-
-		if _, exists := promptData[resp.PromptID]; !exists {
-			promptData[resp.PromptID] = &promptPerformanceData{
-				promptID:  resp.PromptID,
-				responses: []*models.Response{},
-			}
-		}
-
-		promptData[resp.PromptID].responses = append(promptData[resp.PromptID].responses, resp)
-	}
-
 	// Calculate performance metrics for each prompt
 	var promptPerformances []models.PromptPerformance
 
-	for promptID, data := range promptData {
+	for _, metrics := range promptMetrics {
 		// Skip prompts with insufficient data
-		if len(data.responses) < minResponses {
+		if metrics.TotalResponses < minResponses {
 			continue
 		}
 
 		// Get prompt details
-		prompt, err := s.db.GetPrompt(ctx, promptID)
+		prompt, err := s.db.GetPrompt(ctx, metrics.Key)
 		if err != nil || prompt == nil {
 			// Skip if prompt not found
 			continue
 		}
 
-		perf := s.calculatePromptPerformance(prompt, data.responses)
+		perf := s.calculatePromptPerformance(prompt, metrics)
 		promptPerformances = append(promptPerformances, perf)
 	}
 
@@ -134,60 +116,24 @@ func (s *PromptPerformanceService) GetPromptPerformance(
 // calculatePromptPerformance computes performance metrics for a single prompt
 func (s *PromptPerformanceService) calculatePromptPerformance(
 	prompt *models.Prompt,
-	responses []*models.Response,
+	metrics *models.ResponseMetrics,
 ) models.PromptPerformance {
-	totalResponses := len(responses)
-	
-	// Initialize metrics
-	totalVisibility := 0.0
-	totalPosition := 0.0
-	positionCount := 0
-	topPositionCount := 0
-	brandMentionCount := 0
-	sentimentSum := 0.0
-	sentimentCount := 0
-
-	// Aggregate metrics
-	for _, resp := range responses {
-		// Visibility
-		totalVisibility += float64(resp.VisibilityScore)
-
-		// Brand mentions
-		if resp.BrandMentioned {
-			brandMentionCount++
-		}
-
-		// Position tracking
-		if resp.BrandPosition > 0 {
-			totalPosition += float64(resp.BrandPosition)
-			positionCount++
-
-			if resp.BrandPosition <= 3 {
-				topPositionCount++
-			}
-		}
-
-		// Sentiment
-		if resp.Sentiment != "" {
-			sentimentSum += calculateSentimentScore(resp.Sentiment)
-			sentimentCount++
-		}
-	}
+	totalResponses := metrics.TotalResponses
 
 	// Calculate averages
-	avgVisibility := totalVisibility / float64(totalResponses)
-	mentionRate := float64(brandMentionCount) / float64(totalResponses) * 100
+	avgVisibility := float64(metrics.TotalVisibility) / float64(totalResponses)
+	mentionRate := float64(metrics.MentionCount) / float64(totalResponses) * 100
 
 	avgPosition := 0.0
 	topPositionRate := 0.0
-	if positionCount > 0 {
-		avgPosition = totalPosition / float64(positionCount)
-		topPositionRate = float64(topPositionCount) / float64(positionCount) * 100
+	if metrics.PositionCount > 0 {
+		avgPosition = float64(metrics.PositionSum) / float64(metrics.PositionCount)
+		topPositionRate = float64(metrics.TopPositionCount) / float64(metrics.PositionCount) * 100
 	}
 
 	avgSentiment := 0.0
-	if sentimentCount > 0 {
-		avgSentiment = sentimentSum / float64(sentimentCount)
+	if metrics.SentimentCount > 0 {
+		avgSentiment = metrics.SentimentScoreSum / float64(metrics.SentimentCount)
 	}
 
 	// Calculate effectiveness score (0-100)
@@ -214,7 +160,7 @@ func (s *PromptPerformanceService) calculatePromptPerformance(
 		TopPositionRate:     roundToTwo(topPositionRate),
 		AvgSentiment:        roundToTwo(avgSentiment),
 		TotalResponses:      totalResponses,
-		BrandMentions:       brandMentionCount,
+		BrandMentions:       metrics.MentionCount,
 		EffectivenessScore:  roundToTwo(effectivenessScore),
 		EffectivenessGrade:  grade,
 		Status:              status,
@@ -328,10 +274,3 @@ func getPromptRecommendation(score, visibility, mentionRate, position float64) s
 func roundToTwo(val float64) float64 {
 	return float64(int(val*100+0.5)) / 100
 }
-
-// promptPerformanceData is internal helper struct
-type promptPerformanceData struct {
-	promptID  string
-	responses []*models.Response
-}
-
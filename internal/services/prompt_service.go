@@ -19,6 +19,38 @@ func NewPromptManagementService(database db.Database) *PromptManagementService {
 	return &PromptManagementService{db: database}
 }
 
+// ApplyPromptMetadata snapshots the prompt's category, domain, type and tags onto
+// a response, so analytics never have to look the prompt up again
+func ApplyPromptMetadata(response *models.Response, prompt *models.Prompt) {
+	if response == nil || prompt == nil {
+		return
+	}
+	response.PromptCategory = prompt.Category
+	response.PromptDomain = prompt.Domain
+	response.PromptType = prompt.PromptType
+	response.PromptTags = prompt.Tags
+}
+
+// BackfillResponseMetadata copies prompt metadata onto responses saved before it
+// was snapshotted at execution time. Returns the number of responses updated.
+func (s *PromptManagementService) BackfillResponseMetadata(ctx context.Context) (int, error) {
+	prompts, err := s.db.ListPrompts(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list prompts: %w", err)
+	}
+
+	updated := 0
+	for _, prompt := range prompts {
+		count, err := s.db.BackfillResponsePromptMetadata(ctx, prompt)
+		if err != nil {
+			return updated, fmt.Errorf("failed to backfill responses of prompt %s: %w", prompt.ID, err)
+		}
+		updated += count
+	}
+
+	return updated, nil
+}
+
 // ValidatePrompt validates prompt configuration
 func (s *PromptManagementService) ValidatePrompt(prompt *models.Prompt) error {
 	if prompt.Template == "" {
@@ -0,0 +1,166 @@
+package services
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Template variables with a default value. {{brand}} is the brand a prompt
+// runs for and {{year}} the current year, unless a value set binds them.
+const (
+	VariableBrand = "brand"
+	VariableYear  = "year"
+)
+
+var (
+	templateVariablePattern = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_]*)\s*\}\}`)
+	variableNamePattern     = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+)
+
+// TemplateVariables lists the variables a prompt template uses, such as
+// city for "Best CRM in {{city}}?", in order of first use
+func TemplateVariables(template string) []string {
+	var names []string
+	for _, match := range templateVariablePattern.FindAllStringSubmatch(template, -1) {
+		if !contains(names, match[1]) {
+			names = append(names, match[1])
+		}
+	}
+	return names
+}
+
+// ValidateVariables checks the value sets of a schedule or campaign: every
+// name is a valid identifier with at least one non-empty value
+func ValidateVariables(sets map[string][]string) error {
+	for name, values := range sets {
+		if !variableNamePattern.MatchString(name) {
+			return fmt.Errorf("invalid variable name %q: use letters, digits and underscores", name)
+		}
+		if len(values) == 0 {
+			return fmt.Errorf("variable %s has no values", name)
+		}
+		for _, value := range values {
+			if strings.TrimSpace(value) == "" {
+				return fmt.Errorf("variable %s has an empty value", name)
+			}
+		}
+	}
+	return nil
+}
+
+// ValidatePromptVariables checks that a value set or a default exists for
+// every variable of a prompt template
+func ValidatePromptVariables(template, brand string, sets map[string][]string) error {
+	var missing []string
+	for _, name := range TemplateVariables(template) {
+		if _, ok := sets[name]; ok || name == VariableYear || (name == VariableBrand && brand != "") {
+			continue
+		}
+		missing = append(missing, "{{"+name+"}}")
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("prompt %q uses %s without values", truncateTemplate(template), strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// PromptBindings expands the value sets a prompt template uses into their
+// matrix, one binding per combination of values. Sets of variables the
+// template does not use are ignored, so a prompt without {{city}} runs once
+// rather than once per city. A template without bound variables has a single
+// nil binding.
+func PromptBindings(template string, sets map[string][]string) []map[string]string {
+	var names []string
+	for _, name := range TemplateVariables(template) {
+		if len(sets[name]) > 0 {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	bindings := []map[string]string{nil}
+	for _, name := range names {
+		expanded := make([]map[string]string, 0, len(bindings)*len(sets[name]))
+		for _, binding := range bindings {
+			for _, value := range sets[name] {
+				next := make(map[string]string, len(binding)+1)
+				for k, v := range binding {
+					next[k] = v
+				}
+				next[name] = value
+				expanded = append(expanded, next)
+			}
+		}
+		bindings = expanded
+	}
+	return bindings
+}
+
+// BindPrompt renders a prompt template with one binding of its variables.
+// {{brand}} defaults to the given brand and {{year}} to the current year. It
+// returns the prompt text and every variable it substituted, defaults included.
+func BindPrompt(template string, variables map[string]string, brand string) (string, map[string]string, error) {
+	names := TemplateVariables(template)
+	if len(names) == 0 {
+		return template, nil, nil
+	}
+
+	bound := make(map[string]string, len(names))
+	var missing []string
+	for _, name := range names {
+		value, ok := variables[name]
+		switch {
+		case ok:
+		case name == VariableBrand && brand != "":
+			value = brand
+		case name == VariableYear:
+			value = strconv.Itoa(time.Now().Year())
+		default:
+			missing = append(missing, "{{"+name+"}}")
+			continue
+		}
+		bound[name] = value
+	}
+	if len(missing) > 0 {
+		return "", nil, fmt.Errorf("no value for %s in prompt %q", strings.Join(missing, ", "), truncateTemplate(template))
+	}
+
+	text := templateVariablePattern.ReplaceAllStringFunc(template, func(placeholder string) string {
+		return bound[templateVariablePattern.FindStringSubmatch(placeholder)[1]]
+	})
+	return text, bound, nil
+}
+
+// boundBrand returns the brand an execution runs for: the {{brand}} value
+// of its binding, or the brand of its prompt or campaign
+func boundBrand(brand string, variables map[string]string) string {
+	if value := variables[VariableBrand]; value != "" {
+		return value
+	}
+	return brand
+}
+
+// FormatVariables renders value sets or a binding as name=value pairs,
+// sorted by name
+func FormatVariables[V string | []string](variables map[string]V) string {
+	names := make([]string, 0, len(variables))
+	for name := range variables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		switch value := any(variables[name]).(type) {
+		case string:
+			parts = append(parts, name+"="+value)
+		case []string:
+			parts = append(parts, name+"="+strings.Join(value, ","))
+		}
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,59 @@
+package services
+
+import (
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestPromptBindings(t *testing.T) {
+	sets := map[string][]string{
+		"city":    {"Paris", "Berlin"},
+		"persona": {"a founder", "a CFO", "a student"},
+		"unused":  {"x", "y"},
+	}
+
+	bindings := PromptBindings("Best CRM in {{city}} for {{ persona }}?", sets)
+	if len(bindings) != 6 {
+		t.Fatalf("PromptBindings() = %d bindings, want 6", len(bindings))
+	}
+	seen := make(map[string]bool)
+	for _, binding := range bindings {
+		if len(binding) != 2 {
+			t.Errorf("binding %v binds unused variables", binding)
+		}
+		seen[FormatVariables(binding)] = true
+	}
+	if len(seen) != 6 || !seen["city=Berlin persona=a CFO"] {
+		t.Errorf("PromptBindings() = %v", bindings)
+	}
+
+	if bindings := PromptBindings("Best CRM in {{year}}?", sets); len(bindings) != 1 || bindings[0] != nil {
+		t.Errorf("PromptBindings(no sets) = %v, want one nil binding", bindings)
+	}
+}
+
+func TestBindPrompt(t *testing.T) {
+	text, bound, err := BindPrompt("Is {{brand}} the best CRM in {{city}} in {{year}}?", map[string]string{"city": "Paris"}, "Acme")
+	year := strconv.Itoa(time.Now().Year())
+	if err != nil || text != "Is Acme the best CRM in Paris in "+year+"?" {
+		t.Fatalf("BindPrompt() = %q, %v", text, err)
+	}
+	if bound["brand"] != "Acme" || bound["city"] != "Paris" || bound["year"] != year {
+		t.Errorf("BindPrompt() bound = %v", bound)
+	}
+
+	if _, _, err := BindPrompt("Best CRM in {{city}}?", nil, "Acme"); err == nil {
+		t.Error("BindPrompt() accepted an unbound variable")
+	}
+	if text, bound, err := BindPrompt("Best CRM?", nil, ""); err != nil || text != "Best CRM?" || bound != nil {
+		t.Errorf("BindPrompt(plain) = %q, %v, %v", text, bound, err)
+	}
+
+	if err := ValidatePromptVariables("{{brand}} vs {{competitor}}", "", map[string][]string{"competitor": {"Rival"}}); err == nil {
+		t.Error("ValidatePromptVariables() accepted {{brand}} without a brand")
+	}
+	if err := ValidateVariables(map[string][]string{"bad-name": {"x"}}); err == nil {
+		t.Error("ValidateVariables() accepted an invalid name")
+	}
+}
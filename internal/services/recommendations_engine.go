@@ -1,14 +1,14 @@
 package services
 
 import (
-	"fmt"
 	"sort"
 	"strings"
 
 	"github.com/fissionx/gego/internal/models"
 )
 
-// RecommendationsEngine generates actionable insights from GEO data
+// RecommendationsEngine generates actionable insights from GEO data by
+// evaluating the recommendation playbooks
 type RecommendationsEngine struct{}
 
 // NewRecommendationsEngine creates a new recommendations engine
@@ -16,6 +16,14 @@ func NewRecommendationsEngine() *RecommendationsEngine {
 	return &RecommendationsEngine{}
 }
 
+// PlaybookInputs are the analytics a playbook dry run evaluates. Scopes
+// without analytics are skipped.
+type PlaybookInputs struct {
+	Sources     *models.SourceAnalyticsResponse
+	Competitive *models.CompetitiveBenchmarkResponse
+	Position    *models.PositionAnalyticsResponse
+}
+
 // GenerateSourceRecommendations generates recommendations based on source citation analysis
 func (e *RecommendationsEngine) GenerateSourceRecommendations(
 	brand string,
@@ -23,91 +31,7 @@ func (e *RecommendationsEngine) GenerateSourceRecommendations(
 	totalCitations int,
 	brandInSources bool,
 ) []models.Recommendation {
-	var recommendations []models.Recommendation
-	
-	// Analyze top sources and generate recommendations
-	for i, source := range topSources {
-		if i >= 5 { // Only analyze top 5 sources
-			break
-		}
-		
-		categories := categorizeSource(source.Domain)
-		
-		// Review sites recommendations
-		if containsCategory(categories, "review_site") {
-			if source.CitationCount >= 3 {
-				recommendations = append(recommendations, models.Recommendation{
-					Type:        "source_opportunity",
-					Priority:    "high",
-					Title:       fmt.Sprintf("Optimize presence on %s", source.Domain),
-					Description: fmt.Sprintf("The review site %s is frequently cited (%d times, %.1f%% of responses). This is a high-value source for AI visibility.", source.Domain, source.CitationCount, source.MentionRate),
-					Action:      fmt.Sprintf("Create or optimize your profile on %s. Encourage customers to leave reviews. Ensure your listing is complete and up-to-date.", source.Domain),
-					Impact:      "high",
-				})
-			}
-		}
-		
-		// Social media recommendations
-		if containsCategory(categories, "social_media") {
-			if strings.Contains(source.Domain, "reddit") {
-				recommendations = append(recommendations, models.Recommendation{
-					Type:        "content_opportunity",
-					Priority:    "medium",
-					Title:       "Engage in Reddit discussions",
-					Description: fmt.Sprintf("Reddit (%s) appears frequently in citations (%d times). AI models value community discussions.", source.Domain, source.CitationCount),
-					Action:      "Participate authentically in relevant subreddit discussions. Share helpful insights without overtly promoting. Consider doing an AMA if appropriate.",
-					Impact:      "medium",
-				})
-			} else if strings.Contains(source.Domain, "linkedin") {
-				recommendations = append(recommendations, models.Recommendation{
-					Type:        "content_opportunity",
-					Priority:    "medium",
-					Title:       "Increase LinkedIn presence",
-					Description: fmt.Sprintf("LinkedIn is cited %d times. Professional networks are valued by AI models.", source.CitationCount),
-					Action:      "Publish thought leadership articles on LinkedIn. Engage with industry discussions. Ensure company page is complete.",
-					Impact:      "medium",
-				})
-			}
-		}
-		
-		// News and publications recommendations
-		if containsCategory(categories, "news") || containsCategory(categories, "publication") {
-			recommendations = append(recommendations, models.Recommendation{
-				Type:        "pr_opportunity",
-				Priority:    "high",
-				Title:       fmt.Sprintf("Digital PR: Target %s", source.Domain),
-				Description: fmt.Sprintf("Editorial site %s is cited %d times by AI models. Getting featured here significantly boosts visibility.", source.Domain, source.CitationCount),
-				Action:      fmt.Sprintf("Consider digital PR campaigns targeting %s. Pitch newsworthy stories, data releases, or expert commentary.", source.Domain),
-				Impact:      "high",
-			})
-		}
-	}
-	
-	// General recommendations based on overall performance
-	if !brandInSources && len(topSources) > 0 {
-		recommendations = append(recommendations, models.Recommendation{
-			Type:        "source_opportunity",
-			Priority:    "critical",
-			Title:       "Brand not appearing in cited sources",
-			Description: "Your brand is not appearing in the sources that AI models cite. This severely limits visibility.",
-			Action:      fmt.Sprintf("Focus on getting mentioned in these high-value sources: %s", getTopDomainNames(topSources, 5)),
-			Impact:      "critical",
-		})
-	}
-	
-	// Citation diversity recommendation
-	if len(topSources) > 0 && topSources[0].CitationCount > totalCitations/2 {
-		recommendations = append(recommendations, models.Recommendation{
-			Type:        "diversity_warning",
-			Priority:    "medium",
-			Title:       "Low citation diversity",
-			Description: fmt.Sprintf("%.1f%% of citations come from a single source (%s). This creates dependency risk.", topSources[0].MentionRate, topSources[0].Domain),
-			Action:      "Diversify your presence across multiple high-quality sources to reduce risk and increase overall visibility.",
-			Impact:      "medium",
-		})
-	}
-	
-	return recommendations
+	return e.recommend(brand, sourceFacts(brand, topSources, totalCitations, brandInSources))
 }
 
 // GenerateCompetitiveRecommendations generates recommendations based on competitive analysis
@@ -116,94 +40,12 @@ func (e *RecommendationsEngine) GenerateCompetitiveRecommendations(
 	competitors []models.BrandPerformance,
 	marketLeader string,
 ) []models.Recommendation {
-	var recommendations []models.Recommendation
-	
 	// Sort competitors by visibility
 	sort.Slice(competitors, func(i, j int) bool {
 		return competitors[i].Visibility > competitors[j].Visibility
 	})
-	
-	// If we're not the market leader
-	if marketLeader != mainBrand.Brand {
-		topCompetitor := competitors[0]
-		visibilityGap := topCompetitor.Visibility - mainBrand.Visibility
-		
-		if visibilityGap > 3.0 {
-			recommendations = append(recommendations, models.Recommendation{
-				Type:        "competitor_threat",
-				Priority:    "critical",
-				Title:       fmt.Sprintf("Significant visibility gap with %s", topCompetitor.Brand),
-				Description: fmt.Sprintf("Your visibility (%.1f) is %.1f points behind market leader %s (%.1f). This represents a %.1f%% gap.", mainBrand.Visibility, visibilityGap, topCompetitor.Brand, topCompetitor.Visibility, (visibilityGap/topCompetitor.Visibility)*100),
-				Action:      "Analyze what content and sources drive competitor visibility. Focus on getting mentioned in their key citation sources.",
-				Impact:      "critical",
-			})
-		} else if visibilityGap > 1.0 {
-			recommendations = append(recommendations, models.Recommendation{
-				Type:        "competitive_opportunity",
-				Priority:    "high",
-				Title:       "Close visibility gap with market leader",
-				Description: fmt.Sprintf("You're %.1f points behind %s. The gap is closeable with focused effort.", visibilityGap, topCompetitor.Brand),
-				Action:      "Focus on improving position in list-based prompts. Ensure strong presence in review sites and communities.",
-				Impact:      "high",
-			})
-		}
-	} else {
-		// We are the market leader
-		recommendations = append(recommendations, models.Recommendation{
-			Type:        "maintain_leadership",
-			Priority:    "medium",
-			Title:       "Maintain market leadership position",
-			Description: fmt.Sprintf("You're the market leader with %.1f visibility. Focus on maintaining and extending this lead.", mainBrand.Visibility),
-			Action:      "Continue your current strategy. Monitor emerging competitors. Expand to new prompt categories and regions.",
-			Impact:      "medium",
-		})
-	}
-	
-	// Position analysis
-	if mainBrand.AveragePosition > 3.0 && mainBrand.AveragePosition > 0 {
-		recommendations = append(recommendations, models.Recommendation{
-			Type:        "position_improvement",
-			Priority:    "high",
-			Title:       "Improve average position in lists",
-			Description: fmt.Sprintf("Your average position is %.1f (where 1 is best). You're often mentioned but not at the top.", mainBrand.AveragePosition),
-			Action:      "Focus on being the 'best' or 'top choice' in content. Improve review scores and ratings. Get more positive testimonials.",
-			Impact:      "high",
-		})
-	}
-	
-	// Sentiment comparison
-	avgCompetitorSentiment := 0.0
-	for _, comp := range competitors {
-		avgCompetitorSentiment += comp.SentimentScore
-	}
-	if len(competitors) > 0 {
-		avgCompetitorSentiment /= float64(len(competitors))
-		
-		if mainBrand.SentimentScore < avgCompetitorSentiment-0.2 {
-			recommendations = append(recommendations, models.Recommendation{
-				Type:        "sentiment_warning",
-				Priority:    "high",
-				Title:       "Sentiment below competitors",
-				Description: fmt.Sprintf("Your sentiment score (%.2f) is lower than competitors' average (%.2f).", mainBrand.SentimentScore, avgCompetitorSentiment),
-				Action:      "Address negative feedback and reviews. Improve customer satisfaction. Highlight positive case studies.",
-				Impact:      "high",
-			})
-		}
-	}
-	
-	// Grounding rate analysis
-	if mainBrand.GroundingRate < 30.0 {
-		recommendations = append(recommendations, models.Recommendation{
-			Type:        "source_opportunity",
-			Priority:    "critical",
-			Title:       "Low source citation rate",
-			Description: fmt.Sprintf("Your brand appears in cited sources only %.1f%% of the time. This limits credibility.", mainBrand.GroundingRate),
-			Action:      "Focus on getting your website and brand mentioned in authoritative sources that AI models cite (review sites, industry publications, news).",
-			Impact:      "critical",
-		})
-	}
-	
-	return recommendations
+
+	return e.recommend(mainBrand.Brand, competitiveFacts(mainBrand, competitors, marketLeader))
 }
 
 // GeneratePositionRecommendations generates recommendations based on position analysis
@@ -213,56 +55,182 @@ func (e *RecommendationsEngine) GeneratePositionRecommendations(
 	topPositionRate float64,
 	totalMentions int,
 ) []models.Recommendation {
-	var recommendations []models.Recommendation
-	
-	if totalMentions == 0 {
-		recommendations = append(recommendations, models.Recommendation{
-			Type:        "visibility_critical",
-			Priority:    "critical",
-			Title:       "Brand not appearing in AI responses",
-			Description: "Your brand is not being mentioned by AI models at all.",
-			Action:      "Focus on foundational GEO: create authoritative content, get listed in industry directories, obtain reviews, build citations.",
-			Impact:      "critical",
-		})
-		return recommendations
+	return e.recommend(brand, positionFacts(brand, avgPosition, topPositionRate, totalMentions))
+}
+
+// Playbooks returns the built-in playbook followed by those of the playbook
+// file, as written, and the rules in effect for a brand. Without a brand,
+// those are the rules of brands no playbook lists.
+func (e *RecommendationsEngine) Playbooks(brand string) *models.PlaybooksResponse {
+	file := currentPlaybookFile()
+
+	var brands []string
+	if brand != "" {
+		brands = []string{brand}
 	}
-	
-	if avgPosition > 5.0 {
-		recommendations = append(recommendations, models.Recommendation{
-			Type:        "position_improvement",
-			Priority:    "high",
-			Title:       "Average position needs improvement",
-			Description: fmt.Sprintf("Average position is %.1f. You're mentioned but ranked low in lists.", avgPosition),
-			Action:      "Improve your 'best in class' signals: higher review scores, more positive testimonials, authoritative endorsements.",
-			Impact:      "high",
-		})
+	names, rules := resolvePlaybook(file, brands, nil)
+
+	return &models.PlaybooksResponse{
+		Playbooks: append([]models.Playbook{builtinPlaybook}, file.Playbooks...),
+		Effective: models.EffectivePlaybook{Brand: brand, Playbooks: names, Rules: rules},
+	}
+}
+
+// DryRun evaluates the playbook of a brand, patched by extra rules, against
+// the given analytics and reports every rule evaluation, fired or not
+func (e *RecommendationsEngine) DryRun(brand string, extra []models.PlaybookRule, inputs PlaybookInputs) (*models.PlaybookDryRunResponse, error) {
+	names, rules := resolvePlaybook(currentPlaybookFile(), []string{brand}, extra)
+	if len(extra) > 0 {
+		names = append(names, "dry-run")
+		if err := validatePlaybookRules(rules); err != nil {
+			return nil, err
+		}
+	}
+
+	var facts []playbookFacts
+	if in := inputs.Sources; in != nil {
+		facts = append(facts, sourceFacts(brand, in.TopSources, in.TotalCitations, in.BrandInSources)...)
+	}
+	if in := inputs.Competitive; in != nil {
+		facts = append(facts, competitiveFacts(in.MainBrand, in.Competitors, in.MarketLeader)...)
+	}
+	if in := inputs.Position; in != nil {
+		facts = append(facts, positionFacts(brand, in.AveragePosition, in.TopPositionRate, in.TotalMentions)...)
+	}
+
+	results := evaluatePlaybook(rules, facts)
+	recommendations := firedRecommendations(results)
+	if results == nil {
+		results = []models.PlaybookRuleResult{}
 	}
-	
-	if topPositionRate < 20.0 && totalMentions > 10 {
-		recommendations = append(recommendations, models.Recommendation{
-			Type:        "position_improvement",
-			Priority:    "medium",
-			Title:       "Rarely appearing in top positions",
-			Description: fmt.Sprintf("Only %.1f%% of mentions are in top 3 positions. Top positions get most attention.", topPositionRate),
-			Action:      "Focus on superlatives in content ('best', 'top', 'leading'). Improve comparative advantages vs competitors.",
-			Impact:      "medium",
+	if recommendations == nil {
+		recommendations = []models.Recommendation{}
+	}
+
+	return &models.PlaybookDryRunResponse{
+		Brand:           brand,
+		Playbooks:       names,
+		Results:         results,
+		Recommendations: recommendations,
+	}, nil
+}
+
+// recommend evaluates the playbook of a brand and returns the recommendations of the rules that fired
+func (e *RecommendationsEngine) recommend(brand string, facts []playbookFacts) []models.Recommendation {
+	_, rules := resolvePlaybook(currentPlaybookFile(), []string{brand}, nil)
+	return firedRecommendations(evaluatePlaybook(rules, facts))
+}
+
+// sourceFacts returns the metrics of each top source, then of all of them
+func sourceFacts(brand string, topSources []models.SourceInsight, totalCitations int, brandInSources bool) []playbookFacts {
+	var facts []playbookFacts
+	for i, source := range topSources {
+		categories := source.Categories
+		if categories == nil {
+			categories = categorizeSource(source.Domain)
+		}
+		facts = append(facts, playbookFacts{
+			scope:   models.PlaybookScopeSource,
+			subject: source.Domain,
+			values: map[string]interface{}{
+				"brand":          brand,
+				"domain":         source.Domain,
+				"rank":           i + 1,
+				"citationCount":  source.CitationCount,
+				"mentionRate":    source.MentionRate,
+				"categories":     categories,
+				"totalCitations": totalCitations,
+				"sourceCount":    len(topSources),
+				"brandInSources": brandInSources,
+			},
 		})
 	}
-	
-	return recommendations
+
+	values := map[string]interface{}{
+		"brand":            brand,
+		"totalCitations":   totalCitations,
+		"sourceCount":      len(topSources),
+		"brandInSources":   brandInSources,
+		"topDomain":        "",
+		"topDomains":       getTopDomainNames(topSources, 5),
+		"topCitationCount": 0,
+		"topMentionRate":   0.0,
+		"topCitationShare": 0.0,
+	}
+	if len(topSources) > 0 {
+		top := topSources[0]
+		values["topDomain"] = top.Domain
+		values["topCitationCount"] = top.CitationCount
+		values["topMentionRate"] = top.MentionRate
+		if totalCitations > 0 {
+			values["topCitationShare"] = float64(top.CitationCount) / float64(totalCitations) * 100
+		}
+	}
+	return append(facts, playbookFacts{scope: models.PlaybookScopeSources, values: values})
 }
 
-// Helper functions
+// competitiveFacts returns the metrics of a brand against its competitors
+func competitiveFacts(mainBrand models.BrandPerformance, competitors []models.BrandPerformance, marketLeader string) []playbookFacts {
+	values := map[string]interface{}{
+		"brand":                   mainBrand.Brand,
+		"visibility":              mainBrand.Visibility,
+		"mentionRate":             mainBrand.MentionRate,
+		"groundingRate":           mainBrand.GroundingRate,
+		"averagePosition":         mainBrand.AveragePosition,
+		"topPositionRate":         mainBrand.TopPositionRate,
+		"sentimentScore":          mainBrand.SentimentScore,
+		"isLeader":                marketLeader == mainBrand.Brand,
+		"marketLeader":            marketLeader,
+		"topCompetitor":           "",
+		"topCompetitorVisibility": 0.0,
+		"visibilityGap":           0.0,
+		"visibilityGapPercent":    0.0,
+		"competitorCount":         len(competitors),
+		"competitorSentiment":     0.0,
+		"sentimentGap":            0.0,
+	}
+
+	if len(competitors) > 0 {
+		// The most visible competitor
+		top := competitors[0]
+		sentiment := 0.0
+		for _, comp := range competitors {
+			if comp.Visibility > top.Visibility {
+				top = comp
+			}
+			sentiment += comp.SentimentScore
+		}
+		sentiment /= float64(len(competitors))
 
-func containsCategory(categories []string, category string) bool {
-	for _, c := range categories {
-		if c == category {
-			return true
+		gap := top.Visibility - mainBrand.Visibility
+		values["topCompetitor"] = top.Brand
+		values["topCompetitorVisibility"] = top.Visibility
+		values["visibilityGap"] = gap
+		if top.Visibility > 0 {
+			values["visibilityGapPercent"] = gap / top.Visibility * 100
 		}
+		values["competitorSentiment"] = sentiment
+		values["sentimentGap"] = sentiment - mainBrand.SentimentScore
 	}
-	return false
+
+	return []playbookFacts{{scope: models.PlaybookScopeCompetitive, values: values}}
+}
+
+// positionFacts returns the list position metrics of a brand
+func positionFacts(brand string, avgPosition, topPositionRate float64, totalMentions int) []playbookFacts {
+	return []playbookFacts{{
+		scope: models.PlaybookScopePosition,
+		values: map[string]interface{}{
+			"brand":           brand,
+			"averagePosition": avgPosition,
+			"topPositionRate": topPositionRate,
+			"totalMentions":   totalMentions,
+		},
+	}}
 }
 
+// Helper functions
+
 func getTopDomainNames(sources []models.SourceInsight, n int) string {
 	var domains []string
 	for i := 0; i < n && i < len(sources); i++ {
@@ -270,4 +238,3 @@ func getTopDomainNames(sources []models.SourceInsight, n int) string {
 	}
 	return strings.Join(domains, ", ")
 }
-
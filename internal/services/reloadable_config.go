@@ -0,0 +1,154 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// configCheckInterval is how long a loaded config value is used before its
+// files are checked for changes again
+var configCheckInterval = 2 * time.Second
+
+// fileStamp identifies a version of a file by its size and modification time.
+// A missing file has the zero stamp.
+type fileStamp struct {
+	size    int64
+	modTime time.Time
+}
+
+// statFile returns the stamp of a file, zero when it cannot be read
+func statFile(path string) fileStamp {
+	if path == "" {
+		return fileStamp{}
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileStamp{}
+	}
+	return fileStamp{size: info.Size(), modTime: info.ModTime()}
+}
+
+// readYAMLFile decodes a YAML file into v, leaving v untouched when the file
+// does not exist
+func readYAMLFile(path string, v interface{}) error {
+	if path == "" {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := yaml.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return nil
+}
+
+// reloadableConfig is a value loaded from user-editable config files and
+// reloaded when any of them changes. The files are checked at most once per
+// configCheckInterval. An invalid file is reported and the last valid value,
+// or the fallback, stays in use.
+type reloadableConfig[T any] struct {
+	load     func(paths []string) (T, error)
+	fallback func() T
+
+	mu      sync.Mutex
+	paths   []string
+	current atomic.Pointer[loadedConfig[T]]
+}
+
+// loadedConfig is a value with the versions of the files it was loaded from
+type loadedConfig[T any] struct {
+	value     T
+	files     []fileStamp
+	checkedAt time.Time
+}
+
+// newReloadableConfig creates a config loading its value with load. fallback
+// returns the value used until a first valid load.
+func newReloadableConfig[T any](load func(paths []string) (T, error), fallback func() T) *reloadableConfig[T] {
+	return &reloadableConfig[T]{
+		load:     load,
+		fallback: fallback,
+	}
+}
+
+// setPaths sets the files the value is loaded from
+func (c *reloadableConfig[T]) setPaths(paths ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.paths = paths
+	c.current.Store(nil)
+}
+
+// get returns the value of the files, reloading it when they changed
+func (c *reloadableConfig[T]) get() T {
+	if loaded := c.current.Load(); loaded != nil && time.Since(loaded.checkedAt) < configCheckInterval {
+		return loaded.value
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Checked by another caller while waiting for the lock
+	loaded := c.current.Load()
+	if loaded != nil && time.Since(loaded.checkedAt) < configCheckInterval {
+		return loaded.value
+	}
+
+	files := make([]fileStamp, len(c.paths))
+	for i, path := range c.paths {
+		files[i] = statFile(path)
+	}
+	if loaded != nil && slices.Equal(files, loaded.files) {
+		c.current.Store(&loadedConfig[T]{value: loaded.value, files: files, checkedAt: time.Now()})
+		return loaded.value
+	}
+
+	value, err := c.load(c.paths)
+	if err != nil {
+		log.Printf("⚠️  %v", err)
+		if loaded != nil {
+			value = loaded.value
+		} else {
+			value = c.fallback()
+		}
+	}
+
+	c.current.Store(&loadedConfig[T]{value: value, files: files, checkedAt: time.Now()})
+	return value
+}
+
+// loadNow loads the value of the files as they are, failing when one is invalid
+func (c *reloadableConfig[T]) loadNow() (T, []string, error) {
+	c.mu.Lock()
+	paths := c.paths
+	c.mu.Unlock()
+
+	value, err := c.load(paths)
+	return value, paths, err
+}
+
+// update runs fn, which rewrites the files, and reloads the value on next use.
+// A file may be rewritten within the resolution of its modification time, and
+// the change must apply before the next check.
+func (c *reloadableConfig[T]) update(fn func(paths []string) error) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	err := fn(c.paths)
+	c.current.Store(nil)
+	return err
+}
@@ -0,0 +1,98 @@
+package services
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// setConfigCheckInterval changes how often config files are checked for the
+// rest of the test
+func setConfigCheckInterval(t *testing.T, interval time.Duration) {
+	t.Helper()
+	previous := configCheckInterval
+	configCheckInterval = interval
+	t.Cleanup(func() { configCheckInterval = previous })
+}
+
+func TestReloadableConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "limits.yaml")
+	modTime := time.Date(2025, 3, 14, 9, 0, 0, 0, time.UTC)
+	write := func(content string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	loads := 0
+	config := newReloadableConfig(func(paths []string) (int, error) {
+		loads++
+		var limits struct {
+			Max int `yaml:"max"`
+		}
+		if err := readYAMLFile(paths[0], &limits); err != nil {
+			return 0, err
+		}
+		if limits.Max < 0 {
+			return 0, fmt.Errorf("max must not be negative")
+		}
+		return limits.Max, nil
+	}, func() int { return -1 })
+	config.setPaths(path)
+	setConfigCheckInterval(t, time.Hour)
+
+	steps := []struct {
+		name     string
+		content  string // Written before the step when set
+		interval time.Duration
+		want     int
+		wantLoad int
+	}{
+		{name: "missing file", interval: time.Hour, want: 0, wantLoad: 1},
+		{name: "within the interval", content: "max: 5\n", interval: time.Hour, want: 0, wantLoad: 1},
+		{name: "after the interval", interval: 0, want: 5, wantLoad: 2},
+		{name: "unchanged file", interval: 0, want: 5, wantLoad: 2},
+		{name: "same modification time, other size", content: "max: 50\n", interval: 0, want: 50, wantLoad: 3},
+		{name: "invalid file", content: "max: -10\n", interval: 0, want: 50, wantLoad: 4},
+		{name: "invalid file unchanged", interval: 0, want: 50, wantLoad: 4},
+	}
+
+	for _, step := range steps {
+		if step.content != "" {
+			write(step.content)
+		}
+		configCheckInterval = step.interval
+		if got := config.get(); got != step.want || loads != step.wantLoad {
+			t.Errorf("%s: get() = %d after %d loads, want %d after %d", step.name, got, loads, step.want, step.wantLoad)
+		}
+	}
+
+	// An update applies on next use, whatever the interval
+	configCheckInterval = time.Hour
+	err := config.update(func(paths []string) error {
+		return os.WriteFile(paths[0], []byte("max: 7\n"), 0644)
+	})
+	if err != nil {
+		t.Fatalf("update() error = %v", err)
+	}
+	if got := config.get(); got != 7 {
+		t.Errorf("get() after update() = %d, want 7", got)
+	}
+
+	// A first load that fails uses the fallback, and loadNow reports why
+	write("max: -3\n")
+	config.setPaths(path)
+	if got := config.get(); got != -1 {
+		t.Errorf("get() of an invalid file = %d, want the fallback", got)
+	}
+	if _, paths, err := config.loadNow(); err == nil || !strings.Contains(err.Error(), "negative") || paths[0] != path {
+		t.Errorf("loadNow() = %v, %v", paths, err)
+	}
+}
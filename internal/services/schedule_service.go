@@ -37,10 +37,18 @@ func (s *ScheduleService) ValidateSchedule(schedule *models.Schedule) error {
 		return fmt.Errorf("temperature must be between 0.0 and 1.0, got: %.2f", schedule.Temperature)
 	}
 
+	if err := ValidateVariables(schedule.Variables); err != nil {
+		return err
+	}
+
 	for _, promptID := range schedule.PromptIDs {
-		if _, err := s.db.GetPrompt(context.Background(), promptID); err != nil {
+		prompt, err := s.db.GetPrompt(context.Background(), promptID)
+		if err != nil {
 			return fmt.Errorf("prompt %s not found: %w", promptID, err)
 		}
+		if err := ValidatePromptVariables(prompt.Template, prompt.Brand, schedule.Variables); err != nil {
+			return err
+		}
 	}
 
 	for _, llmID := range schedule.LLMIDs {
@@ -176,6 +184,7 @@ func (s *ScheduleService) GetScheduleExecutionPlan(ctx context.Context, schedule
 		ScheduleID:   scheduleID,
 		ScheduleName: schedule.Name,
 		Temperature:  schedule.Temperature,
+		Variables:    schedule.Variables,
 		Prompts:      make([]*models.Prompt, 0, len(schedule.PromptIDs)),
 		LLMs:         make([]*models.LLMConfig, 0, len(schedule.LLMIDs)),
 	}
@@ -204,6 +213,7 @@ type ScheduleExecutionPlan struct {
 	ScheduleID      string              `json:"schedule_id"`
 	ScheduleName    string              `json:"schedule_name"`
 	Temperature     float64             `json:"temperature"`
+	Variables       map[string][]string `json:"variables,omitempty"`
 	Prompts         []*models.Prompt    `json:"prompts"`
 	LLMs            []*models.LLMConfig `json:"llms"`
 	TotalExecutions int                 `json:"total_executions"`
@@ -211,5 +221,9 @@ type ScheduleExecutionPlan struct {
 
 // CalculateTotalExecutions calculates the total number of executions for a plan
 func (plan *ScheduleExecutionPlan) CalculateTotalExecutions() int {
-	return len(plan.Prompts) * len(plan.LLMs)
+	runs := 0
+	for _, prompt := range plan.Prompts {
+		runs += len(PromptBindings(prompt.Template, plan.Variables))
+	}
+	return runs * len(plan.LLMs)
 }
@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"math/rand"
 	"strconv"
-	"strings"
 	"sync"
 	"time"
 
@@ -17,6 +16,7 @@ import (
 	"github.com/fissionx/gego/internal/llm"
 	"github.com/fissionx/gego/internal/logger"
 	"github.com/fissionx/gego/internal/models"
+	"github.com/fissionx/gego/internal/secrets"
 )
 
 // Retry configuration constants
@@ -34,11 +34,14 @@ const (
 
 // SchedulerService manages scheduled prompt executions using robfig/cron
 type SchedulerService struct {
-	db          db.Database
-	llmRegistry *llm.Registry
-	cron        *cron.Cron
-	running     bool
-	mu          sync.RWMutex
+	db            db.Database
+	llmFactory    *llm.Factory
+	queue         *JobQueueService
+	geoAnalyzer   *GEOAnalysisService
+	brandProfiles *BrandProfileService
+	cron          *cron.Cron
+	running       bool
+	mu            sync.RWMutex
 	// Rate limiters per LLM provider (keyed by provider name)
 	rateLimiters map[string]*rate.Limiter
 	rateMu       sync.RWMutex
@@ -47,8 +50,9 @@ type SchedulerService struct {
 	entriesMu       sync.RWMutex
 }
 
-// NewSchedulerService creates a new scheduler service with proper cron configuration
-func NewSchedulerService(database db.Database, llmRegistry *llm.Registry) *SchedulerService {
+// NewSchedulerService creates a new scheduler service with proper cron configuration.
+// Scheduled executions run as jobs on the given queue.
+func NewSchedulerService(database db.Database, llmFactory *llm.Factory, queue *JobQueueService) *SchedulerService {
 	c := cron.New(
 		cron.WithLocation(time.UTC),
 		cron.WithLogger(cron.DefaultLogger),
@@ -57,13 +61,18 @@ func NewSchedulerService(database db.Database, llmRegistry *llm.Registry) *Sched
 		),
 	)
 
-	return &SchedulerService{
+	s := &SchedulerService{
 		db:              database,
-		llmRegistry:     llmRegistry,
+		llmFactory:      llmFactory,
+		queue:           queue,
+		geoAnalyzer:     NewGEOAnalysisService(database, llmFactory),
+		brandProfiles:   NewBrandProfileService(database),
 		cron:            c,
 		rateLimiters:    make(map[string]*rate.Limiter),
 		scheduleEntries: make(map[string]cron.EntryID),
 	}
+	queue.RegisterHandler(models.JobKindSchedule, s)
+	return s
 }
 
 // Start starts the scheduler and loads all enabled schedules
@@ -103,6 +112,9 @@ func (s *SchedulerService) Start(ctx context.Context) error {
 	s.cron.Start()
 	s.running = true
 
+	// Also resumes executions left unfinished by a previous run
+	s.queue.Start(DefaultJobWorkers)
+
 	logger.Info("Scheduler started successfully")
 	return nil
 }
@@ -117,6 +129,7 @@ func (s *SchedulerService) Stop() {
 	}
 
 	s.cron.Stop()
+	s.queue.Stop()
 	s.running = false
 
 	s.entriesMu.Lock()
@@ -143,45 +156,51 @@ func (s *SchedulerService) GetStatus(ctx context.Context) (bool, int, error) {
 	return s.running, len(schedules), nil
 }
 
-// ExecuteNow executes a schedule immediately
+// ExecuteNow queues the executions of a schedule and waits for them to
+// finish. Without a running scheduler, the queue runs until they are done.
 func (s *SchedulerService) ExecuteNow(ctx context.Context, scheduleID string) error {
 	schedule, err := s.db.GetSchedule(ctx, scheduleID)
 	if err != nil {
 		return fmt.Errorf("failed to get schedule: %w", err)
 	}
 
-	return s.executeSchedule(ctx, schedule)
+	jobs, err := s.enqueueSchedule(ctx, schedule)
+	if err != nil {
+		return err
+	}
+	return s.waitForJobs(ctx, jobs)
 }
 
-// ExecutePrompt executes a single prompt with specified LLMs
+// ExecutePrompt executes a single prompt with specified LLMs and waits for
+// the executions to finish
 func (s *SchedulerService) ExecutePrompt(ctx context.Context, promptID string, llmIDs []string) error {
-	prompt, err := s.db.GetPrompt(ctx, promptID)
-	if err != nil {
+	if _, err := s.db.GetPrompt(ctx, promptID); err != nil {
 		return fmt.Errorf("failed to get prompt: %w", err)
 	}
 
-	llms := make([]*models.LLMConfig, 0, len(llmIDs))
-	for _, llmID := range llmIDs {
-		llmConfig, err := s.db.GetLLM(ctx, llmID)
-		if err != nil {
-			logger.Error("Failed to get LLM %s: %v", llmID, err)
-			continue
-		}
-		llms = append(llms, llmConfig)
+	jobs, err := s.enqueueRuns(ctx, &models.Schedule{
+		PromptIDs:   []string{promptID},
+		LLMIDs:      llmIDs,
+		Temperature: 0.7,
+	})
+	if err != nil {
+		return err
 	}
+	return s.waitForJobs(ctx, jobs)
+}
 
-	var wg sync.WaitGroup
-	for _, llmConfig := range llms {
-		wg.Add(1)
-		go func(l *models.LLMConfig) {
-			defer wg.Done()
-			if err := s.executePromptWithRetry(ctx, "", prompt, l, 0.7, DefaultMaxRetries, DefaultRetryDelay); err != nil {
-				logger.Error("Failed to execute prompt %s with LLM %s after all retries: %v", prompt.ID, l.ID, err)
-			}
-		}(llmConfig)
+// waitForJobs waits for queued executions, running the queue meanwhile when
+// no scheduler runs it
+func (s *SchedulerService) waitForJobs(ctx context.Context, jobs []*models.Job) error {
+	if s.queue.Start(DefaultJobWorkers) {
+		defer s.queue.Stop()
 	}
 
-	wg.Wait()
+	logger.Info("Waiting for %d executions", len(jobs))
+	if err := s.queue.Wait(ctx, jobs); err != nil {
+		return err
+	}
+	logger.Info("Completed %d executions", len(jobs))
 	return nil
 }
 
@@ -196,7 +215,7 @@ func (s *SchedulerService) Reload(ctx context.Context) error {
 func (s *SchedulerService) registerSchedule(_ context.Context, schedule *models.Schedule) error {
 	jobFunc := func() {
 		logger.Info("Executing scheduled job: %s", schedule.Name)
-		if err := s.executeSchedule(context.Background(), schedule); err != nil {
+		if _, err := s.enqueueSchedule(context.Background(), schedule); err != nil {
 			logger.Error("Failed to execute schedule %s: %v", schedule.ID, err)
 		}
 	}
@@ -214,9 +233,57 @@ func (s *SchedulerService) registerSchedule(_ context.Context, schedule *models.
 	return nil
 }
 
-// executeSchedule executes a schedule
-func (s *SchedulerService) executeSchedule(ctx context.Context, schedule *models.Schedule) error {
-	logger.Info("Executing schedule: %s", schedule.ID)
+// enqueueSchedule queues one job per prompt/LLM pair of a schedule and
+// records the run on the schedule
+func (s *SchedulerService) enqueueSchedule(ctx context.Context, schedule *models.Schedule) ([]*models.Job, error) {
+	logger.Info("Queueing schedule: %s", schedule.ID)
+
+	jobs, err := s.enqueueRuns(ctx, schedule)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	schedule.LastRun = &now
+	if err := s.db.UpdateSchedule(ctx, schedule); err != nil {
+		logger.Error("Failed to update schedule last run: %v", err)
+	}
+
+	return jobs, nil
+}
+
+// enqueueRuns queues one job per prompt × binding × LLM of a schedule, which
+// may be an unsaved one
+func (s *SchedulerService) enqueueRuns(ctx context.Context, schedule *models.Schedule) ([]*models.Job, error) {
+	prompts, llms := s.getScheduleRuns(ctx, schedule)
+
+	jobs := make([]*models.Job, 0, len(prompts)*len(llms))
+	for _, prompt := range prompts {
+		for _, variables := range PromptBindings(prompt.Template, schedule.Variables) {
+			for _, llmConfig := range llms {
+				jobs = append(jobs, &models.Job{
+					Kind:        models.JobKindSchedule,
+					PromptID:    prompt.ID,
+					LLMID:       llmConfig.ID,
+					ScheduleID:  schedule.ID,
+					Brand:       boundBrand(prompt.Brand, variables),
+					Temperature: scheduleTemperature(schedule),
+					Variables:   variables,
+				})
+			}
+		}
+	}
+
+	if err := s.queue.Enqueue(ctx, jobs); err != nil {
+		return nil, fmt.Errorf("failed to queue schedule executions: %w", err)
+	}
+	logger.Info("Queued %d executions for schedule %s", len(jobs), schedule.ID)
+
+	return jobs, nil
+}
+
+// getScheduleRuns loads the prompts and enabled LLMs of a schedule
+func (s *SchedulerService) getScheduleRuns(ctx context.Context, schedule *models.Schedule) ([]*models.Prompt, []*models.LLMConfig) {
 	logger.Info("Schedule has %d prompts and %d LLMs", len(schedule.PromptIDs), len(schedule.LLMIDs))
 
 	prompts := make([]*models.Prompt, 0, len(schedule.PromptIDs))
@@ -248,89 +315,71 @@ func (s *SchedulerService) executeSchedule(ctx context.Context, schedule *models
 	}
 
 	logger.Info("Found %d prompts and %d enabled LLMs", len(prompts), len(llms))
+	return prompts, llms
+}
 
-	var wg sync.WaitGroup
-	executionCount := 0
-	for _, prompt := range prompts {
-		for _, llmConfig := range llms {
-			wg.Add(1)
-			executionCount++
-			go func(p *models.Prompt, l *models.LLMConfig) {
-				defer wg.Done()
-				logger.Debug("Executing prompt '%s' with LLM '%s'", p.Template, l.Name)
-
-				currentTemperature := schedule.Temperature
-				if schedule.Temperature == -1.0 { // Special value indicating "random" was selected
-					rand.Seed(time.Now().UnixNano())
-					currentTemperature = rand.Float64()
-					logger.Debug("Generated random temperature %.1f for prompt '%s'", currentTemperature, p.Template)
-				}
-
-				if err := s.executePromptWithRetry(ctx, schedule.ID, p, l, currentTemperature, DefaultMaxRetries, DefaultRetryDelay); err != nil {
-					logger.Error("Failed to execute prompt %s with LLM %s after all retries: %v", p.ID, l.ID, err)
-				} else {
-					logger.Debug("Successfully executed prompt %s with LLM %s", p.ID, l.ID)
-				}
-			}(prompt, llmConfig)
-		}
+// scheduleTemperature returns the temperature for one execution of a schedule
+func scheduleTemperature(schedule *models.Schedule) float64 {
+	if schedule.Temperature == -1.0 { // Special value indicating "random" was selected
+		return rand.Float64()
 	}
+	return schedule.Temperature
+}
 
-	logger.Info("Starting %d concurrent executions", executionCount)
-	wg.Wait()
-	logger.Info("Completed %d executions", executionCount)
+// ExecuteJob runs one queued prompt/LLM pair of a schedule
+func (s *SchedulerService) ExecuteJob(ctx context.Context, job *models.Job) error {
+	prompt, err := s.db.GetPrompt(ctx, job.PromptID)
+	if err != nil {
+		return fmt.Errorf("failed to get prompt: %w", err)
+	}
 
-	now := time.Now()
-	schedule.LastRun = &now
-	if err := s.db.UpdateSchedule(ctx, schedule); err != nil {
-		logger.Error("Failed to update schedule last run: %v", err)
+	llmConfig, err := s.db.GetLLM(ctx, job.LLMID)
+	if err != nil {
+		return fmt.Errorf("failed to get LLM: %w", err)
+	}
+	if !llmConfig.Enabled {
+		logger.Warning("LLM %s is disabled, skipping", llmConfig.Name)
+		return ErrJobSkipped
 	}
 
-	logger.Info("Completed schedule: %s", schedule.ID)
-	return nil
+	return s.executePromptWithLLM(ctx, job.ScheduleID, prompt, job.Variables, llmConfig, job.Temperature)
 }
 
-// executePromptWithRetry executes a prompt with retry mechanism
-func (s *SchedulerService) executePromptWithRetry(ctx context.Context, scheduleID string, prompt *models.Prompt, llmConfig *models.LLMConfig, temperature float64, maxRetries int, retryDelay time.Duration) error {
-	var lastErr error
-
-	for attempt := 1; attempt <= maxRetries; attempt++ {
-		logger.Debug("Attempt %d/%d for prompt '%s' with LLM '%s'", attempt, maxRetries, prompt.Template[:min(50, len(prompt.Template))]+"...", llmConfig.Name)
-
-		err := s.executePromptWithLLM(ctx, scheduleID, prompt, llmConfig, temperature)
-		if err == nil {
-			if attempt > 1 {
-				logger.Info("✅ Prompt execution succeeded on attempt %d after %d previous failures", attempt, attempt-1)
-			}
-			return nil
-		}
-
-		lastErr = err
-		logger.Warning("❌ Attempt %d/%d failed for prompt '%s' with LLM '%s': %v", attempt, maxRetries, prompt.Template[:min(50, len(prompt.Template))]+"...", llmConfig.Name, err)
-
-		retryDelayToUse := retryDelay
-		if strings.Contains(err.Error(), "429") || strings.Contains(err.Error(), "quota") || strings.Contains(err.Error(), "rate limit") {
-			retryDelayToUse = 2 * time.Minute // Wait 2 minutes for rate limit errors
-			logger.Info("Rate limit detected, using extended retry delay: %v", retryDelayToUse)
-		}
+// JobFinished records dead-lettered schedule executions as error responses
+func (s *SchedulerService) JobFinished(ctx context.Context, job *models.Job, jobErr error) {
+	if jobErr == nil {
+		return
+	}
 
-		if attempt < maxRetries {
-			logger.Info("⏳ Waiting %v before retry attempt %d...", retryDelayToUse, attempt+1)
-			time.Sleep(retryDelayToUse)
-		}
+	prompt, err := s.db.GetPrompt(ctx, job.PromptID)
+	if err != nil {
+		prompt = &models.Prompt{ID: job.PromptID, Brand: job.Brand}
+	}
+	llmConfig, err := s.db.GetLLM(ctx, job.LLMID)
+	if err != nil {
+		llmConfig = &models.LLMConfig{ID: job.LLMID}
 	}
 
-	logger.Error("💥 All %d attempts failed for prompt '%s' with LLM '%s'. Last error: %v", maxRetries, prompt.Template[:min(50, len(prompt.Template))]+"...", llmConfig.Name, lastErr)
-	return fmt.Errorf("failed after %d attempts, last error: %w", maxRetries, lastErr)
+	if err := s.saveErrorResponse(ctx, job.ScheduleID, prompt, job.Variables, llmConfig, job.Temperature, jobErr); err != nil {
+		logger.Error("Failed to save error response for schedule %s: %v", job.ScheduleID, err)
+	}
 }
 
-// executePromptWithLLM executes a single prompt with a single LLM
-func (s *SchedulerService) executePromptWithLLM(ctx context.Context, scheduleID string, prompt *models.Prompt, llmConfig *models.LLMConfig, temperature float64) error {
-	logger.Info("Starting execution: prompt='%s' LLM='%s' provider='%s' temperature=%.2f", prompt.Template, llmConfig.Name, llmConfig.Provider, temperature)
+// executePromptWithLLM executes a single prompt with a single LLM, binding
+// the prompt's template variables to the given values
+func (s *SchedulerService) executePromptWithLLM(ctx context.Context, scheduleID string, prompt *models.Prompt, variables map[string]string, llmConfig *models.LLMConfig, temperature float64) error {
+	brand := boundBrand(prompt.Brand, variables)
+	promptText, variables, err := BindPrompt(prompt.Template, variables, brand)
+	if err != nil {
+		return err
+	}
+
+	logger.Info("Starting execution: prompt='%s' LLM='%s' provider='%s' temperature=%.2f", promptText, llmConfig.Name, llmConfig.Provider, temperature)
 
-	provider, ok := s.llmRegistry.Get(llmConfig.Provider)
-	if !ok {
-		logger.Error("Provider not found: %s", llmConfig.Provider)
-		return fmt.Errorf("provider not found: %s", llmConfig.Provider)
+	provider, err := s.llmFactory.Get(llmConfig)
+	if err != nil {
+		logger.Error("Provider not available for LLM %s: %v", llmConfig.Name, err)
+		return fmt.Errorf("provider not available: %w", err)
 	}
 	logger.Debug("Found provider for: %s", llmConfig.Provider)
 
@@ -346,6 +395,8 @@ func (s *SchedulerService) executePromptWithLLM(ctx context.Context, scheduleID
 		Model:       llmConfig.Model,
 		Temperature: temperature,
 		MaxTokens:   1000,
+		Brand:       brand,
+		WebSearch:   llm.WebSearchEnabled(llmConfig),
 	}
 
 	if llmConfig.Config != nil {
@@ -378,28 +429,14 @@ func (s *SchedulerService) executePromptWithLLM(ctx context.Context, scheduleID
 
 	logger.Debug("Prepared config for LLM: model=%s temperature=%.2f api_key=%s base_url=%s", llmConfig.Model, temperature, maskAPIKey(llmConfig.APIKey), llmConfig.BaseURL)
 
-	logger.Debug("[%s] Calling LLM provider with prompt: %s", llmConfig.Name, prompt.Template[:min(50, len(prompt.Template))]+"...")
+	logger.Debug("[%s] Calling LLM provider with prompt: %s", llmConfig.Name, promptText[:min(50, len(promptText))]+"...")
 	startTime := time.Now()
-	resp, err := provider.Generate(ctx, prompt.Template, llmConfigStruct)
+	resp, err := provider.Generate(ctx, promptText, llmConfigStruct)
 	duration := time.Since(startTime)
 
 	if err != nil {
 		logger.Error("[%s] LLM call failed after %v: %v", llmConfig.Name, duration, err)
-		response := &models.Response{
-			ID:          uuid.New().String(),
-			PromptID:    prompt.ID,
-			PromptText:  prompt.Template,
-			LLMID:       llmConfig.ID,
-			LLMName:     llmConfig.Name,
-			LLMProvider: llmConfig.Provider,
-			LLMModel:    llmConfig.Model,
-			Temperature: temperature,
-			Error:       err.Error(),
-			ScheduleID:  scheduleID,
-			LatencyMs:   time.Since(startTime).Milliseconds(),
-			CreatedAt:   time.Now(),
-		}
-		return s.db.CreateResponse(ctx, response)
+		return fmt.Errorf("LLM call failed: %w", err)
 	}
 
 	logger.Info("[%s] LLM call succeeded after %v, response length: %d", llmConfig.Name, duration, len(resp.Text))
@@ -407,20 +444,62 @@ func (s *SchedulerService) executePromptWithLLM(ctx context.Context, scheduleID
 	response := &models.Response{
 		ID:           uuid.New().String(),
 		PromptID:     prompt.ID,
-		PromptText:   prompt.Template,
+		PromptText:   promptText,
 		LLMID:        llmConfig.ID,
 		LLMName:      llmConfig.Name,
 		LLMProvider:  llmConfig.Provider,
 		LLMModel:     llmConfig.Model,
 		ResponseText: resp.Text,
+		Brand:        brand,
 		Temperature:  temperature,
 		ScheduleID:   scheduleID,
+		Variables:    variables,
 		TokensUsed:   resp.TokensUsed,
 		LatencyMs:    resp.LatencyMs,
 		Error:        resp.Error,
 		CreatedAt:    time.Now(),
 	}
+	ApplyPromptMetadata(response, prompt)
+	ApplyCitations(response, resp)
+
+	if brand != "" && resp.Error == "" {
+		matcher := s.brandProfiles.GetMatcher(ctx, brand)
+		geoAnalysis, err := s.geoAnalyzer.Analyze(ctx, promptText, brand, resp, llmConfig)
+		if err != nil {
+			logger.Warning("[%s] GEO analysis failed for brand %s: %v", llmConfig.Name, brand, err)
+		} else {
+			ApplyGEOAnalysis(response, geoAnalysis, resp.GroundingSources, matcher)
+		}
+		ApplyBrandExtraction(response, response.ResponseText, matcher)
+	}
+
+	return saveGeneratedResponse(ctx, s.db, response)
+}
+
+// saveErrorResponse records a failed execution as an error response
+func (s *SchedulerService) saveErrorResponse(ctx context.Context, scheduleID string, prompt *models.Prompt, variables map[string]string, llmConfig *models.LLMConfig, temperature float64, execErr error) error {
+	brand := boundBrand(prompt.Brand, variables)
+	promptText := prompt.Template
+	if text, bound, err := BindPrompt(prompt.Template, variables, brand); err == nil {
+		promptText, variables = text, bound
+	}
 
+	response := &models.Response{
+		ID:          uuid.New().String(),
+		PromptID:    prompt.ID,
+		PromptText:  promptText,
+		LLMID:       llmConfig.ID,
+		LLMName:     llmConfig.Name,
+		LLMProvider: llmConfig.Provider,
+		LLMModel:    llmConfig.Model,
+		Brand:       brand,
+		Temperature: temperature,
+		Error:       execErr.Error(),
+		ScheduleID:  scheduleID,
+		Variables:   variables,
+		CreatedAt:   time.Now(),
+	}
+	ApplyPromptMetadata(response, prompt)
 	return s.db.CreateResponse(ctx, response)
 }
 
@@ -451,6 +530,9 @@ func maskAPIKey(apiKey string) string {
 	if apiKey == "" {
 		return "(not set)"
 	}
+	if secrets.IsReference(apiKey) {
+		return apiKey
+	}
 	if len(apiKey) <= 8 {
 		return "***"
 	}
@@ -0,0 +1,55 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fissionx/gego/internal/llm/providers"
+	"github.com/fissionx/gego/internal/models"
+	"github.com/fissionx/gego/internal/shared"
+)
+
+func TestExecuteNowRunsThroughTheQueue(t *testing.T) {
+	ctx := context.Background()
+	database := newTestDB(t)
+	queue := NewJobQueueService(database)
+	scheduler := NewSchedulerService(database, providers.NewReplayFactory("testdata/replay"), queue)
+
+	if err := database.CreateLLM(ctx, &models.LLMConfig{ID: "gpt", Name: "GPT", Provider: "openai", Model: "gpt-4o", Enabled: true}); err != nil {
+		t.Fatalf("CreateLLM() error = %v", err)
+	}
+	if err := database.CreatePrompt(ctx, &models.Prompt{ID: "best-crm", Template: "Best CRM in {{city}}?", Enabled: true}); err != nil {
+		t.Fatalf("CreatePrompt() error = %v", err)
+	}
+	schedule := &models.Schedule{ID: "weekly", Name: "weekly", PromptIDs: []string{"best-crm"}, LLMIDs: []string{"gpt"},
+		CronExpr: "0 9 * * 1", Temperature: 0.7, Variables: map[string][]string{"city": {"Austin"}}, Enabled: true}
+	if err := database.CreateSchedule(ctx, schedule); err != nil {
+		t.Fatalf("CreateSchedule() error = %v", err)
+	}
+
+	if err := scheduler.ExecuteNow(ctx, "weekly"); err != nil {
+		t.Fatalf("ExecuteNow() error = %v", err)
+	}
+
+	// The execution ran as a job, and the queue started for it is stopped again
+	jobs, err := database.ListJobs(ctx, "", 0)
+	if err != nil || len(jobs) != 1 || jobs[0].Status != models.JobStatusDone || jobs[0].ScheduleID != "weekly" {
+		t.Fatalf("ListJobs() = %+v, %v; want one done job of the schedule", jobs, err)
+	}
+	if queue.Start(1) {
+		queue.Stop()
+	} else {
+		t.Error("queue still running after ExecuteNow()")
+	}
+
+	responses, err := database.ListResponses(ctx, shared.ResponseFilter{ScheduleID: "weekly"})
+	if err != nil || len(responses) != 1 {
+		t.Fatalf("ListResponses() = %d responses, %v; want 1", len(responses), err)
+	}
+	if responses[0].PromptText != "Best CRM in Austin?" || responses[0].TokensUsed != 180 {
+		t.Errorf("response = %+v", responses[0])
+	}
+	if stored, err := database.GetSchedule(ctx, "weekly"); err != nil || stored.LastRun == nil {
+		t.Errorf("schedule last run = %+v, %v", stored, err)
+	}
+}
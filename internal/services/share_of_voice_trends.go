@@ -0,0 +1,293 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fissionx/gego/internal/models"
+	"github.com/fissionx/gego/internal/shared"
+)
+
+// ErrInvalidTrendInterval is returned for intervals that are neither a period nor a duration
+var ErrInvalidTrendInterval = errors.New("invalid interval")
+
+// maxTrendCompetitors is the number of competitors charted when none are requested
+const maxTrendCompetitors = 5
+
+// trendInterval buckets responses by their stored week, month or quarter, or
+// by a fixed duration from an origin
+type trendInterval struct {
+	period   string
+	duration time.Duration
+	origin   time.Time
+}
+
+// parseTrendInterval parses week, month, quarter, or a duration such as 7d or
+// 12h. Duration buckets start at start, or on the Unix epoch, to the second.
+func parseTrendInterval(interval string, start *time.Time) (trendInterval, error) {
+	switch interval {
+	case "":
+		return trendInterval{period: shared.PeriodWeek}, nil
+	case shared.PeriodWeek, shared.PeriodMonth, shared.PeriodQuarter:
+		return trendInterval{period: interval}, nil
+	}
+
+	var duration time.Duration
+	if days, ok := strings.CutSuffix(interval, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return trendInterval{}, fmt.Errorf("%w %q: use week, month, quarter or a duration such as 7d", ErrInvalidTrendInterval, interval)
+		}
+		duration = time.Duration(n) * 24 * time.Hour
+	} else {
+		var err error
+		if duration, err = time.ParseDuration(interval); err != nil {
+			return trendInterval{}, fmt.Errorf("%w %q: use week, month, quarter or a duration such as 7d", ErrInvalidTrendInterval, interval)
+		}
+	}
+	if duration < time.Hour {
+		return trendInterval{}, fmt.Errorf("%w %q: durations must be at least an hour", ErrInvalidTrendInterval, interval)
+	}
+
+	origin := time.Unix(0, 0).UTC()
+	if start != nil {
+		origin = start.UTC().Truncate(time.Second)
+	}
+	return trendInterval{duration: duration.Truncate(time.Second), origin: origin}, nil
+}
+
+// group returns the grouping of the responses in the database
+func (i trendInterval) group() shared.ResponseGroup {
+	switch i.period {
+	case shared.PeriodWeek:
+		return shared.GroupByWeek
+	case shared.PeriodMonth:
+		return shared.GroupByMonth
+	case shared.PeriodQuarter:
+		return shared.GroupByQuarter
+	}
+	return shared.GroupByInterval(i.origin, i.duration)
+}
+
+// bounds returns the start and end of the bucket of a group key
+func (i trendInterval) bounds(key string) (time.Time, time.Time, error) {
+	if i.duration == 0 {
+		start, err := shared.ParsePeriod(i.period, key)
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		end, err := shared.PeriodEnd(i.period, start)
+		return start, end, err
+	}
+
+	start, err := time.Parse(time.RFC3339, key)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid interval start: %s", key)
+	}
+	return start.UTC(), start.UTC().Add(i.duration), nil
+}
+
+// trendStats accumulates the metrics of a brand over a period
+type trendStats struct {
+	mentions    int
+	responses   int
+	visibility  int
+	positionSum int
+	positions   int
+}
+
+// add counts the responses of the brand itself
+func (t *trendStats) add(metrics *models.ResponseMetrics) {
+	t.responses += metrics.TotalResponses
+	t.visibility += metrics.TotalVisibility
+	t.positionSum += metrics.PositionSum
+	t.positions += metrics.PositionCount
+}
+
+// trendBucket accumulates the metrics of the brands over a period, main brand first
+type trendBucket struct {
+	models.TrendBucket
+	brands []trendStats
+}
+
+// GetShareOfVoiceTrends buckets the responses of a brand by period and returns
+// the mentions, share of voice, visibility and position of the brand and of
+// its competitors in each
+func (s *CompetitiveBenchmarkService) GetShareOfVoiceTrends(ctx context.Context, req *models.ShareOfVoiceTrendsRequest) (*models.ShareOfVoiceTrendsResponse, error) {
+	interval, err := parseTrendInterval(req.Interval, req.StartTime)
+	if err != nil {
+		return nil, err
+	}
+	groupBy := interval.group()
+
+	// Aggregate the main brand's responses per bucket in the database
+	filter := shared.ResponseFilter{
+		Brand:     req.MainBrand,
+		Region:    req.Region,
+		PromptIDs: req.PromptIDs,
+		LLMIDs:    req.LLMIDs,
+		StartTime: req.StartTime,
+		EndTime:   req.EndTime,
+	}
+	mainMetrics, err := s.db.AggregateResponseMetrics(ctx, filter, groupBy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate responses: %w", err)
+	}
+	competitorCounts, err := s.db.CountGroupedCompetitorMentions(ctx, filter, groupBy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count competitor mentions: %w", err)
+	}
+
+	competitors := req.Competitors
+	if len(competitors) == 0 {
+		competitors = s.mostMentionedCompetitors(ctx, req.MainBrand, competitorCounts, maxTrendCompetitors)
+	}
+	brands := append([]string{req.MainBrand}, competitors...)
+	matchers := s.brandProfiles.GetMatchers(ctx, competitors)
+
+	buckets := make(map[string]*trendBucket)
+	bucketOf := func(key string) (*trendBucket, error) {
+		bucket, ok := buckets[key]
+		if !ok {
+			start, end, err := interval.bounds(key)
+			if err != nil {
+				return nil, err
+			}
+			bucket = &trendBucket{
+				TrendBucket: models.TrendBucket{Period: key, Start: start, End: end},
+				brands:      make([]trendStats, len(brands)),
+			}
+			buckets[key] = bucket
+		}
+		return bucket, nil
+	}
+
+	// Mentions of every brand in the responses of the main brand
+	for _, metrics := range mainMetrics {
+		bucket, err := bucketOf(metrics.Key)
+		if err != nil {
+			return nil, err
+		}
+		bucket.TotalResponses += metrics.TotalResponses
+		bucket.brands[0].add(metrics)
+		bucket.brands[0].mentions += metrics.MentionCount
+	}
+	for key, counts := range competitorCounts {
+		bucket, err := bucketOf(key)
+		if err != nil {
+			return nil, err
+		}
+		for i, comp := range competitors {
+			mentions := 0
+			for name, count := range counts {
+				if matchers[comp].Matches(name) {
+					mentions += count
+				}
+			}
+			// Aliases listed together in a response count once
+			bucket.brands[i+1].mentions += min(mentions, bucket.TotalResponses)
+		}
+	}
+
+	// Visibility and position of the competitors that are tracked themselves
+	for i, comp := range competitors {
+		filter.Brand = comp
+		compMetrics, err := s.db.AggregateResponseMetrics(ctx, filter, groupBy)
+		if err != nil {
+			return nil, fmt.Errorf("failed to aggregate responses: %w", err)
+		}
+		for _, metrics := range compMetrics {
+			bucket, err := bucketOf(metrics.Key)
+			if err != nil {
+				return nil, err
+			}
+			bucket.brands[i+1].add(metrics)
+		}
+	}
+
+	result := &models.ShareOfVoiceTrendsResponse{
+		MainBrand:   req.MainBrand,
+		Competitors: competitors,
+		Interval:    req.Interval,
+		Buckets:     make([]models.TrendBucket, 0, len(buckets)),
+	}
+	if result.Interval == "" {
+		result.Interval = shared.PeriodWeek
+	}
+	if result.Competitors == nil {
+		result.Competitors = []string{}
+	}
+
+	for _, bucket := range buckets {
+		totalMentions := 0
+		for _, stats := range bucket.brands {
+			totalMentions += stats.mentions
+		}
+
+		for i, stats := range bucket.brands {
+			point := models.BrandTrendPoint{
+				Brand:         brands[i],
+				Mentions:      stats.mentions,
+				ResponseCount: stats.responses,
+			}
+			if bucket.TotalResponses > 0 {
+				point.MentionRate = float64(stats.mentions) / float64(bucket.TotalResponses) * 100
+			}
+			if totalMentions > 0 {
+				point.ShareOfVoice = float64(stats.mentions) / float64(totalMentions) * 100
+			}
+			if stats.responses > 0 {
+				point.Visibility = float64(stats.visibility) / float64(stats.responses)
+			}
+			if stats.positions > 0 {
+				point.AveragePosition = float64(stats.positionSum) / float64(stats.positions)
+			}
+			bucket.Brands = append(bucket.Brands, point)
+		}
+		result.Buckets = append(result.Buckets, bucket.TrendBucket)
+	}
+
+	sort.Slice(result.Buckets, func(i, j int) bool {
+		return result.Buckets[i].Start.Before(result.Buckets[j].Start)
+	})
+
+	return result, nil
+}
+
+// mostMentionedCompetitors returns the competitors mentioned in the most
+// responses across the buckets, skipping aliases of the main brand
+func (s *CompetitiveBenchmarkService) mostMentionedCompetitors(ctx context.Context, mainBrand string, bucketCounts map[string]map[string]int, n int) []string {
+	mainMatcher := s.brandProfiles.GetMatcher(ctx, mainBrand)
+
+	counts := make(map[string]int)
+	for _, bucket := range bucketCounts {
+		for comp, count := range bucket {
+			name := strings.TrimSpace(comp)
+			if name == "" || strings.EqualFold(name, mainBrand) || mainMatcher.Matches(name) {
+				continue
+			}
+			counts[name] += count
+		}
+	}
+
+	competitors := make([]string, 0, len(counts))
+	for name := range counts {
+		competitors = append(competitors, name)
+	}
+	sort.Slice(competitors, func(i, j int) bool {
+		if counts[competitors[i]] != counts[competitors[j]] {
+			return counts[competitors[i]] > counts[competitors[j]]
+		}
+		return competitors[i] < competitors[j]
+	})
+
+	if len(competitors) > n {
+		competitors = competitors[:n]
+	}
+	return competitors
+}
@@ -0,0 +1,81 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/fissionx/gego/internal/models"
+)
+
+func TestShareOfVoiceTrends(t *testing.T) {
+	ctx := context.Background()
+	database := newTestDB(t)
+
+	// Two responses for HubSpot in the last week of 2024, which is ISO week
+	// 2025-W01, and one in the second week of 2025
+	responses := []*models.Response{
+		{Brand: "HubSpot", BrandMentioned: true, VisibilityScore: 8, BrandPosition: 2, CompetitorsMention: []string{"Salesforce"},
+			CreatedAt: time.Date(2024, 12, 30, 9, 0, 0, 0, time.UTC)},
+		{Brand: "HubSpot", CompetitorsMention: []string{"Salesforce", "Zoho"},
+			CreatedAt: time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)},
+		{Brand: "HubSpot", BrandMentioned: true, VisibilityScore: 6, BrandPosition: 1,
+			CreatedAt: time.Date(2025, 1, 8, 9, 0, 0, 0, time.UTC)},
+		{Brand: "Salesforce", BrandMentioned: true, VisibilityScore: 9, BrandPosition: 1,
+			CreatedAt: time.Date(2024, 12, 31, 9, 0, 0, 0, time.UTC)},
+	}
+	for i, response := range responses {
+		response.ID = fmt.Sprintf("r%d", i)
+		if err := database.CreateResponse(ctx, response); err != nil {
+			t.Fatalf("CreateResponse() error = %v", err)
+		}
+	}
+	if responses[0].Week != "2025-W01" || responses[0].Month != "2024-12" || responses[0].Quarter != "2024-Q4" {
+		t.Errorf("stored periods = %s %s %s", responses[0].Week, responses[0].Month, responses[0].Quarter)
+	}
+
+	service := NewCompetitiveBenchmarkService(database)
+	trends, err := service.GetShareOfVoiceTrends(ctx, &models.ShareOfVoiceTrendsRequest{MainBrand: "HubSpot"})
+	if err != nil {
+		t.Fatalf("GetShareOfVoiceTrends() error = %v", err)
+	}
+	if len(trends.Competitors) != 2 || trends.Competitors[0] != "Salesforce" {
+		t.Fatalf("competitors = %v, want the most mentioned first", trends.Competitors)
+	}
+	if len(trends.Buckets) != 2 || trends.Buckets[0].Period != "2025-W01" || trends.Buckets[1].Period != "2025-W02" {
+		t.Fatalf("buckets = %+v", trends.Buckets)
+	}
+
+	first := trends.Buckets[0]
+	if !first.Start.Equal(time.Date(2024, 12, 30, 0, 0, 0, 0, time.UTC)) || first.TotalResponses != 2 {
+		t.Errorf("first bucket starts %v with %d responses", first.Start, first.TotalResponses)
+	}
+	hubspot, salesforce := first.Brands[0], first.Brands[1]
+	if hubspot.Mentions != 1 || hubspot.MentionRate != 50 || hubspot.ShareOfVoice != 25 || hubspot.Visibility != 4 || hubspot.AveragePosition != 2 {
+		t.Errorf("HubSpot = %+v", hubspot)
+	}
+	if salesforce.Mentions != 2 || salesforce.ShareOfVoice != 50 || salesforce.Visibility != 9 || salesforce.ResponseCount != 1 {
+		t.Errorf("Salesforce = %+v", salesforce)
+	}
+
+	// Duration buckets start at the start time
+	start := time.Date(2024, 12, 25, 0, 0, 0, 0, time.UTC)
+	trends, err = service.GetShareOfVoiceTrends(ctx, &models.ShareOfVoiceTrendsRequest{
+		MainBrand: "HubSpot", Competitors: []string{"Zoho"}, Interval: "10d", StartTime: &start,
+	})
+	if err != nil {
+		t.Fatalf("GetShareOfVoiceTrends(10d) error = %v", err)
+	}
+	if len(trends.Buckets) != 2 || trends.Buckets[0].Period != "2024-12-25T00:00:00Z" || trends.Buckets[1].TotalResponses != 1 {
+		t.Fatalf("10d buckets = %+v", trends.Buckets)
+	}
+	if zoho := trends.Buckets[0].Brands[1]; zoho.Mentions != 1 || zoho.ShareOfVoice != 50 {
+		t.Errorf("Zoho = %+v", zoho)
+	}
+
+	if _, err := service.GetShareOfVoiceTrends(ctx, &models.ShareOfVoiceTrendsRequest{MainBrand: "HubSpot", Interval: "30m"}); !errors.Is(err, ErrInvalidTrendInterval) {
+		t.Errorf("GetShareOfVoiceTrends(30m) error = %v, want ErrInvalidTrendInterval", err)
+	}
+}
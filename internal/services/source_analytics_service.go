@@ -37,67 +37,42 @@ func (s *SourceAnalyticsService) GetSourceAnalytics(
 		topN = 20
 	}
 	
-	// Fetch all responses for the brand
+	// Aggregate the brand's responses and cited domains in the database
 	filter := shared.ResponseFilter{
+		Brand:     brand,
 		StartTime: startTime,
 		EndTime:   endTime,
-		Limit:     10000,
 	}
 	
-	allResponses, err := s.db.ListResponses(ctx, filter)
+	totals, err := s.db.AggregateResponseMetrics(ctx, filter, shared.GroupByNone)
 	if err != nil {
 		return nil, err
 	}
 	
-	// Filter for brand
-	var brandResponses []*models.Response
-	for _, resp := range allResponses {
-		if resp.Brand == brand {
-			brandResponses = append(brandResponses, resp)
-		}
+	domainStats, err := s.db.AggregateSourceDomains(ctx, filter)
+	if err != nil {
+		return nil, err
 	}
 	
-	// Aggregate source data
-	domainStats := make(map[string]*sourceStats)
-	totalCitations := 0
+	totalResponses := 0
 	brandInSources := false
-	
-	for _, resp := range brandResponses {
-		// Count grounding domains
-		for _, domain := range resp.GroundingDomains {
-			if domain == "" {
-				continue
-			}
-			
-			if _, exists := domainStats[domain]; !exists {
-				domainStats[domain] = &sourceStats{
-					domain:       domain,
-					llmBreakdown: make(map[string]int),
-				}
-			}
-			
-			domainStats[domain].citationCount++
-			domainStats[domain].llmBreakdown[resp.LLMName]++
-			totalCitations++
-		}
-		
-		// Check if brand is in sources
-		if resp.InGroundingSources {
-			brandInSources = true
-		}
+	if len(totals) > 0 {
+		totalResponses = totals[0].TotalResponses
+		brandInSources = totals[0].GroundedCount > 0
 	}
 	
 	// Convert to SourceInsight slice
 	var sourceInsights []models.SourceInsight
-	totalResponses := len(brandResponses)
+	totalCitations := 0
 	
-	for domain, stats := range domainStats {
+	for _, stats := range domainStats {
+		totalCitations += stats.CitationCount
 		insight := models.SourceInsight{
-			Domain:        domain,
-			CitationCount: stats.citationCount,
-			MentionRate:   float64(stats.citationCount) / float64(totalResponses) * 100,
-			LLMBreakdown:  stats.llmBreakdown,
-			Categories:    categorizeSource(domain),
+			Domain:        stats.Domain,
+			CitationCount: stats.CitationCount,
+			MentionRate:   float64(stats.CitationCount) / float64(totalResponses) * 100,
+			LLMBreakdown:  stats.LLMBreakdown,
+			Categories:    categorizeSource(stats.Domain),
 		}
 		sourceInsights = append(sourceInsights, insight)
 	}
@@ -138,12 +113,6 @@ func (s *SourceAnalyticsService) GetSourceAnalytics(
 		Recommendations: recommendations,
 		TotalSources:    len(domainStats),
 		TotalCitations:  totalCitations,
+		BrandInSources:  brandInSources,
 	}, nil
 }
-
-type sourceStats struct {
-	domain        string
-	citationCount int
-	llmBreakdown  map[string]int
-}
-
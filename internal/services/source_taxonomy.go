@@ -0,0 +1,446 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/fissionx/gego/internal/db"
+	"github.com/fissionx/gego/internal/models"
+)
+
+// SourceCategoryDefault is the category of domains no rule matches, most of
+// which are company websites
+const SourceCategoryDefault = "company_website"
+
+// SourceOverridesFile is the file the source overrides are kept in, next to
+// the source taxonomy file
+const SourceOverridesFile = "source_overrides.yaml"
+
+// defaultSourcePack is always enabled, after the packs of the taxonomy file
+var defaultSourcePack = []models.SourceRule{
+	{Suffix: "g2.com", Category: "review_site"},
+	{Suffix: "capterra.com", Category: "review_site"},
+	{Suffix: "trustpilot.com", Category: "review_site"},
+	{Suffix: "yelp.com", Category: "review_site"},
+	{Suffix: "tripadvisor.com", Category: "review_site"},
+	{Suffix: "reddit.com", Category: "social_media"},
+	{Suffix: "twitter.com", Category: "social_media"},
+	{Suffix: "x.com", Category: "social_media"},
+	{Suffix: "linkedin.com", Category: "social_media"},
+	{Suffix: "facebook.com", Category: "social_media"},
+	{Suffix: "instagram.com", Category: "social_media"},
+	{Suffix: "youtube.com", Category: "social_media"},
+	{Suffix: "quora.com", Category: "community"},
+	{Suffix: "stackoverflow.com", Category: "community"},
+	{Suffix: "news.ycombinator.com", Category: "community"},
+	{Suffix: "nytimes.com", Category: "news"},
+	{Suffix: "wsj.com", Category: "news"},
+	{Suffix: "bbc.com", Category: "news"},
+	{Suffix: "bbc.co.uk", Category: "news"},
+	{Suffix: "cnn.com", Category: "news"},
+	{Suffix: "reuters.com", Category: "news"},
+	{Suffix: "bloomberg.com", Category: "news"},
+	{Suffix: "techcrunch.com", Category: "news"},
+	{Suffix: "theverge.com", Category: "news"},
+	{Suffix: "theguardian.com", Category: "news"},
+	{Suffix: "forbes.com", Category: "publication"},
+	{Suffix: "inc.com", Category: "publication"},
+	{Suffix: "entrepreneur.com", Category: "publication"},
+	{Suffix: "wired.com", Category: "publication"},
+	{Suffix: "arstechnica.com", Category: "publication"},
+	{Suffix: "medium.com", Category: "blog"},
+	{Suffix: "substack.com", Category: "blog"},
+	{Suffix: "wikipedia.org", Category: "reference"},
+	{Regex: `\.(edu|ac\.[a-z]{2})$`, Category: "education"},
+	{Regex: `\.gov(\.[a-z]{2})?$`, Category: "government"},
+}
+
+// sourcePacks are the industry packs a taxonomy file can enable
+var sourcePacks = map[string][]models.SourceRule{
+	"saas": {
+		{Suffix: "trustradius.com", Category: "review_site"},
+		{Suffix: "getapp.com", Category: "review_site"},
+		{Suffix: "softwareadvice.com", Category: "review_site"},
+		{Suffix: "producthunt.com", Category: "review_site"},
+		{Suffix: "gartner.com", Category: "analyst"},
+		{Suffix: "forrester.com", Category: "analyst"},
+		{Suffix: "zdnet.com", Category: "publication"},
+		{Suffix: "techradar.com", Category: "publication"},
+		{Suffix: "pcmag.com", Category: "publication"},
+		{Suffix: "github.com", Category: "community"},
+	},
+	"ecommerce": {
+		{Suffix: "amazon.com", Category: "marketplace"},
+		{Suffix: "ebay.com", Category: "marketplace"},
+		{Suffix: "etsy.com", Category: "marketplace"},
+		{Suffix: "walmart.com", Category: "marketplace"},
+		{Suffix: "rtings.com", Category: "review_site"},
+		{Suffix: "consumerreports.org", Category: "review_site"},
+		{Suffix: "goodhousekeeping.com", Category: "publication"},
+	},
+	"travel": {
+		{Suffix: "booking.com", Category: "booking_site"},
+		{Suffix: "expedia.com", Category: "booking_site"},
+		{Suffix: "airbnb.com", Category: "booking_site"},
+		{Suffix: "kayak.com", Category: "booking_site"},
+		{Suffix: "lonelyplanet.com", Category: "publication"},
+		{Suffix: "cntraveler.com", Category: "publication"},
+		{Suffix: "timeout.com", Category: "publication"},
+	},
+	"finance": {
+		{Suffix: "nerdwallet.com", Category: "publication"},
+		{Suffix: "bankrate.com", Category: "publication"},
+		{Suffix: "investopedia.com", Category: "reference"},
+		{Suffix: "fool.com", Category: "publication"},
+		{Suffix: "morningstar.com", Category: "analyst"},
+		{Suffix: "ft.com", Category: "news"},
+	},
+	"health": {
+		{Suffix: "webmd.com", Category: "health_reference"},
+		{Suffix: "mayoclinic.org", Category: "health_reference"},
+		{Suffix: "healthline.com", Category: "health_reference"},
+		{Suffix: "clevelandclinic.org", Category: "health_reference"},
+		{Suffix: "nih.gov", Category: "health_reference"},
+	},
+}
+
+var (
+	sourceCategoryPattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+	sourceDomainPattern   = regexp.MustCompile(`^[a-z0-9-]+(\.[a-z0-9-]+)+$`)
+)
+
+// sourceTaxonomy is the classifier of the taxonomy and overrides files
+var sourceTaxonomy = newReloadableConfig(loadSourceClassifier, func() *sourceClassifier {
+	classifier, _ := newSourceClassifier(models.SourceTaxonomy{}, nil)
+	return classifier
+})
+
+// SetSourceTaxonomyPath sets the path of the source taxonomy file from config.
+// Its overrides are kept in SourceOverridesFile in the same directory. Without
+// a path, domains are classified by the default pack only.
+func SetSourceTaxonomyPath(path string) {
+	if path == "" {
+		sourceTaxonomy.setPaths()
+		return
+	}
+	sourceTaxonomy.setPaths(path, filepath.Join(filepath.Dir(path), SourceOverridesFile))
+}
+
+// sourceRule is a compiled taxonomy rule
+type sourceRule struct {
+	models.SourceRule
+	regex *regexp.Regexp
+}
+
+func (r *sourceRule) matches(domain string) bool {
+	if r.regex != nil {
+		return r.regex.MatchString(domain)
+	}
+	return domain == r.Suffix || strings.HasSuffix(domain, "."+r.Suffix)
+}
+
+// sourceClassifier classifies domains by their overrides, then by the rules of
+// the taxonomy file, its packs and the default pack
+type sourceClassifier struct {
+	taxonomy  models.SourceTaxonomy
+	overrides map[string][]string
+	rules     []sourceRule
+}
+
+// newSourceClassifier validates a taxonomy and its overrides and compiles them
+func newSourceClassifier(taxonomy models.SourceTaxonomy, overrides map[string][]string) (*sourceClassifier, error) {
+	c := &sourceClassifier{taxonomy: taxonomy, overrides: make(map[string][]string, len(overrides))}
+
+	rules := append([]models.SourceRule{}, taxonomy.Rules...)
+	for _, pack := range taxonomy.Packs {
+		packRules, ok := sourcePacks[pack]
+		if !ok {
+			return nil, fmt.Errorf("unknown source pack %q: use one of %s", pack, strings.Join(SourcePacks(), ", "))
+		}
+		rules = append(rules, packRules...)
+	}
+	rules = append(rules, defaultSourcePack...)
+
+	for i, rule := range rules {
+		if !sourceCategoryPattern.MatchString(rule.Category) {
+			return nil, fmt.Errorf("rule %d: invalid category %q: use lowercase letters, digits and underscores", i+1, rule.Category)
+		}
+		switch {
+		case rule.Suffix != "" && rule.Regex != "":
+			return nil, fmt.Errorf("rule %d: set either suffix or regex, not both", i+1)
+		case rule.Regex != "":
+			regex, err := regexp.Compile(rule.Regex)
+			if err != nil {
+				return nil, fmt.Errorf("rule %d: invalid regex: %w", i+1, err)
+			}
+			c.rules = append(c.rules, sourceRule{SourceRule: rule, regex: regex})
+		case rule.Suffix != "":
+			rule.Suffix = normalizeSourceDomain(rule.Suffix)
+			c.rules = append(c.rules, sourceRule{SourceRule: rule})
+		default:
+			return nil, fmt.Errorf("rule %d: set a suffix or a regex", i+1)
+		}
+	}
+
+	for domain, categories := range overrides {
+		normalized, err := validateSourceOverride(domain, categories)
+		if err != nil {
+			return nil, err
+		}
+		c.overrides[normalized] = categories
+	}
+	return c, nil
+}
+
+// classify returns the categories of a domain
+func (c *sourceClassifier) classify(domain string) []string {
+	domain = normalizeSourceDomain(domain)
+
+	// An override covers the domain and its subdomains, the closest one winning
+	for parent := domain; parent != ""; {
+		if categories, ok := c.overrides[parent]; ok {
+			return categories
+		}
+		_, next, found := strings.Cut(parent, ".")
+		if !found {
+			break
+		}
+		parent = next
+	}
+
+	var categories []string
+	for i := range c.rules {
+		if c.rules[i].matches(domain) && !contains(categories, c.rules[i].Category) {
+			categories = append(categories, c.rules[i].Category)
+		}
+	}
+	if len(categories) == 0 {
+		return []string{SourceCategoryDefault}
+	}
+	return categories
+}
+
+// normalizeSourceDomain lowercases a domain and strips www. and a trailing dot
+func normalizeSourceDomain(domain string) string {
+	domain = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(domain)), ".")
+	return strings.TrimPrefix(domain, "www.")
+}
+
+// validateSourceOverride checks an override and returns its normalized domain
+func validateSourceOverride(domain string, categories []string) (string, error) {
+	normalized := normalizeSourceDomain(domain)
+	if !sourceDomainPattern.MatchString(normalized) {
+		return "", fmt.Errorf("invalid domain %q", domain)
+	}
+	if len(categories) == 0 {
+		return "", fmt.Errorf("override of %s has no categories", normalized)
+	}
+	for _, category := range categories {
+		if !sourceCategoryPattern.MatchString(category) {
+			return "", fmt.Errorf("invalid category %q: use lowercase letters, digits and underscores", category)
+		}
+	}
+	return normalized, nil
+}
+
+// loadSourceClassifier reads the taxonomy and overrides files. Missing files
+// leave the default pack in charge.
+func loadSourceClassifier(paths []string) (*sourceClassifier, error) {
+	var taxonomy models.SourceTaxonomy
+	var overrides models.SourceOverrides
+	// paths are the taxonomy and overrides files, or none when unconfigured
+	if len(paths) == 2 {
+		if err := readYAMLFile(paths[0], &taxonomy); err != nil {
+			return nil, err
+		}
+		if err := readYAMLFile(paths[1], &overrides); err != nil {
+			return nil, err
+		}
+	}
+
+	classifier, err := newSourceClassifier(taxonomy, overrides.Overrides)
+	if err != nil {
+		return nil, fmt.Errorf("invalid source taxonomy: %w", err)
+	}
+	return classifier, nil
+}
+
+// currentSourceClassifier returns the classifier of the taxonomy files
+func currentSourceClassifier() *sourceClassifier {
+	return sourceTaxonomy.get()
+}
+
+// categorizeSource categorizes a source domain with the source taxonomy
+func categorizeSource(domain string) []string {
+	return currentSourceClassifier().classify(domain)
+}
+
+// classifySources returns the sorted categories of a response's grounding domains
+func classifySources(domains []string) []string {
+	if len(domains) == 0 {
+		return nil
+	}
+
+	classifier := currentSourceClassifier()
+	var categories []string
+	for _, domain := range domains {
+		for _, category := range classifier.classify(domain) {
+			if !contains(categories, category) {
+				categories = append(categories, category)
+			}
+		}
+	}
+	sort.Strings(categories)
+	return categories
+}
+
+// SourcePacks lists the built-in industry packs
+func SourcePacks() []string {
+	packs := make([]string, 0, len(sourcePacks))
+	for pack := range sourcePacks {
+		packs = append(packs, pack)
+	}
+	sort.Strings(packs)
+	return packs
+}
+
+// SourceTaxonomyService manages the classification of cited domains
+type SourceTaxonomyService struct {
+	db db.Database
+}
+
+// NewSourceTaxonomyService creates a new source taxonomy service
+func NewSourceTaxonomyService(database db.Database) *SourceTaxonomyService {
+	return &SourceTaxonomyService{db: database}
+}
+
+// Taxonomy returns the rules and overrides in use. Unlike classification, it
+// fails when a taxonomy file is invalid.
+func (s *SourceTaxonomyService) Taxonomy() (*models.SourceTaxonomyResponse, error) {
+	classifier, _, err := sourceTaxonomy.loadNow()
+	if err != nil {
+		return nil, err
+	}
+
+	rules := classifier.taxonomy.Rules
+	if rules == nil {
+		rules = []models.SourceRule{}
+	}
+	packs := classifier.taxonomy.Packs
+	if packs == nil {
+		packs = []string{}
+	}
+	return &models.SourceTaxonomyResponse{
+		Packs:          packs,
+		AvailablePacks: SourcePacks(),
+		Rules:          rules,
+		Overrides:      classifier.overrides,
+	}, nil
+}
+
+// Classify returns the categories of each domain
+func (s *SourceTaxonomyService) Classify(domains []string) map[string][]string {
+	classifier := currentSourceClassifier()
+	categories := make(map[string][]string, len(domains))
+	for _, domain := range domains {
+		categories[domain] = classifier.classify(domain)
+	}
+	return categories
+}
+
+// SetOverride sets the categories of a domain and its subdomains, then
+// reclassifies the stored responses. It returns the number of responses whose
+// categories changed.
+func (s *SourceTaxonomyService) SetOverride(ctx context.Context, domain string, categories []string) (int, error) {
+	normalized, err := validateSourceOverride(domain, categories)
+	if err != nil {
+		return 0, err
+	}
+
+	err = updateSourceOverrides(func(overrides map[string][]string) {
+		overrides[normalized] = categories
+	})
+	if err != nil {
+		return 0, err
+	}
+	return s.Reclassify(ctx)
+}
+
+// DeleteOverride removes the override of a domain, then reclassifies the
+// stored responses
+func (s *SourceTaxonomyService) DeleteOverride(ctx context.Context, domain string) (int, error) {
+	normalized := normalizeSourceDomain(domain)
+
+	found := false
+	err := updateSourceOverrides(func(overrides map[string][]string) {
+		_, found = overrides[normalized]
+		delete(overrides, normalized)
+	})
+	if err != nil {
+		return 0, err
+	}
+	if !found {
+		return 0, fmt.Errorf("no override for %s", normalized)
+	}
+	return s.Reclassify(ctx)
+}
+
+// Reclassify recomputes the source categories of the stored responses from
+// their grounding domains, after the taxonomy changed
+func (s *SourceTaxonomyService) Reclassify(ctx context.Context) (int, error) {
+	if _, _, err := sourceTaxonomy.loadNow(); err != nil {
+		return 0, err
+	}
+
+	updated, err := s.db.ReclassifyResponseSources(ctx, classifySources)
+	if err != nil {
+		return 0, fmt.Errorf("failed to reclassify responses: %w", err)
+	}
+	return updated, nil
+}
+
+// updateSourceOverrides applies fn to the overrides file. The file is written
+// next to its final path and renamed, so readers never see half of it.
+func updateSourceOverrides(fn func(overrides map[string][]string)) error {
+	return sourceTaxonomy.update(func(paths []string) error {
+		if len(paths) != 2 {
+			return fmt.Errorf("no source taxonomy file configured")
+		}
+		path := paths[1]
+
+		var overrides models.SourceOverrides
+		if err := readYAMLFile(path, &overrides); err != nil {
+			return err
+		}
+		if overrides.Overrides == nil {
+			overrides.Overrides = make(map[string][]string)
+		}
+		fn(overrides.Overrides)
+
+		data, err := yaml.Marshal(&overrides)
+		if err != nil {
+			return fmt.Errorf("failed to encode source overrides: %w", err)
+		}
+		data = append([]byte("# Managed by gego: edit through the API or the source taxonomy file instead\n"), data...)
+
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return fmt.Errorf("failed to create config directory: %w", err)
+		}
+		tmp := path + ".tmp"
+		if err := os.WriteFile(tmp, data, 0644); err != nil {
+			return fmt.Errorf("failed to write source overrides: %w", err)
+		}
+		if err := os.Rename(tmp, path); err != nil {
+			os.Remove(tmp)
+			return fmt.Errorf("failed to write source overrides: %w", err)
+		}
+		return nil
+	})
+}
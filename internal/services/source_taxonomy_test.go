@@ -0,0 +1,106 @@
+package services
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/fissionx/gego/internal/llm"
+	"github.com/fissionx/gego/internal/models"
+	"github.com/fissionx/gego/internal/shared"
+)
+
+func TestSourceTaxonomy(t *testing.T) {
+	dir := t.TempDir()
+	taxonomyPath := filepath.Join(dir, "source_taxonomy.yaml")
+	SetSourceTaxonomyPath(taxonomyPath)
+	t.Cleanup(func() { SetSourceTaxonomyPath("") })
+	setConfigCheckInterval(t, 0)
+
+	// Without a taxonomy file only the default pack applies
+	for domain, want := range map[string][]string{
+		"www.g2.com":       {"review_site"},
+		"myg2.com":         {"company_website"},
+		"stanford.edu":     {"education"},
+		"hubspot.com":      {"company_website"},
+		"trustradius.com":  {"company_website"},
+		"en.wikipedia.org": {"reference"},
+	} {
+		if got := categorizeSource(domain); !reflect.DeepEqual(got, want) {
+			t.Errorf("categorizeSource(%s) = %v, want %v", domain, got, want)
+		}
+	}
+
+	err := os.WriteFile(taxonomyPath, []byte(`packs: [saas]
+rules:
+  - suffix: hubspot.com
+    category: vendor
+  - regex: '^blog\.'
+    category: blog
+  - suffix: g2.com
+    category: analyst
+`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for domain, want := range map[string][]string{
+		"blog.hubspot.com": {"vendor", "blog"},
+		"g2.com":           {"analyst", "review_site"},
+		"trustradius.com":  {"review_site"},
+	} {
+		if got := categorizeSource(domain); !reflect.DeepEqual(got, want) {
+			t.Errorf("categorizeSource(%s) = %v, want %v", domain, got, want)
+		}
+	}
+
+	ctx := context.Background()
+	database := newTestDB(t)
+	response := &models.Response{ID: "r1", PromptID: "p1", LLMID: "l1", Brand: "HubSpot"}
+	ApplyCitations(response, &llm.Response{GroundingSources: []string{"https://www.g2.com/crm", "https://example.com/"}})
+	if want := []string{"analyst", "company_website", "review_site"}; !reflect.DeepEqual(response.SourceCategories, want) {
+		t.Fatalf("SourceCategories = %v, want %v", response.SourceCategories, want)
+	}
+	if err := database.CreateResponse(ctx, response); err != nil {
+		t.Fatal(err)
+	}
+
+	service := NewSourceTaxonomyService(database)
+	if _, err := service.SetOverride(ctx, "example", []string{"review_site"}); err == nil {
+		t.Error("SetOverride() of an invalid domain succeeded")
+	}
+	updated, err := service.SetOverride(ctx, "WWW.Example.com", []string{"partner"})
+	if err != nil || updated != 1 {
+		t.Fatalf("SetOverride() = %d, %v", updated, err)
+	}
+	if got := categorizeSource("docs.example.com"); !reflect.DeepEqual(got, []string{"partner"}) {
+		t.Errorf("categorizeSource(docs.example.com) = %v after override", got)
+	}
+
+	responses, err := database.ListResponses(ctx, shared.ResponseFilter{SourceCategory: "partner"})
+	if err != nil || len(responses) != 1 {
+		t.Fatalf("ListResponses(partner) = %d responses, %v", len(responses), err)
+	}
+	if want := []string{"analyst", "partner", "review_site"}; !reflect.DeepEqual(responses[0].SourceCategories, want) {
+		t.Errorf("reclassified SourceCategories = %v, want %v", responses[0].SourceCategories, want)
+	}
+
+	if updated, err := service.Reclassify(ctx); err != nil || updated != 0 {
+		t.Errorf("Reclassify() without changes = %d, %v", updated, err)
+	}
+	if updated, err := service.DeleteOverride(ctx, "example.com"); err != nil || updated != 1 {
+		t.Errorf("DeleteOverride() = %d, %v", updated, err)
+	}
+
+	// An invalid file keeps the last valid classification
+	if err := os.WriteFile(taxonomyPath, []byte("packs: [unknown]\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got := categorizeSource("g2.com"); !reflect.DeepEqual(got, []string{"analyst", "review_site"}) {
+		t.Errorf("categorizeSource(g2.com) with an invalid file = %v", got)
+	}
+	if _, err := service.Taxonomy(); err == nil {
+		t.Error("Taxonomy() of an invalid file succeeded")
+	}
+}
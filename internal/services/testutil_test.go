@@ -0,0 +1,42 @@
+package services
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/fissionx/gego/internal/db"
+	"github.com/fissionx/gego/internal/logger"
+	"github.com/fissionx/gego/internal/models"
+)
+
+// TestMain initializes the logger before any worker goroutine uses it, as the
+// commands do
+func TestMain(m *testing.M) {
+	logger.Init(logger.WARNING, os.Stdout)
+	os.Exit(m.Run())
+}
+
+// newTestDB opens a migrated database on a temporary SQLite file and an in-memory document store
+func newTestDB(t *testing.T) *db.HybridDB {
+	t.Helper()
+	ctx := context.Background()
+
+	database, err := db.New(
+		&models.Config{Provider: "sqlite", URI: filepath.Join(t.TempDir(), "gego.db"), SkipSchemaCheck: true},
+		&models.Config{Provider: "sqlite", URI: ":memory:"},
+	)
+	if err != nil {
+		t.Fatalf("db.New() error = %v", err)
+	}
+	if err := database.Connect(ctx); err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	t.Cleanup(func() { database.Disconnect(ctx) })
+
+	if err := db.RunMigrations(ctx, database); err != nil {
+		t.Fatalf("RunMigrations() error = %v", err)
+	}
+	return database
+}
@@ -0,0 +1,766 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/robfig/cron/v3"
+	"gopkg.in/yaml.v3"
+
+	"github.com/fissionx/gego/internal/db"
+	"github.com/fissionx/gego/internal/llm/openaicompat"
+	"github.com/fissionx/gego/internal/models"
+)
+
+// Workspace record kinds
+const (
+	WorkspaceKindLLM           = "llm"
+	WorkspaceKindPrompt        = "prompt"
+	WorkspaceKindPromptLibrary = "prompt_library"
+	WorkspaceKindBrandProfile  = "brand_profile"
+	WorkspaceKindSchedule      = "schedule"
+)
+
+// WorkspaceService reconciles the databases with a declarative workspace file
+type WorkspaceService struct {
+	db db.Database
+}
+
+// NewWorkspaceService creates a new workspace service
+func NewWorkspaceService(database db.Database) *WorkspaceService {
+	return &WorkspaceService{db: database}
+}
+
+// WorkspacePlan lists the changes that bring the databases to a workspace
+type WorkspacePlan struct {
+	Changes []models.WorkspaceChange
+	writes  []func(ctx context.Context) error
+}
+
+// Count counts the changes of one action
+func (p *WorkspacePlan) Count(action string) int {
+	count := 0
+	for _, change := range p.Changes {
+		if change.Action == action {
+			count++
+		}
+	}
+	return count
+}
+
+// ReadWorkspace decodes a YAML or JSON workspace file, rejecting unknown fields.
+// Sections declared without records are kept as empty, rather than nil, slices.
+func ReadWorkspace(r io.Reader) (*models.Workspace, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read workspace: %w", err)
+	}
+
+	var workspace models.Workspace
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&workspace); err != nil {
+		if err == io.EOF {
+			return nil, fmt.Errorf("workspace file is empty")
+		}
+		return nil, fmt.Errorf("invalid workspace: %w", err)
+	}
+
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("invalid workspace: %w", err)
+	}
+	if len(root.Content) == 1 && root.Content[0].Kind == yaml.MappingNode {
+		mapping := root.Content[0].Content
+		for i := 0; i+1 < len(mapping); i += 2 {
+			switch mapping[i].Value {
+			case "llms":
+				if workspace.LLMs == nil {
+					workspace.LLMs = []models.WorkspaceLLM{}
+				}
+			case "prompts":
+				if workspace.Prompts == nil {
+					workspace.Prompts = []models.WorkspacePrompt{}
+				}
+			case "prompt_libraries":
+				if workspace.PromptLibraries == nil {
+					workspace.PromptLibraries = []models.WorkspacePromptLibrary{}
+				}
+			case "brand_profiles":
+				if workspace.BrandProfiles == nil {
+					workspace.BrandProfiles = []models.WorkspaceBrandProfile{}
+				}
+			case "schedules":
+				if workspace.Schedules == nil {
+					workspace.Schedules = []models.WorkspaceSchedule{}
+				}
+			}
+		}
+	}
+
+	return &workspace, nil
+}
+
+// Plan compares a workspace with the databases and returns the changes that
+// applying it makes. The whole workspace is checked first; nothing is written.
+func (s *WorkspaceService) Plan(ctx context.Context, workspace *models.Workspace) (*WorkspacePlan, error) {
+	p := &workspacePlanner{
+		plan:        &WorkspacePlan{},
+		db:          s.db,
+		llmIDs:      make(map[string]string),
+		promptIDs:   make(map[string]string),
+		llmNames:    make(map[string]string),
+		promptNames: make(map[string]string),
+		promptsByID: make(map[string]*models.Prompt),
+	}
+
+	existingLLMs, err := s.db.ListLLMs(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list LLMs: %w", err)
+	}
+	existingPrompts, err := s.db.ListPrompts(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list prompts: %w", err)
+	}
+	existingProfiles, err := s.db.ListBrandProfiles(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list brand profiles: %w", err)
+	}
+	existingLibraries, err := s.db.ListPromptLibraries(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list prompt libraries: %w", err)
+	}
+	existingSchedules, err := s.db.ListSchedules(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list schedules: %w", err)
+	}
+
+	// Schedules and libraries reference what the others become, so the
+	// referenced kinds are planned first
+	p.llms(workspace.LLMs, existingLLMs)
+	p.prompts(workspace.Prompts, existingPrompts)
+	p.brandProfiles(workspace.BrandProfiles, existingProfiles)
+	p.promptLibraries(workspace.PromptLibraries, existingLibraries)
+	p.schedules(workspace.Schedules, existingSchedules)
+
+	if len(p.problems) > 0 {
+		return nil, fmt.Errorf("workspace is invalid:\n  - %s", strings.Join(p.problems, "\n  - "))
+	}
+	return p.plan, nil
+}
+
+// Apply writes the changes of a plan. A failure midway leaves the changes
+// written so far.
+func (s *WorkspaceService) Apply(ctx context.Context, plan *WorkspacePlan) error {
+	for i, write := range plan.writes {
+		if err := write(ctx); err != nil {
+			change := plan.Changes[i]
+			return fmt.Errorf("failed to %s %s %q: %w", change.Action, strings.ReplaceAll(change.Kind, "_", " "), change.Name, err)
+		}
+	}
+	return nil
+}
+
+// workspacePlanner holds the state of one plan: the IDs that names resolve to
+// once the plan is applied, and the problems found so far
+type workspacePlanner struct {
+	plan     *WorkspacePlan
+	db       db.Database
+	problems []string
+
+	llmIDs      map[string]string // Name to ID, "" when several records share the name
+	promptIDs   map[string]string
+	llmNames    map[string]string // ID to name, for display
+	promptNames map[string]string
+	promptsByID map[string]*models.Prompt // Prompts as the plan leaves them, for checking template variables
+}
+
+func (p *workspacePlanner) problem(format string, args ...any) {
+	p.problems = append(p.problems, fmt.Sprintf(format, args...))
+}
+
+// add records a change and the write that makes it
+func (p *workspacePlanner) add(change models.WorkspaceChange, write func(ctx context.Context) error) {
+	p.plan.Changes = append(p.plan.Changes, change)
+	p.plan.writes = append(p.plan.writes, write)
+}
+
+// unique reports the names declared more than once in a section
+func (p *workspacePlanner) unique(kind string, names []string) {
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		if seen[name] {
+			p.problem("%s %q is declared more than once", kind, name)
+		}
+		seen[name] = true
+	}
+}
+
+// resolve returns the IDs of referenced names
+func (p *workspacePlanner) resolve(owner, kind string, names []string, ids map[string]string) []string {
+	resolved := make([]string, 0, len(names))
+	for _, name := range names {
+		id, ok := ids[name]
+		switch {
+		case !ok:
+			p.problem("%s references unknown %s %q", owner, kind, name)
+		case id == "":
+			p.problem("%s references %s %q, a name shared by several records", owner, kind, name)
+		default:
+			resolved = append(resolved, id)
+		}
+	}
+	return resolved
+}
+
+func (p *workspacePlanner) llms(declared []models.WorkspaceLLM, existing []*models.LLMConfig) {
+	byName := make(map[string][]*models.LLMConfig)
+	for _, llm := range existing {
+		byName[llm.Name] = append(byName[llm.Name], llm)
+		p.llmNames[llm.ID] = llm.Name
+	}
+
+	if declared == nil {
+		for name, llms := range byName {
+			p.llmIDs[name] = ""
+			if len(llms) == 1 {
+				p.llmIDs[name] = llms[0].ID
+			}
+		}
+		return
+	}
+
+	var names []string
+	for _, spec := range declared {
+		names = append(names, spec.Name)
+	}
+	p.unique("LLM", names)
+
+	declaredNames := make(map[string]bool, len(declared))
+	for _, spec := range declared {
+		declaredNames[spec.Name] = true
+		if !p.checkLLM(spec) {
+			continue
+		}
+
+		options := make(map[string]string)
+		for name, value := range spec.Headers {
+			options[openaicompat.HeaderOptionPrefix+name] = value
+		}
+		if spec.ModelsEndpoint != "" {
+			options[openaicompat.ModelsEndpointOption] = spec.ModelsEndpoint
+		}
+
+		matches := byName[spec.Name]
+		if len(matches) > 1 {
+			p.problem("%d LLMs are named %q: rename or delete all but one", len(matches), spec.Name)
+			continue
+		}
+
+		if len(matches) == 0 {
+			provider := FromString(spec.Provider)
+			if spec.APIKey == "" && provider != Ollama && provider != OpenAICompatible {
+				p.problem("LLM %q needs an api_key (env:VAR_NAME and file:/path references keep it out of the file)", spec.Name)
+				continue
+			}
+
+			llm := &models.LLMConfig{
+				ID:       uuid.New().String(),
+				Name:     spec.Name,
+				Provider: spec.Provider,
+				Model:    spec.Model,
+				APIKey:   spec.APIKey,
+				BaseURL:  spec.BaseURL,
+				Config:   options,
+				Enabled:  enabledOrDefault(spec.Enabled),
+			}
+			p.llmIDs[llm.Name] = llm.ID
+			p.llmNames[llm.ID] = llm.Name
+			p.add(models.WorkspaceChange{Kind: WorkspaceKindLLM, Name: llm.Name, Action: models.WorkspaceCreate}, func(ctx context.Context) error {
+				return p.db.CreateLLM(ctx, llm)
+			})
+			continue
+		}
+
+		current := matches[0]
+		p.llmIDs[current.Name] = current.ID
+		updated := *current
+		updated.Provider = spec.Provider
+		updated.Model = spec.Model
+		updated.BaseURL = spec.BaseURL
+		updated.Config = options
+		updated.Enabled = enabledOrDefault(spec.Enabled)
+		if spec.APIKey != "" {
+			updated.APIKey = spec.APIKey
+		}
+
+		var fields fieldChanges
+		fields.add("provider", current.Provider, updated.Provider)
+		fields.add("model", current.Model, updated.Model)
+		if updated.APIKey != current.APIKey {
+			fields = append(fields, models.FieldChange{Field: "api_key", Old: MaskAPIKey(current.APIKey), New: MaskAPIKey(updated.APIKey)})
+		}
+		fields.add("base_url", current.BaseURL, updated.BaseURL)
+		fields.add("options", formatOptions(current.Config), formatOptions(updated.Config))
+		fields.add("enabled", strconv.FormatBool(current.Enabled), strconv.FormatBool(updated.Enabled))
+
+		if len(fields) > 0 {
+			p.add(models.WorkspaceChange{Kind: WorkspaceKindLLM, Name: updated.Name, Action: models.WorkspaceUpdate, Fields: fields}, func(ctx context.Context) error {
+				return p.db.UpdateLLM(ctx, &updated)
+			})
+		}
+	}
+
+	for _, llm := range existing {
+		if declaredNames[llm.Name] || !llm.Enabled {
+			continue
+		}
+		disabled := *llm
+		disabled.Enabled = false
+		p.add(models.WorkspaceChange{Kind: WorkspaceKindLLM, Name: llm.Name, Action: models.WorkspaceDisable}, func(ctx context.Context) error {
+			return p.db.UpdateLLM(ctx, &disabled)
+		})
+	}
+}
+
+// checkLLM reports the problems of a declared LLM
+func (p *workspacePlanner) checkLLM(spec models.WorkspaceLLM) bool {
+	ok := true
+	if spec.Name == "" {
+		p.problem("an LLM has no name")
+		ok = false
+	}
+	if FromString(spec.Provider) == 0 {
+		var providers []string
+		for _, provider := range AllProviders() {
+			providers = append(providers, provider.String())
+		}
+		p.problem("LLM %q has unknown provider %q (use %s)", spec.Name, spec.Provider, strings.Join(providers, ", "))
+		ok = false
+	}
+	if spec.Model == "" {
+		p.problem("LLM %q has no model", spec.Name)
+		ok = false
+	}
+	return ok
+}
+
+func (p *workspacePlanner) prompts(declared []models.WorkspacePrompt, existing []*models.Prompt) {
+	byName := make(map[string][]*models.Prompt)
+	for _, prompt := range existing {
+		p.promptsByID[prompt.ID] = prompt
+		if prompt.Name != "" {
+			byName[prompt.Name] = append(byName[prompt.Name], prompt)
+			p.promptNames[prompt.ID] = prompt.Name
+		}
+	}
+
+	if declared == nil {
+		for name, prompts := range byName {
+			p.promptIDs[name] = ""
+			if len(prompts) == 1 {
+				p.promptIDs[name] = prompts[0].ID
+			}
+		}
+		return
+	}
+
+	var names []string
+	for _, spec := range declared {
+		names = append(names, spec.Name)
+	}
+	p.unique("prompt", names)
+
+	declaredNames := make(map[string]bool, len(declared))
+	for _, spec := range declared {
+		declaredNames[spec.Name] = true
+		if spec.Name == "" {
+			p.problem("a prompt has no name")
+			continue
+		}
+		if strings.TrimSpace(spec.Template) == "" {
+			p.problem("prompt %q has no template", spec.Name)
+			continue
+		}
+
+		matches := byName[spec.Name]
+		if len(matches) > 1 {
+			p.problem("%d prompts are named %q: rename or delete all but one", len(matches), spec.Name)
+			continue
+		}
+
+		if len(matches) == 0 {
+			prompt := &models.Prompt{
+				ID:       uuid.New().String(),
+				Name:     spec.Name,
+				Template: strings.TrimSpace(spec.Template),
+				Tags:     spec.Tags,
+				Category: spec.Category,
+				Domain:   spec.Domain,
+				Brand:    spec.Brand,
+				Enabled:  enabledOrDefault(spec.Enabled),
+			}
+			p.promptIDs[prompt.Name] = prompt.ID
+			p.promptNames[prompt.ID] = prompt.Name
+			p.promptsByID[prompt.ID] = prompt
+			p.add(models.WorkspaceChange{Kind: WorkspaceKindPrompt, Name: prompt.Name, Action: models.WorkspaceCreate}, func(ctx context.Context) error {
+				return p.db.CreatePrompt(ctx, prompt)
+			})
+			continue
+		}
+
+		current := matches[0]
+		p.promptIDs[current.Name] = current.ID
+		updated := *current
+		updated.Template = strings.TrimSpace(spec.Template)
+		updated.Tags = spec.Tags
+		updated.Category = spec.Category
+		updated.Domain = spec.Domain
+		updated.Brand = spec.Brand
+		updated.Enabled = enabledOrDefault(spec.Enabled)
+		p.promptsByID[updated.ID] = &updated
+
+		var fields fieldChanges
+		fields.add("template", current.Template, updated.Template)
+		fields.add("tags", formatList(current.Tags), formatList(updated.Tags))
+		fields.add("category", current.Category, updated.Category)
+		fields.add("domain", current.Domain, updated.Domain)
+		fields.add("brand", current.Brand, updated.Brand)
+		fields.add("enabled", strconv.FormatBool(current.Enabled), strconv.FormatBool(updated.Enabled))
+
+		if len(fields) > 0 {
+			p.add(models.WorkspaceChange{Kind: WorkspaceKindPrompt, Name: updated.Name, Action: models.WorkspaceUpdate, Fields: fields}, func(ctx context.Context) error {
+				return p.db.UpdatePrompt(ctx, &updated)
+			})
+		}
+	}
+
+	// Prompts created outside the workspace have no name and are disabled too
+	for _, prompt := range existing {
+		if (prompt.Name != "" && declaredNames[prompt.Name]) || !prompt.Enabled {
+			continue
+		}
+		name := prompt.Name
+		if name == "" {
+			name = truncateTemplate(prompt.Template)
+		}
+		disabled := *prompt
+		disabled.Enabled = false
+		p.add(models.WorkspaceChange{Kind: WorkspaceKindPrompt, Name: name, Action: models.WorkspaceDisable}, func(ctx context.Context) error {
+			return p.db.UpdatePrompt(ctx, &disabled)
+		})
+	}
+}
+
+func (p *workspacePlanner) brandProfiles(declared []models.WorkspaceBrandProfile, existing []*models.BrandProfile) {
+	byName := make(map[string]*models.BrandProfile)
+	for _, profile := range existing {
+		if byName[profile.BrandName] == nil {
+			byName[profile.BrandName] = profile
+		}
+	}
+
+	var names []string
+	for _, spec := range declared {
+		names = append(names, spec.BrandName)
+	}
+	p.unique("brand profile", names)
+
+	for _, spec := range declared {
+		if spec.BrandName == "" {
+			p.problem("a brand profile has no brand_name")
+			continue
+		}
+
+		desired := models.BrandProfile{
+			BrandName:        spec.BrandName,
+			Domain:           spec.Domain,
+			Category:         spec.Category,
+			Website:          spec.Website,
+			Description:      spec.Description,
+			Competitors:      spec.Competitors,
+			Aliases:          spec.Aliases,
+			ProductNames:     spec.ProductNames,
+			Domains:          spec.Domains,
+			NegativePatterns: spec.NegativePatterns,
+		}
+
+		current := byName[spec.BrandName]
+		if current == nil {
+			profile := desired
+			profile.ID = uuid.New().String()
+			p.add(models.WorkspaceChange{Kind: WorkspaceKindBrandProfile, Name: profile.BrandName, Action: models.WorkspaceCreate}, func(ctx context.Context) error {
+				return p.db.CreateBrandProfile(ctx, &profile)
+			})
+			continue
+		}
+
+		updated := desired
+		updated.ID = current.ID
+		updated.CreatedAt = current.CreatedAt
+
+		var fields fieldChanges
+		fields.add("domain", current.Domain, updated.Domain)
+		fields.add("category", current.Category, updated.Category)
+		fields.add("website", current.Website, updated.Website)
+		fields.add("description", current.Description, updated.Description)
+		fields.add("competitors", formatList(current.Competitors), formatList(updated.Competitors))
+		fields.add("aliases", formatList(current.Aliases), formatList(updated.Aliases))
+		fields.add("product_names", formatList(current.ProductNames), formatList(updated.ProductNames))
+		fields.add("domains", formatList(current.Domains), formatList(updated.Domains))
+		fields.add("negative_patterns", formatList(current.NegativePatterns), formatList(updated.NegativePatterns))
+
+		if len(fields) > 0 {
+			p.add(models.WorkspaceChange{Kind: WorkspaceKindBrandProfile, Name: updated.BrandName, Action: models.WorkspaceUpdate, Fields: fields}, func(ctx context.Context) error {
+				return p.db.UpdateBrandProfile(ctx, &updated)
+			})
+		}
+	}
+}
+
+func (p *workspacePlanner) promptLibraries(declared []models.WorkspacePromptLibrary, existing []*models.PromptLibrary) {
+	key := func(brand, domain, category string) string {
+		return brand + "/" + domain + "/" + category
+	}
+	byKey := make(map[string]*models.PromptLibrary)
+	for _, library := range existing {
+		k := key(library.Brand, library.Domain, library.Category)
+		if byKey[k] == nil {
+			byKey[k] = library
+		}
+	}
+
+	var keys []string
+	for _, spec := range declared {
+		keys = append(keys, key(spec.Brand, spec.Domain, spec.Category))
+	}
+	p.unique("prompt library", keys)
+
+	for _, spec := range declared {
+		name := key(spec.Brand, spec.Domain, spec.Category)
+		if spec.Domain == "" || spec.Category == "" {
+			p.problem("prompt library %q needs a domain and a category", name)
+			continue
+		}
+		promptIDs := p.resolve(fmt.Sprintf("prompt library %q", name), "prompt", spec.Prompts, p.promptIDs)
+
+		current := byKey[name]
+		if current == nil {
+			library := &models.PromptLibrary{
+				ID:        uuid.New().String(),
+				Brand:     spec.Brand,
+				Domain:    spec.Domain,
+				Category:  spec.Category,
+				PromptIDs: promptIDs,
+			}
+			p.add(models.WorkspaceChange{Kind: WorkspaceKindPromptLibrary, Name: name, Action: models.WorkspaceCreate}, func(ctx context.Context) error {
+				return p.db.CreatePromptLibrary(ctx, library)
+			})
+			continue
+		}
+
+		var fields fieldChanges
+		fields.add("prompts", p.formatRefs(current.PromptIDs, p.promptNames), p.formatRefs(promptIDs, p.promptNames))
+		if len(fields) > 0 {
+			updated := *current
+			updated.PromptIDs = promptIDs
+			p.add(models.WorkspaceChange{Kind: WorkspaceKindPromptLibrary, Name: name, Action: models.WorkspaceUpdate, Fields: fields}, func(ctx context.Context) error {
+				return p.db.UpdatePromptLibrary(ctx, &updated)
+			})
+		}
+	}
+}
+
+func (p *workspacePlanner) schedules(declared []models.WorkspaceSchedule, existing []*models.Schedule) {
+	if declared == nil {
+		return
+	}
+
+	byName := make(map[string][]*models.Schedule)
+	for _, schedule := range existing {
+		byName[schedule.Name] = append(byName[schedule.Name], schedule)
+	}
+
+	var names []string
+	for _, spec := range declared {
+		names = append(names, spec.Name)
+	}
+	p.unique("schedule", names)
+
+	declaredNames := make(map[string]bool, len(declared))
+	for _, spec := range declared {
+		declaredNames[spec.Name] = true
+		if spec.Name == "" {
+			p.problem("a schedule has no name")
+			continue
+		}
+		owner := fmt.Sprintf("schedule %q", spec.Name)
+
+		promptIDs := p.resolve(owner, "prompt", spec.Prompts, p.promptIDs)
+		llmIDs := p.resolve(owner, "LLM", spec.LLMs, p.llmIDs)
+		if len(spec.Prompts) == 0 || len(spec.LLMs) == 0 {
+			p.problem("%s needs at least one prompt and one LLM", owner)
+		}
+
+		cronExpr := strings.TrimSpace(spec.Cron)
+		if cronExpr == "" {
+			p.problem("%s has no cron expression", owner)
+		} else if _, err := cron.ParseStandard(cronExpr); err != nil {
+			p.problem("%s has an invalid cron expression %q: %v", owner, cronExpr, err)
+		}
+
+		temperature, err := parseWorkspaceTemperature(spec.Temperature)
+		if err != nil {
+			p.problem("%s: %v", owner, err)
+		}
+
+		if err := ValidateVariables(spec.Variables); err != nil {
+			p.problem("%s: %v", owner, err)
+		} else {
+			for _, id := range promptIDs {
+				prompt := p.promptsByID[id]
+				if err := ValidatePromptVariables(prompt.Template, prompt.Brand, spec.Variables); err != nil {
+					p.problem("%s: %v", owner, err)
+				}
+			}
+		}
+
+		matches := byName[spec.Name]
+		if len(matches) > 1 {
+			p.problem("%d schedules are named %q: rename or delete all but one", len(matches), spec.Name)
+			continue
+		}
+
+		desired := models.Schedule{
+			Name:        spec.Name,
+			PromptIDs:   promptIDs,
+			LLMIDs:      llmIDs,
+			CronExpr:    cronExpr,
+			Temperature: temperature,
+			Variables:   spec.Variables,
+			Enabled:     enabledOrDefault(spec.Enabled),
+		}
+
+		if len(matches) == 0 {
+			schedule := desired
+			schedule.ID = uuid.New().String()
+			p.add(models.WorkspaceChange{Kind: WorkspaceKindSchedule, Name: schedule.Name, Action: models.WorkspaceCreate}, func(ctx context.Context) error {
+				return p.db.CreateSchedule(ctx, &schedule)
+			})
+			continue
+		}
+
+		current := matches[0]
+		updated := *current
+		updated.PromptIDs = desired.PromptIDs
+		updated.LLMIDs = desired.LLMIDs
+		updated.CronExpr = desired.CronExpr
+		updated.Temperature = desired.Temperature
+		updated.Variables = desired.Variables
+		updated.Enabled = desired.Enabled
+
+		var fields fieldChanges
+		fields.add("prompts", p.formatRefs(current.PromptIDs, p.promptNames), p.formatRefs(updated.PromptIDs, p.promptNames))
+		fields.add("llms", p.formatRefs(current.LLMIDs, p.llmNames), p.formatRefs(updated.LLMIDs, p.llmNames))
+		fields.add("cron", current.CronExpr, updated.CronExpr)
+		fields.add("temperature", formatTemperature(current.Temperature), formatTemperature(updated.Temperature))
+		fields.add("variables", FormatVariables(current.Variables), FormatVariables(updated.Variables))
+		fields.add("enabled", strconv.FormatBool(current.Enabled), strconv.FormatBool(updated.Enabled))
+
+		if len(fields) > 0 {
+			p.add(models.WorkspaceChange{Kind: WorkspaceKindSchedule, Name: updated.Name, Action: models.WorkspaceUpdate, Fields: fields}, func(ctx context.Context) error {
+				return p.db.UpdateSchedule(ctx, &updated)
+			})
+		}
+	}
+
+	for _, schedule := range existing {
+		if declaredNames[schedule.Name] || !schedule.Enabled {
+			continue
+		}
+		disabled := *schedule
+		disabled.Enabled = false
+		p.add(models.WorkspaceChange{Kind: WorkspaceKindSchedule, Name: schedule.Name, Action: models.WorkspaceDisable}, func(ctx context.Context) error {
+			return p.db.UpdateSchedule(ctx, &disabled)
+		})
+	}
+}
+
+// formatRefs formats referenced IDs by name, keeping the IDs of unnamed records
+func (p *workspacePlanner) formatRefs(ids []string, names map[string]string) string {
+	formatted := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if name := names[id]; name != "" {
+			formatted = append(formatted, name)
+		} else {
+			formatted = append(formatted, id)
+		}
+	}
+	return formatList(formatted)
+}
+
+// fieldChanges collects the fields whose value changes
+type fieldChanges []models.FieldChange
+
+func (f *fieldChanges) add(field, oldValue, newValue string) {
+	if oldValue != newValue {
+		*f = append(*f, models.FieldChange{Field: field, Old: oldValue, New: newValue})
+	}
+}
+
+func formatList(values []string) string {
+	return strings.Join(values, ", ")
+}
+
+// formatOptions formats provider options as sorted name=value pairs
+func formatOptions(options map[string]string) string {
+	pairs := make([]string, 0, len(options))
+	for name, value := range options {
+		pairs = append(pairs, name+"="+value)
+	}
+	sort.Strings(pairs)
+	return formatList(pairs)
+}
+
+func formatTemperature(temperature float64) string {
+	if temperature == -1.0 {
+		return "random"
+	}
+	return strconv.FormatFloat(temperature, 'f', -1, 64)
+}
+
+// parseWorkspaceTemperature parses a schedule temperature, 0.7 when empty and
+// -1.0 for random
+func parseWorkspaceTemperature(value string) (float64, error) {
+	value = strings.TrimSpace(value)
+	switch strings.ToLower(value) {
+	case "":
+		return 0.7, nil
+	case "random":
+		return -1.0, nil
+	}
+
+	temperature, err := strconv.ParseFloat(value, 64)
+	if err != nil || temperature < 0.0 || temperature > 1.0 {
+		return 0, fmt.Errorf("invalid temperature %q (use a number between 0.0 and 1.0 or random)", value)
+	}
+	return temperature, nil
+}
+
+func truncateTemplate(template string) string {
+	if len(template) > 50 {
+		return template[:47] + "..."
+	}
+	return template
+}
+
+// enabledOrDefault reads an optional enabled flag, true when not set
+func enabledOrDefault(flag *bool) bool {
+	return flag == nil || *flag
+}
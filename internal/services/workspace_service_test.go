@@ -0,0 +1,206 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/fissionx/gego/internal/models"
+)
+
+const testWorkspace = `
+llms:
+  - name: local
+    provider: ollama
+    model: llama3
+prompts:
+  - name: best-crm
+    template: What are the best CRM tools?
+    tags: [crm]
+  - name: crm-pricing
+    template: How much does a CRM cost?
+prompt_libraries:
+  - brand: Acme
+    domain: acme.com
+    category: CRM
+    prompts: [best-crm]
+brand_profiles:
+  - brand_name: Acme
+    aliases: [Acme CRM]
+schedules:
+  - name: daily
+    prompts: [best-crm, crm-pricing]
+    llms: [local]
+    cron: "0 9 * * *"
+    temperature: 0.5
+`
+
+func planWorkspace(t *testing.T, service *WorkspaceService, content string) *WorkspacePlan {
+	t.Helper()
+	workspace, err := ReadWorkspace(strings.NewReader(content))
+	if err != nil {
+		t.Fatalf("ReadWorkspace() error = %v", err)
+	}
+	plan, err := service.Plan(context.Background(), workspace)
+	if err != nil {
+		t.Fatalf("Plan() error = %v", err)
+	}
+	return plan
+}
+
+func TestWorkspaceApply(t *testing.T) {
+	ctx := context.Background()
+	database := newTestDB(t)
+	service := NewWorkspaceService(database)
+
+	stray := &models.Prompt{ID: "stray", Template: "Stray prompt", Enabled: true}
+	if err := database.CreatePrompt(ctx, stray); err != nil {
+		t.Fatal(err)
+	}
+
+	plan := planWorkspace(t, service, testWorkspace)
+	if got := plan.Count(models.WorkspaceCreate); got != 6 {
+		t.Errorf("Count(create) = %d, want 6", got)
+	}
+	if got := plan.Count(models.WorkspaceDisable); got != 1 {
+		t.Errorf("Count(disable) = %d, want 1", got)
+	}
+	if err := service.Apply(ctx, plan); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+
+	if plan := planWorkspace(t, service, testWorkspace); len(plan.Changes) != 0 {
+		t.Errorf("re-plan changes = %+v, want none", plan.Changes)
+	}
+
+	stray, err := database.GetPrompt(ctx, "stray")
+	if err != nil || stray.Enabled {
+		t.Errorf("stray prompt = %+v, %v, want disabled", stray, err)
+	}
+	schedules, err := database.ListSchedules(ctx, nil)
+	if err != nil || len(schedules) != 1 || len(schedules[0].PromptIDs) != 2 || schedules[0].Temperature != 0.5 {
+		t.Fatalf("schedules = %+v, %v", schedules, err)
+	}
+
+	updated := strings.Replace(testWorkspace, "temperature: 0.5", "temperature: random", 1)
+	plan = planWorkspace(t, service, updated)
+	if len(plan.Changes) != 1 || plan.Changes[0].Action != models.WorkspaceUpdate || len(plan.Changes[0].Fields) != 1 {
+		t.Fatalf("update plan = %+v", plan.Changes)
+	}
+	if field := plan.Changes[0].Fields[0]; field.Field != "temperature" || field.Old != "0.5" || field.New != "random" {
+		t.Errorf("field change = %+v", field)
+	}
+
+	// A workspace without a schedules section leaves the schedule alone
+	partial := testWorkspace[:strings.Index(testWorkspace, "schedules:")]
+	if plan := planWorkspace(t, service, partial); len(plan.Changes) != 0 {
+		t.Errorf("partial plan changes = %+v, want none", plan.Changes)
+	}
+
+	// An empty one disables it
+	if plan := planWorkspace(t, service, partial+"schedules:\n"); plan.Count(models.WorkspaceDisable) != 1 {
+		t.Errorf("empty section plan = %+v, want one disable", plan.Changes)
+	}
+}
+
+func TestWorkspacePlanInvalid(t *testing.T) {
+	service := NewWorkspaceService(newTestDB(t))
+
+	workspace, err := ReadWorkspace(strings.NewReader(`
+schedules:
+  - name: daily
+    prompts: [missing]
+    cron: "bad"
+  - name: daily
+`))
+	if err != nil {
+		t.Fatalf("ReadWorkspace() error = %v", err)
+	}
+
+	_, err = service.Plan(context.Background(), workspace)
+	if err == nil {
+		t.Fatal("Plan() accepted an invalid workspace")
+	}
+	for _, want := range []string{`unknown prompt "missing"`, `invalid cron expression "bad"`, `"daily"`} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Plan() error = %v, want it to mention %s", err, want)
+		}
+	}
+
+	if _, err := ReadWorkspace(strings.NewReader("llm: []\n")); err == nil {
+		t.Error("ReadWorkspace() accepted an unknown section")
+	}
+}
+
+const variablesWorkspace = `
+llms:
+  - name: local
+    provider: ollama
+    model: llama3
+prompts:
+  - name: local-crm
+    template: Best CRM in {{city}} for {{brand}}?
+    brand: Acme
+schedules:
+  - name: cities
+    prompts: [local-crm]
+    llms: [local]
+    cron: "0 9 * * 1"
+    variables:
+      city: [Paris, Berlin]
+`
+
+func TestWorkspaceScheduleVariables(t *testing.T) {
+	ctx := context.Background()
+	database := newTestDB(t)
+	service := NewWorkspaceService(database)
+
+	if err := service.Apply(ctx, planWorkspace(t, service, variablesWorkspace)); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	schedules, err := database.ListSchedules(ctx, nil)
+	if err != nil || len(schedules) != 1 {
+		t.Fatalf("ListSchedules() = %+v, %v", schedules, err)
+	}
+	if got := FormatVariables(schedules[0].Variables); got != "city=Paris,Berlin" {
+		t.Errorf("schedule variables = %s, want city=Paris,Berlin", got)
+	}
+
+	// Changing a value set updates the schedule
+	updated := strings.Replace(variablesWorkspace, "[Paris, Berlin]", "[Paris, Berlin, Madrid]", 1)
+	plan := planWorkspace(t, service, updated)
+	if len(plan.Changes) != 1 || plan.Changes[0].Action != models.WorkspaceUpdate || len(plan.Changes[0].Fields) != 1 {
+		t.Fatalf("update plan = %+v", plan.Changes)
+	}
+	if field := plan.Changes[0].Fields[0]; field.Field != "variables" || field.Old != "city=Paris,Berlin" || field.New != "city=Paris,Berlin,Madrid" {
+		t.Errorf("field change = %+v", field)
+	}
+
+	tests := []struct {
+		name      string
+		variables string
+		want      string
+	}{
+		{"missing value set", "", "uses {{city}} without values"},
+		{"empty value", "\n    variables:\n      city: [Paris, \"\"]", "variable city has an empty value"},
+		{"invalid name", "\n    variables:\n      city: [Paris]\n      bad-name: [x]", `invalid variable name "bad-name"`},
+	}
+	head := variablesWorkspace[:strings.Index(variablesWorkspace, "\n    variables:")]
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			workspace, err := ReadWorkspace(strings.NewReader(head + tt.variables + "\n"))
+			if err != nil {
+				t.Fatalf("ReadWorkspace() error = %v", err)
+			}
+			_, err = service.Plan(ctx, workspace)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Plan() error = %v, want it to mention %s", err, tt.want)
+			}
+		})
+	}
+
+	// The plan of an invalid workspace writes nothing
+	if stored, err := database.ListSchedules(ctx, nil); err != nil || len(stored) != 1 || stored[0].Name != "cities" {
+		t.Errorf("schedules after invalid plans = %+v, %v", stored, err)
+	}
+}
@@ -0,0 +1,180 @@
+package shared
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/fissionx/gego/internal/models"
+)
+
+// BrandMatcher finds a brand and its aliases in text on word boundaries,
+// so "Apple" does not match "pineapple" and "HubSpot CRM" counts once
+type BrandMatcher struct {
+	terms     []string
+	pattern   *regexp.Regexp
+	negatives []*regexp.Regexp
+}
+
+// NewBrandMatcher creates a matcher for the given terms (brand name, aliases,
+// product names, domains). Matching is case-insensitive and longer terms win
+// over shorter ones. Matches overlapping a negative pattern are discarded;
+// negative patterns are case-insensitive regular expressions, and invalid ones
+// are treated as literal text.
+func NewBrandMatcher(terms []string, negativePatterns []string) *BrandMatcher {
+	matcher := &BrandMatcher{}
+
+	seen := make(map[string]bool)
+	for _, term := range terms {
+		term = strings.TrimSpace(term)
+		key := strings.ToLower(term)
+		if term == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		matcher.terms = append(matcher.terms, term)
+	}
+
+	for _, pattern := range negativePatterns {
+		pattern = strings.TrimSpace(pattern)
+		if pattern == "" {
+			continue
+		}
+		re, err := regexp.Compile("(?i)" + pattern)
+		if err != nil {
+			re = regexp.MustCompile("(?i)" + regexp.QuoteMeta(pattern))
+		}
+		matcher.negatives = append(matcher.negatives, re)
+	}
+
+	if len(matcher.terms) == 0 {
+		return matcher
+	}
+
+	sorted := make([]string, len(matcher.terms))
+	copy(sorted, matcher.terms)
+	sort.SliceStable(sorted, func(i, j int) bool { return len(sorted[i]) > len(sorted[j]) })
+
+	quoted := make([]string, len(sorted))
+	for i, term := range sorted {
+		quoted[i] = regexp.QuoteMeta(term)
+	}
+	matcher.pattern = regexp.MustCompile(`(?i)(?:` + strings.Join(quoted, "|") + `)`)
+
+	return matcher
+}
+
+// BrandMatcherForProfile builds a matcher from the brand name and the profile's
+// aliases, product names, domains and negative patterns. profile may be nil.
+func BrandMatcherForProfile(brand string, profile *models.BrandProfile) *BrandMatcher {
+	if profile == nil {
+		return NewBrandMatcher([]string{brand}, nil)
+	}
+
+	terms := []string{brand, profile.BrandName}
+	terms = append(terms, profile.Aliases...)
+	terms = append(terms, profile.ProductNames...)
+	terms = append(terms, profile.Domains...)
+	if profile.Website != "" {
+		terms = append(terms, ExtractDomainFromURL(profile.Website))
+	}
+
+	return NewBrandMatcher(terms, profile.NegativePatterns)
+}
+
+// Terms returns the distinct terms the matcher looks for
+func (m *BrandMatcher) Terms() []string {
+	if m == nil {
+		return nil
+	}
+	return m.terms
+}
+
+// Pattern returns a case-insensitive regex source matching any term, suitable
+// for a coarse database pre-filter. Word boundaries are not applied.
+func (m *BrandMatcher) Pattern() string {
+	if m == nil || len(m.terms) == 0 {
+		return ""
+	}
+	quoted := make([]string, len(m.terms))
+	for i, term := range m.terms {
+		quoted[i] = regexp.QuoteMeta(term)
+	}
+	return strings.Join(quoted, "|")
+}
+
+// FindAll returns the byte ranges of every match in text
+func (m *BrandMatcher) FindAll(text string) [][]int {
+	if m == nil || m.pattern == nil || text == "" {
+		return nil
+	}
+
+	var excluded [][]int
+	for _, re := range m.negatives {
+		excluded = append(excluded, re.FindAllStringIndex(text, -1)...)
+	}
+
+	var matches [][]int
+	for _, loc := range m.pattern.FindAllStringIndex(text, -1) {
+		if !isWordBoundary(text, loc[0], loc[1]) || overlapsAny(loc, excluded) {
+			continue
+		}
+		matches = append(matches, loc)
+	}
+	return matches
+}
+
+// Count returns the number of matches in text
+func (m *BrandMatcher) Count(text string) int {
+	return len(m.FindAll(text))
+}
+
+// Matches reports whether text contains one of the terms
+func (m *BrandMatcher) Matches(text string) bool {
+	return m.Count(text) > 0
+}
+
+// MatchedTerms returns the configured terms that were found in text, in term order
+func (m *BrandMatcher) MatchedTerms(text string) []string {
+	var found []string
+	seen := make(map[string]bool)
+	for _, loc := range m.FindAll(text) {
+		match := text[loc[0]:loc[1]]
+		for _, term := range m.terms {
+			if strings.EqualFold(term, match) && !seen[term] {
+				seen[term] = true
+				found = append(found, term)
+			}
+		}
+	}
+	return found
+}
+
+// isWordBoundary checks that the match is not glued to letters or digits on either side
+func isWordBoundary(text string, start, end int) bool {
+	if start > 0 {
+		r, _ := utf8.DecodeLastRuneInString(text[:start])
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return false
+		}
+	}
+	if end < len(text) {
+		r, _ := utf8.DecodeRuneInString(text[end:])
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// overlapsAny reports whether loc overlaps any of the given ranges
+func overlapsAny(loc []int, ranges [][]int) bool {
+	for _, r := range ranges {
+		if loc[0] < r[1] && r[0] < loc[1] {
+			return true
+		}
+	}
+	return false
+}
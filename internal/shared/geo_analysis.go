@@ -0,0 +1,151 @@
+package shared
+
+import (
+	"encoding/json"
+	"log"
+	"regexp"
+	"strings"
+
+	"github.com/fissionx/gego/internal/models"
+)
+
+var jsonBlockRegex = regexp.MustCompile("(?s)```(?:json)?\\s*(.+?)\\s*```")
+
+// GEOAnalysisResult represents the parsed GEO analysis from LLM
+type GEOAnalysisResult struct {
+	SearchAnswer string `json:"search_answer"`
+	GEOAnalysis  struct {
+		VisibilityScore    int      `json:"visibility_score"`
+		BrandMentioned     bool     `json:"brand_mentioned"`
+		InGroundingSources bool     `json:"in_grounding_sources"`
+		MentionStatus      string   `json:"mention_status"`
+		Reason             string   `json:"reason"`
+		Sentiment          string   `json:"sentiment"`
+		Competitors        []string `json:"competitors"`
+		Insights           []string `json:"insights"`
+		Actions            []string `json:"actions"`
+		CompetitorInfo     string   `json:"competitor_info"`
+	} `json:"geo_analysis"`
+
+	// Raw is the analyser output the result was parsed from
+	Raw string `json:"-"`
+}
+
+// ParseGEOAnalysis extracts and parses JSON from the LLM response
+func ParseGEOAnalysis(text string) *GEOAnalysisResult {
+	// Clean up the response - remove markdown code blocks if present
+	cleanedText := strings.TrimSpace(text)
+
+	// Remove markdown code block wrappers (```json ... ``` or ``` ... ```)
+	if matches := jsonBlockRegex.FindStringSubmatch(cleanedText); len(matches) > 1 {
+		cleanedText = strings.TrimSpace(matches[1])
+	} else {
+		// Try simple prefix/suffix removal
+		cleanedText = strings.TrimPrefix(cleanedText, "```json")
+		cleanedText = strings.TrimPrefix(cleanedText, "```")
+		cleanedText = strings.TrimSuffix(cleanedText, "```")
+		cleanedText = strings.TrimSpace(cleanedText)
+	}
+
+	// Try to find JSON object in the text if it's mixed with other content
+	if !strings.HasPrefix(cleanedText, "{") {
+		jsonStartIdx := strings.Index(cleanedText, "{")
+		jsonEndIdx := strings.LastIndex(cleanedText, "}")
+		if jsonStartIdx != -1 && jsonEndIdx != -1 && jsonEndIdx > jsonStartIdx {
+			cleanedText = cleanedText[jsonStartIdx : jsonEndIdx+1]
+		}
+	}
+
+	var result GEOAnalysisResult
+	if err := json.Unmarshal([]byte(cleanedText), &result); err != nil {
+		log.Printf("❌ Failed to parse GEO analysis JSON: %v", err)
+		log.Printf("Cleaned text (first 500 chars): %s", truncateForLog(cleanedText, 500))
+		return nil
+	}
+	result.Raw = text
+
+	log.Printf("✅ Parsed GEO: Score=%d, Mentioned=%v, Sentiment=%s, Competitors=%v",
+		result.GEOAnalysis.VisibilityScore,
+		result.GEOAnalysis.BrandMentioned,
+		result.GEOAnalysis.Sentiment,
+		result.GEOAnalysis.Competitors)
+
+	return &result
+}
+
+// ToModel converts the parsed analysis into the API model
+func (r *GEOAnalysisResult) ToModel() *models.GEOAnalysis {
+	if r == nil {
+		return nil
+	}
+
+	geo := r.GEOAnalysis
+	return &models.GEOAnalysis{
+		VisibilityScore:    geo.VisibilityScore,
+		BrandMentioned:     geo.BrandMentioned,
+		InGroundingSources: geo.InGroundingSources,
+		MentionStatus:      geo.MentionStatus,
+		Reason:             geo.Reason,
+		Insights:           geo.Insights,
+		Actions:            geo.Actions,
+		CompetitorInfo:     geo.CompetitorInfo,
+		Competitors:        geo.Competitors,
+		Sentiment:          geo.Sentiment,
+	}
+}
+
+// SplitCombinedAnswer parses the {"search_answer": ..., "geo_analysis": ...}
+// document that Google answers used to be stored as. It returns nil for any
+// other text, including answers that merely mention geo_analysis.
+func SplitCombinedAnswer(text string) *GEOAnalysisResult {
+	trimmed := strings.TrimSpace(text)
+	if !strings.HasPrefix(trimmed, "{") || !strings.Contains(trimmed, `"geo_analysis"`) {
+		return nil
+	}
+
+	var keys map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(trimmed), &keys); err != nil {
+		return nil
+	}
+	if _, ok := keys["search_answer"]; !ok {
+		return nil
+	}
+	if _, ok := keys["geo_analysis"]; !ok {
+		return nil
+	}
+
+	var result GEOAnalysisResult
+	if err := json.Unmarshal([]byte(trimmed), &result); err != nil {
+		return nil
+	}
+	result.Raw = text
+	return &result
+}
+
+// SplitResponseAnalysis moves the analysis out of a response stored with the
+// combined answer and analysis document as its text: the text becomes the
+// answer and the document its raw analysis. It reports whether the response
+// changed.
+func SplitResponseAnalysis(response *models.Response) bool {
+	if response == nil || response.AnalysisRaw != "" {
+		return false
+	}
+
+	result := SplitCombinedAnswer(response.ResponseText)
+	if result == nil {
+		return false
+	}
+
+	response.ResponseText = result.SearchAnswer
+	response.Analysis = result.ToModel()
+	response.AnalysisRaw = result.Raw
+	return true
+}
+
+// truncateForLog truncates a string for logging
+func truncateForLog(s string, maxLen int) string {
+	if len(s) <= maxLen {
+		return s
+	}
+	return s[:maxLen] + "..."
+}
@@ -0,0 +1,114 @@
+package shared
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/fissionx/gego/internal/models"
+)
+
+// Calendar periods stored on each response, in UTC
+const (
+	PeriodWeek    = "week"    // ISO 8601 week, e.g. 2025-W07
+	PeriodMonth   = "month"   // e.g. 2025-02
+	PeriodQuarter = "quarter" // e.g. 2025-Q1
+)
+
+// WeekString returns the ISO 8601 week of a time, e.g. 2025-W07. Weeks start
+// on Monday and the first week of a year holds its first Thursday.
+func WeekString(t time.Time) string {
+	year, week := t.UTC().ISOWeek()
+	return fmt.Sprintf("%d-W%02d", year, week)
+}
+
+// MonthString returns the month of a time, e.g. 2025-02
+func MonthString(t time.Time) string {
+	return t.UTC().Format("2006-01")
+}
+
+// QuarterString returns the quarter of a time, e.g. 2025-Q1
+func QuarterString(t time.Time) string {
+	t = t.UTC()
+	return fmt.Sprintf("%d-Q%d", t.Year(), (int(t.Month())-1)/3+1)
+}
+
+// PeriodString returns the week, month or quarter of a time
+func PeriodString(period string, t time.Time) (string, error) {
+	switch period {
+	case PeriodWeek:
+		return WeekString(t), nil
+	case PeriodMonth:
+		return MonthString(t), nil
+	case PeriodQuarter:
+		return QuarterString(t), nil
+	}
+	return "", fmt.Errorf("unknown period: %s", period)
+}
+
+// PeriodStart returns the start of the week, month or quarter of a time
+func PeriodStart(period string, t time.Time) (time.Time, error) {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	switch period {
+	case PeriodWeek:
+		// Days since Monday
+		return day.AddDate(0, 0, -(int(day.Weekday())+6)%7), nil
+	case PeriodMonth:
+		return day.AddDate(0, 0, 1-day.Day()), nil
+	case PeriodQuarter:
+		month := time.Month((int(t.Month())-1)/3*3 + 1)
+		return time.Date(t.Year(), month, 1, 0, 0, 0, 0, time.UTC), nil
+	}
+	return time.Time{}, fmt.Errorf("unknown period: %s", period)
+}
+
+// ParsePeriod returns the start of a week, month or quarter from its string,
+// e.g. 2025-W07
+func ParsePeriod(period, value string) (time.Time, error) {
+	var year, n int
+	switch period {
+	case PeriodWeek:
+		if _, err := fmt.Sscanf(value, "%d-W%d", &year, &n); err != nil || n < 1 || n > 53 {
+			return time.Time{}, fmt.Errorf("invalid week: %s", value)
+		}
+		// January 4th is always in the first week
+		firstWeek, _ := PeriodStart(PeriodWeek, time.Date(year, time.January, 4, 0, 0, 0, 0, time.UTC))
+		return firstWeek.AddDate(0, 0, (n-1)*7), nil
+	case PeriodMonth:
+		start, err := time.Parse("2006-01", value)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid month: %s", value)
+		}
+		return start, nil
+	case PeriodQuarter:
+		if _, err := fmt.Sscanf(value, "%d-Q%d", &year, &n); err != nil || n < 1 || n > 4 {
+			return time.Time{}, fmt.Errorf("invalid quarter: %s", value)
+		}
+		return time.Date(year, time.Month((n-1)*3+1), 1, 0, 0, 0, 0, time.UTC), nil
+	}
+	return time.Time{}, fmt.Errorf("unknown period: %s", period)
+}
+
+// PeriodEnd returns the start of the period following the one starting at start
+func PeriodEnd(period string, start time.Time) (time.Time, error) {
+	switch period {
+	case PeriodWeek:
+		return start.AddDate(0, 0, 7), nil
+	case PeriodMonth:
+		return start.AddDate(0, 1, 0), nil
+	case PeriodQuarter:
+		return start.AddDate(0, 3, 0), nil
+	}
+	return time.Time{}, fmt.Errorf("unknown period: %s", period)
+}
+
+// SetResponsePeriods sets the week, month and quarter of a response from its
+// creation time. It reports whether any of them changed.
+func SetResponsePeriods(response *models.Response) bool {
+	week, month, quarter := WeekString(response.CreatedAt), MonthString(response.CreatedAt), QuarterString(response.CreatedAt)
+	if response.Week == week && response.Month == month && response.Quarter == quarter {
+		return false
+	}
+	response.Week, response.Month, response.Quarter = week, month, quarter
+	return true
+}
@@ -0,0 +1,36 @@
+package shared
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParsePeriod(t *testing.T) {
+	tests := []struct {
+		period string
+		value  string
+		want   time.Time
+	}{
+		{PeriodWeek, "2025-W01", time.Date(2024, 12, 30, 0, 0, 0, 0, time.UTC)},
+		{PeriodWeek, "2026-W53", time.Date(2026, 12, 28, 0, 0, 0, 0, time.UTC)},
+		{PeriodMonth, "2025-02", time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{PeriodQuarter, "2025-Q3", time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParsePeriod(tt.period, tt.value)
+			if err != nil || !got.Equal(tt.want) {
+				t.Fatalf("ParsePeriod() = %v, %v, want %v", got, err, tt.want)
+			}
+			// The string of the start is the value parsed
+			if value, _ := PeriodString(tt.period, got); value != tt.value {
+				t.Errorf("PeriodString() = %s", value)
+			}
+		})
+	}
+
+	if _, err := ParsePeriod(PeriodQuarter, "2025-Q5"); err == nil {
+		t.Error("ParsePeriod() of an invalid quarter succeeded")
+	}
+}
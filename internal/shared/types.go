@@ -1,17 +1,77 @@
 package shared
 
 import (
+	"fmt"
+	"strings"
 	"time"
 )
 
 // ResponseFilter provides filtering options for listing responses
 type ResponseFilter struct {
-	PromptID   string
-	LLMID      string
-	ScheduleID string
-	Keyword    string
-	StartTime  *time.Time
-	EndTime    *time.Time
-	Limit      int
-	Offset     int
+	PromptID       string
+	PromptIDs      []string
+	LLMID          string
+	LLMIDs         []string
+	ScheduleID     string
+	CampaignID     string
+	Brand          string
+	Region         string
+	Language       string
+	LLMProvider    string
+	PromptType     string
+	SourceCategory string // Responses citing a source of this category
+	Keyword        string
+	StartTime      *time.Time
+	EndTime        *time.Time
+	Limit          int // 0 returns all matching responses
+	Offset         int
+}
+
+// ResponseGroup is the dimension response metrics are aggregated by
+type ResponseGroup string
+
+const (
+	GroupByNone      ResponseGroup = ""          // A single group with the totals
+	GroupByPrompt    ResponseGroup = "prompt"    // Grouped by prompt ID
+	GroupByLLM       ResponseGroup = "llm"       // Grouped by LLM provider and name
+	GroupByCategory  ResponseGroup = "category"  // Grouped by the category of the prompt
+	GroupBySentiment ResponseGroup = "sentiment" // Grouped by sentiment
+	GroupByWeek      ResponseGroup = "week"      // Grouped by the ISO week of the response
+	GroupByMonth     ResponseGroup = "month"     // Grouped by the month of the response
+	GroupByQuarter   ResponseGroup = "quarter"   // Grouped by the quarter of the response
+)
+
+// intervalGroupPrefix starts the groupings made by GroupByInterval
+const intervalGroupPrefix = "interval:"
+
+// GroupByInterval groups responses into consecutive intervals of the given
+// duration from origin, both to the second. Groups are keyed by the start of
+// their interval in RFC 3339.
+func GroupByInterval(origin time.Time, duration time.Duration) ResponseGroup {
+	return ResponseGroup(fmt.Sprintf("%s%d:%d", intervalGroupPrefix, origin.Unix(), int64(duration/time.Second)))
+}
+
+// Interval returns the origin and duration of a grouping made by
+// GroupByInterval, and false for any other grouping
+func (g ResponseGroup) Interval() (time.Time, time.Duration, bool) {
+	spec, ok := strings.CutPrefix(string(g), intervalGroupPrefix)
+	if !ok {
+		return time.Time{}, 0, false
+	}
+	var origin, seconds int64
+	if _, err := fmt.Sscanf(spec, "%d:%d", &origin, &seconds); err != nil || seconds <= 0 {
+		return time.Time{}, 0, false
+	}
+	return time.Unix(origin, 0).UTC(), time.Duration(seconds) * time.Second, true
+}
+
+// IntervalStart returns the start of the interval of the given duration from
+// origin that holds t
+func IntervalStart(origin time.Time, duration time.Duration, t time.Time) time.Time {
+	elapsed := t.Sub(origin)
+	n := elapsed / duration
+	if elapsed < 0 && elapsed%duration != 0 {
+		n--
+	}
+	return origin.Add(n * duration).UTC()
 }
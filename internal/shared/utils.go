@@ -2,6 +2,7 @@ package shared
 
 import (
 	"bufio"
+	"net/url"
 	"os"
 	"path/filepath"
 	"regexp"
@@ -181,3 +182,22 @@ func CountOccurrences(text, keyword string) int {
 
 	return count
 }
+
+// ExtractDomainFromURL extracts the domain from a URL
+func ExtractDomainFromURL(urlStr string) string {
+	// Handle URLs without scheme
+	if !strings.HasPrefix(urlStr, "http://") && !strings.HasPrefix(urlStr, "https://") {
+		urlStr = "https://" + urlStr
+	}
+
+	parsedURL, err := url.Parse(urlStr)
+	if err != nil {
+		return ""
+	}
+
+	// Get the hostname and remove www. prefix
+	domain := parsedURL.Hostname()
+	domain = strings.TrimPrefix(domain, "www.")
+
+	return domain
+}